@@ -12,6 +12,7 @@ require (
 	github.com/charmbracelet/colorprofile v0.4.3
 	github.com/charmbracelet/ultraviolet v0.0.0-20260422141423-a0f1f21775f7
 	github.com/charmbracelet/x/ansi v0.11.7
+	github.com/goccy/go-yaml v1.19.2
 	github.com/hashicorp/vault/api v1.23.0
 	github.com/lrstanley/bubbletint/chromatint/v2 v2.0.1
 	github.com/lrstanley/bubbletint/v2 v2.0.1
@@ -22,6 +23,7 @@ require (
 	github.com/lrstanley/x/logging/handlers v0.0.0-20260418212558-96c5cf679e1d
 	github.com/lrstanley/x/sync v0.0.0-20260418212558-96c5cf679e1d
 	github.com/lrstanley/x/text/fuzzy v0.0.0-20260418212558-96c5cf679e1d
+	github.com/lucasb-eyer/go-colorful v1.4.0
 	github.com/segmentio/ksuid v1.0.4
 	gopkg.in/natefinch/lumberjack.v2 v2.2.1
 )
@@ -37,7 +39,6 @@ require (
 	github.com/clipperhouse/uax29/v2 v2.7.0 // indirect
 	github.com/dlclark/regexp2 v1.12.0 // indirect
 	github.com/go-jose/go-jose/v4 v4.1.4 // indirect
-	github.com/goccy/go-yaml v1.19.2 // indirect
 	github.com/hashicorp/errwrap v1.1.0 // indirect
 	github.com/hashicorp/go-cleanhttp v0.5.2 // indirect
 	github.com/hashicorp/go-multierror v1.1.1 // indirect
@@ -48,7 +49,6 @@ require (
 	github.com/hashicorp/go-sockaddr v1.0.7 // indirect
 	github.com/hashicorp/hcl v1.0.1-vault-7 // indirect
 	github.com/lmittmann/tint v1.1.3 // indirect
-	github.com/lucasb-eyer/go-colorful v1.4.0 // indirect
 	github.com/mattn/go-runewidth v0.0.23 // indirect
 	github.com/mitchellh/go-homedir v1.1.0 // indirect
 	github.com/mitchellh/mapstructure v1.5.0 // indirect
@@ -0,0 +1,541 @@
+// Copyright (c) Liam Stanley <liam@liam.sh>. All rights reserved. Use of
+// this source code is governed by the MIT license that can be found in
+// the LICENSE file.
+
+package api
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"mime"
+	"net/http"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	vapi "github.com/hashicorp/vault/api"
+	"github.com/lrstanley/vex/internal/config"
+)
+
+// CassetteVersion is the version of the cassette format written by [Recorder].
+const CassetteVersion = 1
+
+// Redacted replaces all tokens and secret values in cassettes.
+const Redacted = "[redacted]"
+
+// ClientOption configures optional behavior of [NewClient], applied after the
+// default configuration (and transport stack) has been set up.
+type ClientOption func(cfg *vapi.Config)
+
+// WithRecorder records all requests made by the client (and their responses)
+// with the provided recorder.
+func WithRecorder(r *Recorder) ClientOption {
+	return func(cfg *vapi.Config) {
+		r.base = cfg.HttpClient.Transport
+		cfg.HttpClient.Transport = r
+	}
+}
+
+// WithReplayer serves all requests made by the client from the provided
+// replayer, rather than a Vault server.
+func WithReplayer(r *Replayer) ClientOption {
+	return func(cfg *vapi.Config) {
+		cfg.Error = nil
+		if r.header.Address != "" {
+			cfg.Address = r.header.Address
+		}
+		cfg.HttpClient.Transport = r
+	}
+}
+
+// cassetteHeader is the first line of a cassette.
+type cassetteHeader struct {
+	Version    int       `json:"version"`
+	AppVersion string    `json:"app_version"`
+	Address    string    `json:"address"`
+	RecordedAt time.Time `json:"recorded_at"`
+}
+
+// cassetteInteraction is a single request/response pair in a cassette, with all
+// tokens and secret values redacted.
+type cassetteInteraction struct {
+	Time         time.Time       `json:"time"`
+	Duration     string          `json:"duration"`
+	Method       string          `json:"method"`
+	Path         string          `json:"path"`
+	Query        string          `json:"query,omitempty"`
+	RequestBody  json.RawMessage `json:"request_body,omitempty"`
+	Status       int             `json:"status"`
+	ContentType  string          `json:"content_type,omitempty"`
+	ResponseBody json.RawMessage `json:"response_body,omitempty"`
+}
+
+// key is used to match requests to recorded interactions.
+func (i *cassetteInteraction) key() string {
+	return i.Method + " " + i.Path + "?" + i.Query
+}
+
+// Recorder is a [http.RoundTripper] which records all requests (and their
+// responses) to a cassette file, which can be served with [Replayer]. Tokens
+// are never recorded, and secret values are redacted, keeping only their keys.
+type Recorder struct {
+	base http.RoundTripper
+
+	mu      sync.Mutex
+	file    *os.File
+	started bool
+	mounts  map[string]int // Mount path to KV version, learned from mount listings.
+}
+
+// NewRecorder creates the cassette file at the provided path, which must not
+// already exist. Callers must call [Recorder.Close] when done.
+func NewRecorder(path string) (*Recorder, error) {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cassette: %w", err)
+	}
+	return &Recorder{base: http.DefaultTransport, file: f, mounts: map[string]int{}}, nil
+}
+
+// Close closes the cassette file.
+func (r *Recorder) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.file.Close()
+}
+
+// streamBody returns true if a request or response body shouldn't be buffered
+// for recording, and is instead streamed through untouched. This is the case for
+// raft snapshots (which can be very large), and any other body which isn't JSON,
+// as those are never recorded (see [redactBody]).
+func streamBody(path string, header http.Header) bool {
+	if strings.HasPrefix(path, "sys/storage/raft/snapshot") {
+		return true
+	}
+
+	ct := header.Get("Content-Type")
+	if ct == "" {
+		return false
+	}
+	mt, _, err := mime.ParseMediaType(ct)
+	return err != nil || (mt != "application/json" && !strings.HasSuffix(mt, "+json"))
+}
+
+// RoundTrip implements [http.RoundTripper].
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	path := strings.TrimPrefix(req.URL.Path, "/v1/")
+	list := req.Method == "LIST" || req.URL.Query().Get("list") == "true"
+
+	var reqBody []byte
+	if req.Body != nil && req.Body != http.NoBody && !streamBody(path, req.Header) {
+		var err error
+		reqBody, err = io.ReadAll(req.Body)
+		_ = req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		req.Body = io.NopCloser(bytes.NewReader(reqBody))
+	}
+
+	started := time.Now()
+	resp, err := r.base.RoundTrip(req)
+	if err != nil {
+		return resp, err
+	}
+
+	var respBody []byte
+	if !streamBody(path, resp.Header) {
+		respBody, err = io.ReadAll(resp.Body)
+		_ = resp.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		resp.Body = io.NopCloser(bytes.NewReader(respBody))
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if resp.StatusCode == http.StatusOK && (path == "sys/internal/ui/mounts" || path == "sys/mounts") {
+		r.learnMounts(respBody)
+	}
+
+	rule := r.rule(path, list)
+	interaction := &cassetteInteraction{
+		Time:         started.UTC(),
+		Duration:     time.Since(started).Round(time.Microsecond).String(),
+		Method:       req.Method,
+		Path:         req.URL.Path,
+		Query:        req.URL.Query().Encode(),
+		RequestBody:  redactBody(reqBody, rule, false),
+		Status:       resp.StatusCode,
+		ContentType:  resp.Header.Get("Content-Type"),
+		ResponseBody: redactBody(respBody, rule, true),
+	}
+
+	// Failing to record shouldn't affect the request itself.
+	if err = r.write(req, interaction); err != nil {
+		slog.Warn("failed to record request to cassette", "error", err) //nolint:sloglint
+	}
+	return resp, nil
+}
+
+// write appends the interaction to the cassette, preceded by the header if it's
+// the first interaction. Must be called with the lock held.
+func (r *Recorder) write(req *http.Request, interaction *cassetteInteraction) error {
+	enc := json.NewEncoder(r.file)
+
+	if !r.started {
+		err := enc.Encode(cassetteHeader{
+			Version:    CassetteVersion,
+			AppVersion: config.AppVersion,
+			Address:    req.URL.Scheme + "://" + req.URL.Host,
+			RecordedAt: interaction.Time,
+		})
+		if err != nil {
+			return err
+		}
+		r.started = true
+	}
+
+	return enc.Encode(interaction)
+}
+
+// learnMounts records the KV version of all mounts in a mount listing (from
+// either "sys/internal/ui/mounts" or "sys/mounts"), so secret paths can be told
+// apart from metadata. Must be called with the lock held.
+func (r *Recorder) learnMounts(body []byte) {
+	var resp struct {
+		Data struct {
+			Secret map[string]*vapi.MountOutput `json:"secret"`
+		} `json:"data"`
+	}
+	if json.Unmarshal(body, &resp) != nil {
+		return
+	}
+
+	mounts := resp.Data.Secret
+	if mounts == nil {
+		var data struct {
+			Data map[string]*vapi.MountOutput `json:"data"`
+		}
+		if json.Unmarshal(body, &data) != nil {
+			return
+		}
+		mounts = data.Data
+	}
+
+	for path, mount := range mounts {
+		if mount == nil {
+			continue
+		}
+		switch {
+		case mount.Type == "kv" && mount.Options["version"] == "2":
+			r.mounts[path] = 2
+		case mount.Type == "kv" || mount.Type == "generic" || mount.Type == "cubbyhole":
+			r.mounts[path] = 1
+		}
+	}
+}
+
+// redactRule describes what is redacted from the bodies of an interaction.
+type redactRule int
+
+const (
+	// redactKeys only redacts values of sensitive keys (e.g. tokens), for
+	// endpoints which don't contain secret values (e.g. mounts, or list results).
+	redactKeys redactRule = iota
+
+	// redactKVv2Data redacts the secret data of KVv2 "data/" endpoints, which is
+	// under "data" in requests, and "data.data" in responses.
+	redactKVv2Data
+
+	// redactAll redacts all values under "data" in responses, and all values in
+	// requests, used for KVv1 secrets and anything not known to be safe.
+	redactAll
+)
+
+// rule returns how the bodies of requests to the provided path (relative to
+// "/v1/") are redacted. Must be called with the lock held.
+func (r *Recorder) rule(path string, list bool) redactRule {
+	if list {
+		return redactKeys
+	}
+
+	// The input of audit hashes is usually a secret value (e.g. a token).
+	if strings.HasPrefix(path, "sys/audit-hash/") {
+		return redactAll
+	}
+
+	for _, prefix := range []string{"sys/", "auth/", "identity/"} {
+		if strings.HasPrefix(path, prefix) {
+			return redactKeys
+		}
+	}
+
+	var mount string
+	for m := range r.mounts {
+		if strings.HasPrefix(path, m) && len(m) > len(mount) {
+			mount = m
+		}
+	}
+
+	if mount == "" || r.mounts[mount] != 2 {
+		return redactAll
+	}
+
+	rest := strings.TrimPrefix(path, mount)
+	switch {
+	case strings.HasPrefix(rest, "data/"):
+		return redactKVv2Data
+	case rest == "config",
+		strings.HasPrefix(rest, "metadata/"),
+		strings.HasPrefix(rest, "delete/"),
+		strings.HasPrefix(rest, "undelete/"),
+		strings.HasPrefix(rest, "destroy/"):
+		return redactKeys
+	default:
+		return redactAll
+	}
+}
+
+// sensitiveKeys are keys whose values are always redacted, wherever they are in
+// a request or response body.
+var sensitiveKeys = []string{
+	"id",
+	"accessor",
+	"client_token",
+	"token",
+	"secret_id",
+	"entity_id",
+	"password",
+	"key",               // Unseal key shares.
+	"leader_client_key", // Raft join TLS keys.
+}
+
+// redactBody returns a redacted copy of a JSON request or response body. Bodies
+// which aren't JSON are dropped entirely, as their contents are unknown.
+func redactBody(body []byte, rule redactRule, response bool) json.RawMessage {
+	if len(bytes.TrimSpace(body)) == 0 {
+		return nil
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber()
+
+	var v any
+	if dec.Decode(&v) != nil {
+		return nil
+	}
+
+	v = redactKeysOf(v)
+
+	if obj, ok := v.(map[string]any); ok {
+		switch {
+		case rule == redactAll && !response:
+			v = redactValues(obj)
+		case rule == redactAll:
+			if _, ok := obj["data"]; ok {
+				obj["data"] = redactValues(obj["data"])
+			}
+		case rule == redactKVv2Data && !response:
+			if _, ok := obj["data"]; ok {
+				obj["data"] = redactValues(obj["data"])
+			}
+		case rule == redactKVv2Data:
+			if data, ok := obj["data"].(map[string]any); ok && data["data"] != nil {
+				data["data"] = redactValues(data["data"])
+			}
+		}
+	}
+
+	out, err := json.Marshal(v)
+	if err != nil {
+		return nil
+	}
+	return out
+}
+
+// redactKeysOf recursively redacts the values of [sensitiveKeys] in v.
+func redactKeysOf(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		for k, vv := range v {
+			if slices.Contains(sensitiveKeys, k) && vv != nil {
+				v[k] = Redacted
+				continue
+			}
+			v[k] = redactKeysOf(vv)
+		}
+	case []any:
+		for i := range v {
+			v[i] = redactKeysOf(v[i])
+		}
+	}
+	return v
+}
+
+// redactValues recursively redacts all values in v, keeping keys (and the shape
+// of v), so secret data can still be navigated.
+func redactValues(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		for k := range v {
+			v[k] = redactValues(v[k])
+		}
+		return v
+	case []any:
+		for i := range v {
+			v[i] = redactValues(v[i])
+		}
+		return v
+	case nil:
+		return nil
+	default:
+		return Redacted
+	}
+}
+
+// Replayer is a [http.RoundTripper] which serves all requests from a cassette
+// recorded with [Recorder], without making any network requests. Requests are
+// matched by method, path and query. When the same request was recorded more
+// than once, responses are served in the order they were recorded, repeating
+// the last one once exhausted (e.g. for periodic health checks).
+type Replayer struct {
+	header cassetteHeader
+
+	mu           sync.Mutex
+	interactions map[string][]*cassetteInteraction
+	served       map[string]int
+}
+
+// LoadCassette loads the cassette at the provided path, for replaying.
+func LoadCassette(path string) (*Replayer, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open cassette: %w", err)
+	}
+	defer f.Close()
+
+	r := &Replayer{
+		interactions: map[string][]*cassetteInteraction{},
+		served:       map[string]int{},
+	}
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 64*1024*1024)
+
+	for line := 1; scanner.Scan(); line++ {
+		if line == 1 {
+			if err = json.Unmarshal(scanner.Bytes(), &r.header); err != nil {
+				return nil, fmt.Errorf("failed to parse cassette header: %w", err)
+			}
+			if r.header.Version != CassetteVersion {
+				return nil, fmt.Errorf("unsupported cassette version: %d", r.header.Version)
+			}
+			continue
+		}
+
+		interaction := &cassetteInteraction{}
+		if err = json.Unmarshal(scanner.Bytes(), interaction); err != nil {
+			return nil, fmt.Errorf("failed to parse cassette line %d: %w", line, err)
+		}
+		r.interactions[interaction.key()] = append(r.interactions[interaction.key()], interaction)
+	}
+
+	if err = scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read cassette: %w", err)
+	}
+
+	if r.header.Version == 0 {
+		return nil, errors.New("cassette is empty")
+	}
+
+	return r, nil
+}
+
+// Address returns the address of the Vault server the cassette was recorded
+// against.
+func (r *Replayer) Address() string {
+	return r.header.Address
+}
+
+// RoundTrip implements [http.RoundTripper].
+func (r *Replayer) RoundTrip(req *http.Request) (*http.Response, error) {
+	var reqBody []byte
+	if req.Body != nil {
+		reqBody, _ = io.ReadAll(req.Body)
+		_ = req.Body.Close()
+	}
+
+	key := (&cassetteInteraction{
+		Method: req.Method,
+		Path:   req.URL.Path,
+		Query:  req.URL.Query().Encode(),
+	}).key()
+
+	// Requests which only differ by body (e.g. capability checks) prefer responses
+	// recorded with the same body. Bodies with redacted values never match, so
+	// those fall back to all responses for the request.
+	interactions := r.interactions[key]
+	if body := redactBody(reqBody, redactKeys, false); body != nil {
+		var matched []*cassetteInteraction
+		for _, interaction := range interactions {
+			if bytes.Equal(interaction.RequestBody, body) {
+				matched = append(matched, interaction)
+			}
+		}
+		if len(matched) > 0 {
+			interactions = matched
+			key += " " + string(body)
+		}
+	}
+
+	r.mu.Lock()
+	var interaction *cassetteInteraction
+	if len(interactions) > 0 {
+		i := r.served[key]
+		interaction = interactions[i]
+		if i < len(interactions)-1 {
+			r.served[key]++
+		}
+	}
+	r.mu.Unlock()
+
+	resp := &http.Response{
+		Proto:      "HTTP/1.1",
+		ProtoMajor: 1,
+		ProtoMinor: 1,
+		Header:     http.Header{},
+		Request:    req,
+	}
+
+	var body []byte
+	if interaction == nil {
+		// 501 responses aren't retried by the Vault client, unlike other 5xx errors.
+		resp.StatusCode = http.StatusNotImplemented
+		resp.Header.Set("Content-Type", "application/json")
+		body, _ = json.Marshal(vapi.ErrorResponse{Errors: []string{
+			"no recorded response for " + req.Method + " " + req.URL.Path,
+		}})
+	} else {
+		resp.StatusCode = interaction.Status
+		if interaction.ContentType != "" {
+			resp.Header.Set("Content-Type", interaction.ContentType)
+		}
+		body = interaction.ResponseBody
+	}
+
+	resp.Status = strconv.Itoa(resp.StatusCode) + " " + http.StatusText(resp.StatusCode)
+	resp.ContentLength = int64(len(body))
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	return resp, nil
+}
@@ -0,0 +1,175 @@
+// Copyright (c) Liam Stanley <liam@liam.sh>. All rights reserved. Use of
+// this source code is governed by the MIT license that can be found in
+// the LICENSE file.
+
+package api
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	vapi "github.com/hashicorp/vault/api"
+	"github.com/lrstanley/vex/internal/api/fakevault"
+	"github.com/lrstanley/vex/internal/types"
+)
+
+func TestCassette(t *testing.T) {
+	srv := newTestServer(t)
+	token := srv.AddToken(fakevault.Token{
+		DisplayName: "tester",
+		Rules: map[string][]types.ClientCapability{
+			"*": {types.CapabilityRead, types.CapabilityList, types.CapabilityCreate, types.CapabilityUpdate},
+		},
+	})
+	srv.PutSecret("secret/", "app/db", map[string]any{"password": "hunter2"})
+	srv.PutSecret("kv/", "app/api", map[string]any{"key": "s3cr3t"})
+	run[types.ClientSuccessMsg](t, newTestClient(t, srv, fakevault.RootToken).EnableAuditDevice("", "file", &vapi.EnableAuditOptions{
+		Type:    "file",
+		Options: map[string]string{"file_path": "stdout"},
+	}))
+
+	path := filepath.Join(t.TempDir(), "vex.cassette")
+
+	recorder, err := NewRecorder(path)
+	if err != nil {
+		t.Fatalf("failed to create recorder: %v", err)
+	}
+
+	c := newTestClient(t, srv, token, WithRecorder(recorder))
+	kv1, kv2 := getMount(t, c, "kv/"), getMount(t, c, "secret/")
+	run[types.ClientTokenLookupSelfMsg](t, c.TokenLookupSelf(""))
+	run[types.ClientGetSecretMsg](t, c.GetKVSecret("", kv2, "app/db", 0))
+	run[types.ClientGetSecretMsg](t, c.GetKVSecret("", kv1, "app/api", 0))
+	run[types.ClientListSecretsMsg](t, c.ListSecrets("", kv2, "app/"))
+	run[types.ClientSuccessMsg](t, c.PutKVSecret("", kv1, "app/new", map[string]any{"key": "n3w"}, -1))
+	run[types.ClientAuditHashMsg](t, c.AuditHash("", "file", "s.h4sh"))
+
+	if err = recorder.Close(); err != nil {
+		t.Fatalf("failed to close recorder: %v", err)
+	}
+
+	if _, err = NewRecorder(path); err == nil {
+		t.Fatal("expected existing cassette to not be overwritten")
+	}
+
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read cassette: %v", err)
+	}
+	for _, leak := range []string{token, "hunter2", "s3cr3t", "n3w", "s.h4sh"} {
+		if strings.Contains(string(raw), leak) {
+			t.Fatalf("cassette contains %q:\n%s", leak, raw)
+		}
+	}
+
+	// Replay entirely from the cassette, without a server.
+	srv.Close()
+
+	replayer, err := LoadCassette(path)
+	if err != nil {
+		t.Fatalf("failed to load cassette: %v", err)
+	}
+	if replayer.Address() != srv.URL() {
+		t.Fatalf("expected address %q, got %q", srv.URL(), replayer.Address())
+	}
+
+	c = newTestClient(t, srv, "", WithReplayer(replayer))
+	kv1, kv2 = getMount(t, c, "kv/"), getMount(t, c, "secret/")
+
+	lookup := run[types.ClientTokenLookupSelfMsg](t, c.TokenLookupSelf(""))
+	if lookup.Result.DisplayName != "tester" {
+		t.Fatalf("unexpected token lookup: %+v", lookup.Result)
+	}
+
+	for _, tt := range []struct {
+		mount *types.Mount
+		path  string
+		key   string
+	}{
+		{mount: kv2, path: "app/db", key: "password"},
+		{mount: kv1, path: "app/api", key: "key"},
+	} {
+		secret := run[types.ClientGetSecretMsg](t, c.GetKVSecret("", tt.mount, tt.path, 0))
+		if secret.Data[tt.key] != Redacted {
+			t.Fatalf("expected %s%s to be redacted, got %v", tt.mount.Path, tt.path, secret.Data)
+		}
+	}
+
+	list := run[types.ClientListSecretsMsg](t, c.ListSecrets("", kv2, "app/"))
+	if len(list.Values) != 1 || list.Values[0].Path != "app/db" {
+		t.Fatalf("unexpected list: %+v", list.Values)
+	}
+
+	err = runErr(t, c.GetKVSecret("", kv2, "app/missing", 0))
+	if !strings.Contains(err.Error(), "no recorded response") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+// roundTripFunc is a [http.RoundTripper] which calls itself.
+type roundTripFunc func(req *http.Request) (*http.Response, error)
+
+func (fn roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return fn(req)
+}
+
+func TestRecorderStreaming(t *testing.T) {
+	recorder, err := NewRecorder(filepath.Join(t.TempDir(), "vex.cassette"))
+	if err != nil {
+		t.Fatalf("failed to create recorder: %v", err)
+	}
+	t.Cleanup(func() { _ = recorder.Close() })
+
+	tests := []struct {
+		name        string
+		method      string
+		path        string
+		contentType string
+		reqStreamed bool
+		streamed    bool
+	}{
+		{name: "json", method: http.MethodGet, path: "/v1/sys/health", contentType: "application/json"},
+		{name: "snapshot-save", method: http.MethodGet, path: "/v1/sys/storage/raft/snapshot", reqStreamed: true, streamed: true},
+		{name: "snapshot-restore", method: http.MethodPost, path: "/v1/sys/storage/raft/snapshot-force", reqStreamed: true, streamed: true},
+		{name: "not-json", method: http.MethodGet, path: "/v1/sys/metrics", contentType: "text/plain; version=0.0.4", streamed: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reqBody := io.NopCloser(strings.NewReader("{}"))
+			respBody := io.NopCloser(strings.NewReader("{}"))
+
+			recorder.base = roundTripFunc(func(req *http.Request) (*http.Response, error) {
+				if got := req.Body == reqBody; got != tt.reqStreamed {
+					t.Errorf("expected request body streamed=%v, got %v", tt.reqStreamed, got)
+				}
+				rec := httptest.NewRecorder()
+				if tt.contentType != "" {
+					rec.Header().Set("Content-Type", tt.contentType)
+				}
+				resp := rec.Result()
+				resp.Body = respBody
+				return resp, nil
+			})
+
+			req := httptest.NewRequest(tt.method, "http://127.0.0.1:8200"+tt.path, nil)
+			req.Body = reqBody
+
+			resp, err := recorder.RoundTrip(req)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got := resp.Body == respBody; got != tt.streamed {
+				t.Fatalf("expected response body streamed=%v, got %v", tt.streamed, got)
+			}
+			if b, _ := io.ReadAll(resp.Body); string(b) != "{}" {
+				t.Fatalf("unexpected response body: %q", b)
+			}
+		})
+	}
+}
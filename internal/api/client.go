@@ -8,6 +8,7 @@ import (
 	"fmt"
 	"log/slog"
 	"net/http"
+	"strings"
 	"sync/atomic"
 	"time"
 
@@ -29,6 +30,10 @@ type client struct {
 	api  *vapi.Client
 	http *http.Client
 
+	// maxConcurrentRequests is the maximum number of in-flight requests, also
+	// used to bound goroutines for requests that fan out (e.g. per-secret).
+	maxConcurrentRequests int
+
 	firstHealthChecked atomic.Bool
 	health             types.AtomicExpires[vapi.HealthResponse]
 }
@@ -37,12 +42,24 @@ func (c *client) TokenType() types.TokenType {
 	return types.TokenTypeFromRaw(c.api.Token())
 }
 
-func NewClient(logger *slog.Logger, maxConcurrentRequests int) (types.Client, error) {
+func (c *client) Profile() string {
+	if ns := c.api.Namespace(); ns != "" {
+		return strings.TrimSuffix(c.api.Address(), "/") + "#" + strings.Trim(ns, "/")
+	}
+	return strings.TrimSuffix(c.api.Address(), "/")
+}
+
+func (c *client) ReadOnly() bool {
+	return false
+}
+
+func NewClient(logger *slog.Logger, maxConcurrentRequests int, opts ...ClientOption) (types.Client, error) {
 	c := &client{}
 
 	if maxConcurrentRequests <= 0 {
 		maxConcurrentRequests = 10
 	}
+	c.maxConcurrentRequests = maxConcurrentRequests
 
 	cfg := vapi.DefaultConfig()
 	cfg.MaxRetries = 5
@@ -66,6 +83,10 @@ func NewClient(logger *slog.Logger, maxConcurrentRequests int) (types.Client, er
 		Logger: logger,
 	}))
 
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
 	if cfg.Error != nil {
 		return nil, fmt.Errorf("failed to create vault client: %w", cfg.Error)
 	}
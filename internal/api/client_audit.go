@@ -0,0 +1,64 @@
+// Copyright (c) Liam Stanley <liam@liam.sh>. All rights reserved. Use of
+// this source code is governed by the MIT license that can be found in
+// the LICENSE file.
+
+package api
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	tea "charm.land/bubbletea/v2"
+	vapi "github.com/hashicorp/vault/api"
+	"github.com/lrstanley/vex/internal/types"
+)
+
+func (c *client) ListAuditDevices(uuid string) tea.Cmd {
+	return wrapHandler(uuid, func() (*types.ClientListAuditDevicesMsg, error) {
+		devices, err := c.api.Sys().ListAudit()
+		if err != nil {
+			return nil, fmt.Errorf("list audit devices: %w", err)
+		}
+
+		msg := &types.ClientListAuditDevicesMsg{Devices: make([]*vapi.Audit, 0, len(devices))}
+		for path, device := range devices {
+			if device.Path == "" {
+				device.Path = path
+			}
+			msg.Devices = append(msg.Devices, device)
+		}
+		slices.SortFunc(msg.Devices, func(a, b *vapi.Audit) int {
+			return strings.Compare(a.Path, b.Path)
+		})
+		return msg, nil
+	})
+}
+
+func (c *client) EnableAuditDevice(uuid, path string, options *vapi.EnableAuditOptions) tea.Cmd {
+	return wrapHandler(uuid, func() (*types.ClientSuccessMsg, error) {
+		if err := c.api.Sys().EnableAuditWithOptions(path, options); err != nil {
+			return nil, fmt.Errorf("enable audit device: %w", err)
+		}
+		return &types.ClientSuccessMsg{Message: "audit device enabled"}, nil
+	})
+}
+
+func (c *client) DisableAuditDevice(uuid, path string) tea.Cmd {
+	return wrapHandler(uuid, func() (*types.ClientSuccessMsg, error) {
+		if err := c.api.Sys().DisableAudit(path); err != nil {
+			return nil, fmt.Errorf("disable audit device: %w", err)
+		}
+		return &types.ClientSuccessMsg{Message: "audit device disabled"}, nil
+	})
+}
+
+func (c *client) AuditHash(uuid, path, input string) tea.Cmd {
+	return wrapHandler(uuid, func() (*types.ClientAuditHashMsg, error) {
+		hash, err := c.api.Sys().AuditHash(path, input)
+		if err != nil {
+			return nil, fmt.Errorf("audit hash: %w", err)
+		}
+		return &types.ClientAuditHashMsg{Path: path, Hash: hash}, nil
+	})
+}
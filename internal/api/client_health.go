@@ -31,6 +31,40 @@ func (c *client) GetHealth(uuid string) tea.Cmd {
 	})
 }
 
+// nodeHealthTimeout is the timeout for health checks of individual nodes, which
+// aren't retried, so unreachable nodes are reported quickly.
+const nodeHealthTimeout = 2 * time.Second
+
+func (c *client) GetNodeHealth(uuid, address string) tea.Cmd {
+	return wrapHandler(uuid, func() (*types.ClientNodeHealthMsg, error) {
+		msg := &types.ClientNodeHealthMsg{Address: address}
+
+		node, err := c.api.Clone()
+		if err == nil {
+			err = node.SetAddress(address)
+		}
+		if err != nil {
+			msg.Error = err.Error()
+			return msg, nil
+		}
+
+		// sys/health is only available in the root namespace.
+		node.ClearNamespace()
+		node.SetMaxRetries(0)
+		node.SetClientTimeout(nodeHealthTimeout)
+
+		start := time.Now()
+		msg.Health, err = node.Sys().Health()
+		msg.Latency = time.Since(start)
+
+		if err != nil {
+			msg.Health = nil
+			msg.Error = err.Error()
+		}
+		return msg, nil
+	})
+}
+
 func (c *client) GetConfigState(uuid string) tea.Cmd {
 	return wrapHandler(uuid, func() (*types.ClientConfigStateMsg, error) {
 		// No Go client method for this.
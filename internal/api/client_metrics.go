@@ -0,0 +1,96 @@
+// Copyright (c) Liam Stanley <liam@liam.sh>. All rights reserved. Use of
+// this source code is governed by the MIT license that can be found in
+// the LICENSE file.
+
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/lrstanley/vex/internal/types"
+)
+
+// metricsTimestampLayout is the layout of the timestamp of the metrics summary.
+const metricsTimestampLayout = "2006-01-02 15:04:05 -0700 MST"
+
+// metricsSummary is the JSON format of sys/metrics, which is the in-memory sink
+// of go-metrics. Unlike the Prometheus format, it's available regardless of the
+// telemetry configuration of the server.
+type metricsSummary struct {
+	Timestamp string `json:"Timestamp"`
+	Gauges    []struct {
+		Name   string            `json:"Name"`
+		Value  float64           `json:"Value"`
+		Labels map[string]string `json:"Labels"`
+	} `json:"Gauges"`
+	Counters []metricsSampledValue `json:"Counters"`
+	Samples  []metricsSampledValue `json:"Samples"`
+}
+
+type metricsSampledValue struct {
+	Name   string            `json:"Name"`
+	Count  int               `json:"Count"`
+	Rate   float64           `json:"Rate"`
+	Sum    float64           `json:"Sum"`
+	Min    float64           `json:"Min"`
+	Max    float64           `json:"Max"`
+	Mean   float64           `json:"Mean"`
+	Labels map[string]string `json:"Labels"`
+}
+
+func (v *metricsSampledValue) metric(kind types.MetricKind) *types.Metric {
+	return &types.Metric{
+		Kind:   kind,
+		Name:   v.Name,
+		Labels: v.Labels,
+		Count:  v.Count,
+		Rate:   v.Rate,
+		Sum:    v.Sum,
+		Min:    v.Min,
+		Max:    v.Max,
+		Mean:   v.Mean,
+	}
+}
+
+func (s *metricsSummary) msg() *types.ClientMetricsMsg {
+	msg := &types.ClientMetricsMsg{
+		Metrics: make([]*types.Metric, 0, len(s.Gauges)+len(s.Counters)+len(s.Samples)),
+	}
+	msg.Timestamp, _ = time.Parse(metricsTimestampLayout, s.Timestamp)
+
+	for _, g := range s.Gauges {
+		msg.Metrics = append(msg.Metrics, &types.Metric{
+			Kind:   types.MetricGauge,
+			Name:   g.Name,
+			Labels: g.Labels,
+			Value:  g.Value,
+		})
+	}
+	for i := range s.Counters {
+		msg.Metrics = append(msg.Metrics, s.Counters[i].metric(types.MetricCounter))
+	}
+	for i := range s.Samples {
+		msg.Metrics = append(msg.Metrics, s.Samples[i].metric(types.MetricSample))
+	}
+	return msg
+}
+
+func (c *client) GetMetrics(uuid string) tea.Cmd {
+	return wrapHandler(uuid, func() (*types.ClientMetricsMsg, error) {
+		// No Go client method for this.
+		summary, err := request[metricsSummary](
+			c,
+			http.MethodGet,
+			"/v1/sys/metrics",
+			nil,
+			nil,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("get metrics: %w", err)
+		}
+		return summary.msg(), nil
+	})
+}
@@ -0,0 +1,51 @@
+// Copyright (c) Liam Stanley <liam@liam.sh>. All rights reserved. Use of
+// this source code is governed by the MIT license that can be found in
+// the LICENSE file.
+
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/lrstanley/vex/internal/types"
+)
+
+// passwordPolicyPath returns the path used to generate a password from the
+// provided policy, escaping the policy name so it can't reference other paths.
+func passwordPolicyPath(policy string) (string, error) {
+	if policy == "" || policy == "." || policy == ".." {
+		return "", fmt.Errorf("invalid password policy name %q", policy)
+	}
+	return "sys/policies/password/" + url.PathEscape(policy) + "/generate", nil
+}
+
+func (c *client) GeneratePassword(uuid, policy string) tea.Cmd {
+	return wrapHandler(uuid, func() (*types.ClientGeneratePasswordMsg, error) {
+		path, err := passwordPolicyPath(policy)
+		if err != nil {
+			return nil, fmt.Errorf("generate password: %w", err)
+		}
+
+		secret, err := c.api.Logical().Read(path)
+		if err != nil {
+			return nil, fmt.Errorf("generate password from policy %s: %w", policy, err)
+		}
+
+		if secret == nil || secret.Data == nil {
+			return nil, fmt.Errorf("generate password from policy %s: %w", policy, errors.New("empty response"))
+		}
+
+		password, ok := secret.Data["password"].(string)
+		if !ok || password == "" {
+			return nil, fmt.Errorf("generate password from policy %s: %w", policy, errors.New("no password in response"))
+		}
+
+		return &types.ClientGeneratePasswordMsg{
+			Policy:   policy,
+			Password: password,
+		}, nil
+	})
+}
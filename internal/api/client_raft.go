@@ -5,11 +5,15 @@
 package api
 
 import (
+	"errors"
+	"fmt"
+	"io"
 	"net/http"
 	"slices"
 	"strings"
 
 	tea "charm.land/bubbletea/v2"
+	vapi "github.com/hashicorp/vault/api"
 	"github.com/lrstanley/vex/internal/types"
 )
 
@@ -50,3 +54,73 @@ func (c *client) RemoveRaftPeer(uuid, serverID string) tea.Cmd {
 		return &types.ClientSuccessMsg{Message: "raft peer removed"}, nil
 	})
 }
+
+// errAutopilotUnavailable is returned when autopilot isn't available, as the
+// Vault server doesn't use integrated storage.
+var errAutopilotUnavailable = errors.New("autopilot unavailable (integrated storage not in use)")
+
+func (c *client) GetRaftAutopilotState(uuid string) tea.Cmd {
+	return wrapHandler(uuid, func() (*types.ClientRaftAutopilotStateMsg, error) {
+		state, err := c.api.Sys().RaftAutopilotState()
+		if err != nil {
+			return nil, fmt.Errorf("get autopilot state: %w", err)
+		}
+		if state == nil {
+			return nil, fmt.Errorf("get autopilot state: %w", errAutopilotUnavailable)
+		}
+		return &types.ClientRaftAutopilotStateMsg{State: state}, nil
+	})
+}
+
+func (c *client) GetRaftAutopilotConfig(uuid string) tea.Cmd {
+	return wrapHandler(uuid, func() (*types.ClientRaftAutopilotConfigMsg, error) {
+		config, err := c.api.Sys().RaftAutopilotConfiguration()
+		if err != nil {
+			return nil, fmt.Errorf("get autopilot config: %w", err)
+		}
+		if config == nil {
+			return nil, fmt.Errorf("get autopilot config: %w", errAutopilotUnavailable)
+		}
+		return &types.ClientRaftAutopilotConfigMsg{Config: config}, nil
+	})
+}
+
+func (c *client) PutRaftAutopilotConfig(uuid string, config *vapi.AutopilotConfig) tea.Cmd {
+	return wrapHandler(uuid, func() (*types.ClientSuccessMsg, error) {
+		if err := c.api.Sys().PutRaftAutopilotConfiguration(config); err != nil {
+			return nil, fmt.Errorf("update autopilot config: %w", err)
+		}
+		return &types.ClientSuccessMsg{Message: "autopilot config updated"}, nil
+	})
+}
+
+func (c *client) SaveRaftSnapshot(uuid string, w io.Writer) tea.Cmd {
+	return wrapHandler(uuid, func() (*types.ClientSuccessMsg, error) {
+		if err := c.api.Sys().RaftSnapshot(w); err != nil {
+			return nil, fmt.Errorf("save raft snapshot: %w", err)
+		}
+		return &types.ClientSuccessMsg{Message: "raft snapshot saved"}, nil
+	})
+}
+
+func (c *client) RestoreRaftSnapshot(uuid string, r io.Reader, force bool) tea.Cmd {
+	return wrapHandler(uuid, func() (*types.ClientSuccessMsg, error) {
+		if err := c.api.Sys().RaftSnapshotRestore(r, force); err != nil {
+			return nil, fmt.Errorf("restore raft snapshot: %w", err)
+		}
+		return &types.ClientSuccessMsg{Message: "raft snapshot restored"}, nil
+	})
+}
+
+func (c *client) JoinRaft(uuid string, req *vapi.RaftJoinRequest) tea.Cmd {
+	return wrapHandler(uuid, func() (*types.ClientSuccessMsg, error) {
+		resp, err := c.api.Sys().RaftJoin(req)
+		if err != nil {
+			return nil, fmt.Errorf("join raft cluster: %w", err)
+		}
+		if !resp.Joined {
+			return nil, fmt.Errorf("join raft cluster: %w", errors.New("node did not join the cluster"))
+		}
+		return &types.ClientSuccessMsg{Message: "joined raft cluster"}, nil
+	})
+}
@@ -0,0 +1,87 @@
+// Copyright (c) Liam Stanley <liam@liam.sh>. All rights reserved. Use of
+// this source code is governed by the MIT license that can be found in
+// the LICENSE file.
+
+package api
+
+import (
+	"fmt"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/lrstanley/vex/internal/types"
+)
+
+func (c *client) GetSealStatus(uuid string) tea.Cmd {
+	return wrapHandler(uuid, func() (*types.ClientSealStatusMsg, error) {
+		status, err := c.api.Sys().SealStatus()
+		if err != nil {
+			return nil, fmt.Errorf("get seal status: %w", err)
+		}
+
+		msg := &types.ClientSealStatusMsg{Status: status}
+		if status.Sealed {
+			// Everything else requires an unsealed node.
+			return msg, nil
+		}
+
+		msg.Leader, err = c.api.Sys().Leader()
+		if err != nil {
+			return nil, fmt.Errorf("get leader: %w", err)
+		}
+
+		caps, err := c.getCapabilities("sys/seal", "sys/step-down")
+		if err != nil {
+			return nil, fmt.Errorf("get capabilities: %w", err)
+		}
+		msg.SealCapabilities = caps["sys/seal"]
+		msg.StepDownCapabilities = caps["sys/step-down"]
+		return msg, nil
+	})
+}
+
+func (c *client) Unseal(uuid, key string) tea.Cmd {
+	return wrapHandler(uuid, func() (*types.ClientSuccessMsg, error) {
+		status, err := c.api.Sys().Unseal(key)
+		if err != nil {
+			return nil, fmt.Errorf("unseal: %w", err)
+		}
+		c.health.Set(nil, 0)
+
+		if !status.Sealed {
+			return &types.ClientSuccessMsg{Message: "unsealed"}, nil
+		}
+		return &types.ClientSuccessMsg{
+			Message: fmt.Sprintf("unseal key accepted (%d/%d)", status.Progress, status.T),
+		}, nil
+	})
+}
+
+func (c *client) ResetUnseal(uuid string) tea.Cmd {
+	return wrapHandler(uuid, func() (*types.ClientSuccessMsg, error) {
+		_, err := c.api.Sys().ResetUnsealProcess()
+		if err != nil {
+			return nil, fmt.Errorf("reset unseal: %w", err)
+		}
+		return &types.ClientSuccessMsg{Message: "unseal progress reset"}, nil
+	})
+}
+
+func (c *client) Seal(uuid string) tea.Cmd {
+	return wrapHandler(uuid, func() (*types.ClientSuccessMsg, error) {
+		if err := c.api.Sys().Seal(); err != nil {
+			return nil, fmt.Errorf("seal: %w", err)
+		}
+		c.health.Set(nil, 0)
+		return &types.ClientSuccessMsg{Message: "sealed"}, nil
+	})
+}
+
+func (c *client) StepDown(uuid string) tea.Cmd {
+	return wrapHandler(uuid, func() (*types.ClientSuccessMsg, error) {
+		if err := c.api.Sys().StepDown(); err != nil {
+			return nil, fmt.Errorf("step down: %w", err)
+		}
+		c.health.Set(nil, 0)
+		return &types.ClientSuccessMsg{Message: "stepped down"}, nil
+	})
+}
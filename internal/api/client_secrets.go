@@ -8,10 +8,12 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"net/http"
 	"slices"
 	"strings"
 	"sync"
 	"sync/atomic"
+	"time"
 
 	tea "charm.land/bubbletea/v2"
 	vapi "github.com/hashicorp/vault/api"
@@ -322,6 +324,133 @@ func (c *client) GetKVv2Metadata(uuid string, mount *types.Mount, path string) t
 	})
 }
 
+func (c *client) PutKVv2Metadata(uuid string, mount *types.Mount, path string, input *types.KVv2MetadataInput) tea.Cmd {
+	return wrapHandler(uuid, func() (*types.ClientSuccessMsg, error) {
+		if mount.KVVersion() != 2 {
+			return nil, fmt.Errorf("put secret metadata: %w", errors.New("mount is not a kv v2 mount"))
+		}
+
+		// Writing to the metadata endpoint only updates the fields which are
+		// provided, however custom_metadata is always replaced as a whole.
+		data := map[string]any{}
+		if input.MaxVersions != nil {
+			data["max_versions"] = *input.MaxVersions
+		}
+		if input.CASRequired != nil {
+			data["cas_required"] = *input.CASRequired
+		}
+		if input.DeleteVersionAfter != nil {
+			data["delete_version_after"] = input.DeleteVersionAfter.String()
+		}
+		if input.CustomMetadata != nil {
+			data["custom_metadata"] = input.CustomMetadata
+		}
+
+		_, err := c.api.Logical().Write(strings.TrimSuffix(mount.Path, "/")+"/metadata/"+path, data)
+		if err != nil {
+			return nil, fmt.Errorf("put secret metadata: %w", err)
+		}
+		return &types.ClientSuccessMsg{Message: "updated secret metadata"}, nil
+	})
+}
+
+func (c *client) ListKVv2Metadata(uuid string, mount *types.Mount, paths ...string) tea.Cmd {
+	return wrapHandler(uuid, func() (*types.ClientListKVv2MetadataMsg, error) {
+		if mount.KVVersion() != 2 {
+			return nil, fmt.Errorf("list secret metadata: %w", errors.New("mount is not a kv v2 mount"))
+		}
+
+		var mu sync.Mutex
+		wg := conc.NewGroup().WithMaxGoroutines(c.maxConcurrentRequests)
+		msg := &types.ClientListKVv2MetadataMsg{
+			Mount:    mount,
+			Metadata: make(map[string]*vapi.KVMetadata, len(paths)),
+			Errors:   make(map[string]error),
+		}
+
+		for _, path := range paths {
+			if strings.HasSuffix(path, "/") {
+				continue
+			}
+
+			wg.Go(func() {
+				metadata, err := c.api.KVv2(mount.Path).GetMetadata(context.Background(), path)
+
+				mu.Lock()
+				defer mu.Unlock()
+				if err != nil {
+					msg.Errors[path] = err
+					return
+				}
+				msg.Metadata[path] = metadata
+			})
+		}
+		wg.Wait()
+
+		return msg, nil
+	})
+}
+
+type kvv2MountConfigResponse struct {
+	MaxVersions        int    `json:"max_versions"`
+	CASRequired        bool   `json:"cas_required"`
+	DeleteVersionAfter string `json:"delete_version_after"`
+}
+
+func (c *client) GetKVv2MountConfig(uuid string, mount *types.Mount) tea.Cmd {
+	return wrapHandler(uuid, func() (*types.ClientGetKVv2MountConfigMsg, error) {
+		if mount.KVVersion() != 2 {
+			return nil, fmt.Errorf("get mount config: %w", errors.New("mount is not a kv v2 mount"))
+		}
+
+		out, err := request[wrappedResponse[kvv2MountConfigResponse]](
+			c,
+			http.MethodGet,
+			"/v1/"+strings.TrimSuffix(mount.Path, "/")+"/config",
+			nil,
+			nil,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("get mount config: %w", err)
+		}
+
+		config := &types.KVv2MountConfig{
+			MaxVersions: out.Data.MaxVersions,
+			CASRequired: out.Data.CASRequired,
+		}
+
+		if out.Data.DeleteVersionAfter != "" {
+			config.DeleteVersionAfter, err = time.ParseDuration(out.Data.DeleteVersionAfter)
+			if err != nil {
+				return nil, fmt.Errorf("get mount config: %w", err)
+			}
+		}
+
+		return &types.ClientGetKVv2MountConfigMsg{
+			Mount:  mount,
+			Config: config,
+		}, nil
+	})
+}
+
+func (c *client) PutKVv2MountConfig(uuid string, mount *types.Mount, config *types.KVv2MountConfig) tea.Cmd {
+	return wrapHandler(uuid, func() (*types.ClientSuccessMsg, error) {
+		if mount.KVVersion() != 2 {
+			return nil, fmt.Errorf("put mount config: %w", errors.New("mount is not a kv v2 mount"))
+		}
+
+		_, err := c.api.Logical().Write(strings.TrimSuffix(mount.Path, "/")+"/config", map[string]any{
+			"max_versions":         config.MaxVersions,
+			"cas_required":         config.CASRequired,
+			"delete_version_after": config.DeleteVersionAfter.String(),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("put mount config: %w", err)
+		}
+		return &types.ClientSuccessMsg{Message: "updated mount config"}, nil
+	})
+}
+
 func (c *client) ListKVv2Versions(uuid string, mount *types.Mount, path string) tea.Cmd {
 	return wrapHandler(uuid, func() (*types.ClientListKVv2VersionsMsg, error) {
 		if mount.KVVersion() != 2 {
@@ -357,30 +486,63 @@ func (c *client) GetKVSecret(uuid string, mount *types.Mount, path string, versi
 			return nil, fmt.Errorf("get secret: %w", err)
 		}
 
-		var data map[string]any
+		msg := &types.ClientGetSecretMsg{
+			Mount: mount,
+			Path:  path,
+		}
+
 		if secret != nil && secret.Data != nil {
-			data = secret.Data
+			msg.Data = secret.Data
 
-			if v, ok := data["data"]; ok && mount.KVVersion() == 2 {
+			if v, ok := msg.Data["data"]; ok && mount.KVVersion() == 2 {
 				if vv, vok := v.(map[string]any); vok {
-					data = vv
+					msg.Data = vv
 				}
 			}
 		}
 
-		return &types.ClientGetSecretMsg{
-			Mount: mount,
-			Path:  path,
-			Data:  data,
-		}, nil
+		if mount.KVVersion() == 2 && secret != nil && secret.VersionMetadata != nil {
+			msg.Version = secret.VersionMetadata.Version
+			msg.CurrentVersion = secret.VersionMetadata.Version
+
+			// When a specific version is requested, it may not be the latest,
+			// which is what check-and-set writes need to be based on. Tokens
+			// which can only read data/ can still view the version, and writes
+			// based on an outdated version are caught by check-and-set.
+			if version > 0 {
+				var metadata *vapi.KVMetadata
+				metadata, err = c.api.KVv2(mount.Path).GetMetadata(context.Background(), path)
+				switch {
+				case err == nil:
+					msg.CurrentVersion = metadata.CurrentVersion
+				case !isPermissionDenied(err):
+					return nil, fmt.Errorf("get secret metadata: %w", err)
+				}
+			}
+		}
+
+		return msg, nil
 	})
 }
 
-func (c *client) PutKVSecret(uuid string, mount *types.Mount, path string, data map[string]any) tea.Cmd {
+func (c *client) PutKVSecret(uuid string, mount *types.Mount, path string, data map[string]any, cas int) tea.Cmd {
 	return wrapHandler(uuid, func() (*types.ClientSuccessMsg, error) {
 		var err error
+		msg := &types.ClientSuccessMsg{Message: "updated secret"}
 		if mount.KVVersion() == 2 {
-			_, err = c.api.KVv2(mount.Path).Put(context.Background(), path, data, vapi.WithMergeMethod("rw"))
+			opts := []vapi.KVOption{vapi.WithMergeMethod("rw")}
+			if cas >= 0 {
+				opts = append(opts, vapi.WithCheckAndSet(cas))
+			}
+
+			var secret *vapi.KVSecret
+			secret, err = c.api.KVv2(mount.Path).Put(context.Background(), path, data, opts...)
+			if err != nil && strings.Contains(err.Error(), "check-and-set parameter did not match") {
+				return nil, fmt.Errorf("put secret: %w", types.ErrCheckAndSetMismatch)
+			}
+			if err == nil && secret != nil && secret.VersionMetadata != nil {
+				msg.Version = secret.VersionMetadata.Version
+			}
 		} else {
 			// KV v1 and cubbyhole share the same write semantics.
 			err = c.api.KVv1(mount.Path).Put(context.Background(), path, data)
@@ -388,7 +550,7 @@ func (c *client) PutKVSecret(uuid string, mount *types.Mount, path string, data
 		if err != nil {
 			return nil, fmt.Errorf("put secret: %w", err)
 		}
-		return &types.ClientSuccessMsg{Message: "updated secret"}, nil
+		return msg, nil
 	})
 }
 
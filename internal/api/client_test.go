@@ -0,0 +1,677 @@
+// Copyright (c) Liam Stanley <liam@liam.sh>. All rights reserved. Use of
+// this source code is governed by the MIT license that can be found in
+// the LICENSE file.
+
+package api
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"log/slog"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	tea "charm.land/bubbletea/v2"
+	vapi "github.com/hashicorp/vault/api"
+	"github.com/lrstanley/vex/internal/api/fakevault"
+	"github.com/lrstanley/vex/internal/types"
+)
+
+// newTestServer starts a fake Vault server, which is closed when the test ends.
+func newTestServer(t *testing.T) *fakevault.Server {
+	t.Helper()
+	srv := fakevault.New()
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+// newTestClient returns a client for the provided server, authenticated with the
+// provided token, configured the same way as it would be by a user's environment.
+func newTestClient(t *testing.T, srv *fakevault.Server, token string, opts ...ClientOption) types.Client {
+	t.Helper()
+
+	t.Setenv("VAULT_ADDR", srv.URL())
+	t.Setenv("VAULT_TOKEN", token)
+	t.Setenv("VAULT_NAMESPACE", "")
+
+	c, err := NewClient(slog.New(slog.DiscardHandler), 0, opts...)
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+	return c
+}
+
+// run invokes cmd, failing the test if it results in an error, and returns the
+// result.
+func run[T any](t *testing.T, cmd tea.Cmd) T {
+	t.Helper()
+
+	msg, ok := cmd().(types.ClientMsg)
+	if !ok {
+		t.Fatalf("expected %T, got %T", types.ClientMsg{}, msg)
+	}
+	if msg.Error != nil {
+		t.Fatalf("unexpected error: %v", msg.Error)
+	}
+
+	v, ok := msg.Msg.(T)
+	if !ok {
+		t.Fatalf("expected %T, got %T", v, msg.Msg)
+	}
+	return v
+}
+
+// runErr invokes cmd, failing the test if it doesn't result in an error.
+func runErr(t *testing.T, cmd tea.Cmd) error {
+	t.Helper()
+
+	msg, ok := cmd().(types.ClientMsg)
+	if !ok {
+		t.Fatalf("expected %T, got %T", types.ClientMsg{}, msg)
+	}
+	if msg.Error == nil {
+		t.Fatalf("expected error, got %#v", msg.Msg)
+	}
+	return msg.Error
+}
+
+// getMount returns the mount with the provided path, as listed by the client.
+func getMount(t *testing.T, c types.Client, path string) *types.Mount {
+	t.Helper()
+
+	for _, mount := range run[types.ClientListMountsMsg](t, c.ListMounts("")).Mounts {
+		if mount.Path == path {
+			return mount
+		}
+	}
+	t.Fatalf("mount %q not found", path)
+	return nil
+}
+
+func TestClientHealth(t *testing.T) {
+	srv := newTestServer(t)
+	c := newTestClient(t, srv, fakevault.RootToken)
+
+	msg := run[types.ClientConfigMsg](t, c.GetHealth(""))
+	if msg.Address != srv.URL() || msg.Health == nil || !msg.Health.Initialized || msg.Health.Sealed {
+		t.Fatalf("unexpected health: %+v", msg)
+	}
+
+	state := run[types.ClientConfigStateMsg](t, c.GetConfigState(""))
+	if !strings.Contains(string(state.Data), "raft") {
+		t.Fatalf("unexpected config state: %s", state.Data)
+	}
+
+	// Sealed (and standby) servers respond with non-200 status codes, which
+	// shouldn't be treated as errors.
+	srv.SetHealth(vapi.HealthResponse{Initialized: true, Sealed: true, Version: "1.20.0"})
+	c = newTestClient(t, srv, fakevault.RootToken)
+
+	msg = run[types.ClientConfigMsg](t, c.GetHealth(""))
+	if !msg.Health.Sealed {
+		t.Fatalf("expected sealed health, got %+v", msg.Health)
+	}
+}
+
+func TestClientNodeHealth(t *testing.T) {
+	srv := newTestServer(t)
+	standby := newTestServer(t)
+	standby.SetHealth(vapi.HealthResponse{Initialized: true, Standby: true, Version: "1.19.0"})
+
+	// Health checks of other nodes don't depend on the configured address.
+	c := newTestClient(t, srv, fakevault.RootToken)
+
+	msg := run[types.ClientNodeHealthMsg](t, c.GetNodeHealth("", standby.URL()))
+	if msg.Address != standby.URL() || msg.Error != "" || msg.Health == nil {
+		t.Fatalf("unexpected node health: %+v", msg)
+	}
+	if !msg.Health.Standby || msg.Health.Version != "1.19.0" || msg.Latency <= 0 {
+		t.Fatalf("unexpected node health: %+v", msg.Health)
+	}
+
+	// Unreachable nodes are reported through the message, rather than as errors.
+	closed := fakevault.New()
+	closed.Close()
+
+	msg = run[types.ClientNodeHealthMsg](t, c.GetNodeHealth("", closed.URL()))
+	if msg.Health != nil || msg.Error == "" {
+		t.Fatalf("expected unreachable node, got %+v", msg)
+	}
+}
+
+func TestClientMetrics(t *testing.T) {
+	srv := newTestServer(t)
+	c := newTestClient(t, srv, fakevault.RootToken)
+
+	msg := run[types.ClientMetricsMsg](t, c.GetMetrics(""))
+	if msg.Timestamp.IsZero() {
+		t.Fatal("expected metrics timestamp to be parsed")
+	}
+
+	metrics := make(map[string]*types.Metric, len(msg.Metrics))
+	for _, metric := range msg.Metrics {
+		metrics[metric.ID()] = metric
+	}
+
+	requests := metrics["vault.core.handle_request"]
+	if requests == nil || requests.Kind != types.MetricSample || requests.Count == 0 || requests.PerSecond() <= 0 {
+		t.Fatalf("unexpected request metric: %+v", requests)
+	}
+
+	tokens := metrics[`vault.token.count{namespace="root"}`]
+	if tokens == nil || tokens.Kind != types.MetricGauge || tokens.Value < 1 {
+		t.Fatalf("unexpected token metric: %+v", tokens)
+	}
+}
+
+func TestClientTokenLookupSelf(t *testing.T) {
+	srv := newTestServer(t)
+	token := srv.AddToken(fakevault.Token{DisplayName: "userpass-alice", Policies: []string{"default", "dev"}})
+	c := newTestClient(t, srv, token)
+
+	msg := run[types.ClientTokenLookupSelfMsg](t, c.TokenLookupSelf(""))
+	if msg.Result.DisplayName != "userpass-alice" || !slices.Equal(msg.Result.Policies, []string{"default", "dev"}) {
+		t.Fatalf("unexpected token lookup: %+v", msg.Result)
+	}
+
+	srv.RevokeToken(token)
+	if err := runErr(t, c.TokenLookupSelf("")); !strings.Contains(err.Error(), "permission denied") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestClientListMounts(t *testing.T) {
+	srv := newTestServer(t)
+
+	mounts := run[types.ClientListMountsMsg](t, newTestClient(t, srv, fakevault.RootToken).ListMounts("")).Mounts
+	paths := make([]string, len(mounts))
+	for i, mount := range mounts {
+		paths[i] = mount.Path
+		if !mount.Capabilities.Contains(types.CapabilityRead) {
+			t.Fatalf("expected root to have all capabilities on %q, got %v", mount.Path, mount.Capabilities)
+		}
+	}
+	if !slices.Equal(paths, []string{"cubbyhole/", "identity/", "kv/", "secret/", "sys/"}) {
+		t.Fatalf("unexpected mounts: %v", paths)
+	}
+
+	// Tokens only see the mounts they have access to.
+	token := srv.AddToken(fakevault.Token{Rules: map[string][]types.ClientCapability{
+		"secret/*": {types.CapabilityRead, types.CapabilityList},
+	}})
+
+	mounts = run[types.ClientListMountsMsg](t, newTestClient(t, srv, token).ListMounts("")).Mounts
+	if len(mounts) != 1 || mounts[0].Path != "secret/" || mounts[0].KVVersion() != 2 {
+		t.Fatalf("unexpected mounts: %+v", mounts)
+	}
+	if !mounts[0].Capabilities.Contains(types.CapabilityList) || mounts[0].Capabilities.Contains(types.CapabilityUpdate) {
+		t.Fatalf("unexpected capabilities: %v", mounts[0].Capabilities)
+	}
+}
+
+func TestClientKVv2(t *testing.T) {
+	srv := newTestServer(t)
+	c := newTestClient(t, srv, fakevault.RootToken)
+	mount := getMount(t, c, "secret/")
+
+	run[types.ClientSuccessMsg](t, c.PutKVSecret("", mount, "app/db", map[string]any{"user": "admin"}, 0))
+	if res := run[types.ClientSuccessMsg](t, c.PutKVSecret("", mount, "app/db", map[string]any{"user": "root", "port": 5432}, 1)); res.Version != 2 {
+		t.Fatalf("expected write to create version 2, got %d", res.Version)
+	}
+	run[types.ClientSuccessMsg](t, c.PutKVSecret("", mount, "top", map[string]any{"a": "b"}, -1))
+
+	// Writes based on a stale version are rejected.
+	if err := runErr(t, c.PutKVSecret("", mount, "app/db", map[string]any{"user": "stale"}, 1)); !errors.Is(err, types.ErrCheckAndSetMismatch) {
+		t.Fatalf("expected check-and-set mismatch, got %v", err)
+	}
+
+	secret := run[types.ClientGetSecretMsg](t, c.GetKVSecret("", mount, "app/db", 0))
+	if secret.Data["user"] != "root" || secret.Version != 2 || secret.CurrentVersion != 2 {
+		t.Fatalf("unexpected secret: %+v", secret)
+	}
+
+	secret = run[types.ClientGetSecretMsg](t, c.GetKVSecret("", mount, "app/db", 1))
+	if secret.Data["user"] != "admin" || secret.Version != 1 || secret.CurrentVersion != 2 {
+		t.Fatalf("unexpected secret: %+v", secret)
+	}
+
+	list := run[types.ClientListSecretsMsg](t, c.ListSecrets("", mount, ""))
+	if len(list.Values) != 2 || list.Values[0].Path != "app/" || list.Values[1].Path != "top" {
+		t.Fatalf("unexpected list: %+v", list.Values)
+	}
+
+	list = run[types.ClientListSecretsMsg](t, c.ListSecrets("", mount, "app/"))
+	if len(list.Values) != 1 || list.Values[0].Path != "app/db" {
+		t.Fatalf("unexpected list: %+v", list.Values)
+	}
+
+	metadata := run[types.ClientListKVv2MetadataMsg](t, c.ListKVv2Metadata("", mount, "app/", "top"))
+	if len(metadata.Metadata) != 1 || metadata.Metadata["top"].CurrentVersion != 1 {
+		t.Fatalf("unexpected metadata: %+v", metadata.Metadata)
+	}
+
+	// Soft deletes, which can be undone.
+	run[types.ClientSuccessMsg](t, c.DeleteKVSecret("", mount, "app/db"))
+	if secret = run[types.ClientGetSecretMsg](t, c.GetKVSecret("", mount, "app/db", 0)); secret.Data != nil {
+		t.Fatalf("expected deleted secret to have no data, got %+v", secret)
+	}
+
+	run[types.ClientSuccessMsg](t, c.UndeleteKVSecret("", mount, "app/db", 2))
+	if _, ok := srv.Secret("secret/", "app/db"); !ok {
+		t.Fatal("expected secret to be undeleted")
+	}
+
+	run[types.ClientSuccessMsg](t, c.DeleteKVSecret("", mount, "app/db", 1))
+	run[types.ClientSuccessMsg](t, c.DestroyKVSecret("", mount, "app/db", 2))
+
+	versions := run[types.ClientListKVv2VersionsMsg](t, c.ListKVv2Versions("", mount, "app/db")).Versions
+	if len(versions) != 2 || versions[0].DeletionTime.IsZero() || !versions[1].Destroyed {
+		t.Fatalf("unexpected versions: %+v", versions)
+	}
+
+	// Destroying without versions removes all versions, and the metadata.
+	run[types.ClientSuccessMsg](t, c.DestroyKVSecret("", mount, "app/db"))
+	if err := runErr(t, c.GetKVv2Metadata("", mount, "app/db")); !errors.Is(err, vapi.ErrSecretNotFound) {
+		t.Fatalf("expected secret not found, got %v", err)
+	}
+}
+
+func TestClientKVv2Metadata(t *testing.T) {
+	srv := newTestServer(t)
+	c := newTestClient(t, srv, fakevault.RootToken)
+	mount := getMount(t, c, "secret/")
+
+	srv.PutSecret("secret/", "app", map[string]any{"a": "b"})
+
+	maxVersions := 3
+	after := time.Hour
+	run[types.ClientSuccessMsg](t, c.PutKVv2Metadata("", mount, "app", &types.KVv2MetadataInput{
+		MaxVersions:        &maxVersions,
+		DeleteVersionAfter: &after,
+		CustomMetadata:     map[string]any{"owner": "team-a"},
+	}))
+
+	metadata := run[types.ClientGetKVv2MetadataMsg](t, c.GetKVv2Metadata("", mount, "app")).Metadata
+	if metadata.MaxVersions != 3 || metadata.DeleteVersionAfter != time.Hour || metadata.CustomMetadata["owner"] != "team-a" {
+		t.Fatalf("unexpected metadata: %+v", metadata)
+	}
+
+	// Older versions are pruned beyond the max versions.
+	for i := range 4 {
+		srv.PutSecret("secret/", "app", map[string]any{"i": i})
+	}
+	metadata = run[types.ClientGetKVv2MetadataMsg](t, c.GetKVv2Metadata("", mount, "app")).Metadata
+	if metadata.CurrentVersion != 5 || metadata.OldestVersion != 3 || len(metadata.Versions) != 3 {
+		t.Fatalf("unexpected metadata: %+v", metadata)
+	}
+
+	run[types.ClientSuccessMsg](t, c.PutKVv2MountConfig("", mount, &types.KVv2MountConfig{
+		MaxVersions:        5,
+		CASRequired:        true,
+		DeleteVersionAfter: 24 * time.Hour,
+	}))
+
+	cfg := run[types.ClientGetKVv2MountConfigMsg](t, c.GetKVv2MountConfig("", mount)).Config
+	if cfg.MaxVersions != 5 || !cfg.CASRequired || cfg.DeleteVersionAfter != 24*time.Hour {
+		t.Fatalf("unexpected mount config: %+v", cfg)
+	}
+
+	// Check-and-set is now required for all writes.
+	if err := runErr(t, c.PutKVSecret("", mount, "app", map[string]any{"a": "b"}, -1)); !strings.Contains(err.Error(), "check-and-set parameter required") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestClientKVv1(t *testing.T) {
+	srv := newTestServer(t)
+	c := newTestClient(t, srv, fakevault.RootToken)
+
+	for _, path := range []string{"kv/", "cubbyhole/"} {
+		t.Run(strings.TrimSuffix(path, "/"), func(t *testing.T) {
+			mount := getMount(t, c, path)
+
+			run[types.ClientSuccessMsg](t, c.PutKVSecret("", mount, "app/db", map[string]any{"user": "admin"}, -1))
+			run[types.ClientSuccessMsg](t, c.PutKVSecret("", mount, "other", map[string]any{"a": "b"}, -1))
+
+			secret := run[types.ClientGetSecretMsg](t, c.GetKVSecret("", mount, "app/db", 0))
+			if secret.Data["user"] != "admin" || secret.Version != 0 {
+				t.Fatalf("unexpected secret: %+v", secret)
+			}
+
+			list := run[types.ClientListSecretsMsg](t, c.ListSecrets("", mount, ""))
+			if len(list.Values) != 2 || list.Values[0].Path != "app/" || list.Values[1].Path != "other" {
+				t.Fatalf("unexpected list: %+v", list.Values)
+			}
+
+			run[types.ClientSuccessMsg](t, c.DeleteKVSecret("", mount, "app/db"))
+			if _, ok := srv.Secret(path, "app/db"); ok {
+				t.Fatal("expected secret to be deleted")
+			}
+
+			if err := runErr(t, c.UndeleteKVSecret("", mount, "other")); !strings.Contains(err.Error(), "not a kv v2 mount") {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestClientListAllSecretsRecursive(t *testing.T) {
+	srv := newTestServer(t)
+	c := newTestClient(t, srv, fakevault.RootToken)
+
+	for _, path := range []string{"a", "b/c", "b/d/e", "b/d/f"} {
+		srv.PutSecret("secret/", path, map[string]any{"k": "v"})
+	}
+	srv.PutSecret("kv/", "g", map[string]any{"k": "v"})
+
+	msg := run[types.ClientListAllSecretsRecursiveMsg](t, c.ListAllSecretsRecursive("", nil))
+
+	var secrets []string
+	for ref := range msg.Tree.IterRefs() {
+		if ref.IsSecret() && ref.Parent != nil {
+			secrets = append(secrets, ref.GetFullPath(true))
+			if !ref.Capabilities.Contains(types.CapabilityRead) {
+				t.Fatalf("expected capabilities on %q, got %v", ref.GetFullPath(true), ref.Capabilities)
+			}
+		}
+	}
+	slices.Sort(secrets)
+
+	want := []string{"kv/g", "secret/a", "secret/b/c", "secret/b/d/e", "secret/b/d/f"}
+	if !slices.Equal(secrets, want) {
+		t.Fatalf("expected %v, got %v", want, secrets)
+	}
+}
+
+func TestClientPermissions(t *testing.T) {
+	srv := newTestServer(t)
+	srv.PutSecret("secret/", "public/a", map[string]any{"k": "v"})
+	srv.PutSecret("secret/", "private/b", map[string]any{"k": "v"})
+
+	token := srv.AddToken(fakevault.Token{Rules: map[string][]types.ClientCapability{
+		"secret/metadata/*":        {types.CapabilityList},
+		"secret/metadata/public/*": {types.CapabilityRead, types.CapabilityList},
+		"secret/data/public/*":     {types.CapabilityRead},
+		"secret/data/private/*":    {types.CapabilityDeny},
+		"secret/public/*":          {types.CapabilityRead, types.CapabilityUpdate},
+	}})
+	c := newTestClient(t, srv, token)
+	mount := getMount(t, c, "secret/")
+
+	if secret := run[types.ClientGetSecretMsg](t, c.GetKVSecret("", mount, "public/a", 0)); secret.Data["k"] != "v" {
+		t.Fatalf("unexpected secret: %+v", secret)
+	}
+
+	// Specific versions can be read without access to the metadata.
+	dataOnly := newTestClient(t, srv, srv.AddToken(fakevault.Token{Rules: map[string][]types.ClientCapability{
+		"secret/data/public/*": {types.CapabilityRead},
+	}}))
+	if secret := run[types.ClientGetSecretMsg](t, dataOnly.GetKVSecret("", mount, "public/a", 1)); secret.Data["k"] != "v" || secret.CurrentVersion != 1 {
+		t.Fatalf("unexpected secret: %+v", secret)
+	}
+
+	for _, cmd := range []tea.Cmd{
+		c.GetKVSecret("", mount, "private/b", 0),
+		c.PutKVSecret("", mount, "public/a", map[string]any{"k": "x"}, -1),
+		c.DeleteKVSecret("", mount, "public/a"),
+		c.ListACLPolicies(""),
+	} {
+		if err := runErr(t, cmd); !strings.Contains(err.Error(), "permission denied") {
+			t.Fatalf("expected permission denied, got %v", err)
+		}
+	}
+
+	// Metadata of secrets which can't be read doesn't prevent listing the rest.
+	metadata := run[types.ClientListKVv2MetadataMsg](t, c.ListKVv2Metadata("", mount, "public/a", "private/b"))
+	if len(metadata.Metadata) != 1 || metadata.Metadata["public/a"] == nil {
+		t.Fatalf("unexpected metadata: %+v", metadata.Metadata)
+	}
+	if err := metadata.Errors["private/b"]; err == nil || !strings.Contains(err.Error(), "permission denied") {
+		t.Fatalf("expected permission denied for private/b, got %v", metadata.Errors)
+	}
+
+	caps := run[types.ClientListSecretsMsg](t, c.ListSecrets("", mount, "")).Values
+	if len(caps) != 2 || caps[0].Path != "private/" || caps[0].Capabilities.Contains(types.CapabilityRead) {
+		t.Fatalf("unexpected list: %+v", caps)
+	}
+	if !caps[1].Capabilities.Contains(types.CapabilityUpdate) {
+		t.Fatalf("expected update capability on %q, got %v", caps[1].Path, caps[1].Capabilities)
+	}
+}
+
+func TestClientPolicies(t *testing.T) {
+	srv := newTestServer(t)
+	srv.SetPolicy("dev", `path "secret/*" { capabilities = ["read"] }`)
+	srv.AddPasswordPolicy("strong", 32)
+	c := newTestClient(t, srv, fakevault.RootToken)
+
+	policies := run[types.ClientListACLPoliciesMsg](t, c.ListACLPolicies("")).Policies
+	if !slices.Equal(policies, []string{"default", "dev", "root"}) {
+		t.Fatalf("unexpected policies: %v", policies)
+	}
+
+	policy := run[types.ClientGetACLPolicyMsg](t, c.GetACLPolicy("", "dev"))
+	if !strings.Contains(policy.Content, `path "secret/*"`) {
+		t.Fatalf("unexpected policy: %+v", policy)
+	}
+
+	if pw := run[types.ClientGeneratePasswordMsg](t, c.GeneratePassword("", "strong")); len(pw.Password) != 32 {
+		t.Fatalf("unexpected password: %q", pw.Password)
+	}
+	runErr(t, c.GeneratePassword("", "unknown"))
+	runErr(t, c.GeneratePassword("", ""))
+	runErr(t, c.GeneratePassword("", "unknown/../strong"))
+}
+
+func TestClientRaft(t *testing.T) {
+	srv := newTestServer(t)
+	srv.SetRaftPeers(
+		&types.RaftConfigPeer{NodeID: "node-2", Address: "10.0.0.2:8201", Voter: true},
+		&types.RaftConfigPeer{NodeID: "node-1", Address: "10.0.0.1:8201", Voter: true, Leader: true},
+	)
+	c := newTestClient(t, srv, fakevault.RootToken)
+
+	peers := run[types.ClientRaftConfigMsg](t, c.GetRaftConfig("")).Peers
+	if len(peers) != 2 || peers[0].NodeID != "node-1" || !peers[0].Leader {
+		t.Fatalf("unexpected peers: %+v", peers)
+	}
+
+	run[types.ClientSuccessMsg](t, c.RemoveRaftPeer("", "node-2"))
+	if peers = srv.RaftPeers(); len(peers) != 1 || peers[0].NodeID != "node-1" {
+		t.Fatalf("unexpected peers: %+v", peers)
+	}
+
+	if err := runErr(t, c.RemoveRaftPeer("", "node-3")); !strings.Contains(err.Error(), "no server with id") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestClientRaftAutopilot(t *testing.T) {
+	srv := newTestServer(t)
+	srv.SetRaftPeers(
+		&types.RaftConfigPeer{NodeID: "node-1", Address: "10.0.0.1:8201", Voter: true, Leader: true},
+		&types.RaftConfigPeer{NodeID: "node-2", Address: "10.0.0.2:8201", Voter: true},
+		&types.RaftConfigPeer{NodeID: "node-3", Address: "10.0.0.3:8201"},
+	)
+	c := newTestClient(t, srv, fakevault.RootToken)
+
+	state := run[types.ClientRaftAutopilotStateMsg](t, c.GetRaftAutopilotState("")).State
+	if !state.Healthy || state.Leader != "node-1" || len(state.Servers) != 3 || !slices.Equal(state.NonVoters, []string{"node-3"}) {
+		t.Fatalf("unexpected autopilot state: %+v", state)
+	}
+	if server := state.Servers["node-2"]; server == nil || server.Status != "voter" || !server.Healthy {
+		t.Fatalf("unexpected server: %+v", server)
+	}
+
+	config := run[types.ClientRaftAutopilotConfigMsg](t, c.GetRaftAutopilotConfig("")).Config
+	if config.LastContactThreshold != 10*time.Second || config.MaxTrailingLogs != 1000 {
+		t.Fatalf("unexpected autopilot config: %+v", config)
+	}
+
+	config.CleanupDeadServers = true
+	config.MinQuorum = 3
+	config.DeadServerLastContactThreshold = time.Hour
+	run[types.ClientSuccessMsg](t, c.PutRaftAutopilotConfig("", config))
+
+	updated := run[types.ClientRaftAutopilotConfigMsg](t, c.GetRaftAutopilotConfig("")).Config
+	if *updated != *config {
+		t.Fatalf("expected %+v, got %+v", config, updated)
+	}
+}
+
+func TestClientRaftSnapshot(t *testing.T) {
+	srv := newTestServer(t)
+	c := newTestClient(t, srv, fakevault.RootToken)
+
+	var snapshot bytes.Buffer
+	run[types.ClientSuccessMsg](t, c.SaveRaftSnapshot("", &snapshot))
+
+	srv.SetRaftPeers()
+	run[types.ClientSuccessMsg](t, c.RestoreRaftSnapshot("", bytes.NewReader(snapshot.Bytes()), false))
+	if peers := srv.RaftPeers(); len(peers) != 1 || peers[0].NodeID != "node-1" {
+		t.Fatalf("expected peers to be restored, got %+v", peers)
+	}
+
+	if err := runErr(t, c.RestoreRaftSnapshot("", strings.NewReader("invalid"), true)); !strings.Contains(err.Error(), "failed to read snapshot") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Snapshots from a different cluster can only be restored when forced.
+	srv.SetHealth(vapi.HealthResponse{Initialized: true, ClusterID: "other"})
+	if err := runErr(t, c.RestoreRaftSnapshot("", bytes.NewReader(snapshot.Bytes()), false)); !strings.Contains(err.Error(), "different cluster") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	run[types.ClientSuccessMsg](t, c.RestoreRaftSnapshot("", bytes.NewReader(snapshot.Bytes()), true))
+
+	// Snapshots require sudo.
+	token := srv.AddToken(fakevault.Token{Rules: map[string][]types.ClientCapability{
+		"sys/storage/raft/*": {types.CapabilityRead, types.CapabilityUpdate},
+	}})
+	c = newTestClient(t, srv, token)
+	if err := runErr(t, c.SaveRaftSnapshot("", io.Discard)); !strings.Contains(err.Error(), "permission denied") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestClientRaftJoin(t *testing.T) {
+	srv := newTestServer(t)
+	c := newTestClient(t, srv, fakevault.RootToken)
+
+	run[types.ClientSuccessMsg](t, c.JoinRaft("", &vapi.RaftJoinRequest{LeaderAPIAddr: "https://10.0.0.1:8200"}))
+	if err := runErr(t, c.JoinRaft("", &vapi.RaftJoinRequest{})); !strings.Contains(err.Error(), "leader_api_addr") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestClientSeal(t *testing.T) {
+	srv := newTestServer(t)
+	c := newTestClient(t, srv, fakevault.RootToken)
+
+	status := run[types.ClientSealStatusMsg](t, c.GetSealStatus(""))
+	if status.Status.Sealed || status.Leader == nil || !status.Leader.IsSelf {
+		t.Fatalf("unexpected seal status: %+v", status)
+	}
+	if !status.SealCapabilities.Contains(types.CapabilitySudo) || !status.StepDownCapabilities.Contains(types.CapabilitySudo) {
+		t.Fatalf("unexpected capabilities: %v, %v", status.SealCapabilities, status.StepDownCapabilities)
+	}
+
+	run[types.ClientSuccessMsg](t, c.StepDown(""))
+	run[types.ClientSuccessMsg](t, c.Seal(""))
+
+	// HA status and capabilities aren't available while sealed.
+	status = run[types.ClientSealStatusMsg](t, c.GetSealStatus(""))
+	if !status.Status.Sealed || status.Status.T != 2 || status.Leader != nil || status.SealCapabilities != nil {
+		t.Fatalf("unexpected seal status: %+v", status)
+	}
+	if err := runErr(t, c.ListMounts("")); !strings.Contains(err.Error(), "Vault is sealed") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	keys := srv.UnsealKeys()
+	if msg := run[types.ClientSuccessMsg](t, c.Unseal("", keys[0])); msg.Message != "unseal key accepted (1/2)" {
+		t.Fatalf("unexpected message: %q", msg.Message)
+	}
+	run[types.ClientSuccessMsg](t, c.ResetUnseal(""))
+	if status = run[types.ClientSealStatusMsg](t, c.GetSealStatus("")); status.Status.Progress != 0 {
+		t.Fatalf("expected unseal progress to be reset, got %d", status.Status.Progress)
+	}
+
+	if err := runErr(t, c.Unseal("", "invalid")); !strings.Contains(err.Error(), "invalid key") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	run[types.ClientSuccessMsg](t, c.Unseal("", keys[1]))
+	if msg := run[types.ClientSuccessMsg](t, c.Unseal("", keys[2])); msg.Message != "unsealed" {
+		t.Fatalf("unexpected message: %q", msg.Message)
+	}
+	if health := run[types.ClientConfigMsg](t, c.GetHealth("")); health.Health.Sealed {
+		t.Fatal("expected health to reflect the unsealed server")
+	}
+
+	// Sealing requires sudo.
+	token := srv.AddToken(fakevault.Token{Rules: map[string][]types.ClientCapability{
+		"sys/*": {types.CapabilityUpdate},
+	}})
+	c = newTestClient(t, srv, token)
+
+	status = run[types.ClientSealStatusMsg](t, c.GetSealStatus(""))
+	if status.SealCapabilities.Contains(types.CapabilitySudo) {
+		t.Fatalf("unexpected capabilities: %v", status.SealCapabilities)
+	}
+	if err := runErr(t, c.Seal("")); !strings.Contains(err.Error(), "permission denied") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestClientAuditDevices(t *testing.T) {
+	srv := newTestServer(t)
+	c := newTestClient(t, srv, fakevault.RootToken)
+
+	run[types.ClientSuccessMsg](t, c.EnableAuditDevice("", "file", &vapi.EnableAuditOptions{
+		Type:        "file",
+		Description: "audit log",
+		Options:     map[string]string{"file_path": "/var/log/vault/audit.log"},
+	}))
+	run[types.ClientSuccessMsg](t, c.EnableAuditDevice("", "syslog", &vapi.EnableAuditOptions{Type: "syslog", Local: true}))
+
+	if err := runErr(t, c.EnableAuditDevice("", "file", &vapi.EnableAuditOptions{
+		Type:    "file",
+		Options: map[string]string{"file_path": "stdout"},
+	})); !strings.Contains(err.Error(), "path already in use") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := runErr(t, c.EnableAuditDevice("", "socket", &vapi.EnableAuditOptions{Type: "socket"})); !strings.Contains(err.Error(), "address is required") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	devices := run[types.ClientListAuditDevicesMsg](t, c.ListAuditDevices("")).Devices
+	if len(devices) != 2 || devices[0].Path != "file/" || devices[0].Options["file_path"] != "/var/log/vault/audit.log" || !devices[1].Local {
+		t.Fatalf("unexpected audit devices: %+v", devices)
+	}
+
+	hash := run[types.ClientAuditHashMsg](t, c.AuditHash("", "file", "s.secret"))
+	if hash.Hash != srv.AuditHash("file", "s.secret") || !strings.HasPrefix(hash.Hash, "hmac-sha256:") {
+		t.Fatalf("unexpected hash: %+v", hash)
+	}
+
+	run[types.ClientSuccessMsg](t, c.DisableAuditDevice("", "file/"))
+	if _, ok := srv.AuditDevices()["file/"]; ok {
+		t.Fatal("expected audit device to be disabled")
+	}
+	if err := runErr(t, c.AuditHash("", "file", "s.secret")); !strings.Contains(err.Error(), "unknown audit backend") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Managing audit devices requires sudo, but hashing doesn't.
+	token := srv.AddToken(fakevault.Token{Rules: map[string][]types.ClientCapability{
+		"sys/audit*": {types.CapabilityRead, types.CapabilityUpdate},
+	}})
+	c = newTestClient(t, srv, token)
+	if err := runErr(t, c.ListAuditDevices("")); !strings.Contains(err.Error(), "permission denied") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	run[types.ClientAuditHashMsg](t, c.AuditHash("", "syslog", "s.secret"))
+}
@@ -0,0 +1,142 @@
+// Copyright (c) Liam Stanley <liam@liam.sh>. All rights reserved. Use of
+// this source code is governed by the MIT license that can be found in
+// the LICENSE file.
+
+package fakevault
+
+import (
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/lrstanley/vex/internal/types"
+)
+
+// Token is a token accepted by the server.
+type Token struct {
+	ID          string
+	DisplayName string
+	Policies    []string
+
+	// Rules maps path patterns to the capabilities granted on them, the same as
+	// "path" stanzas in Vault policies, e.g. {"secret/data/app/*": {"read"}}.
+	// Patterns may end with "*" (prefix match), and "+" matches a single path
+	// segment. The most specific matching pattern applies. Tokens with the "root"
+	// policy are granted all capabilities.
+	Rules map[string][]types.ClientCapability
+}
+
+// AddToken adds a token to the server, returning its ID (generated if not set).
+func (s *Server) AddToken(token Token) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if token.ID == "" {
+		token.ID = "hvs." + randomString(24)
+	}
+	if token.DisplayName == "" {
+		token.DisplayName = "token"
+	}
+	if len(token.Policies) == 0 {
+		token.Policies = []string{"default"}
+	}
+	s.tokens[token.ID] = &token
+	return token.ID
+}
+
+// RevokeToken removes a token from the server.
+func (s *Server) RevokeToken(id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.tokens, id)
+}
+
+func (t *Token) isRoot() bool {
+	return slices.Contains(t.Policies, "root")
+}
+
+// capabilities returns the capabilities of the token on the provided path.
+func (t *Token) capabilities(path string) types.ClientCapabilities {
+	if t.isRoot() {
+		return types.ClientCapabilities{types.CapabilityRoot}
+	}
+
+	best := ""
+	var caps []types.ClientCapability
+	for pattern, c := range t.Rules {
+		if matchPattern(pattern, path) && moreSpecific(pattern, best) {
+			best, caps = pattern, c
+		}
+	}
+
+	if len(caps) == 0 {
+		return types.ClientCapabilities{types.CapabilityDeny}
+	}
+	return slices.Clone(caps)
+}
+
+// allowed returns true if the token is allowed to make a request with the
+// provided method to the provided path. exists determines if writes require the
+// "create" or "update" capability.
+func (t *Token) allowed(method, path string, exists bool) bool {
+	caps := t.capabilities(path)
+
+	switch method {
+	case "LIST":
+		return caps.Contains(types.CapabilityList)
+	case http.MethodGet, http.MethodHead:
+		return caps.Contains(types.CapabilityRead)
+	case http.MethodDelete:
+		return caps.Contains(types.CapabilityDelete)
+	case http.MethodPatch:
+		return caps.Contains(types.CapabilityPatch)
+	default:
+		if exists {
+			return caps.Contains(types.CapabilityUpdate)
+		}
+		return caps.Contains(types.CapabilityCreate)
+	}
+}
+
+// matchPattern returns true if the path matches the policy path pattern.
+func matchPattern(pattern, path string) bool {
+	prefix, glob := strings.CutSuffix(pattern, "*")
+
+	patternParts := strings.Split(prefix, "/")
+	pathParts := strings.Split(path, "/")
+
+	if len(pathParts) < len(patternParts) || (!glob && len(pathParts) != len(patternParts)) {
+		return false
+	}
+
+	for i, part := range patternParts {
+		last := i == len(patternParts)-1
+		switch {
+		case part == "+":
+			continue
+		case last && glob:
+			if !strings.HasPrefix(pathParts[i], part) {
+				return false
+			}
+		case part != pathParts[i]:
+			return false
+		}
+	}
+	return true
+}
+
+// moreSpecific returns true if pattern a takes priority over pattern b, which is
+// a simplified version of Vault's priority matching: fewer "+" segments, then no
+// trailing glob, then the longest pattern.
+func moreSpecific(a, b string) bool {
+	if b == "" {
+		return true
+	}
+	if ca, cb := strings.Count(a, "+"), strings.Count(b, "+"); ca != cb {
+		return ca < cb
+	}
+	if ga, gb := strings.HasSuffix(a, "*"), strings.HasSuffix(b, "*"); ga != gb {
+		return gb
+	}
+	return len(a) > len(b)
+}
@@ -0,0 +1,128 @@
+// Copyright (c) Liam Stanley <liam@liam.sh>. All rights reserved. Use of
+// this source code is governed by the MIT license that can be found in
+// the LICENSE file.
+
+package fakevault
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"maps"
+	"net/http"
+	"strings"
+
+	vapi "github.com/hashicorp/vault/api"
+)
+
+// auditDevice is an enabled audit device. Nothing is ever logged to it.
+type auditDevice struct {
+	vapi.Audit
+	salt string
+}
+
+// hash hashes the input the same way as Vault does, with the salt of the device.
+func (d *auditDevice) hash(input string) string {
+	mac := hmac.New(sha256.New, []byte(d.salt))
+	_, _ = mac.Write([]byte(input))
+	return "hmac-sha256:" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// auditRequiredOptions are the options which must be provided for each
+// (supported) audit device type.
+var auditRequiredOptions = map[string]string{
+	"file":   "file_path",
+	"socket": "address",
+	"syslog": "",
+}
+
+// AuditDevices returns the enabled audit devices, keyed by path (e.g. "file/").
+func (s *Server) AuditDevices() map[string]vapi.Audit {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	devices := make(map[string]vapi.Audit, len(s.audit))
+	for path, device := range s.audit {
+		devices[path] = device.Audit
+	}
+	return devices
+}
+
+// AuditHash returns the hash of input, as it would appear in the log of the
+// audit device at path.
+func (s *Server) AuditHash(path, input string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	device, ok := s.audit[strings.TrimSuffix(path, "/")+"/"]
+	if !ok {
+		return ""
+	}
+	return device.hash(input)
+}
+
+func (s *Server) handleListAudit(w http.ResponseWriter) {
+	devices := make(map[string]vapi.Audit, len(s.audit))
+	for path, device := range s.audit {
+		devices[path] = device.Audit
+	}
+	writeData(w, http.StatusOK, devices)
+}
+
+func (s *Server) handleEnableAudit(w http.ResponseWriter, r *http.Request, path string) {
+	var input vapi.EnableAuditOptions
+	if err := decodeBody(r, &input); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	path = strings.TrimSuffix(path, "/") + "/"
+	required, ok := auditRequiredOptions[input.Type]
+
+	switch {
+	case path == "/":
+		writeError(w, http.StatusBadRequest, "missing path")
+	case !ok:
+		writeError(w, http.StatusBadRequest, "unknown backend type: \""+input.Type+"\"")
+	case required != "" && input.Options[required] == "":
+		writeError(w, http.StatusBadRequest, required+" is required")
+	case s.audit[path] != nil:
+		writeError(w, http.StatusBadRequest, "path already in use")
+	default:
+		s.audit[path] = &auditDevice{
+			Audit: vapi.Audit{
+				Type:        input.Type,
+				Description: input.Description,
+				Options:     maps.Clone(input.Options),
+				Local:       input.Local,
+				Path:        path,
+			},
+			salt: randomString(32),
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// handleDisableAudit disables the audit device at path. Same as Vault, disabling
+// a device which doesn't exist isn't an error.
+func (s *Server) handleDisableAudit(w http.ResponseWriter, path string) {
+	delete(s.audit, strings.TrimSuffix(path, "/")+"/")
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) handleAuditHash(w http.ResponseWriter, r *http.Request, path string) {
+	var input struct {
+		Input string `json:"input"`
+	}
+	if err := decodeBody(r, &input); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	device, ok := s.audit[strings.TrimSuffix(path, "/")+"/"]
+	if !ok {
+		writeError(w, http.StatusBadRequest, "unknown audit backend")
+		return
+	}
+	writeData(w, http.StatusOK, map[string]any{"hash": device.hash(input.Input)})
+}
@@ -0,0 +1,429 @@
+// Copyright (c) Liam Stanley <liam@liam.sh>. All rights reserved. Use of
+// this source code is governed by the MIT license that can be found in
+// the LICENSE file.
+
+// Package fakevault provides an in-process fake Vault server, implementing the
+// subset of the Vault HTTP API used by vex (KVv1, KVv2, cubbyhole, mounts, ACL
+// policies, capabilities, token lookups, health, seal/unseal, raft, metrics
+// and audit devices), for hermetic tests.
+// It is not a complete (or strictly accurate) implementation of Vault.
+package fakevault
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	vapi "github.com/hashicorp/vault/api"
+	"github.com/lrstanley/vex/internal/types"
+)
+
+// RootToken is the token which is always accepted, with all capabilities.
+const RootToken = "root"
+
+// Server is a fake Vault server. All methods are safe for concurrent use.
+type Server struct {
+	srv *httptest.Server
+
+	mu          sync.Mutex
+	mounts      map[string]*vapi.MountOutput // Keyed by path, e.g. "secret/".
+	kv1         map[string]map[string]map[string]any
+	kv2         map[string]map[string]*kvv2Secret
+	kv2Config   map[string]*kvv2Config
+	tokens      map[string]*Token
+	policies    map[string]string
+	pwPolicies  map[string]int // Name to generated password length.
+	raft        []*types.RaftConfigPeer
+	autopilot   vapi.AutopilotConfig
+	health      vapi.HealthResponse
+	configState map[string]any
+	activeTime  time.Time
+	audit       map[string]*auditDevice // Keyed by path, e.g. "file/".
+
+	unsealKeys      []string
+	unsealThreshold int
+	unsealProgress  []string // Key shares submitted so far.
+	unsealNonce     string
+
+	requests atomic.Int64 // Number of requests handled, reported in the metrics.
+}
+
+// New starts a fake Vault server, with a KVv2 mount at "secret/", a KVv1 mount
+// at "kv/", and a cubbyhole mount at "cubbyhole/" (shared by all tokens, unlike
+// Vault). Callers must call [Server.Close] when done.
+func New() *Server {
+	s := &Server{
+		mounts:    map[string]*vapi.MountOutput{},
+		kv1:       map[string]map[string]map[string]any{},
+		kv2:       map[string]map[string]*kvv2Secret{},
+		kv2Config: map[string]*kvv2Config{},
+		tokens: map[string]*Token{
+			RootToken: {ID: RootToken, DisplayName: "root", Policies: []string{"root"}},
+		},
+		policies: map[string]string{
+			"default": `path "auth/token/lookup-self" { capabilities = ["read"] }`,
+			"root":    "",
+		},
+		pwPolicies: map[string]int{},
+		audit:      map[string]*auditDevice{},
+		health: vapi.HealthResponse{
+			Initialized: true,
+			Version:     "1.20.0",
+			ClusterName: "vault-cluster-fake",
+			ClusterID:   "00000000-0000-0000-0000-000000000000",
+		},
+		configState: map[string]any{
+			"disable_mlock": true,
+			"listeners":     []any{map[string]any{"type": "tcp"}},
+			"storage":       map[string]any{"type": "raft"},
+		},
+		autopilot: vapi.AutopilotConfig{
+			LastContactThreshold:           10 * time.Second,
+			DeadServerLastContactThreshold: 24 * time.Hour,
+			MaxTrailingLogs:                1000,
+			ServerStabilizationTime:        10 * time.Second,
+		},
+		activeTime:      time.Now(),
+		unsealKeys:      []string{randomString(44), randomString(44), randomString(44)},
+		unsealThreshold: 2,
+	}
+
+	s.AddMount("sys/", "system", nil)
+	s.AddMount("identity/", "identity", nil)
+	s.AddMount("cubbyhole/", "cubbyhole", nil)
+	s.AddMount("secret/", "kv", map[string]string{"version": "2"})
+	s.AddMount("kv/", "kv", map[string]string{"version": "1"})
+
+	s.srv = httptest.NewServer(s)
+	s.raft = []*types.RaftConfigPeer{{
+		NodeID:          "node-1",
+		Address:         s.srv.Listener.Addr().String(),
+		Leader:          true,
+		Voter:           true,
+		ProtocolVersion: "3",
+	}}
+	return s
+}
+
+// URL returns the address of the server, e.g. for VAULT_ADDR.
+func (s *Server) URL() string {
+	return s.srv.URL
+}
+
+// Close shuts down the server.
+func (s *Server) Close() {
+	s.srv.Close()
+}
+
+// AddMount adds (or replaces) a secret engine mount at the provided path, e.g.
+// AddMount("other/", "kv", map[string]string{"version": "2"}).
+func (s *Server) AddMount(path, engine string, options map[string]string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	path = strings.TrimSuffix(path, "/") + "/"
+	s.mounts[path] = &vapi.MountOutput{
+		UUID:     randomString(16),
+		Type:     engine,
+		Accessor: engine + "_" + randomString(8),
+		Options:  options,
+	}
+
+	switch {
+	case engine == "cubbyhole" || (engine == "kv" && options["version"] != "2"):
+		s.kv1[path] = map[string]map[string]any{}
+	case engine == "kv":
+		s.kv2[path] = map[string]*kvv2Secret{}
+		s.kv2Config[path] = &kvv2Config{DeleteVersionAfter: "0s"}
+	}
+}
+
+// SetPolicy adds (or replaces) an ACL policy. Policies are only stored, to be
+// listed and read back. Permissions are controlled by [Token.Rules].
+func (s *Server) SetPolicy(name, policy string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.policies[name] = policy
+}
+
+// AddPasswordPolicy adds a password policy, which generates passwords of the
+// provided length.
+func (s *Server) AddPasswordPolicy(name string, length int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.pwPolicies[name] = length
+}
+
+// SetHealth replaces the health (and version) reported by the server.
+func (s *Server) SetHealth(health vapi.HealthResponse) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.health = health
+}
+
+// UnsealKeys returns the unseal key shares accepted by the server, of which 2 are
+// required to unseal it (see [Server.SetHealth] to seal it).
+func (s *Server) UnsealKeys() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return slices.Clone(s.unsealKeys)
+}
+
+// SetRaftPeers replaces the peers in the raft configuration.
+func (s *Server) SetRaftPeers(peers ...*types.RaftConfigPeer) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.raft = peers
+}
+
+// RaftPeers returns the peers in the raft configuration.
+func (s *Server) RaftPeers() []*types.RaftConfigPeer {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return slices.Clone(s.raft)
+}
+
+// ServeHTTP implements [http.Handler].
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.requests.Add(1)
+
+	path, ok := strings.CutPrefix(r.URL.Path, "/v1/")
+	if !ok {
+		writeError(w, http.StatusNotFound)
+		return
+	}
+
+	method := r.Method
+	if method == http.MethodGet && r.URL.Query().Get("list") == "true" {
+		method = "LIST"
+	}
+
+	// Same as Vault, list requests always refer to a "folder".
+	if method == "LIST" && !strings.HasSuffix(path, "/") {
+		path += "/"
+	}
+
+	// Unauthenticated endpoints.
+	switch path {
+	case "sys/health":
+		s.handleHealth(w, r)
+		return
+	case "sys/seal-status":
+		s.handleSealStatus(w)
+		return
+	case "sys/unseal":
+		s.handleUnseal(w, r)
+		return
+	case "sys/leader":
+		s.handleLeader(w)
+		return
+	case "sys/storage/raft/join":
+		// Same as Vault, joining is unauthenticated, as the node joining a cluster
+		// isn't initialized yet.
+		s.handleRaftJoin(w, r)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	token, ok := s.tokens[r.Header.Get("X-Vault-Token")]
+	if !ok {
+		writeError(w, http.StatusForbidden, "permission denied")
+		return
+	}
+
+	if s.health.Sealed {
+		writeError(w, http.StatusServiceUnavailable, "Vault is sealed")
+		return
+	}
+
+	// Endpoints which all tokens have access to (through the default policy).
+	switch path {
+	case "auth/token/lookup-self":
+		s.handleLookupSelf(w, token)
+		return
+	case "sys/capabilities-self":
+		s.handleCapabilitiesSelf(w, r, token)
+		return
+	case "sys/internal/ui/mounts":
+		s.handleUIMounts(w, token)
+		return
+	}
+
+	if !token.allowed(method, path, s.exists(path)) {
+		writeError(w, http.StatusForbidden, "permission denied")
+		return
+	}
+
+	// Same as Vault, some endpoints also require sudo.
+	if requiresSudo(path) && !token.capabilities(path).Contains(types.CapabilitySudo) {
+		writeError(w, http.StatusForbidden, "permission denied")
+		return
+	}
+
+	switch {
+	case path == "sys/mounts":
+		s.handleMounts(w)
+	case path == "sys/policies/acl/" && method == "LIST":
+		s.handleListPolicies(w)
+	case strings.HasPrefix(path, "sys/policies/acl/") && method == http.MethodGet:
+		s.handleGetPolicy(w, strings.TrimPrefix(path, "sys/policies/acl/"))
+	case strings.HasPrefix(path, "sys/policies/password/") && strings.HasSuffix(path, "/generate"):
+		s.handleGeneratePassword(w, strings.TrimSuffix(strings.TrimPrefix(path, "sys/policies/password/"), "/generate"))
+	case path == "sys/config/state/sanitized":
+		writeData(w, http.StatusOK, s.configState)
+	case path == "sys/metrics" && method == http.MethodGet:
+		s.handleMetrics(w)
+	case path == "sys/storage/raft/configuration":
+		s.handleRaftConfig(w)
+	case path == "sys/storage/raft/remove-peer" && (method == http.MethodPost || method == http.MethodPut):
+		s.handleRaftRemovePeer(w, r)
+	case path == "sys/storage/raft/autopilot/state":
+		s.handleAutopilotState(w)
+	case path == "sys/storage/raft/autopilot/configuration" && method == http.MethodGet:
+		writeData(w, http.StatusOK, &s.autopilot)
+	case path == "sys/storage/raft/autopilot/configuration" && (method == http.MethodPost || method == http.MethodPut):
+		s.handleAutopilotConfig(w, r)
+	case path == "sys/storage/raft/snapshot" && method == http.MethodGet:
+		s.handleRaftSnapshot(w)
+	case (path == "sys/storage/raft/snapshot" || path == "sys/storage/raft/snapshot-force") && (method == http.MethodPost || method == http.MethodPut):
+		s.handleRaftRestore(w, r, path == "sys/storage/raft/snapshot-force")
+	case path == "sys/seal" && (method == http.MethodPost || method == http.MethodPut):
+		s.handleSeal(w)
+	case path == "sys/step-down" && (method == http.MethodPost || method == http.MethodPut):
+		s.handleStepDown(w)
+	case path == "sys/audit" && method == http.MethodGet:
+		s.handleListAudit(w)
+	case strings.HasPrefix(path, "sys/audit/") && (method == http.MethodPost || method == http.MethodPut):
+		s.handleEnableAudit(w, r, strings.TrimPrefix(path, "sys/audit/"))
+	case strings.HasPrefix(path, "sys/audit/") && method == http.MethodDelete:
+		s.handleDisableAudit(w, strings.TrimPrefix(path, "sys/audit/"))
+	case strings.HasPrefix(path, "sys/audit-hash/") && (method == http.MethodPost || method == http.MethodPut):
+		s.handleAuditHash(w, r, strings.TrimPrefix(path, "sys/audit-hash/"))
+	default:
+		mount, rest := s.route(path)
+		switch {
+		case mount == "":
+			writeError(w, http.StatusNotFound)
+		case s.kv2[mount] != nil:
+			s.handleKVv2(w, r, method, mount, rest)
+		case s.kv1[mount] != nil:
+			s.handleKVv1(w, r, method, mount, rest)
+		default:
+			writeError(w, http.StatusNotFound)
+		}
+	}
+}
+
+// sudoPaths are the (supported) paths which require the "sudo" capability, in
+// addition to the capability for the request method.
+var sudoPaths = []string{
+	"sys/seal",
+	"sys/step-down",
+	"sys/storage/raft/snapshot",
+	"sys/storage/raft/snapshot-force",
+	"sys/audit",
+}
+
+// requiresSudo returns true if the provided path requires the "sudo" capability.
+// All paths of audit devices require sudo, except for hashing values.
+func requiresSudo(path string) bool {
+	return slices.Contains(sudoPaths, path) || strings.HasPrefix(path, "sys/audit/")
+}
+
+// route returns the mount which the provided path is under (longest match), and
+// the remainder of the path.
+func (s *Server) route(path string) (mount, rest string) {
+	for m := range s.mounts {
+		if strings.HasPrefix(path+"/", m) && len(m) > len(mount) {
+			mount = m
+		}
+	}
+	if mount == "" {
+		return "", ""
+	}
+	return mount, strings.TrimPrefix(strings.TrimPrefix(path, strings.TrimSuffix(mount, "/")), "/")
+}
+
+// exists returns true if the provided path refers to existing data, used to
+// determine if writes require the "create" or "update" capability.
+func (s *Server) exists(path string) bool {
+	mount, rest := s.route(path)
+	switch {
+	case mount == "":
+		return true
+	case s.kv2[mount] != nil:
+		_, name, _ := strings.Cut(rest, "/")
+		_, ok := s.kv2[mount][name]
+		return ok
+	case s.kv1[mount] != nil:
+		_, ok := s.kv1[mount][rest]
+		return ok
+	}
+	return true
+}
+
+// response is the envelope of all (non-error) responses.
+type response struct {
+	RequestID     string   `json:"request_id"`
+	LeaseID       string   `json:"lease_id"`
+	LeaseDuration int      `json:"lease_duration"`
+	Renewable     bool     `json:"renewable"`
+	Data          any      `json:"data"`
+	Warnings      []string `json:"warnings"`
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeData(w http.ResponseWriter, status int, data any) {
+	writeJSON(w, status, response{RequestID: randomString(16), Data: data})
+}
+
+func writeError(w http.ResponseWriter, status int, errs ...string) {
+	if errs == nil {
+		errs = []string{}
+	}
+	writeJSON(w, status, map[string][]string{"errors": errs})
+}
+
+// decodeBody decodes the JSON request body into v, keeping numbers as
+// [json.Number], so secret data round-trips unchanged.
+func decodeBody(r *http.Request, v any) error {
+	dec := json.NewDecoder(r.Body)
+	dec.UseNumber()
+	if err := dec.Decode(v); err != nil {
+		return fmt.Errorf("failed to parse JSON input: %w", err)
+	}
+	return nil
+}
+
+const randomAlphabet = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
+
+func randomString(n int) string {
+	b := make([]byte, n)
+	_, _ = rand.Read(b)
+	for i := range b {
+		b[i] = randomAlphabet[int(b[i])%len(randomAlphabet)]
+	}
+	return string(b)
+}
+
+// timestamp formats a time the same way Vault does.
+func timestamp(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339Nano)
+}
@@ -0,0 +1,459 @@
+// Copyright (c) Liam Stanley <liam@liam.sh>. All rights reserved. Use of
+// this source code is governed by the MIT license that can be found in
+// the LICENSE file.
+
+package fakevault
+
+import (
+	"encoding/json"
+	"maps"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// kvv2Version is a single version of a KVv2 secret.
+type kvv2Version struct {
+	data      map[string]any
+	created   time.Time
+	deleted   time.Time
+	destroyed bool
+}
+
+// kvv2Secret is a KVv2 secret, with all of its versions and metadata.
+type kvv2Secret struct {
+	versions           map[int]*kvv2Version
+	current            int
+	oldest             int
+	maxVersions        int
+	casRequired        bool
+	deleteVersionAfter string
+	customMetadata     map[string]any
+	created            time.Time
+	updated            time.Time
+}
+
+// kvv2Config is the configuration of a KVv2 mount.
+type kvv2Config struct {
+	MaxVersions        int    `json:"max_versions"`
+	CASRequired        bool   `json:"cas_required"`
+	DeleteVersionAfter string `json:"delete_version_after"`
+}
+
+// PutSecret writes a secret directly (bypassing ACLs), creating a new version
+// for KVv2 mounts. mount is the mount path, e.g. "secret/".
+func (s *Server) PutSecret(mount, path string, data map[string]any) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	mount = strings.TrimSuffix(mount, "/") + "/"
+	if secrets, ok := s.kv1[mount]; ok {
+		secrets[path] = maps.Clone(data)
+		return
+	}
+	s.putKVv2(mount, path, maps.Clone(data))
+}
+
+// Secret returns the data of a secret (the latest version for KVv2 mounts), and
+// false if it doesn't exist (or the latest version was deleted).
+func (s *Server) Secret(mount, path string) (map[string]any, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	mount = strings.TrimSuffix(mount, "/") + "/"
+	if secrets, ok := s.kv1[mount]; ok {
+		data, ok := secrets[path]
+		return maps.Clone(data), ok
+	}
+
+	secret, ok := s.kv2[mount][path]
+	if !ok {
+		return nil, false
+	}
+	v := secret.versions[secret.current]
+	if v == nil || !v.deleted.IsZero() || v.destroyed {
+		return nil, false
+	}
+	return maps.Clone(v.data), true
+}
+
+// listKeys returns the immediate children of prefix, in the provided keys, with
+// "folders" suffixed with "/", the same as Vault LIST responses.
+func listKeys[V any](secrets map[string]V, prefix string) []string {
+	if prefix != "" {
+		prefix = strings.TrimSuffix(prefix, "/") + "/"
+	}
+
+	var keys []string
+	for path := range secrets {
+		rest, ok := strings.CutPrefix(path, prefix)
+		if !ok || rest == "" {
+			continue
+		}
+		if i := strings.Index(rest, "/"); i >= 0 {
+			rest = rest[:i+1]
+		}
+		if !slices.Contains(keys, rest) {
+			keys = append(keys, rest)
+		}
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+func writeKeys(w http.ResponseWriter, keys []string) {
+	if len(keys) == 0 {
+		writeError(w, http.StatusNotFound)
+		return
+	}
+	writeData(w, http.StatusOK, map[string]any{"keys": keys})
+}
+
+func (s *Server) handleKVv1(w http.ResponseWriter, r *http.Request, method, mount, path string) {
+	secrets := s.kv1[mount]
+
+	switch method {
+	case "LIST":
+		writeKeys(w, listKeys(secrets, path))
+	case http.MethodGet:
+		data, ok := secrets[path]
+		if !ok {
+			writeError(w, http.StatusNotFound)
+			return
+		}
+		writeData(w, http.StatusOK, data)
+	case http.MethodPost, http.MethodPut:
+		var data map[string]any
+		if err := decodeBody(r, &data); err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		secrets[path] = data
+		w.WriteHeader(http.StatusNoContent)
+	case http.MethodDelete:
+		delete(secrets, path)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		writeError(w, http.StatusMethodNotAllowed)
+	}
+}
+
+func (s *Server) handleKVv2(w http.ResponseWriter, r *http.Request, method, mount, path string) {
+	if path == "config" {
+		s.handleKVv2Config(w, r, method, mount)
+		return
+	}
+
+	endpoint, name, _ := strings.Cut(path, "/")
+	secrets := s.kv2[mount]
+	secret := secrets[name]
+
+	switch {
+	case endpoint == "metadata" && method == "LIST":
+		writeKeys(w, listKeys(secrets, name))
+	case endpoint == "metadata" && method == http.MethodGet:
+		if secret == nil {
+			writeError(w, http.StatusNotFound)
+			return
+		}
+		writeData(w, http.StatusOK, secret.metadata())
+	case endpoint == "metadata" && (method == http.MethodPost || method == http.MethodPut):
+		s.handleKVv2PutMetadata(w, r, mount, name)
+	case endpoint == "metadata" && method == http.MethodDelete:
+		delete(secrets, name)
+		w.WriteHeader(http.StatusNoContent)
+	case endpoint == "data" && method == http.MethodGet:
+		s.handleKVv2Get(w, r, secret)
+	case endpoint == "data" && (method == http.MethodPost || method == http.MethodPut):
+		s.handleKVv2Put(w, r, mount, name, secret)
+	case endpoint == "data" && method == http.MethodDelete:
+		if secret == nil {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		secret.setVersions([]int{secret.current}, func(v *kvv2Version) {
+			v.deleted = time.Now()
+		})
+		w.WriteHeader(http.StatusNoContent)
+	case slices.Contains([]string{"delete", "undelete", "destroy"}, endpoint) && (method == http.MethodPost || method == http.MethodPut):
+		var input struct {
+			Versions []json.Number `json:"versions"`
+		}
+		if err := decodeBody(r, &input); err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		if len(input.Versions) == 0 {
+			writeError(w, http.StatusBadRequest, "no version number provided")
+			return
+		}
+
+		// Versions may be provided as numbers, or strings.
+		versions := make([]int, len(input.Versions))
+		for i, v := range input.Versions {
+			version, err := strconv.Atoi(v.String())
+			if err != nil {
+				writeError(w, http.StatusBadRequest, "invalid version: "+v.String())
+				return
+			}
+			versions[i] = version
+		}
+
+		if secret == nil {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		secret.setVersions(versions, func(v *kvv2Version) {
+			switch endpoint {
+			case "delete":
+				v.deleted = time.Now()
+			case "undelete":
+				v.deleted = time.Time{}
+			case "destroy":
+				v.destroyed = true
+				v.data = nil
+			}
+		})
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		writeError(w, http.StatusMethodNotAllowed)
+	}
+}
+
+func (s *Server) handleKVv2Get(w http.ResponseWriter, r *http.Request, secret *kvv2Secret) {
+	if secret == nil {
+		writeError(w, http.StatusNotFound)
+		return
+	}
+
+	version := secret.current
+	if v := r.URL.Query().Get("version"); v != "" && v != "0" {
+		var err error
+		version, err = strconv.Atoi(v)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "invalid version")
+			return
+		}
+	}
+
+	v, ok := secret.versions[version]
+	if !ok {
+		writeError(w, http.StatusNotFound)
+		return
+	}
+
+	// Deleted and destroyed versions still return their metadata.
+	if !v.deleted.IsZero() || v.destroyed {
+		writeData(w, http.StatusNotFound, map[string]any{
+			"data":     nil,
+			"metadata": secret.versionMetadata(version),
+		})
+		return
+	}
+
+	writeData(w, http.StatusOK, map[string]any{
+		"data":     v.data,
+		"metadata": secret.versionMetadata(version),
+	})
+}
+
+func (s *Server) handleKVv2Put(w http.ResponseWriter, r *http.Request, mount, name string, secret *kvv2Secret) {
+	var input struct {
+		Data    map[string]any `json:"data"`
+		Options struct {
+			CAS *int `json:"cas"`
+		} `json:"options"`
+	}
+	if err := decodeBody(r, &input); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	current := 0
+	casRequired := s.kv2Config[mount].CASRequired
+	if secret != nil {
+		current = secret.current
+		casRequired = casRequired || secret.casRequired
+	}
+
+	if input.Options.CAS == nil && casRequired {
+		writeError(w, http.StatusBadRequest, "check-and-set parameter required for this call")
+		return
+	}
+	if input.Options.CAS != nil && *input.Options.CAS != current {
+		writeError(w, http.StatusBadRequest, "check-and-set parameter did not match the current version")
+		return
+	}
+
+	secret = s.putKVv2(mount, name, input.Data)
+	writeData(w, http.StatusOK, secret.versionMetadata(secret.current))
+}
+
+// putKVv2 writes a new version of a KVv2 secret, pruning the oldest versions
+// beyond the max versions of the secret (or mount).
+func (s *Server) putKVv2(mount, name string, data map[string]any) *kvv2Secret {
+	now := time.Now()
+
+	secret, ok := s.kv2[mount][name]
+	if !ok {
+		secret = &kvv2Secret{
+			versions:           map[int]*kvv2Version{},
+			oldest:             1,
+			deleteVersionAfter: "0s",
+			created:            now,
+		}
+		s.kv2[mount][name] = secret
+	}
+
+	secret.current++
+	secret.updated = now
+	secret.versions[secret.current] = &kvv2Version{data: data, created: now}
+
+	limit := secret.maxVersions
+	if limit == 0 {
+		limit = s.kv2Config[mount].MaxVersions
+	}
+	if limit == 0 {
+		limit = 10 // Vault's default.
+	}
+	for len(secret.versions) > limit {
+		delete(secret.versions, secret.oldest)
+		secret.oldest++
+	}
+
+	return secret
+}
+
+func (s *Server) handleKVv2PutMetadata(w http.ResponseWriter, r *http.Request, mount, name string) {
+	var input struct {
+		MaxVersions        *int           `json:"max_versions"`
+		CASRequired        *bool          `json:"cas_required"`
+		DeleteVersionAfter *string        `json:"delete_version_after"`
+		CustomMetadata     map[string]any `json:"custom_metadata"`
+	}
+	if err := decodeBody(r, &input); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	secret, ok := s.kv2[mount][name]
+	if !ok {
+		now := time.Now()
+		secret = &kvv2Secret{
+			versions:           map[int]*kvv2Version{},
+			oldest:             0,
+			deleteVersionAfter: "0s",
+			created:            now,
+			updated:            now,
+		}
+		s.kv2[mount][name] = secret
+	}
+
+	if input.MaxVersions != nil {
+		secret.maxVersions = *input.MaxVersions
+	}
+	if input.CASRequired != nil {
+		secret.casRequired = *input.CASRequired
+	}
+	if input.DeleteVersionAfter != nil {
+		d, err := time.ParseDuration(*input.DeleteVersionAfter)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "invalid delete_version_after")
+			return
+		}
+		secret.deleteVersionAfter = d.String()
+	}
+	if input.CustomMetadata != nil {
+		secret.customMetadata = input.CustomMetadata
+	}
+	secret.updated = time.Now()
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) handleKVv2Config(w http.ResponseWriter, r *http.Request, method, mount string) {
+	cfg := s.kv2Config[mount]
+
+	switch method {
+	case http.MethodGet:
+		writeData(w, http.StatusOK, cfg)
+	case http.MethodPost, http.MethodPut:
+		var input struct {
+			MaxVersions        *json.Number `json:"max_versions"`
+			CASRequired        *bool        `json:"cas_required"`
+			DeleteVersionAfter *string      `json:"delete_version_after"`
+		}
+		if err := decodeBody(r, &input); err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		if input.MaxVersions != nil {
+			v, err := input.MaxVersions.Int64()
+			if err != nil {
+				writeError(w, http.StatusBadRequest, "invalid max_versions")
+				return
+			}
+			cfg.MaxVersions = int(v)
+		}
+		if input.CASRequired != nil {
+			cfg.CASRequired = *input.CASRequired
+		}
+		if input.DeleteVersionAfter != nil {
+			d, err := time.ParseDuration(*input.DeleteVersionAfter)
+			if err != nil {
+				writeError(w, http.StatusBadRequest, "invalid delete_version_after")
+				return
+			}
+			cfg.DeleteVersionAfter = d.String()
+		}
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		writeError(w, http.StatusMethodNotAllowed)
+	}
+}
+
+// setVersions invokes fn on all of the provided versions which exist (and
+// haven't been destroyed).
+func (s *kvv2Secret) setVersions(versions []int, fn func(v *kvv2Version)) {
+	for _, version := range versions {
+		if v, ok := s.versions[version]; ok && !v.destroyed {
+			fn(v)
+		}
+	}
+	s.updated = time.Now()
+}
+
+func (s *kvv2Secret) versionMetadata(version int) map[string]any {
+	v := s.versions[version]
+	return map[string]any{
+		"version":         version,
+		"created_time":    timestamp(v.created),
+		"deletion_time":   timestamp(v.deleted),
+		"destroyed":       v.destroyed,
+		"custom_metadata": s.customMetadata,
+	}
+}
+
+func (s *kvv2Secret) metadata() map[string]any {
+	versions := make(map[string]any, len(s.versions))
+	for version, v := range s.versions {
+		versions[strconv.Itoa(version)] = map[string]any{
+			"created_time":  timestamp(v.created),
+			"deletion_time": timestamp(v.deleted),
+			"destroyed":     v.destroyed,
+		}
+	}
+
+	return map[string]any{
+		"cas_required":         s.casRequired,
+		"created_time":         timestamp(s.created),
+		"current_version":      s.current,
+		"custom_metadata":      s.customMetadata,
+		"delete_version_after": s.deleteVersionAfter,
+		"max_versions":         s.maxVersions,
+		"oldest_version":       s.oldest,
+		"updated_time":         timestamp(s.updated),
+		"versions":             versions,
+	}
+}
@@ -0,0 +1,487 @@
+// Copyright (c) Liam Stanley <liam@liam.sh>. All rights reserved. Use of
+// this source code is governed by the MIT license that can be found in
+// the LICENSE file.
+
+package fakevault
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"maps"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	vapi "github.com/hashicorp/vault/api"
+	"github.com/lrstanley/vex/internal/types"
+)
+
+// handleHealth responds with the health of the server, honoring the status code
+// overrides supported by Vault (e.g. "sealedcode").
+func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	health := s.health
+	s.mu.Unlock()
+
+	health.ServerTimeUTC = time.Now().Unix()
+
+	code := func(param string, fallback int) int {
+		if v, err := strconv.Atoi(r.URL.Query().Get(param)); err == nil {
+			return v
+		}
+		return fallback
+	}
+
+	status := http.StatusOK
+	switch {
+	case !health.Initialized:
+		status = code("uninitcode", http.StatusNotImplemented)
+	case health.Sealed:
+		status = code("sealedcode", http.StatusServiceUnavailable)
+	case health.PerformanceStandby:
+		status = code("performancestandbycode", http.StatusTooManyRequests)
+	case health.Standby:
+		status = code("standbycode", http.StatusTooManyRequests)
+	}
+
+	writeJSON(w, status, health)
+}
+
+func (s *Server) handleLookupSelf(w http.ResponseWriter, token *Token) {
+	data := map[string]any{
+		"id":           token.ID,
+		"accessor":     "accessor-" + token.DisplayName,
+		"display_name": token.DisplayName,
+		"policies":     token.Policies,
+		"path":         "auth/token/create",
+		"type":         "service",
+		"ttl":          0,
+		"expire_time":  nil,
+	}
+	if token.ID == RootToken {
+		data["path"] = "auth/token/root"
+	}
+	writeData(w, http.StatusOK, data)
+}
+
+func (s *Server) handleCapabilitiesSelf(w http.ResponseWriter, r *http.Request, token *Token) {
+	var input struct {
+		Paths []string `json:"paths"`
+	}
+	if err := decodeBody(r, &input); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	// Vault returns the capabilities both at the top level and under "data".
+	results := map[string]any{}
+	var all types.ClientCapabilities
+	for _, path := range input.Paths {
+		caps := token.capabilities(strings.TrimPrefix(path, "/"))
+		results[path] = caps
+		all = append(all, caps...)
+	}
+	results["capabilities"] = all
+
+	out := maps.Clone(results)
+	out["data"] = results
+	writeJSON(w, http.StatusOK, out)
+}
+
+// handleUIMounts responds with the mounts the token has access to, similar to
+// Vault, which only lists mounts the token has at least one capability on.
+func (s *Server) handleUIMounts(w http.ResponseWriter, token *Token) {
+	secret := map[string]*vapi.MountOutput{}
+	for path, mount := range s.mounts {
+		if token.isRoot() || token.canAccessMount(path) {
+			secret[path] = mount
+		}
+	}
+
+	writeData(w, http.StatusOK, map[string]any{
+		"secret": secret,
+		"auth":   map[string]any{},
+	})
+}
+
+// canAccessMount returns true if the token has any (non-deny) rules under the
+// provided mount path.
+func (t *Token) canAccessMount(mount string) bool {
+	for pattern, caps := range t.Rules {
+		if slices.Contains(caps, types.CapabilityDeny) {
+			continue
+		}
+		prefix := strings.TrimSuffix(pattern, "*")
+		if strings.HasPrefix(prefix, "+/") || strings.HasPrefix(prefix, mount) || strings.HasPrefix(mount, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *Server) handleMounts(w http.ResponseWriter) {
+	writeData(w, http.StatusOK, s.mounts)
+}
+
+func (s *Server) handleListPolicies(w http.ResponseWriter) {
+	writeData(w, http.StatusOK, map[string]any{
+		"keys": slices.Sorted(maps.Keys(s.policies)),
+	})
+}
+
+func (s *Server) handleGetPolicy(w http.ResponseWriter, name string) {
+	policy, ok := s.policies[name]
+	if !ok {
+		writeError(w, http.StatusNotFound)
+		return
+	}
+	writeData(w, http.StatusOK, map[string]any{
+		"name":   name,
+		"policy": policy,
+	})
+}
+
+func (s *Server) handleGeneratePassword(w http.ResponseWriter, name string) {
+	length, ok := s.pwPolicies[name]
+	if !ok {
+		writeError(w, http.StatusBadRequest, "policy does not exist")
+		return
+	}
+	writeData(w, http.StatusOK, map[string]any{"password": randomString(length)})
+}
+
+func (s *Server) handleRaftConfig(w http.ResponseWriter) {
+	writeData(w, http.StatusOK, map[string]any{
+		"config": map[string]any{
+			"servers": s.raft,
+			"index":   len(s.raft),
+		},
+	})
+}
+
+func (s *Server) handleRaftRemovePeer(w http.ResponseWriter, r *http.Request) {
+	var input struct {
+		ServerID string `json:"server_id"`
+	}
+	if err := decodeBody(r, &input); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	i := slices.IndexFunc(s.raft, func(p *types.RaftConfigPeer) bool {
+		return p.NodeID == input.ServerID
+	})
+	if i < 0 {
+		writeError(w, http.StatusBadRequest, "no server with id "+strconv.Quote(input.ServerID))
+		return
+	}
+
+	s.raft = slices.Delete(s.raft, i, i+1)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// sealStatus returns the seal status of the server. Must be called with the lock
+// held.
+func (s *Server) sealStatus() *vapi.SealStatusResponse {
+	status := &vapi.SealStatusResponse{
+		Type:        "shamir",
+		Initialized: s.health.Initialized,
+		Sealed:      s.health.Sealed,
+		T:           s.unsealThreshold,
+		N:           len(s.unsealKeys),
+		Progress:    len(s.unsealProgress),
+		Nonce:       s.unsealNonce,
+		Version:     s.health.Version,
+		StorageType: "raft",
+	}
+	if !status.Sealed {
+		status.ClusterName = s.health.ClusterName
+		status.ClusterID = s.health.ClusterID
+	}
+	return status
+}
+
+func (s *Server) handleSealStatus(w http.ResponseWriter) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	writeJSON(w, http.StatusOK, s.sealStatus())
+}
+
+// handleUnseal accepts a single unseal key share, unsealing the server once the
+// threshold is reached. Unlike Vault, invalid shares are rejected immediately.
+func (s *Server) handleUnseal(w http.ResponseWriter, r *http.Request) {
+	var input struct {
+		Key   string `json:"key"`
+		Reset bool   `json:"reset"`
+	}
+	if err := decodeBody(r, &input); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch {
+	case input.Reset:
+		s.unsealProgress = nil
+		s.unsealNonce = ""
+	case !s.health.Sealed:
+	case !slices.Contains(s.unsealKeys, input.Key):
+		writeError(w, http.StatusBadRequest, "invalid key")
+		return
+	case !slices.Contains(s.unsealProgress, input.Key):
+		if s.unsealNonce == "" {
+			s.unsealNonce = randomString(16)
+		}
+		s.unsealProgress = append(s.unsealProgress, input.Key)
+		if len(s.unsealProgress) >= s.unsealThreshold {
+			s.health.Sealed = false
+			s.unsealProgress = nil
+			s.unsealNonce = ""
+			s.activeTime = time.Now()
+		}
+	}
+
+	writeJSON(w, http.StatusOK, s.sealStatus())
+}
+
+func (s *Server) handleLeader(w http.ResponseWriter) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.health.Sealed {
+		writeError(w, http.StatusServiceUnavailable, "Vault is sealed")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, vapi.LeaderResponse{
+		HAEnabled:            true,
+		IsSelf:               !s.health.Standby,
+		ActiveTime:           s.activeTime,
+		LeaderAddress:        s.srv.URL,
+		LeaderClusterAddress: "https://" + s.srv.Listener.Addr().String(),
+	})
+}
+
+func (s *Server) handleSeal(w http.ResponseWriter) {
+	s.health.Sealed = true
+	s.unsealProgress = nil
+	s.unsealNonce = ""
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleStepDown steps down the active node, which (as the server is the only
+// node) is immediately re-elected.
+func (s *Server) handleStepDown(w http.ResponseWriter) {
+	if s.health.Standby {
+		writeError(w, http.StatusBadRequest, "node is not active")
+		return
+	}
+	s.activeTime = time.Now()
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleAutopilotState reports all raft peers as healthy.
+func (s *Server) handleAutopilotState(w http.ResponseWriter) {
+	state := map[string]any{
+		"healthy":      true,
+		"voters":       []string{},
+		"non_voters":   []string{},
+		"servers":      map[string]any{},
+		"leader":       "",
+		"upgrade_info": nil,
+	}
+
+	servers := state["servers"].(map[string]any) //nolint:forcetypeassert
+	var voters, nonVoters []string
+	for _, peer := range s.raft {
+		status := "non-voter"
+		switch {
+		case peer.Leader:
+			status = "leader"
+			state["leader"] = peer.NodeID
+		case peer.Voter:
+			status = "voter"
+		}
+		if peer.Voter {
+			voters = append(voters, peer.NodeID)
+		} else {
+			nonVoters = append(nonVoters, peer.NodeID)
+		}
+
+		servers[peer.NodeID] = map[string]any{
+			"id":           peer.NodeID,
+			"name":         peer.NodeID,
+			"address":      peer.Address,
+			"node_status":  "alive",
+			"last_contact": "0s",
+			"last_term":    1,
+			"last_index":   len(s.raft),
+			"healthy":      true,
+			"stable_since": timestamp(s.activeTime),
+			"status":       status,
+			"version":      s.health.Version,
+			"node_type":    "voter",
+		}
+	}
+
+	state["voters"] = voters
+	state["non_voters"] = nonVoters
+	state["failure_tolerance"] = max(0, (len(voters)-1)/2)
+	writeData(w, http.StatusOK, state)
+}
+
+// handleAutopilotConfig updates the autopilot configuration. Same as Vault,
+// fields which aren't provided are left unchanged.
+func (s *Server) handleAutopilotConfig(w http.ResponseWriter, r *http.Request) {
+	var input map[string]any
+	if err := decodeBody(r, &input); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	current, _ := json.Marshal(&s.autopilot)
+	var merged map[string]any
+	_ = json.Unmarshal(current, &merged)
+	maps.Copy(merged, input)
+
+	b, _ := json.Marshal(merged)
+	var config vapi.AutopilotConfig
+	if err := config.UnmarshalJSON(b); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	s.autopilot = config
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// raftSnapshot is the contents of "raft.json" within a snapshot of the server.
+// Only the raft configuration is included.
+type raftSnapshot struct {
+	ClusterID string                  `json:"cluster_id"`
+	Raft      []*types.RaftConfigPeer `json:"raft"`
+	Autopilot *vapi.AutopilotConfig   `json:"autopilot"`
+}
+
+// handleRaftSnapshot responds with a snapshot of the server, which (same as
+// Vault) is a gzipped tar archive, ending with a "SHA256SUMS.sealed" file.
+func (s *Server) handleRaftSnapshot(w http.ResponseWriter) {
+	autopilot := s.autopilot
+	data, _ := json.Marshal(raftSnapshot{
+		ClusterID: s.health.ClusterID,
+		Raft:      s.raft,
+		Autopilot: &autopilot,
+	})
+	sum := sha256.Sum256(data)
+
+	w.Header().Set("Content-Type", "application/gzip")
+	w.WriteHeader(http.StatusOK)
+
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+	for _, file := range []struct {
+		name string
+		data []byte
+	}{
+		{"raft.json", data},
+		{"SHA256SUMS.sealed", []byte(hex.EncodeToString(sum[:]) + "  raft.json\n")},
+	} {
+		_ = tw.WriteHeader(&tar.Header{Name: file.name, Mode: 0o600, Size: int64(len(file.data))})
+		_, _ = tw.Write(file.data)
+	}
+	_ = tw.Close()
+	_ = gz.Close()
+}
+
+// handleRaftRestore restores a snapshot taken with [Server.handleRaftSnapshot].
+// Unless forced, snapshots taken on a different cluster are rejected.
+func (s *Server) handleRaftRestore(w http.ResponseWriter, r *http.Request, force bool) {
+	var snapshot *raftSnapshot
+	var sealed bool
+
+	gz, err := gzip.NewReader(r.Body)
+	if err == nil {
+		tr := tar.NewReader(gz)
+		for {
+			var h *tar.Header
+			if h, err = tr.Next(); err != nil {
+				break
+			}
+			switch h.Name {
+			case "raft.json":
+				snapshot = &raftSnapshot{}
+				if json.NewDecoder(tr).Decode(snapshot) != nil {
+					snapshot = nil
+				}
+			case "SHA256SUMS.sealed":
+				sealed = true
+			}
+		}
+	}
+
+	switch {
+	case snapshot == nil || snapshot.Autopilot == nil || !sealed:
+		writeError(w, http.StatusBadRequest, "failed to read snapshot file")
+	case snapshot.ClusterID != s.health.ClusterID && !force:
+		writeError(w, http.StatusBadRequest, "snapshot was taken on a different cluster, use force to restore")
+	default:
+		s.raft = snapshot.Raft
+		s.autopilot = *snapshot.Autopilot
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// handleRaftJoin accepts all requests to join a cluster (as the server is always
+// already part of its own cluster), as long as the leader is provided.
+func (s *Server) handleRaftJoin(w http.ResponseWriter, r *http.Request) {
+	var input vapi.RaftJoinRequest
+	if err := decodeBody(r, &input); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if input.LeaderAPIAddr == "" && input.AutoJoin == "" {
+		writeError(w, http.StatusBadRequest, "leader_api_addr or auto_join must be provided")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{"joined": true})
+}
+
+// handleMetrics responds with metrics in the JSON format of the in-memory sink
+// of go-metrics (the default format of sys/metrics). Only a few metrics are
+// reported, with every request assumed to have taken 1ms.
+func (s *Server) handleMetrics(w http.ResponseWriter) {
+	requests := float64(s.requests.Load())
+
+	writeJSON(w, http.StatusOK, map[string]any{
+		"Timestamp": time.Now().Truncate(10 * time.Second).UTC().String(),
+		"Gauges": []map[string]any{
+			{"Name": "vault.expire.num_leases", "Value": 0, "Labels": map[string]string{}},
+			{
+				"Name":   "vault.token.count",
+				"Value":  len(s.tokens),
+				"Labels": map[string]string{"namespace": "root"},
+			},
+		},
+		"Counters": []map[string]any{},
+		"Samples": []map[string]any{{
+			"Name":   "vault.core.handle_request",
+			"Count":  requests,
+			"Rate":   requests / 10,
+			"Sum":    requests,
+			"Min":    1,
+			"Max":    1,
+			"Mean":   1,
+			"Stddev": 0,
+			"Labels": map[string]string{},
+		}},
+	})
+}
@@ -0,0 +1,180 @@
+// Copyright (c) Liam Stanley <liam@liam.sh>. All rights reserved. Use of
+// this source code is governed by the MIT license that can be found in
+// the LICENSE file.
+
+package api
+
+import (
+	"io"
+	"log/slog"
+	"os"
+	"os/user"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	tea "charm.land/bubbletea/v2"
+	vapi "github.com/hashicorp/vault/api"
+	"github.com/lrstanley/vex/internal/config"
+	"github.com/lrstanley/vex/internal/types"
+)
+
+// Journal operations, see [config.JournalEntry.Operation].
+const (
+	JournalOpWrite          = "write"
+	JournalOpDelete         = "delete"
+	JournalOpUndelete       = "undelete"
+	JournalOpDestroy        = "destroy"
+	JournalOpWriteMetadata  = "write-metadata"
+	JournalOpWriteMountConf = "write-mount-config"
+	JournalOpRemoveRaftPeer = "remove-raft-peer"
+	JournalOpWriteAutopilot = "write-autopilot-config"
+	JournalOpRestoreRaft    = "restore-raft-snapshot"
+	JournalOpJoinRaft       = "join-raft"
+	JournalOpUnseal         = "unseal"
+	JournalOpResetUnseal    = "reset-unseal"
+	JournalOpSeal           = "seal"
+	JournalOpStepDown       = "step-down"
+	JournalOpEnableAudit    = "enable-audit-device"
+	JournalOpDisableAudit   = "disable-audit-device"
+)
+
+var _ types.Client = &journalClient{} // Ensure journalClient implements types.Client.
+
+// journalClient wraps a client, recording all operations which modify the Vault
+// server in the action journal (see [config.AppendJournal]).
+type journalClient struct {
+	types.Client
+
+	user     string
+	identity atomic.Pointer[string]
+}
+
+// NewJournalClient wraps the provided client, so that all operations which modify
+// the Vault server are recorded in the action journal once they complete, whether
+// they succeed or not.
+func NewJournalClient(c types.Client) types.Client {
+	jc := &journalClient{Client: c, user: os.Getenv("USER")}
+	if u, err := user.Current(); err == nil {
+		jc.user = u.Username
+	}
+	return jc
+}
+
+func (c *journalClient) Update(msg tea.Msg) tea.Cmd {
+	// Track the identity of the token, so it can be recorded alongside the local user.
+	if vm, ok := msg.(types.ClientMsg); ok && vm.Error == nil {
+		if v, ok := vm.Msg.(types.ClientTokenLookupSelfMsg); ok && v.Result != nil {
+			c.identity.Store(&v.Result.DisplayName)
+		}
+	}
+	return c.Client.Update(msg)
+}
+
+// record returns a command which invokes cmd, and records the result in the
+// journal before passing it on.
+func (c *journalClient) record(op, path string, versions []int, cmd tea.Cmd) tea.Cmd {
+	return func() tea.Msg {
+		msg := cmd()
+
+		cluster, namespace, _ := strings.Cut(c.Profile(), "#")
+		entry := config.JournalEntry{
+			Time:      time.Now(),
+			User:      c.user,
+			Cluster:   cluster,
+			Namespace: namespace,
+			Operation: op,
+			Path:      path,
+			Versions:  versions,
+		}
+		if identity := c.identity.Load(); identity != nil {
+			entry.Identity = *identity
+		}
+		if vm, ok := msg.(types.ClientMsg); ok {
+			if vm.Error != nil {
+				entry.Error = vm.Error.Error()
+			} else if v, ok := vm.Msg.(types.ClientSuccessMsg); ok && len(versions) == 0 && v.Version > 0 {
+				// Writes aren't aware of the version they create until they complete.
+				entry.Versions = []int{v.Version}
+			}
+		}
+
+		if err := config.AppendJournal(entry); err != nil {
+			slog.Error("failed to record action in journal", "error", err, "operation", op, "path", path) //nolint:sloglint
+		}
+		return msg
+	}
+}
+
+func (c *journalClient) PutKVv2Metadata(uuid string, mount *types.Mount, path string, input *types.KVv2MetadataInput) tea.Cmd {
+	return c.record(JournalOpWriteMetadata, mount.Path+path, nil, c.Client.PutKVv2Metadata(uuid, mount, path, input))
+}
+
+func (c *journalClient) PutKVv2MountConfig(uuid string, mount *types.Mount, cfg *types.KVv2MountConfig) tea.Cmd {
+	return c.record(JournalOpWriteMountConf, mount.Path, nil, c.Client.PutKVv2MountConfig(uuid, mount, cfg))
+}
+
+func (c *journalClient) PutKVSecret(uuid string, mount *types.Mount, path string, data map[string]any, cas int) tea.Cmd {
+	return c.record(JournalOpWrite, mount.Path+path, nil, c.Client.PutKVSecret(uuid, mount, path, data, cas))
+}
+
+func (c *journalClient) DeleteKVSecret(uuid string, mount *types.Mount, path string, versions ...int) tea.Cmd {
+	return c.record(JournalOpDelete, mount.Path+path, versions, c.Client.DeleteKVSecret(uuid, mount, path, versions...))
+}
+
+func (c *journalClient) UndeleteKVSecret(uuid string, mount *types.Mount, path string, versions ...int) tea.Cmd {
+	return c.record(JournalOpUndelete, mount.Path+path, versions, c.Client.UndeleteKVSecret(uuid, mount, path, versions...))
+}
+
+func (c *journalClient) DestroyKVSecret(uuid string, mount *types.Mount, path string, versions ...int) tea.Cmd {
+	return c.record(JournalOpDestroy, mount.Path+path, versions, c.Client.DestroyKVSecret(uuid, mount, path, versions...))
+}
+
+func (c *journalClient) RemoveRaftPeer(uuid, serverID string) tea.Cmd {
+	return c.record(JournalOpRemoveRaftPeer, serverID, nil, c.Client.RemoveRaftPeer(uuid, serverID))
+}
+
+func (c *journalClient) PutRaftAutopilotConfig(uuid string, config *vapi.AutopilotConfig) tea.Cmd {
+	return c.record(
+		JournalOpWriteAutopilot,
+		"sys/storage/raft/autopilot/configuration",
+		nil,
+		c.Client.PutRaftAutopilotConfig(uuid, config),
+	)
+}
+
+func (c *journalClient) RestoreRaftSnapshot(uuid string, r io.Reader, force bool) tea.Cmd {
+	path := "sys/storage/raft/snapshot"
+	if force {
+		path = "sys/storage/raft/snapshot-force"
+	}
+	return c.record(JournalOpRestoreRaft, path, nil, c.Client.RestoreRaftSnapshot(uuid, r, force))
+}
+
+func (c *journalClient) JoinRaft(uuid string, req *vapi.RaftJoinRequest) tea.Cmd {
+	return c.record(JournalOpJoinRaft, req.LeaderAPIAddr, nil, c.Client.JoinRaft(uuid, req))
+}
+
+func (c *journalClient) Unseal(uuid, key string) tea.Cmd {
+	return c.record(JournalOpUnseal, "sys/unseal", nil, c.Client.Unseal(uuid, key))
+}
+
+func (c *journalClient) ResetUnseal(uuid string) tea.Cmd {
+	return c.record(JournalOpResetUnseal, "sys/unseal", nil, c.Client.ResetUnseal(uuid))
+}
+
+func (c *journalClient) Seal(uuid string) tea.Cmd {
+	return c.record(JournalOpSeal, "sys/seal", nil, c.Client.Seal(uuid))
+}
+
+func (c *journalClient) StepDown(uuid string) tea.Cmd {
+	return c.record(JournalOpStepDown, "sys/step-down", nil, c.Client.StepDown(uuid))
+}
+
+func (c *journalClient) EnableAuditDevice(uuid, path string, options *vapi.EnableAuditOptions) tea.Cmd {
+	return c.record(JournalOpEnableAudit, "sys/audit/"+path, nil, c.Client.EnableAuditDevice(uuid, path, options))
+}
+
+func (c *journalClient) DisableAuditDevice(uuid, path string) tea.Cmd {
+	return c.record(JournalOpDisableAudit, "sys/audit/"+path, nil, c.Client.DisableAuditDevice(uuid, path))
+}
@@ -0,0 +1,51 @@
+// Copyright (c) Liam Stanley <liam@liam.sh>. All rights reserved. Use of
+// this source code is governed by the MIT license that can be found in
+// the LICENSE file.
+
+package api
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/lrstanley/vex/internal/config"
+	"github.com/lrstanley/vex/internal/types"
+)
+
+func TestJournalClient(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	config.InitConfigPath()
+
+	c := NewJournalClient(NewMockClient())
+	mount := getMount(t, c, "kv-v2-1/")
+
+	run[types.ClientSuccessMsg](t, c.PutKVSecret("", mount, "app/db", map[string]any{"user": "admin"}, 0))
+	run[types.ClientSuccessMsg](t, c.PutKVSecret("", mount, "app/db", map[string]any{"user": "root"}, 1))
+	run[types.ClientSuccessMsg](t, c.DeleteKVSecret("", mount, "app/db", 1))
+	_ = runErr(t, c.PutKVSecret("", mount, "app/db", map[string]any{"user": "stale"}, 1))
+
+	entries, err := config.ReadJournal()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []struct {
+		op       string
+		versions []int
+		failed   bool
+	}{
+		{op: JournalOpWrite, versions: []int{1}},
+		{op: JournalOpWrite, versions: []int{2}},
+		{op: JournalOpDelete, versions: []int{1}},
+		{op: JournalOpWrite, failed: true},
+	}
+	if len(entries) != len(want) {
+		t.Fatalf("expected %d journal entries, got %d: %+v", len(want), len(entries), entries)
+	}
+
+	for i, e := range entries {
+		if e.Operation != want[i].op || e.Path != "kv-v2-1/app/db" || !slices.Equal(e.Versions, want[i].versions) || e.Succeeded() == want[i].failed {
+			t.Errorf("unexpected journal entry %d: %+v", i, e)
+		}
+	}
+}
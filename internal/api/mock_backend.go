@@ -0,0 +1,533 @@
+// Copyright (c) Liam Stanley <liam@liam.sh>. All rights reserved. Use of
+// this source code is governed by the MIT license that can be found in
+// the LICENSE file.
+
+package api
+
+import (
+	"errors"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	vapi "github.com/hashicorp/vault/api"
+	"github.com/lrstanley/vex/internal/types"
+)
+
+var (
+	// errMockSealed is returned for all requests (other than health checks) while
+	// the mock is sealed.
+	errMockSealed = errors.New("Vault is sealed") //nolint:staticcheck
+
+	// errMockPermissionDenied is returned for requests to paths without the
+	// required capability.
+	errMockPermissionDenied = errors.New("permission denied")
+)
+
+// MockFlags are the command line flags used to run vex against a [MockClient],
+// rather than a Vault server.
+type MockFlags struct {
+	Mock        bool `help:"run against an in-memory mock vault server with example data, rather than a vault server (no changes are persisted)"`
+	MockOptions struct {
+		Latency time.Duration `default:"150ms" help:"latency of every request to the mock vault server"`
+		Sealed  bool          `help:"start with the mock vault server sealed"`
+		Deny    []string      `placeholder:"PATTERN" help:"deny access to paths matching the pattern (e.g. kv-v2-1/foo/*)"`
+		Fail    []string      `placeholder:"PATTERN" help:"fail all requests to paths matching the pattern (e.g. sys/storage/raft/*)"`
+	} `embed:"" group:"mock" prefix:"mock." envprefix:"MOCK_"`
+}
+
+// NewMockClientFromFlags returns a new [MockClient], configured using the provided
+// flags.
+func NewMockClientFromFlags(flags MockFlags) *MockClient {
+	m := NewMockClient()
+	m.SetLatency(flags.MockOptions.Latency)
+	m.SetSealed(flags.MockOptions.Sealed)
+	for _, pattern := range flags.MockOptions.Deny {
+		m.DenyPath(pattern)
+	}
+	for _, pattern := range flags.MockOptions.Fail {
+		m.FailPath(pattern, errors.New("injected failure"))
+	}
+	return m
+}
+
+// mockUnsealedPaths are the paths which are available while the mock is sealed.
+var mockUnsealedPaths = []string{"sys/health", "sys/seal-status", "sys/unseal"}
+
+// mockKVv2Version is a single version of a KVv2 secret.
+type mockKVv2Version struct {
+	data      map[string]any
+	created   time.Time
+	deleted   time.Time
+	destroyed bool
+}
+
+// mockKVv2Secret is a KVv2 secret, with all of its versions and metadata.
+type mockKVv2Secret struct {
+	versions           map[int]*mockKVv2Version
+	current            int
+	oldest             int
+	maxVersions        int
+	casRequired        bool
+	deleteVersionAfter time.Duration
+	customMetadata     map[string]any
+	created            time.Time
+	updated            time.Time
+}
+
+// mockRule applies a value to all paths matching a pattern.
+type mockRule[T any] struct {
+	pattern string
+	value   T
+}
+
+// seed populates the mock with example data.
+func (m *MockClient) seed() {
+	m.AddMount("kv-v1-1/", "kv", map[string]string{"version": "1"})
+	m.AddMount("kv-v2-1/", "kv", map[string]string{"version": "2"})
+	m.AddMount("cubbyhole/", "cubbyhole", nil)
+
+	m.mu.Lock()
+	m.mounts[0].Description = "KV v1"
+	m.mounts[0].PluginVersion = "v0.24.0+builtin"
+	m.mounts[0].DeprecationStatus = "supported"
+	m.mounts[1].Description = "KV v2"
+	m.mounts[2].Description = "per-token private secret storage"
+	m.mu.Unlock()
+
+	for _, mount := range []string{"kv-v1-1/", "kv-v2-1/"} {
+		for _, path := range []string{"bar", "baz", "foo/bar"} {
+			m.PutSecret(mount, path, map[string]any{"foo": "bar"})
+			m.PutSecret(mount, path, map[string]any{"foo": "bar", "bar": "baz"})
+		}
+		m.PutSecret(mount, "foo/json", map[string]any{
+			"foo":   "bar",
+			"bar":   "baz",
+			"inner": map[string]any{"foo": "bar", "bar": "baz"},
+		})
+	}
+	m.PutSecret("cubbyhole/", "scratch", map[string]any{"note": "only visible to this token"})
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.kv2["kv-v2-1/"]["bar"].customMetadata = map[string]any{"foo": "bar"}
+
+	for i := 1; i <= 5; i++ {
+		m.policies["test-policy-"+strconv.Itoa(i)] = mockPolicy
+	}
+
+	m.raft = []*types.RaftConfigPeer{
+		{NodeID: "raft-1", Address: "10.0.0.1:8201", Leader: true, Voter: true, ProtocolVersion: "\u0003"},
+		{NodeID: "raft-2", Address: "10.0.0.2:8201", Voter: true, ProtocolVersion: "\u0003"},
+		{NodeID: "raft-3", Address: "10.0.0.3:8201", ProtocolVersion: "\u0003"},
+	}
+
+	m.autopilot = vapi.AutopilotConfig{
+		LastContactThreshold:           10 * time.Second,
+		DeadServerLastContactThreshold: 24 * time.Hour,
+		MaxTrailingLogs:                1000,
+		ServerStabilizationTime:        10 * time.Second,
+	}
+
+	m.capabilities = []mockRule[types.ClientCapabilities]{
+		{
+			pattern: "*",
+			value: types.ClientCapabilities{
+				types.CapabilityCreate,
+				types.CapabilityRead,
+				types.CapabilityUpdate,
+				types.CapabilityDelete,
+				types.CapabilityList,
+			},
+		},
+		{pattern: "sys/seal", value: types.ClientCapabilities{types.CapabilityUpdate, types.CapabilitySudo}},
+		{pattern: "sys/step-down", value: types.ClientCapabilities{types.CapabilityUpdate, types.CapabilitySudo}},
+		{
+			pattern: "sys/audit*",
+			value: types.ClientCapabilities{
+				types.CapabilityCreate,
+				types.CapabilityRead,
+				types.CapabilityUpdate,
+				types.CapabilityDelete,
+				types.CapabilitySudo,
+			},
+		},
+		{
+			pattern: "sys/storage/raft/snapshot*",
+			value:   types.ClientCapabilities{types.CapabilityRead, types.CapabilityUpdate, types.CapabilitySudo},
+		},
+	}
+
+	m.audit = map[string]*vapi.Audit{
+		"file/": {
+			Type:        "file",
+			Description: "primary audit log",
+			Options:     map[string]string{"file_path": "/var/log/vault/audit.log", "format": "json"},
+			Path:        "file/",
+		},
+		"syslog/": {
+			Type:    "syslog",
+			Options: map[string]string{"facility": "AUTH", "tag": "vault"},
+			Local:   true,
+			Path:    "syslog/",
+		},
+	}
+
+	m.unsealThreshold = 3
+	m.unsealShares = 5
+	m.activeTime = time.Now().Add(-72 * time.Hour)
+}
+
+// SetLatency sets how long every request takes.
+func (m *MockClient) SetLatency(d time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.latency = d
+}
+
+// SetSealed seals (or unseals) the mock. While sealed, health checks report the
+// server as sealed, and all other requests (other than unsealing) fail. Any 3
+// distinct keys unseal the mock.
+func (m *MockClient) SetSealed(sealed bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.sealed = sealed
+	m.unsealProgress = nil
+}
+
+// FailPath causes all requests to paths matching pattern to fail with err.
+// Patterns use the same syntax as Vault ACL policy paths ("+" matches a single
+// path segment, and a trailing "*" matches anything), and are matched against
+// the full path, including the mount, e.g. "kv-v2-1/foo/*" or "sys/health".
+func (m *MockClient) FailPath(pattern string, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.faults = append(m.faults, mockRule[error]{pattern: pattern, value: err})
+}
+
+// SetCapabilities sets the capabilities of the token on paths matching pattern
+// (see [MockClient.FailPath] for the syntax), which are enforced on all requests.
+// Rules added later take precedence. By default, the token can create, read,
+// update, delete and list all paths.
+func (m *MockClient) SetCapabilities(pattern string, capabilities ...types.ClientCapability) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.capabilities = append(m.capabilities, mockRule[types.ClientCapabilities]{
+		pattern: pattern,
+		value:   slices.Clone(capabilities),
+	})
+}
+
+// DenyPath denies access to paths matching pattern (see [MockClient.FailPath]
+// for the syntax).
+func (m *MockClient) DenyPath(pattern string) {
+	m.SetCapabilities(pattern, types.CapabilityDeny)
+}
+
+// AddMount adds (or replaces) a secret engine mount at the provided path, e.g.
+// AddMount("other/", "kv", map[string]string{"version": "2"}).
+func (m *MockClient) AddMount(path, engine string, options map[string]string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	path = strings.TrimSuffix(path, "/") + "/"
+	mount := &types.Mount{
+		Path: path,
+		MountOutput: &vapi.MountOutput{
+			UUID:     "mock-" + strconv.Itoa(len(m.mounts)+1),
+			Type:     engine,
+			Accessor: engine + "_mock",
+			Options:  options,
+		},
+	}
+
+	if i := slices.IndexFunc(m.mounts, func(v *types.Mount) bool { return v.Path == path }); i >= 0 {
+		m.mounts[i] = mount
+	} else {
+		m.mounts = append(m.mounts, mount)
+	}
+
+	delete(m.kv1, path)
+	delete(m.kv2, path)
+	switch {
+	case mount.KVVersion() == 2:
+		m.kv2[path] = map[string]*mockKVv2Secret{}
+		m.kv2Config[path] = &types.KVv2MountConfig{}
+	case mount.IsKVLike():
+		m.kv1[path] = map[string]map[string]any{}
+	}
+}
+
+// PutSecret writes a secret directly (bypassing faults and capabilities),
+// creating a new version for KVv2 mounts. mount is the mount path, e.g.
+// "kv-v2-1/".
+func (m *MockClient) PutSecret(mount, path string, data map[string]any) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	mount = strings.TrimSuffix(mount, "/") + "/"
+	if secrets, ok := m.kv1[mount]; ok {
+		secrets[path] = maps.Clone(data)
+		return
+	}
+	if _, ok := m.kv2[mount]; ok {
+		m.putKVv2(mount, path, maps.Clone(data))
+	}
+}
+
+// Secret returns the data of a secret (the latest version for KVv2 mounts), and
+// false if it doesn't exist (or the latest version was deleted).
+func (m *MockClient) Secret(mount, path string) (map[string]any, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	mount = strings.TrimSuffix(mount, "/") + "/"
+	if secrets, ok := m.kv1[mount]; ok {
+		data, ok := secrets[path]
+		return maps.Clone(data), ok
+	}
+
+	v, ok := m.kvv2Version(mount, path, 0)
+	if !ok {
+		return nil, false
+	}
+	return maps.Clone(v.data), true
+}
+
+// request simulates a request to path, which requires the provided capability
+// (if any), returning an error if the request should fail. Must not be called
+// with the lock held.
+func (m *MockClient) request(path string, capability types.ClientCapability) error {
+	m.mu.Lock()
+	latency, sealed := m.latency, m.sealed
+	var fault error
+	for _, rule := range m.faults {
+		if mockMatch(rule.pattern, path) {
+			fault = rule.value
+		}
+	}
+	capabilities := m.capabilitiesFor(path)
+	m.mu.Unlock()
+
+	time.Sleep(latency)
+
+	switch {
+	case m.ShouldError:
+		return errors.New("test error")
+	case fault != nil:
+		return fault
+	case sealed && !slices.Contains(mockUnsealedPaths, path):
+		return errMockSealed
+	case capability != "" && !capabilities.Contains(capability):
+		return errMockPermissionDenied
+	}
+	return nil
+}
+
+// capabilitiesFor returns the capabilities of the token on path. Must be called
+// with the lock held.
+func (m *MockClient) capabilitiesFor(path string) types.ClientCapabilities {
+	capabilities := types.ClientCapabilities{types.CapabilityDeny}
+	for _, rule := range m.capabilities {
+		if mockMatch(rule.pattern, path) {
+			capabilities = rule.value
+		}
+	}
+	return slices.Clone(capabilities)
+}
+
+// writeCapability returns the capability required to write the secret at path,
+// which depends on whether it already exists.
+func (m *MockClient) writeCapability(mount *types.Mount, path string) types.ClientCapability {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	_, exists := m.kv1[mount.Path][path]
+	if !exists {
+		_, exists = m.kv2[mount.Path][path]
+	}
+	if exists {
+		return types.CapabilityUpdate
+	}
+	return types.CapabilityCreate
+}
+
+// mockMatch returns true if path matches pattern, using the same syntax as Vault
+// ACL policy paths.
+func mockMatch(pattern, path string) bool {
+	prefix, glob := strings.CutSuffix(pattern, "*")
+
+	patternParts := strings.Split(prefix, "/")
+	pathParts := strings.Split(path, "/")
+
+	if len(pathParts) < len(patternParts) || (!glob && len(pathParts) != len(patternParts)) {
+		return false
+	}
+
+	for i, part := range patternParts {
+		switch {
+		case part == "+":
+			continue
+		case glob && i == len(patternParts)-1:
+			if !strings.HasPrefix(pathParts[i], part) {
+				return false
+			}
+		case part != pathParts[i]:
+			return false
+		}
+	}
+	return true
+}
+
+// mockListKeys returns the immediate children of prefix, in the provided keys,
+// with "folders" suffixed with "/", the same as Vault LIST responses.
+func mockListKeys[V any](secrets map[string]V, prefix string) []string {
+	var keys []string
+	for path := range secrets {
+		rest, ok := strings.CutPrefix(path, prefix)
+		if !ok || rest == "" {
+			continue
+		}
+		if i := strings.Index(rest, "/"); i >= 0 {
+			rest = rest[:i+1]
+		}
+		if !slices.Contains(keys, rest) {
+			keys = append(keys, rest)
+		}
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+// list returns the keys directly under path, within the mount. Must be called
+// with the lock held.
+func (m *MockClient) list(mount *types.Mount, path string) []string {
+	if path != "" {
+		path = strings.TrimSuffix(path, "/") + "/"
+	}
+	if secrets, ok := m.kv1[mount.Path]; ok {
+		return mockListKeys(secrets, path)
+	}
+	return mockListKeys(m.kv2[mount.Path], path)
+}
+
+// secretTree returns the tree of all secrets under path, within the mount. Must
+// be called with the lock held.
+func (m *MockClient) secretTree(mount *types.Mount, path string, requests *int64) types.ClientSecretTree {
+	*requests++
+
+	var tree types.ClientSecretTree
+	for _, key := range m.list(mount, path) {
+		ref := &types.ClientSecretTreeRef{
+			Mount:        mount,
+			Path:         key,
+			Capabilities: m.capabilitiesFor(mount.Path + path + key),
+		}
+		if strings.HasSuffix(key, "/") {
+			ref.Leafs = m.secretTree(mount, path+key, requests)
+		}
+		tree = append(tree, ref)
+	}
+	return tree
+}
+
+// kvv2Version returns the provided version of a KVv2 secret (or the current
+// version, if version is < 1), and false if it doesn't exist, or has been deleted
+// or destroyed. Must be called with the lock held.
+func (m *MockClient) kvv2Version(mount, path string, version int) (*mockKVv2Version, bool) {
+	secret, ok := m.kv2[mount][path]
+	if !ok {
+		return nil, false
+	}
+	if version < 1 {
+		version = secret.current
+	}
+	v, ok := secret.versions[version]
+	if !ok || !v.deleted.IsZero() || v.destroyed {
+		return nil, false
+	}
+	return v, true
+}
+
+// putKVv2 writes a new version of a KVv2 secret, pruning the oldest versions
+// beyond the max versions of the secret (or mount). Must be called with the lock
+// held.
+func (m *MockClient) putKVv2(mount, path string, data map[string]any) {
+	now := time.Now()
+
+	secret, ok := m.kv2[mount][path]
+	if !ok {
+		secret = &mockKVv2Secret{
+			versions: map[int]*mockKVv2Version{},
+			oldest:   1,
+			created:  now,
+		}
+		m.kv2[mount][path] = secret
+	}
+
+	secret.current++
+	secret.updated = now
+	secret.versions[secret.current] = &mockKVv2Version{data: data, created: now}
+
+	limit := secret.maxVersions
+	if limit == 0 {
+		limit = m.kv2Config[mount].MaxVersions
+	}
+	if limit == 0 {
+		limit = 10 // Vault's default.
+	}
+	for len(secret.versions) > limit {
+		delete(secret.versions, secret.oldest)
+		secret.oldest++
+	}
+}
+
+// setVersions invokes fn on all of the provided versions which exist (and
+// haven't been destroyed).
+func (s *mockKVv2Secret) setVersions(versions []int, fn func(v *mockKVv2Version)) {
+	for _, version := range versions {
+		if v, ok := s.versions[version]; ok && !v.destroyed {
+			fn(v)
+		}
+	}
+	s.updated = time.Now()
+}
+
+// versionList returns the metadata of all versions, sorted by version.
+func (s *mockKVv2Secret) versionList() []vapi.KVVersionMetadata {
+	versions := make([]vapi.KVVersionMetadata, 0, len(s.versions))
+	for version, v := range s.versions {
+		versions = append(versions, vapi.KVVersionMetadata{
+			Version:      version,
+			CreatedTime:  v.created,
+			DeletionTime: v.deleted,
+			Destroyed:    v.destroyed,
+		})
+	}
+	slices.SortFunc(versions, func(a, b vapi.KVVersionMetadata) int {
+		return a.Version - b.Version
+	})
+	return versions
+}
+
+func (s *mockKVv2Secret) metadata() *vapi.KVMetadata {
+	versions := make(map[string]vapi.KVVersionMetadata, len(s.versions))
+	for _, v := range s.versionList() {
+		versions[strconv.Itoa(v.Version)] = v
+	}
+
+	return &vapi.KVMetadata{
+		CASRequired:        s.casRequired,
+		CreatedTime:        s.created,
+		CurrentVersion:     s.current,
+		CustomMetadata:     maps.Clone(s.customMetadata),
+		DeleteVersionAfter: s.deleteVersionAfter,
+		MaxVersions:        s.maxVersions,
+		OldestVersion:      s.oldest,
+		UpdatedTime:        s.updated,
+		Versions:           versions,
+	}
+}
@@ -5,10 +5,20 @@
 package api
 
 import (
+	"compress/gzip"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
+	"maps"
+	"math"
+	"slices"
+	"strconv"
 	"strings"
+	"sync"
 	"sync/atomic"
 	"time"
 
@@ -17,50 +27,6 @@ import (
 	"github.com/lrstanley/vex/internal/types"
 )
 
-var mockMounts = []*types.Mount{
-	{
-		Path: "kv-v1-1/",
-		MountOutput: &vapi.MountOutput{
-			Description: "KV v1",
-			UUID:        "abc-123",
-			Type:        "kv",
-			Options: map[string]string{
-				"version": "1",
-			},
-			SealWrap:          false,
-			PluginVersion:     "v0.24.0+builtin",
-			DeprecationStatus: "supported",
-		},
-	},
-	{
-		Path: "kv-v2-1/",
-		MountOutput: &vapi.MountOutput{
-			Description: "KV v2",
-			UUID:        "abc-123-2",
-			Type:        "kv",
-			Options: map[string]string{
-				"version": "2",
-			},
-		},
-	},
-	{
-		Path: "cubbyhole/",
-		MountOutput: &vapi.MountOutput{
-			Description: "per-token private secret storage",
-			UUID:        "abc-123-3",
-			Type:        "cubbyhole",
-		},
-	},
-}
-
-var mockPolicyList = []string{
-	"test-policy-1",
-	"test-policy-2",
-	"test-policy-3",
-	"test-policy-4",
-	"test-policy-5",
-}
-
 var mockPolicy = `# Admin policy for general administration
 path "auth/*" {
   capabilities = ["create", "read", "update", "delete", "list"]
@@ -84,6 +50,11 @@ path "kv-v2-*/*" {
 
 var _ types.Client = &MockClient{}
 
+// MockClient is a [types.Client] backed by an in-memory mock of Vault, seeded
+// with example data. Writes, KVv2 versions, deletes and destroys are all
+// reflected in subsequent requests, and latency, errors, permission denials and
+// the seal status can be injected. It isn't a complete (or strictly accurate)
+// implementation of Vault.
 type MockClient struct {
 	ShouldError        bool
 	firstHealthChecked atomic.Bool
@@ -91,6 +62,35 @@ type MockClient struct {
 	// MockTokenType, if set, overrides the token type reported by
 	// [MockClient.TokenType]. Defaults to [types.TokenTypeService].
 	MockTokenType types.TokenType
+
+	mu           sync.Mutex
+	latency      time.Duration
+	sealed       bool
+	mounts       []*types.Mount
+	kv1          map[string]map[string]map[string]any // Mount to path to data.
+	kv2          map[string]map[string]*mockKVv2Secret
+	kv2Config    map[string]*types.KVv2MountConfig
+	policies     map[string]string
+	raft         []*types.RaftConfigPeer
+	autopilot    vapi.AutopilotConfig
+	audit        map[string]*vapi.Audit // Keyed by path, e.g. "file/".
+	capabilities []mockRule[types.ClientCapabilities]
+	faults       []mockRule[error]
+
+	unsealThreshold int
+	unsealShares    int
+	unsealProgress  []string // Key shares submitted so far.
+	activeTime      time.Time
+
+	metricsPolls int // Number of times the metrics were read, used to vary them.
+}
+
+func (m *MockClient) Profile() string {
+	return "http://127.0.0.1:8200"
+}
+
+func (m *MockClient) ReadOnly() bool {
+	return false
 }
 
 func (m *MockClient) TokenType() types.TokenType {
@@ -100,8 +100,18 @@ func (m *MockClient) TokenType() types.TokenType {
 	return types.TokenTypeService
 }
 
+// NewMockClient returns a new [MockClient], seeded with example data, which
+// responds without any latency.
 func NewMockClient() *MockClient {
-	return &MockClient{}
+	m := &MockClient{
+		kv1:       map[string]map[string]map[string]any{},
+		kv2:       map[string]map[string]*mockKVv2Secret{},
+		kv2Config: map[string]*types.KVv2MountConfig{},
+		policies:  map[string]string{},
+		audit:     map[string]*vapi.Audit{},
+	}
+	m.seed()
+	return m
 }
 
 func (m *MockClient) Init() tea.Cmd {
@@ -146,72 +156,148 @@ func (m *MockClient) Update(msg tea.Msg) tea.Cmd { //nolint:dupl
 	return tea.Batch(cmds...)
 }
 
-func (m *MockClient) ErrorOr(uuid string, msg tea.Msg) tea.Cmd {
-	if m.ShouldError {
-		return func() tea.Msg {
-			return types.ClientMsg{
-				UUID:  uuid,
-				Error: errors.New("test error"),
-			}
-		}
-	}
-	return func() tea.Msg {
-		return types.ClientMsg{
-			UUID: uuid,
-			Msg:  msg,
+func (m *MockClient) GetHealth(uuid string) tea.Cmd {
+	return wrapHandler(uuid, func() (*types.ClientConfigMsg, error) {
+		if err := m.request("sys/health", ""); err != nil {
+			return nil, err
 		}
-	}
+
+		m.mu.Lock()
+		defer m.mu.Unlock()
+
+		return &types.ClientConfigMsg{
+			Address: "http://localhost:8200",
+			Health: &vapi.HealthResponse{
+				Initialized: true,
+				Sealed:      m.sealed,
+				Standby:     false,
+				Version:     "1.2.3",
+				ClusterName: "test-cluster",
+				ClusterID:   "test-cluster-id",
+			},
+		}, nil
+	})
 }
 
-func (m *MockClient) GetHealth(uuid string) tea.Cmd {
-	return m.ErrorOr(uuid, types.ClientConfigMsg{
-		Address: "http://localhost:8200",
-		Health: &vapi.HealthResponse{
-			Initialized: true,
-			Sealed:      false,
-			Standby:     false,
-			Version:     "1.2.3",
-			ClusterName: "test-cluster",
-			ClusterID:   "test-cluster-id",
-		},
+// GetNodeHealth reports the health of the nodes of the mock raft configuration,
+// at the default API port (8200) of their cluster address. The configured
+// address is the leader, and non-voters are performance standbys. Any other
+// address is unreachable.
+func (m *MockClient) GetNodeHealth(uuid, address string) tea.Cmd {
+	return wrapHandler(uuid, func() (*types.ClientNodeHealthMsg, error) {
+		msg := &types.ClientNodeHealthMsg{Address: address}
+
+		if err := m.request("sys/health", ""); err != nil {
+			msg.Error = err.Error()
+			return msg, nil
+		}
+
+		m.mu.Lock()
+		defer m.mu.Unlock()
+
+		i := slices.IndexFunc(m.raft, func(p *types.RaftConfigPeer) bool {
+			host, _, _ := strings.Cut(p.Address, ":")
+			return address == "http://"+host+":8200" || (p.Leader && address == m.Profile())
+		})
+		if i == -1 {
+			msg.Error = fmt.Sprintf("Get %q: dial tcp: connect: connection refused", address+"/v1/sys/health")
+			return msg, nil
+		}
+
+		peer := m.raft[i]
+		msg.Latency = m.latency + time.Duration(i+1)*time.Millisecond
+		msg.Health = &vapi.HealthResponse{
+			Initialized:        true,
+			Sealed:             peer.Leader && m.sealed,
+			Standby:            !peer.Leader,
+			PerformanceStandby: !peer.Voter,
+			Version:            "1.2.3",
+			ClusterName:        "test-cluster",
+			ClusterID:          "test-cluster-id",
+		}
+		return msg, nil
 	})
 }
 
 func (m *MockClient) TokenLookupSelf(uuid string) tea.Cmd {
-	return m.ErrorOr(uuid, types.ClientTokenLookupSelfMsg{
-		Result: &types.TokenLookupResult{
-			EntityID:       "9021dde1-6d4c-26c2-24c0-a91343128bf9",
-			Accessor:       "ckkvhbhlvToTUIQmBW3Wubjs",
-			ID:             "abc12345-6d4c-26c2-24c0-a91343128bf9",
-			DisplayName:    "dev1",
-			CreationTime:   time.Now().Add(-(24 * time.Hour)).Unix(),
-			IssueTime:      time.Now().Add(-(24 * time.Hour)),
-			ExpireTime:     time.Now().Add(24 * time.Hour),
-			CreationTTL:    int64((24 * time.Hour).Seconds()),
-			ExplicitMaxTTL: int64((24 * time.Hour).Seconds()),
-			TTL:            int64((12 * time.Hour).Seconds()),
-			Renewable:      true,
-			Policies:       []string{"default", "dev-policy-1"},
-			Path:           "auth/userpass/login/dev1",
-			Type:           "service",
-			Meta: map[string]any{
-				"username": "dev1",
+	return wrapHandler(uuid, func() (*types.ClientTokenLookupSelfMsg, error) {
+		if err := m.request("auth/token/lookup-self", types.CapabilityRead); err != nil {
+			return nil, err
+		}
+
+		return &types.ClientTokenLookupSelfMsg{
+			Result: &types.TokenLookupResult{
+				EntityID:       "9021dde1-6d4c-26c2-24c0-a91343128bf9",
+				Accessor:       "ckkvhbhlvToTUIQmBW3Wubjs",
+				ID:             "abc12345-6d4c-26c2-24c0-a91343128bf9",
+				DisplayName:    "dev1",
+				CreationTime:   time.Now().Add(-(24 * time.Hour)).Unix(),
+				IssueTime:      time.Now().Add(-(24 * time.Hour)),
+				ExpireTime:     time.Now().Add(24 * time.Hour),
+				CreationTTL:    int64((24 * time.Hour).Seconds()),
+				ExplicitMaxTTL: int64((24 * time.Hour).Seconds()),
+				TTL:            int64((12 * time.Hour).Seconds()),
+				Renewable:      true,
+				Policies:       []string{"default", "dev-policy-1"},
+				Path:           "auth/userpass/login/dev1",
+				Type:           "service",
+				Meta: map[string]any{
+					"username": "dev1",
+				},
+				Orphan: true,
 			},
-			Orphan: true,
-		},
+		}, nil
 	})
 }
 
 func (m *MockClient) ListACLPolicies(uuid string) tea.Cmd {
-	return m.ErrorOr(uuid, types.ClientListACLPoliciesMsg{
-		Policies: mockPolicyList,
+	return wrapHandler(uuid, func() (*types.ClientListACLPoliciesMsg, error) {
+		if err := m.request("sys/policies/acl", types.CapabilityList); err != nil {
+			return nil, err
+		}
+
+		m.mu.Lock()
+		defer m.mu.Unlock()
+
+		return &types.ClientListACLPoliciesMsg{
+			Policies: slices.Sorted(maps.Keys(m.policies)),
+		}, nil
 	})
 }
 
 func (m *MockClient) GetACLPolicy(uuid, policyName string) tea.Cmd {
-	return m.ErrorOr(uuid, types.ClientGetACLPolicyMsg{
-		Name:    policyName,
-		Content: mockPolicy,
+	return wrapHandler(uuid, func() (*types.ClientGetACLPolicyMsg, error) {
+		if err := m.request("sys/policies/acl/"+policyName, types.CapabilityRead); err != nil {
+			return nil, err
+		}
+
+		m.mu.Lock()
+		defer m.mu.Unlock()
+
+		policy, ok := m.policies[policyName]
+		if !ok {
+			return nil, fmt.Errorf("policy %q not found", policyName)
+		}
+		return &types.ClientGetACLPolicyMsg{
+			Name:    policyName,
+			Content: policy,
+		}, nil
+	})
+}
+
+func (m *MockClient) GeneratePassword(uuid, policy string) tea.Cmd {
+	return wrapHandler(uuid, func() (*types.ClientGeneratePasswordMsg, error) {
+		path, err := passwordPolicyPath(policy)
+		if err != nil {
+			return nil, fmt.Errorf("generate password: %w", err)
+		}
+		if err = m.request(path, types.CapabilityRead); err != nil {
+			return nil, err
+		}
+		return &types.ClientGeneratePasswordMsg{
+			Policy:   policy,
+			Password: "Vh2k-9xQp-Lm4R-zT7w",
+		}, nil
 	})
 }
 
@@ -243,147 +329,907 @@ func (m *MockClient) GetConfigState(uuid string) tea.Cmd {
     "mount_type": "system"
 }`
 
-	var out json.RawMessage
-	err := json.Unmarshal([]byte(data), &out)
-	if err != nil {
-		panic(err)
-	}
-	return m.ErrorOr(uuid, types.ClientConfigStateMsg{Data: out})
+	return wrapHandler(uuid, func() (*types.ClientConfigStateMsg, error) {
+		if err := m.request("sys/config/state/sanitized", types.CapabilityRead); err != nil {
+			return nil, err
+		}
+
+		var out json.RawMessage
+		if err := json.Unmarshal([]byte(data), &out); err != nil {
+			return nil, err
+		}
+		return &types.ClientConfigStateMsg{Data: out}, nil
+	})
 }
 
 func (m *MockClient) ListMounts(uuid string) tea.Cmd {
-	return m.ErrorOr(uuid, types.ClientListMountsMsg{
-		Mounts: mockMounts,
+	return wrapHandler(uuid, func() (*types.ClientListMountsMsg, error) {
+		if err := m.request("sys/internal/ui/mounts", types.CapabilityRead); err != nil {
+			return nil, err
+		}
+
+		m.mu.Lock()
+		defer m.mu.Unlock()
+
+		mounts := make([]*types.Mount, 0, len(m.mounts))
+		for _, mount := range m.mounts {
+			mounts = append(mounts, &types.Mount{
+				MountOutput:  mount.MountOutput,
+				Path:         mount.Path,
+				Capabilities: m.capabilitiesFor(mount.Path),
+			})
+		}
+		return &types.ClientListMountsMsg{Mounts: mounts}, nil
 	})
 }
 
 func (m *MockClient) ListSecrets(uuid string, mount *types.Mount, path string) tea.Cmd {
-	return m.ErrorOr(uuid, types.ClientListSecretsMsg{
-		Values: []*types.SecretListRef{
-			{Path: fmt.Sprintf("%s%s/foo/", mount.Path, path), Mount: mount},
-			{Path: fmt.Sprintf("%s%s/bar", mount.Path, path), Mount: mount},
-			{Path: fmt.Sprintf("%s%s/baz", mount.Path, path), Mount: mount},
-		},
-	})
-}
-
-func (m *MockClient) ListAllSecretsRecursive(uuid string, _ *types.Mount) tea.Cmd {
-	tree := types.ClientSecretTree{
-		{
-			Mount: mockMounts[0],
-			Path:  "kv-v1-1/foo/",
-			Leafs: types.ClientSecretTree{
-				{
-					Mount: mockMounts[0],
-					Path:  "kv-v1-1/foo/bar",
-				},
-			},
-		},
-	}
-	tree.SetParentOnLeafs(nil)
-	return m.ErrorOr(uuid, types.ClientListAllSecretsRecursiveMsg{
-		Tree:        tree,
-		Requests:    10,
-		MaxRequests: MaxRecursiveRequests,
+	return wrapHandler(uuid, func() (*types.ClientListSecretsMsg, error) {
+		if err := m.request(mount.Path+path, types.CapabilityList); err != nil {
+			return nil, fmt.Errorf("list secrets: %w", err)
+		}
+
+		m.mu.Lock()
+		defer m.mu.Unlock()
+
+		var values []*types.SecretListRef
+		for _, key := range m.list(mount, path) {
+			values = append(values, &types.SecretListRef{
+				Mount:        mount,
+				Path:         path + key,
+				Capabilities: m.capabilitiesFor(mount.Path + path + key),
+			})
+		}
+		return &types.ClientListSecretsMsg{Values: values}, nil
+	})
+}
+
+func (m *MockClient) ListAllSecretsRecursive(uuid string, mount *types.Mount) tea.Cmd {
+	return wrapHandler(uuid, func() (*types.ClientListAllSecretsRecursiveMsg, error) {
+		path := "sys/internal/ui/mounts"
+		if mount != nil {
+			path = mount.Path
+		}
+		if err := m.request(path, types.CapabilityList); err != nil {
+			return nil, err
+		}
+
+		m.mu.Lock()
+		defer m.mu.Unlock()
+
+		mounts := m.mounts
+		if mount != nil {
+			mounts = []*types.Mount{mount}
+		}
+
+		var tree types.ClientSecretTree
+		var requests int64
+		for _, mount := range mounts {
+			if !mount.IsKVLike() {
+				continue
+			}
+			tree = append(tree, &types.ClientSecretTreeRef{
+				Mount:        mount,
+				Path:         mount.Path,
+				Capabilities: m.capabilitiesFor(mount.Path),
+				Leafs:        m.secretTree(mount, "", &requests),
+			})
+		}
+
+		slices.SortFunc(tree, func(a, b *types.ClientSecretTreeRef) int {
+			return strings.Compare(a.Path, b.Path)
+		})
+		tree.SetParentOnLeafs(nil)
+
+		return &types.ClientListAllSecretsRecursiveMsg{
+			Tree:            tree,
+			RequestAttempts: requests,
+			Requests:        requests,
+			MaxRequests:     MaxRecursiveRequests,
+		}, nil
 	})
 }
 
 func (m *MockClient) GetKVv2Metadata(uuid string, mount *types.Mount, path string) tea.Cmd {
-	return m.ErrorOr(uuid, types.ClientGetKVv2MetadataMsg{
-		Mount: mount,
-		Path:  path,
-		Metadata: &vapi.KVMetadata{
-			CASRequired:    false,
-			CreatedTime:    time.Now().Add(-(24 * time.Hour)),
-			UpdatedTime:    time.Now().Add(-(12 * time.Hour)),
-			CurrentVersion: 2,
-			CustomMetadata: map[string]any{
-				"foo": "bar",
-			},
-			Versions: map[string]vapi.KVVersionMetadata{
-				"1": {
-					Version:     1,
-					CreatedTime: time.Now().Add(-(24 * time.Hour)),
-				},
-				"2": {
-					Version:     2,
-					CreatedTime: time.Now().Add(-(12 * time.Hour)),
-				},
-			},
-		},
+	return wrapHandler(uuid, func() (*types.ClientGetKVv2MetadataMsg, error) {
+		if mount.KVVersion() != 2 {
+			return nil, fmt.Errorf("get secret metadata: %w", errors.New("mount is not a kv v2 mount"))
+		}
+		if err := m.request(mount.Path+path, types.CapabilityRead); err != nil {
+			return nil, fmt.Errorf("get secret metadata: %w", err)
+		}
+
+		m.mu.Lock()
+		defer m.mu.Unlock()
+
+		secret, ok := m.kv2[mount.Path][path]
+		if !ok {
+			return nil, fmt.Errorf("get secret metadata: %w", vapi.ErrSecretNotFound)
+		}
+		return &types.ClientGetKVv2MetadataMsg{
+			Mount:    mount,
+			Path:     path,
+			Metadata: secret.metadata(),
+		}, nil
+	})
+}
+
+func (m *MockClient) PutKVv2Metadata(uuid string, mount *types.Mount, path string, input *types.KVv2MetadataInput) tea.Cmd {
+	return wrapHandler(uuid, func() (*types.ClientSuccessMsg, error) {
+		if mount.KVVersion() != 2 {
+			return nil, fmt.Errorf("put secret metadata: %w", errors.New("mount is not a kv v2 mount"))
+		}
+		if err := m.request(mount.Path+path, m.writeCapability(mount, path)); err != nil {
+			return nil, fmt.Errorf("put secret metadata: %w", err)
+		}
+
+		m.mu.Lock()
+		defer m.mu.Unlock()
+
+		secret, ok := m.kv2[mount.Path][path]
+		if !ok {
+			now := time.Now()
+			secret = &mockKVv2Secret{
+				versions: map[int]*mockKVv2Version{},
+				oldest:   1,
+				created:  now,
+			}
+			m.kv2[mount.Path][path] = secret
+		}
+
+		if input.MaxVersions != nil {
+			secret.maxVersions = *input.MaxVersions
+		}
+		if input.CASRequired != nil {
+			secret.casRequired = *input.CASRequired
+		}
+		if input.DeleteVersionAfter != nil {
+			secret.deleteVersionAfter = *input.DeleteVersionAfter
+		}
+		if input.CustomMetadata != nil {
+			secret.customMetadata = maps.Clone(input.CustomMetadata)
+		}
+		secret.updated = time.Now()
+
+		return &types.ClientSuccessMsg{Message: "updated secret metadata"}, nil
+	})
+}
+
+func (m *MockClient) ListKVv2Metadata(uuid string, mount *types.Mount, paths ...string) tea.Cmd {
+	return wrapHandler(uuid, func() (*types.ClientListKVv2MetadataMsg, error) {
+		if mount.KVVersion() != 2 {
+			return nil, fmt.Errorf("list secret metadata: %w", errors.New("mount is not a kv v2 mount"))
+		}
+
+		msg := &types.ClientListKVv2MetadataMsg{
+			Mount:    mount,
+			Metadata: make(map[string]*vapi.KVMetadata, len(paths)),
+			Errors:   make(map[string]error),
+		}
+		for _, path := range paths {
+			if strings.HasSuffix(path, "/") {
+				continue
+			}
+			if err := m.request(mount.Path+path, types.CapabilityRead); err != nil {
+				msg.Errors[path] = err
+				continue
+			}
+
+			m.mu.Lock()
+			secret, ok := m.kv2[mount.Path][path]
+			if ok {
+				msg.Metadata[path] = secret.metadata()
+			}
+			m.mu.Unlock()
+
+			if !ok {
+				msg.Errors[path] = vapi.ErrSecretNotFound
+			}
+		}
+
+		return msg, nil
+	})
+}
+
+func (m *MockClient) GetKVv2MountConfig(uuid string, mount *types.Mount) tea.Cmd {
+	return wrapHandler(uuid, func() (*types.ClientGetKVv2MountConfigMsg, error) {
+		if mount.KVVersion() != 2 {
+			return nil, fmt.Errorf("get mount config: %w", errors.New("mount is not a kv v2 mount"))
+		}
+		if err := m.request(mount.Path+"config", types.CapabilityRead); err != nil {
+			return nil, fmt.Errorf("get mount config: %w", err)
+		}
+
+		m.mu.Lock()
+		defer m.mu.Unlock()
+
+		config := *m.kv2Config[mount.Path]
+		return &types.ClientGetKVv2MountConfigMsg{
+			Mount:  mount,
+			Config: &config,
+		}, nil
+	})
+}
+
+func (m *MockClient) PutKVv2MountConfig(uuid string, mount *types.Mount, config *types.KVv2MountConfig) tea.Cmd {
+	return wrapHandler(uuid, func() (*types.ClientSuccessMsg, error) {
+		if mount.KVVersion() != 2 {
+			return nil, fmt.Errorf("put mount config: %w", errors.New("mount is not a kv v2 mount"))
+		}
+		if err := m.request(mount.Path+"config", types.CapabilityUpdate); err != nil {
+			return nil, fmt.Errorf("put mount config: %w", err)
+		}
+
+		m.mu.Lock()
+		defer m.mu.Unlock()
+
+		cfg := *config
+		m.kv2Config[mount.Path] = &cfg
+		return &types.ClientSuccessMsg{Message: "updated mount config"}, nil
 	})
 }
 
-func (m *MockClient) PutKVSecret(uuid string, _ *types.Mount, _ string, _ map[string]any) tea.Cmd {
-	return m.ErrorOr(uuid, types.ClientSuccessMsg{Message: "updated secret"})
+func (m *MockClient) PutKVSecret(uuid string, mount *types.Mount, path string, data map[string]any, cas int) tea.Cmd {
+	return wrapHandler(uuid, func() (*types.ClientSuccessMsg, error) {
+		if err := m.request(mount.Path+path, m.writeCapability(mount, path)); err != nil {
+			return nil, fmt.Errorf("put secret: %w", err)
+		}
+
+		m.mu.Lock()
+		defer m.mu.Unlock()
+
+		if mount.KVVersion() != 2 {
+			// KV v1 and cubbyhole share the same write semantics.
+			m.kv1[mount.Path][path] = maps.Clone(data)
+			return &types.ClientSuccessMsg{Message: "updated secret"}, nil
+		}
+
+		current := 0
+		casRequired := m.kv2Config[mount.Path].CASRequired
+		if secret, ok := m.kv2[mount.Path][path]; ok {
+			current = secret.current
+			casRequired = casRequired || secret.casRequired
+		}
+
+		if cas < 0 && casRequired {
+			return nil, fmt.Errorf("put secret: %w", errors.New("check-and-set parameter required for this call"))
+		}
+		if cas >= 0 && cas != current {
+			return nil, fmt.Errorf("put secret: %w", types.ErrCheckAndSetMismatch)
+		}
+
+		m.putKVv2(mount.Path, path, maps.Clone(data))
+		return &types.ClientSuccessMsg{Message: "updated secret", Version: m.kv2[mount.Path][path].current}, nil
+	})
 }
 
 func (m *MockClient) ListKVv2Versions(uuid string, mount *types.Mount, path string) tea.Cmd {
-	return m.ErrorOr(uuid, types.ClientListKVv2VersionsMsg{
-		Mount: mount,
-		Path:  path,
-		Versions: []vapi.KVVersionMetadata{
-			{Version: 1, CreatedTime: time.Now().Add(-(24 * time.Hour))},
-			{Version: 2, CreatedTime: time.Now().Add(-(12 * time.Hour))},
-		},
+	return wrapHandler(uuid, func() (*types.ClientListKVv2VersionsMsg, error) {
+		if mount.KVVersion() != 2 {
+			return nil, fmt.Errorf("list kv v2 versions: %w", errors.New("mount is not a kv v2 mount"))
+		}
+		if err := m.request(mount.Path+path, types.CapabilityRead); err != nil {
+			return nil, fmt.Errorf("list kv v2 versions: %w", err)
+		}
+
+		m.mu.Lock()
+		defer m.mu.Unlock()
+
+		secret, ok := m.kv2[mount.Path][path]
+		if !ok {
+			return nil, fmt.Errorf("list kv v2 versions: %w", vapi.ErrSecretNotFound)
+		}
+		return &types.ClientListKVv2VersionsMsg{
+			Mount:    mount,
+			Path:     path,
+			Versions: secret.versionList(),
+		}, nil
 	})
 }
 
-func (m *MockClient) GetKVSecret(uuid string, mount *types.Mount, path string, _ int) tea.Cmd {
-	if strings.Contains(path, "json") {
-		return m.ErrorOr(uuid, types.ClientGetSecretMsg{
+func (m *MockClient) GetKVSecret(uuid string, mount *types.Mount, path string, version int) tea.Cmd {
+	return wrapHandler(uuid, func() (*types.ClientGetSecretMsg, error) {
+		if err := m.request(mount.Path+path, types.CapabilityRead); err != nil {
+			return nil, fmt.Errorf("get secret: %w", err)
+		}
+
+		m.mu.Lock()
+		defer m.mu.Unlock()
+
+		msg := &types.ClientGetSecretMsg{
 			Mount: mount,
 			Path:  path,
-			Data:  map[string]any{"foo": "bar", "bar": "baz", "inner": map[string]any{"foo": "bar", "bar": "baz"}},
-		})
-	}
-	return m.ErrorOr(uuid, types.ClientGetSecretMsg{
-		Mount: mount,
-		Path:  path,
-		Data:  map[string]any{"foo": "bar", "bar": "baz"},
+		}
+
+		if mount.KVVersion() != 2 {
+			data, ok := m.kv1[mount.Path][path]
+			if !ok {
+				return nil, fmt.Errorf("get secret: %w", vapi.ErrSecretNotFound)
+			}
+			msg.Data = maps.Clone(data)
+			return msg, nil
+		}
+
+		secret, ok := m.kv2[mount.Path][path]
+		if !ok {
+			return nil, fmt.Errorf("get secret: %w", vapi.ErrSecretNotFound)
+		}
+
+		msg.Version = secret.current
+		if version > 0 {
+			msg.Version = version
+		}
+		msg.CurrentVersion = secret.current
+
+		v, ok := secret.versions[msg.Version]
+		if !ok {
+			return nil, fmt.Errorf("get secret: %w", vapi.ErrSecretNotFound)
+		}
+
+		// Deleted and destroyed versions are still returned, without any data.
+		if v.deleted.IsZero() && !v.destroyed {
+			msg.Data = maps.Clone(v.data)
+		}
+		return msg, nil
+	})
+}
+
+func (m *MockClient) DeleteKVSecret(uuid string, mount *types.Mount, path string, versions ...int) tea.Cmd {
+	return wrapHandler(uuid, func() (*types.ClientSuccessMsg, error) {
+		if err := m.request(mount.Path+path, types.CapabilityDelete); err != nil {
+			return nil, fmt.Errorf("delete secret: %w", err)
+		}
+
+		m.mu.Lock()
+		defer m.mu.Unlock()
+
+		if mount.KVVersion() != 2 {
+			delete(m.kv1[mount.Path], path)
+			return &types.ClientSuccessMsg{Message: "deleted secret"}, nil
+		}
+
+		if secret, ok := m.kv2[mount.Path][path]; ok {
+			if len(versions) == 0 {
+				versions = []int{secret.current}
+			}
+			secret.setVersions(versions, func(v *mockKVv2Version) {
+				v.deleted = time.Now()
+			})
+		}
+		return &types.ClientSuccessMsg{Message: "deleted secret"}, nil
 	})
 }
 
-func (m *MockClient) DeleteKVSecret(uuid string, _ *types.Mount, _ string, _ ...int) tea.Cmd {
-	return m.ErrorOr(uuid, types.ClientSuccessMsg{Message: "deleted secret"})
+func (m *MockClient) UndeleteKVSecret(uuid string, mount *types.Mount, path string, versions ...int) tea.Cmd {
+	return wrapHandler(uuid, func() (*types.ClientSuccessMsg, error) {
+		if mount.KVVersion() != 2 {
+			return nil, fmt.Errorf("undelete secret: %w", errors.New("not a kv v2 mount"))
+		}
+		if err := m.request(mount.Path+path, types.CapabilityUpdate); err != nil {
+			return nil, fmt.Errorf("undelete secret: %w", err)
+		}
+
+		m.mu.Lock()
+		defer m.mu.Unlock()
+
+		secret, ok := m.kv2[mount.Path][path]
+		if !ok || len(secret.versions) == 0 {
+			return nil, errors.New("no versions to undelete")
+		}
+		if len(versions) == 0 {
+			versions = []int{secret.current}
+		}
+		secret.setVersions(versions, func(v *mockKVv2Version) {
+			v.deleted = time.Time{}
+		})
+		return &types.ClientSuccessMsg{Message: "undeleted secret"}, nil
+	})
 }
 
-func (m *MockClient) UndeleteKVSecret(uuid string, _ *types.Mount, _ string, _ ...int) tea.Cmd {
-	return m.ErrorOr(uuid, types.ClientSuccessMsg{Message: "undeleted secret"})
+func (m *MockClient) DestroyKVSecret(uuid string, mount *types.Mount, path string, versions ...int) tea.Cmd {
+	return wrapHandler(uuid, func() (*types.ClientSuccessMsg, error) {
+		capability := types.CapabilityUpdate
+		if mount.KVVersion() != 2 || len(versions) == 0 {
+			capability = types.CapabilityDelete
+		}
+		if err := m.request(mount.Path+path, capability); err != nil {
+			return nil, fmt.Errorf("destroy secret: %w", err)
+		}
+
+		m.mu.Lock()
+		defer m.mu.Unlock()
+
+		switch {
+		case mount.KVVersion() != 2:
+			delete(m.kv1[mount.Path], path)
+		case len(versions) == 0:
+			delete(m.kv2[mount.Path], path)
+		default:
+			if secret, ok := m.kv2[mount.Path][path]; ok {
+				secret.setVersions(versions, func(v *mockKVv2Version) {
+					v.destroyed = true
+					v.data = nil
+				})
+			}
+		}
+		return &types.ClientSuccessMsg{Message: "destroyed secret"}, nil
+	})
 }
 
-func (m *MockClient) DestroyKVSecret(uuid string, _ *types.Mount, _ string, _ ...int) tea.Cmd {
-	return m.ErrorOr(uuid, types.ClientSuccessMsg{Message: "destroyed secret"})
+// mockMetricsInterval is the telemetry interval the mock metrics are aggregated
+// over, which is the default of Vault.
+const mockMetricsInterval = 10.0
+
+// GetMetrics returns a fixed set of the metrics reported by Vault, varying each
+// time they're read. KV secret counts reflect the mock data.
+func (m *MockClient) GetMetrics(uuid string) tea.Cmd {
+	return wrapHandler(uuid, func() (*types.ClientMetricsMsg, error) {
+		if err := m.request("sys/metrics", types.CapabilityRead); err != nil {
+			return nil, err
+		}
+
+		m.mu.Lock()
+		defer m.mu.Unlock()
+
+		m.metricsPolls++
+		wave := func(base, amplitude float64, period int) float64 {
+			return base + amplitude*math.Sin(2*math.Pi*float64(m.metricsPolls%period)/float64(period))
+		}
+
+		gauge := func(name string, value float64, labels map[string]string) *types.Metric {
+			return &types.Metric{Kind: types.MetricGauge, Name: name, Value: value, Labels: labels}
+		}
+		counter := func(name string, sum float64) *types.Metric {
+			return &types.Metric{
+				Kind:  types.MetricCounter,
+				Name:  name,
+				Count: int(sum),
+				Sum:   sum,
+				Min:   1,
+				Max:   1,
+				Mean:  1,
+				Rate:  sum / mockMetricsInterval,
+			}
+		}
+		sample := func(name string, count int, mean float64) *types.Metric {
+			return &types.Metric{
+				Kind:  types.MetricSample,
+				Name:  name,
+				Count: count,
+				Sum:   float64(count) * mean,
+				Min:   mean / 4,
+				Max:   mean * 3,
+				Mean:  mean,
+				Rate:  float64(count) * mean / mockMetricsInterval,
+			}
+		}
+
+		requests := int(wave(400, 150, 12))
+		metrics := []*types.Metric{
+			gauge("vault.expire.num_leases", math.Round(wave(180, 20, 30)), nil),
+			gauge("vault.expire.num_irrevocable_leases", 0, nil),
+			gauge("vault.token.count", 12, map[string]string{"auth_method": "token", "namespace": "root"}),
+			gauge("vault.token.count", 31, map[string]string{"auth_method": "approle", "namespace": "root"}),
+			gauge("vault.runtime.num_goroutines", math.Round(wave(320, 40, 7)), nil),
+			gauge("vault.runtime.alloc_bytes", math.Round(wave(96<<20, 16<<20, 9)), nil),
+			counter("vault.raft.apply", math.Round(wave(60, 30, 10))),
+			sample("vault.core.handle_request", requests, wave(2.5, 1.5, 8)),
+			sample("vault.core.handle_login_request", int(wave(20, 10, 6)), wave(12, 4, 5)),
+			sample("vault.raft.commitTime", int(wave(60, 30, 10)), wave(3, 2, 11)),
+			sample("vault.barrier.get", requests*2, 0.4),
+			sample("vault.barrier.put", int(wave(60, 30, 10)), 1.2),
+			sample("vault.barrier.list", requests/10, 0.8),
+			sample("vault.barrier.delete", requests/50, 1),
+		}
+
+		for _, mount := range slices.Sorted(maps.Keys(m.kv2)) {
+			metrics = append(metrics, gauge(
+				"vault.secret.kv.count",
+				float64(len(m.kv2[mount])),
+				map[string]string{"mount_point": mount, "namespace": "root"},
+			))
+		}
+
+		return &types.ClientMetricsMsg{
+			Timestamp: time.Now().Truncate(10 * time.Second),
+			Metrics:   metrics,
+		}, nil
+	})
 }
 
 func (m *MockClient) GetRaftConfig(uuid string) tea.Cmd {
-	return m.ErrorOr(uuid, types.ClientRaftConfigMsg{
-		Peers: []*types.RaftConfigPeer{
-			{
-				NodeID:          "raft-1",
-				Address:         "10.0.0.1:8201",
-				Leader:          true,
-				Voter:           true,
-				ProtocolVersion: "\u0003",
-			},
-			{
-				NodeID:          "raft-2",
-				Address:         "10.0.0.2:8201",
-				Leader:          false,
-				Voter:           true,
-				ProtocolVersion: "\u0003",
-			},
-			{
-				NodeID:          "raft-3",
-				Address:         "10.0.0.3:8201",
-				Leader:          false,
-				Voter:           false,
-				ProtocolVersion: "\u0003",
+	return wrapHandler(uuid, func() (*types.ClientRaftConfigMsg, error) {
+		if err := m.request("sys/storage/raft/configuration", types.CapabilityRead); err != nil {
+			return nil, err
+		}
+
+		m.mu.Lock()
+		defer m.mu.Unlock()
+
+		peers := make([]*types.RaftConfigPeer, 0, len(m.raft))
+		for _, peer := range m.raft {
+			p := *peer
+			peers = append(peers, &p)
+		}
+		return &types.ClientRaftConfigMsg{Peers: peers}, nil
+	})
+}
+
+func (m *MockClient) RemoveRaftPeer(uuid, serverID string) tea.Cmd {
+	return wrapHandler(uuid, func() (*types.ClientSuccessMsg, error) {
+		if err := m.request("sys/storage/raft/remove-peer", types.CapabilityUpdate); err != nil {
+			return nil, err
+		}
+
+		m.mu.Lock()
+		defer m.mu.Unlock()
+
+		i := slices.IndexFunc(m.raft, func(p *types.RaftConfigPeer) bool { return p.NodeID == serverID })
+		if i < 0 {
+			return nil, fmt.Errorf("raft peer %q not found", serverID)
+		}
+		m.raft = slices.Delete(m.raft, i, i+1)
+		return &types.ClientSuccessMsg{Message: "raft peer removed"}, nil
+	})
+}
+
+func (m *MockClient) GetRaftAutopilotState(uuid string) tea.Cmd {
+	return wrapHandler(uuid, func() (*types.ClientRaftAutopilotStateMsg, error) {
+		if err := m.request("sys/storage/raft/autopilot/state", types.CapabilityRead); err != nil {
+			return nil, fmt.Errorf("get autopilot state: %w", err)
+		}
+
+		m.mu.Lock()
+		defer m.mu.Unlock()
+
+		state := &vapi.AutopilotState{
+			Healthy: true,
+			Servers: make(map[string]*vapi.AutopilotServer, len(m.raft)),
+		}
+		for i, peer := range m.raft {
+			server := &vapi.AutopilotServer{
+				ID:          peer.NodeID,
+				Name:        peer.NodeID,
+				Address:     peer.Address,
+				NodeStatus:  "alive",
+				LastContact: strconv.Itoa(i*3) + "ms",
+				LastTerm:    3,
+				LastIndex:   uint64(1024 - i), //nolint:gosec
+				Healthy:     true,
+				StableSince: m.activeTime.UTC().Format(time.RFC3339),
+				Version:     "1.2.3",
+				NodeType:    "voter",
+			}
+			switch {
+			case peer.Leader:
+				server.Status = "leader"
+				state.Leader = peer.NodeID
+				state.Voters = append(state.Voters, peer.NodeID)
+			case peer.Voter:
+				server.Status = "voter"
+				state.Voters = append(state.Voters, peer.NodeID)
+			default:
+				server.Status = "non-voter"
+				server.NodeType = "non-voter"
+				state.NonVoters = append(state.NonVoters, peer.NodeID)
+			}
+			state.Servers[peer.NodeID] = server
+		}
+		state.FailureTolerance = max(0, (len(state.Voters)-1)/2)
+		state.OptimisticFailureTolerance = state.FailureTolerance + len(state.NonVoters)
+		return &types.ClientRaftAutopilotStateMsg{State: state}, nil
+	})
+}
+
+func (m *MockClient) GetRaftAutopilotConfig(uuid string) tea.Cmd {
+	return wrapHandler(uuid, func() (*types.ClientRaftAutopilotConfigMsg, error) {
+		if err := m.request("sys/storage/raft/autopilot/configuration", types.CapabilityRead); err != nil {
+			return nil, fmt.Errorf("get autopilot config: %w", err)
+		}
+
+		m.mu.Lock()
+		defer m.mu.Unlock()
+
+		config := m.autopilot
+		return &types.ClientRaftAutopilotConfigMsg{Config: &config}, nil
+	})
+}
+
+func (m *MockClient) PutRaftAutopilotConfig(uuid string, config *vapi.AutopilotConfig) tea.Cmd {
+	return wrapHandler(uuid, func() (*types.ClientSuccessMsg, error) {
+		if err := m.request("sys/storage/raft/autopilot/configuration", types.CapabilityUpdate); err != nil {
+			return nil, fmt.Errorf("update autopilot config: %w", err)
+		}
+
+		m.mu.Lock()
+		defer m.mu.Unlock()
+
+		m.autopilot = *config
+		return &types.ClientSuccessMsg{Message: "autopilot config updated"}, nil
+	})
+}
+
+// mockSnapshot is the contents of a (gzipped) snapshot of the mock. Only the
+// raft configuration is included.
+type mockSnapshot struct {
+	ClusterID string                  `json:"cluster_id"`
+	Raft      []*types.RaftConfigPeer `json:"raft"`
+	Autopilot *vapi.AutopilotConfig   `json:"autopilot"`
+}
+
+func (m *MockClient) SaveRaftSnapshot(uuid string, w io.Writer) tea.Cmd {
+	return wrapHandler(uuid, func() (*types.ClientSuccessMsg, error) {
+		if err := m.request("sys/storage/raft/snapshot", types.CapabilitySudo); err != nil {
+			return nil, fmt.Errorf("save raft snapshot: %w", err)
+		}
+
+		m.mu.Lock()
+		autopilot := m.autopilot
+		snapshot := mockSnapshot{
+			ClusterID: "test-cluster-id",
+			Raft:      slices.Clone(m.raft),
+			Autopilot: &autopilot,
+		}
+		m.mu.Unlock()
+
+		gz := gzip.NewWriter(w)
+		err := json.NewEncoder(gz).Encode(snapshot)
+		if cerr := gz.Close(); err == nil {
+			err = cerr
+		}
+		if err != nil {
+			return nil, fmt.Errorf("save raft snapshot: %w", err)
+		}
+		return &types.ClientSuccessMsg{Message: "raft snapshot saved"}, nil
+	})
+}
+
+func (m *MockClient) RestoreRaftSnapshot(uuid string, r io.Reader, force bool) tea.Cmd {
+	return wrapHandler(uuid, func() (*types.ClientSuccessMsg, error) {
+		path := "sys/storage/raft/snapshot"
+		if force {
+			path = "sys/storage/raft/snapshot-force"
+		}
+		if err := m.request(path, types.CapabilitySudo); err != nil {
+			return nil, fmt.Errorf("restore raft snapshot: %w", err)
+		}
+
+		var snapshot mockSnapshot
+		gz, err := gzip.NewReader(r)
+		if err == nil {
+			err = json.NewDecoder(gz).Decode(&snapshot)
+		}
+		switch {
+		case err != nil || snapshot.Autopilot == nil:
+			return nil, fmt.Errorf("restore raft snapshot: %w", errors.New("invalid snapshot"))
+		case snapshot.ClusterID != "test-cluster-id" && !force:
+			return nil, fmt.Errorf("restore raft snapshot: %w", errors.New("snapshot was taken on a different cluster"))
+		}
+
+		m.mu.Lock()
+		defer m.mu.Unlock()
+
+		m.raft = snapshot.Raft
+		m.autopilot = *snapshot.Autopilot
+		return &types.ClientSuccessMsg{Message: "raft snapshot restored"}, nil
+	})
+}
+
+func (m *MockClient) JoinRaft(uuid string, req *vapi.RaftJoinRequest) tea.Cmd {
+	return wrapHandler(uuid, func() (*types.ClientSuccessMsg, error) {
+		if err := m.request("sys/storage/raft/join", types.CapabilityUpdate); err != nil {
+			return nil, fmt.Errorf("join raft cluster: %w", err)
+		}
+		if req.LeaderAPIAddr == "" && req.AutoJoin == "" {
+			return nil, fmt.Errorf("join raft cluster: %w", errors.New("leader_api_addr or auto_join must be provided"))
+		}
+
+		// The mock is already part of its own cluster, so joining has no effect.
+		return &types.ClientSuccessMsg{Message: "joined raft cluster"}, nil
+	})
+}
+
+func (m *MockClient) GetSealStatus(uuid string) tea.Cmd {
+	return wrapHandler(uuid, func() (*types.ClientSealStatusMsg, error) {
+		if err := m.request("sys/seal-status", ""); err != nil {
+			return nil, fmt.Errorf("get seal status: %w", err)
+		}
+
+		m.mu.Lock()
+		defer m.mu.Unlock()
+
+		msg := &types.ClientSealStatusMsg{
+			Status: &vapi.SealStatusResponse{
+				Type:        "shamir",
+				Initialized: true,
+				Sealed:      m.sealed,
+				T:           m.unsealThreshold,
+				N:           m.unsealShares,
+				Progress:    len(m.unsealProgress),
+				Version:     "1.2.3",
+				StorageType: "raft",
 			},
-		},
+		}
+		if m.sealed {
+			if len(m.unsealProgress) > 0 {
+				msg.Status.Nonce = "d3a3c7b5-4e1c-3f0a-a8a2-9c1b6f0e2d4a"
+			}
+			return msg, nil
+		}
+
+		msg.Status.ClusterName = "test-cluster"
+		msg.Status.ClusterID = "test-cluster-id"
+		msg.Leader = &vapi.LeaderResponse{
+			HAEnabled:            true,
+			IsSelf:               true,
+			ActiveTime:           m.activeTime,
+			LeaderAddress:        "http://127.0.0.1:8200",
+			LeaderClusterAddress: "https://127.0.0.1:8201",
+		}
+		msg.SealCapabilities = m.capabilitiesFor("sys/seal")
+		msg.StepDownCapabilities = m.capabilitiesFor("sys/step-down")
+		return msg, nil
+	})
+}
+
+func (m *MockClient) Unseal(uuid, key string) tea.Cmd {
+	return wrapHandler(uuid, func() (*types.ClientSuccessMsg, error) {
+		if err := m.request("sys/unseal", ""); err != nil {
+			return nil, fmt.Errorf("unseal: %w", err)
+		}
+
+		m.mu.Lock()
+		defer m.mu.Unlock()
+
+		if !m.sealed {
+			return &types.ClientSuccessMsg{Message: "unsealed"}, nil
+		}
+		if key == "" {
+			return nil, fmt.Errorf("unseal: %w", errors.New("missing unseal key"))
+		}
+
+		if !slices.Contains(m.unsealProgress, key) {
+			m.unsealProgress = append(m.unsealProgress, key)
+		}
+		if len(m.unsealProgress) >= m.unsealThreshold {
+			m.sealed = false
+			m.unsealProgress = nil
+			m.activeTime = time.Now()
+			return &types.ClientSuccessMsg{Message: "unsealed"}, nil
+		}
+		return &types.ClientSuccessMsg{
+			Message: fmt.Sprintf("unseal key accepted (%d/%d)", len(m.unsealProgress), m.unsealThreshold),
+		}, nil
+	})
+}
+
+func (m *MockClient) ResetUnseal(uuid string) tea.Cmd {
+	return wrapHandler(uuid, func() (*types.ClientSuccessMsg, error) {
+		if err := m.request("sys/unseal", ""); err != nil {
+			return nil, fmt.Errorf("reset unseal: %w", err)
+		}
+
+		m.mu.Lock()
+		defer m.mu.Unlock()
+
+		m.unsealProgress = nil
+		return &types.ClientSuccessMsg{Message: "unseal progress reset"}, nil
 	})
 }
 
-func (m *MockClient) RemoveRaftPeer(uuid, _ string) tea.Cmd {
-	return m.ErrorOr(uuid, types.ClientSuccessMsg{Message: "raft peer removed"})
+func (m *MockClient) Seal(uuid string) tea.Cmd {
+	return wrapHandler(uuid, func() (*types.ClientSuccessMsg, error) {
+		if err := m.request("sys/seal", types.CapabilitySudo); err != nil {
+			return nil, fmt.Errorf("seal: %w", err)
+		}
+
+		m.SetSealed(true)
+		return &types.ClientSuccessMsg{Message: "sealed"}, nil
+	})
+}
+
+func (m *MockClient) StepDown(uuid string) tea.Cmd {
+	return wrapHandler(uuid, func() (*types.ClientSuccessMsg, error) {
+		if err := m.request("sys/step-down", types.CapabilitySudo); err != nil {
+			return nil, fmt.Errorf("step down: %w", err)
+		}
+
+		// The mock is the only node, so it's immediately re-elected.
+		m.mu.Lock()
+		m.activeTime = time.Now()
+		m.mu.Unlock()
+		return &types.ClientSuccessMsg{Message: "stepped down"}, nil
+	})
+}
+
+func (m *MockClient) ListAuditDevices(uuid string) tea.Cmd {
+	return wrapHandler(uuid, func() (*types.ClientListAuditDevicesMsg, error) {
+		if err := m.request("sys/audit", types.CapabilitySudo); err != nil {
+			return nil, fmt.Errorf("list audit devices: %w", err)
+		}
+
+		m.mu.Lock()
+		defer m.mu.Unlock()
+
+		msg := &types.ClientListAuditDevicesMsg{}
+		for _, path := range slices.Sorted(maps.Keys(m.audit)) {
+			device := *m.audit[path]
+			device.Options = maps.Clone(device.Options)
+			msg.Devices = append(msg.Devices, &device)
+		}
+		return msg, nil
+	})
+}
+
+func (m *MockClient) EnableAuditDevice(uuid, path string, options *vapi.EnableAuditOptions) tea.Cmd {
+	return wrapHandler(uuid, func() (*types.ClientSuccessMsg, error) {
+		path = strings.TrimSuffix(path, "/") + "/"
+		if err := m.request("sys/audit/"+path, types.CapabilitySudo); err != nil {
+			return nil, fmt.Errorf("enable audit device: %w", err)
+		}
+
+		m.mu.Lock()
+		defer m.mu.Unlock()
+
+		switch {
+		case path == "/":
+			return nil, fmt.Errorf("enable audit device: %w", errors.New("missing path"))
+		case !slices.Contains([]string{"file", "socket", "syslog"}, options.Type):
+			return nil, fmt.Errorf("enable audit device: unknown backend type: %q", options.Type)
+		case options.Type == "file" && options.Options["file_path"] == "":
+			return nil, fmt.Errorf("enable audit device: %w", errors.New("file_path is required"))
+		case options.Type == "socket" && options.Options["address"] == "":
+			return nil, fmt.Errorf("enable audit device: %w", errors.New("address is required"))
+		case m.audit[path] != nil:
+			return nil, fmt.Errorf("enable audit device: %w", errors.New("path already in use"))
+		}
+
+		m.audit[path] = &vapi.Audit{
+			Type:        options.Type,
+			Description: options.Description,
+			Options:     maps.Clone(options.Options),
+			Local:       options.Local,
+			Path:        path,
+		}
+		return &types.ClientSuccessMsg{Message: "audit device enabled"}, nil
+	})
+}
+
+func (m *MockClient) DisableAuditDevice(uuid, path string) tea.Cmd {
+	return wrapHandler(uuid, func() (*types.ClientSuccessMsg, error) {
+		path = strings.TrimSuffix(path, "/") + "/"
+		if err := m.request("sys/audit/"+path, types.CapabilitySudo); err != nil {
+			return nil, fmt.Errorf("disable audit device: %w", err)
+		}
+
+		m.mu.Lock()
+		delete(m.audit, path)
+		m.mu.Unlock()
+		return &types.ClientSuccessMsg{Message: "audit device disabled"}, nil
+	})
+}
+
+// AuditHash hashes input the same way as Vault, using a salt derived from the
+// path of the audit device, so hashes are stable across runs.
+func (m *MockClient) AuditHash(uuid, path, input string) tea.Cmd {
+	return wrapHandler(uuid, func() (*types.ClientAuditHashMsg, error) {
+		path = strings.TrimSuffix(path, "/") + "/"
+		if err := m.request("sys/audit-hash/"+path, types.CapabilityUpdate); err != nil {
+			return nil, fmt.Errorf("audit hash: %w", err)
+		}
+
+		m.mu.Lock()
+		_, ok := m.audit[path]
+		m.mu.Unlock()
+		if !ok {
+			return nil, fmt.Errorf("audit hash: %w", errors.New("unknown audit backend"))
+		}
+
+		mac := hmac.New(sha256.New, []byte("mock-salt-"+path))
+		_, _ = mac.Write([]byte(input))
+		return &types.ClientAuditHashMsg{
+			Path: path,
+			Hash: "hmac-sha256:" + hex.EncodeToString(mac.Sum(nil)),
+		}, nil
+	})
 }
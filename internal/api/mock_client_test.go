@@ -0,0 +1,305 @@
+// Copyright (c) Liam Stanley <liam@liam.sh>. All rights reserved. Use of
+// this source code is governed by the MIT license that can be found in
+// the LICENSE file.
+
+package api
+
+import (
+	"bytes"
+	"errors"
+	"slices"
+	"testing"
+
+	vapi "github.com/hashicorp/vault/api"
+	"github.com/lrstanley/vex/internal/types"
+)
+
+func TestMockClientKVv2(t *testing.T) {
+	m := NewMockClient()
+	mount := getMount(t, m, "kv-v2-1/")
+
+	list := run[types.ClientListSecretsMsg](t, m.ListSecrets("", mount, "foo/"))
+	if len(list.Values) != 2 || list.Values[0].Path != "foo/bar" || list.Values[1].Path != "foo/json" {
+		t.Fatalf("unexpected list: %+v", list.Values)
+	}
+
+	run[types.ClientSuccessMsg](t, m.PutKVSecret("", mount, "app/db", map[string]any{"user": "admin"}, 0))
+	if res := run[types.ClientSuccessMsg](t, m.PutKVSecret("", mount, "app/db", map[string]any{"user": "root"}, 1)); res.Version != 2 {
+		t.Fatalf("expected write to create version 2, got %d", res.Version)
+	}
+
+	if err := runErr(t, m.PutKVSecret("", mount, "app/db", map[string]any{"user": "stale"}, 1)); !errors.Is(err, types.ErrCheckAndSetMismatch) {
+		t.Fatalf("expected check-and-set mismatch, got %v", err)
+	}
+
+	secret := run[types.ClientGetSecretMsg](t, m.GetKVSecret("", mount, "app/db", 1))
+	if secret.Data["user"] != "admin" || secret.Version != 1 || secret.CurrentVersion != 2 {
+		t.Fatalf("unexpected secret: %+v", secret)
+	}
+
+	run[types.ClientSuccessMsg](t, m.DeleteKVSecret("", mount, "app/db"))
+	if _, ok := m.Secret("kv-v2-1/", "app/db"); ok {
+		t.Fatal("expected secret to be deleted")
+	}
+	if secret = run[types.ClientGetSecretMsg](t, m.GetKVSecret("", mount, "app/db", 0)); secret.Data != nil {
+		t.Fatalf("expected deleted secret to have no data, got %+v", secret)
+	}
+
+	run[types.ClientSuccessMsg](t, m.UndeleteKVSecret("", mount, "app/db", 2))
+	if data, ok := m.Secret("kv-v2-1/", "app/db"); !ok || data["user"] != "root" {
+		t.Fatalf("expected secret to be undeleted, got %v", data)
+	}
+
+	run[types.ClientSuccessMsg](t, m.DestroyKVSecret("", mount, "app/db", 1))
+	versions := run[types.ClientListKVv2VersionsMsg](t, m.ListKVv2Versions("", mount, "app/db")).Versions
+	if len(versions) != 2 || !versions[0].Destroyed || versions[1].Destroyed {
+		t.Fatalf("unexpected versions: %+v", versions)
+	}
+
+	run[types.ClientSuccessMsg](t, m.DestroyKVSecret("", mount, "app/db"))
+	if err := runErr(t, m.GetKVv2Metadata("", mount, "app/db")); !errors.Is(err, vapi.ErrSecretNotFound) {
+		t.Fatalf("expected secret not found, got %v", err)
+	}
+
+	// Old versions are pruned, based on the mount config.
+	run[types.ClientSuccessMsg](t, m.PutKVv2MountConfig("", mount, &types.KVv2MountConfig{MaxVersions: 2}))
+	m.PutSecret("kv-v2-1/", "bar", map[string]any{"foo": "v3"})
+	versions = run[types.ClientListKVv2VersionsMsg](t, m.ListKVv2Versions("", mount, "bar")).Versions
+	if len(versions) != 2 || versions[0].Version != 2 || versions[1].Version != 3 {
+		t.Fatalf("unexpected versions: %+v", versions)
+	}
+}
+
+func TestMockClientKVv1(t *testing.T) {
+	m := NewMockClient()
+	mount := getMount(t, m, "kv-v1-1/")
+
+	run[types.ClientSuccessMsg](t, m.PutKVSecret("", mount, "app", map[string]any{"a": "b"}, -1))
+	secret := run[types.ClientGetSecretMsg](t, m.GetKVSecret("", mount, "app", 0))
+	if secret.Data["a"] != "b" || secret.Version != 0 {
+		t.Fatalf("unexpected secret: %+v", secret)
+	}
+
+	if err := runErr(t, m.UndeleteKVSecret("", mount, "app")); err == nil {
+		t.Fatal("expected undelete to fail on a kv v1 mount")
+	}
+
+	run[types.ClientSuccessMsg](t, m.DeleteKVSecret("", mount, "app"))
+	if err := runErr(t, m.GetKVSecret("", mount, "app", 0)); !errors.Is(err, vapi.ErrSecretNotFound) {
+		t.Fatalf("expected secret not found, got %v", err)
+	}
+}
+
+func TestMockClientFaults(t *testing.T) {
+	m := NewMockClient()
+	mount := getMount(t, m, "kv-v2-1/")
+
+	m.SetCapabilities("kv-v2-1/foo/*", types.CapabilityRead, types.CapabilityList)
+	m.DenyPath("kv-v2-1/baz")
+
+	list := run[types.ClientListSecretsMsg](t, m.ListSecrets("", mount, "foo/"))
+	if !slices.Equal(list.Values[0].Capabilities, types.ClientCapabilities{types.CapabilityRead, types.CapabilityList}) {
+		t.Fatalf("unexpected capabilities: %v", list.Values[0].Capabilities)
+	}
+
+	run[types.ClientGetSecretMsg](t, m.GetKVSecret("", mount, "foo/bar", 0))
+	if err := runErr(t, m.PutKVSecret("", mount, "foo/bar", map[string]any{}, -1)); !errors.Is(err, errMockPermissionDenied) {
+		t.Fatalf("expected permission denied, got %v", err)
+	}
+	if err := runErr(t, m.GetKVSecret("", mount, "baz", 0)); !errors.Is(err, errMockPermissionDenied) {
+		t.Fatalf("expected permission denied, got %v", err)
+	}
+
+	injected := errors.New("injected")
+	m.FailPath("sys/storage/raft/+", injected)
+	if err := runErr(t, m.GetRaftConfig("")); !errors.Is(err, injected) {
+		t.Fatalf("expected injected error, got %v", err)
+	}
+
+	m.SetSealed(true)
+	if health := run[types.ClientConfigMsg](t, m.GetHealth("")); !health.Health.Sealed {
+		t.Fatalf("expected sealed health, got %+v", health.Health)
+	}
+	if err := runErr(t, m.ListMounts("")); !errors.Is(err, errMockSealed) {
+		t.Fatalf("expected sealed error, got %v", err)
+	}
+}
+
+func TestMockClientSeal(t *testing.T) {
+	m := NewMockClient()
+
+	run[types.ClientSuccessMsg](t, m.Seal(""))
+	status := run[types.ClientSealStatusMsg](t, m.GetSealStatus(""))
+	if !status.Status.Sealed || status.Leader != nil {
+		t.Fatalf("unexpected seal status: %+v", status)
+	}
+
+	for i, key := range []string{"a", "b", "b", "c"} {
+		msg := run[types.ClientSuccessMsg](t, m.Unseal("", key))
+		if i == 2 && msg.Message != "unseal key accepted (2/3)" {
+			t.Fatalf("expected duplicate keys to be ignored, got %q", msg.Message)
+		}
+	}
+
+	status = run[types.ClientSealStatusMsg](t, m.GetSealStatus(""))
+	if status.Status.Sealed || status.Leader == nil || !status.SealCapabilities.Contains(types.CapabilitySudo) {
+		t.Fatalf("unexpected seal status: %+v", status)
+	}
+
+	m.SetCapabilities("sys/step-down", types.CapabilityUpdate)
+	if err := runErr(t, m.StepDown("")); !errors.Is(err, errMockPermissionDenied) {
+		t.Fatalf("expected permission denied, got %v", err)
+	}
+}
+
+func TestMockClientRaft(t *testing.T) {
+	m := NewMockClient()
+
+	state := run[types.ClientRaftAutopilotStateMsg](t, m.GetRaftAutopilotState("")).State
+	if state.Leader != "raft-1" || len(state.Voters) != 2 || state.Servers["raft-3"].Status != "non-voter" {
+		t.Fatalf("unexpected autopilot state: %+v", state)
+	}
+
+	var snapshot bytes.Buffer
+	run[types.ClientSuccessMsg](t, m.SaveRaftSnapshot("", &snapshot))
+
+	config := run[types.ClientRaftAutopilotConfigMsg](t, m.GetRaftAutopilotConfig("")).Config
+	config.CleanupDeadServers = true
+	run[types.ClientSuccessMsg](t, m.PutRaftAutopilotConfig("", config))
+	run[types.ClientSuccessMsg](t, m.RemoveRaftPeer("", "raft-3"))
+
+	run[types.ClientSuccessMsg](t, m.RestoreRaftSnapshot("", &snapshot, false))
+	if config = run[types.ClientRaftAutopilotConfigMsg](t, m.GetRaftAutopilotConfig("")).Config; config.CleanupDeadServers {
+		t.Fatal("expected autopilot config to be restored")
+	}
+	if peers := run[types.ClientRaftConfigMsg](t, m.GetRaftConfig("")).Peers; len(peers) != 3 {
+		t.Fatalf("expected raft peers to be restored, got %+v", peers)
+	}
+
+	m.SetCapabilities("sys/storage/raft/snapshot", types.CapabilityRead)
+	if err := runErr(t, m.SaveRaftSnapshot("", &snapshot)); !errors.Is(err, errMockPermissionDenied) {
+		t.Fatalf("expected permission denied, got %v", err)
+	}
+}
+
+func TestMockClientNodeHealth(t *testing.T) {
+	m := NewMockClient()
+
+	tests := []struct {
+		address string
+		want    func(h *vapi.HealthResponse) bool
+	}{
+		{address: m.Profile(), want: func(h *vapi.HealthResponse) bool { return !h.Standby }},
+		{address: "http://10.0.0.1:8200", want: func(h *vapi.HealthResponse) bool { return !h.Standby }},
+		{address: "http://10.0.0.2:8200", want: func(h *vapi.HealthResponse) bool { return h.Standby && !h.PerformanceStandby }},
+		{address: "http://10.0.0.3:8200", want: func(h *vapi.HealthResponse) bool { return h.PerformanceStandby }},
+	}
+
+	for _, tt := range tests {
+		msg := run[types.ClientNodeHealthMsg](t, m.GetNodeHealth("", tt.address))
+		if msg.Health == nil || !tt.want(msg.Health) {
+			t.Errorf("unexpected health for %q: %+v", tt.address, msg)
+		}
+	}
+
+	if msg := run[types.ClientNodeHealthMsg](t, m.GetNodeHealth("", "http://10.0.0.4:8200")); msg.Error == "" {
+		t.Fatalf("expected unknown node to be unreachable, got %+v", msg)
+	}
+}
+
+func TestMockClientMetrics(t *testing.T) {
+	m := NewMockClient()
+
+	first := run[types.ClientMetricsMsg](t, m.GetMetrics(""))
+	second := run[types.ClientMetricsMsg](t, m.GetMetrics(""))
+	if len(first.Metrics) == 0 || len(first.Metrics) != len(second.Metrics) {
+		t.Fatalf("unexpected metrics: %d then %d", len(first.Metrics), len(second.Metrics))
+	}
+
+	var tokens float64
+	var changed bool
+	for i, metric := range second.Metrics {
+		if metric.Name == "vault.token.count" {
+			tokens += metric.Value
+		}
+		if metric.ID() != first.Metrics[i].ID() {
+			t.Fatalf("expected stable metric order, got %q then %q", first.Metrics[i].ID(), metric.ID())
+		}
+		changed = changed || metric.Value != first.Metrics[i].Value || metric.Mean != first.Metrics[i].Mean
+	}
+
+	if tokens != 43 {
+		t.Errorf("expected 43 tokens, got %v", tokens)
+	}
+	if !changed {
+		t.Error("expected metrics to vary between reads")
+	}
+}
+
+func TestMockClientAuditDevices(t *testing.T) {
+	m := NewMockClient()
+
+	run[types.ClientSuccessMsg](t, m.EnableAuditDevice("", "socket", &vapi.EnableAuditOptions{
+		Type:    "socket",
+		Options: map[string]string{"address": "127.0.0.1:9090", "socket_type": "tcp"},
+	}))
+	run[types.ClientSuccessMsg](t, m.DisableAuditDevice("", "syslog"))
+
+	devices := run[types.ClientListAuditDevicesMsg](t, m.ListAuditDevices("")).Devices
+	if len(devices) != 2 || devices[0].Path != "file/" || devices[1].Path != "socket/" {
+		t.Fatalf("unexpected audit devices: %+v", devices)
+	}
+
+	// Hashes are stable, and differ between devices.
+	first := run[types.ClientAuditHashMsg](t, m.AuditHash("", "file", "foo"))
+	second := run[types.ClientAuditHashMsg](t, m.AuditHash("", "file/", "foo"))
+	other := run[types.ClientAuditHashMsg](t, m.AuditHash("", "socket", "foo"))
+	if first.Hash != second.Hash || first.Hash == other.Hash {
+		t.Fatalf("unexpected hashes: %q, %q, %q", first.Hash, second.Hash, other.Hash)
+	}
+
+	m.SetCapabilities("sys/audit*", types.CapabilityRead, types.CapabilityUpdate)
+	if err := runErr(t, m.DisableAuditDevice("", "file")); !errors.Is(err, errMockPermissionDenied) {
+		t.Fatalf("expected permission denied, got %v", err)
+	}
+}
+
+func TestMockMatch(t *testing.T) {
+	tests := []struct {
+		pattern string
+		path    string
+		want    bool
+	}{
+		{"*", "kv-v2-1/foo/bar", true},
+		{"kv-v2-1/foo/*", "kv-v2-1/foo/bar", true},
+		{"kv-v2-1/foo/*", "kv-v2-1/foo", false},
+		{"kv-v2-1/f*", "kv-v2-1/foo/bar", true},
+		{"kv-v2-1/+/bar", "kv-v2-1/foo/bar", true},
+		{"kv-v2-1/+/bar", "kv-v2-1/foo/baz", false},
+		{"sys/health", "sys/health", true},
+		{"sys/health", "sys/healthz", false},
+	}
+
+	for _, tt := range tests {
+		if got := mockMatch(tt.pattern, tt.path); got != tt.want {
+			t.Errorf("mockMatch(%q, %q) = %v, want %v", tt.pattern, tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestOfflineClient(t *testing.T) {
+	c := NewOfflineClient()
+	if c.Init() != nil {
+		t.Fatal("expected offline client to not check health on init")
+	}
+
+	for _, err := range []error{
+		runErr(t, c.GetHealth("")),
+		runErr(t, c.TokenLookupSelf("")),
+		runErr(t, c.ListMounts("")),
+	} {
+		if !errors.Is(err, types.ErrOffline) {
+			t.Fatalf("expected offline error, got %v", err)
+		}
+	}
+}
@@ -0,0 +1,38 @@
+// Copyright (c) Liam Stanley <liam@liam.sh>. All rights reserved. Use of
+// this source code is governed by the MIT license that can be found in
+// the LICENSE file.
+
+package api
+
+import (
+	tea "charm.land/bubbletea/v2"
+	"github.com/lrstanley/vex/internal/types"
+)
+
+var _ types.Client = &offlineClient{} // Ensure offlineClient implements types.Client.
+
+// offlineClient is a client which never connects to a Vault server, backed by an
+// empty [MockClient] which fails all requests.
+type offlineClient struct {
+	*MockClient
+}
+
+// NewOfflineClient returns a client for when vex is only used to view local
+// files (e.g. audit logs), which never connects to a Vault server, and doesn't
+// poll anything. All operations respond with an error wrapping
+// [types.ErrOffline].
+func NewOfflineClient() types.Client {
+	m := &MockClient{}
+	m.FailPath("*", types.ErrOffline)
+	return &offlineClient{MockClient: m}
+}
+
+// Init doesn't check the health of the server (or look up the token), as there
+// is none.
+func (c *offlineClient) Init() tea.Cmd {
+	return nil
+}
+
+func (c *offlineClient) Profile() string {
+	return ""
+}
@@ -0,0 +1,108 @@
+// Copyright (c) Liam Stanley <liam@liam.sh>. All rights reserved. Use of
+// this source code is governed by the MIT license that can be found in
+// the LICENSE file.
+
+package api
+
+import (
+	"fmt"
+	"io"
+
+	tea "charm.land/bubbletea/v2"
+	vapi "github.com/hashicorp/vault/api"
+	"github.com/lrstanley/vex/internal/types"
+)
+
+var _ types.Client = &readOnlyClient{} // Ensure readOnlyClient implements types.Client.
+
+// readOnlyClient wraps a client, refusing all operations which would modify the
+// Vault server.
+type readOnlyClient struct {
+	types.Client
+}
+
+// NewReadOnlyClient wraps the provided client, so that all operations which would
+// modify the Vault server respond with an error wrapping [types.ErrReadOnly],
+// without making any requests.
+func NewReadOnlyClient(c types.Client) types.Client {
+	if c.ReadOnly() {
+		return c
+	}
+	return &readOnlyClient{Client: c}
+}
+
+// refuse returns a command which responds with an error wrapping
+// [types.ErrReadOnly], for the provided operation.
+func refuse[T any](uuid, op string) tea.Cmd {
+	return wrapHandler(uuid, func() (*T, error) {
+		return nil, fmt.Errorf("%s: %w", op, types.ErrReadOnly)
+	})
+}
+
+func (c *readOnlyClient) ReadOnly() bool {
+	return true
+}
+
+func (c *readOnlyClient) PutKVv2Metadata(uuid string, _ *types.Mount, _ string, _ *types.KVv2MetadataInput) tea.Cmd {
+	return refuse[types.ClientSuccessMsg](uuid, "put secret metadata")
+}
+
+func (c *readOnlyClient) PutKVv2MountConfig(uuid string, _ *types.Mount, _ *types.KVv2MountConfig) tea.Cmd {
+	return refuse[types.ClientSuccessMsg](uuid, "put mount config")
+}
+
+func (c *readOnlyClient) PutKVSecret(uuid string, _ *types.Mount, _ string, _ map[string]any, _ int) tea.Cmd {
+	return refuse[types.ClientSuccessMsg](uuid, "put secret")
+}
+
+func (c *readOnlyClient) DeleteKVSecret(uuid string, _ *types.Mount, _ string, _ ...int) tea.Cmd {
+	return refuse[types.ClientSuccessMsg](uuid, "delete secret")
+}
+
+func (c *readOnlyClient) UndeleteKVSecret(uuid string, _ *types.Mount, _ string, _ ...int) tea.Cmd {
+	return refuse[types.ClientSuccessMsg](uuid, "undelete secret")
+}
+
+func (c *readOnlyClient) DestroyKVSecret(uuid string, _ *types.Mount, _ string, _ ...int) tea.Cmd {
+	return refuse[types.ClientSuccessMsg](uuid, "destroy secret")
+}
+
+func (c *readOnlyClient) RemoveRaftPeer(uuid, _ string) tea.Cmd {
+	return refuse[types.ClientSuccessMsg](uuid, "remove raft peer")
+}
+
+func (c *readOnlyClient) PutRaftAutopilotConfig(uuid string, _ *vapi.AutopilotConfig) tea.Cmd {
+	return refuse[types.ClientSuccessMsg](uuid, "update autopilot config")
+}
+
+func (c *readOnlyClient) RestoreRaftSnapshot(uuid string, _ io.Reader, _ bool) tea.Cmd {
+	return refuse[types.ClientSuccessMsg](uuid, "restore raft snapshot")
+}
+
+func (c *readOnlyClient) JoinRaft(uuid string, _ *vapi.RaftJoinRequest) tea.Cmd {
+	return refuse[types.ClientSuccessMsg](uuid, "join raft cluster")
+}
+
+func (c *readOnlyClient) Unseal(uuid, _ string) tea.Cmd {
+	return refuse[types.ClientSuccessMsg](uuid, "unseal")
+}
+
+func (c *readOnlyClient) ResetUnseal(uuid string) tea.Cmd {
+	return refuse[types.ClientSuccessMsg](uuid, "reset unseal")
+}
+
+func (c *readOnlyClient) Seal(uuid string) tea.Cmd {
+	return refuse[types.ClientSuccessMsg](uuid, "seal")
+}
+
+func (c *readOnlyClient) StepDown(uuid string) tea.Cmd {
+	return refuse[types.ClientSuccessMsg](uuid, "step down")
+}
+
+func (c *readOnlyClient) EnableAuditDevice(uuid, _ string, _ *vapi.EnableAuditOptions) tea.Cmd {
+	return refuse[types.ClientSuccessMsg](uuid, "enable audit device")
+}
+
+func (c *readOnlyClient) DisableAuditDevice(uuid, _ string) tea.Cmd {
+	return refuse[types.ClientSuccessMsg](uuid, "disable audit device")
+}
@@ -0,0 +1,172 @@
+// Copyright (c) Liam Stanley <liam@liam.sh>. All rights reserved. Use of
+// this source code is governed by the MIT license that can be found in
+// the LICENSE file.
+
+package api
+
+import (
+	"fmt"
+	"time"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/lrstanley/vex/internal/types"
+)
+
+var _ types.Client = &undoClient{} // Ensure undoClient implements types.Client.
+
+// undoClient wraps a client, offering to undo mutations of secrets (see
+// [types.OfferUndo]) once they succeed. Snapshots of secret data are only ever
+// kept in memory, for as long as the undo is offered.
+type undoClient struct {
+	types.Client
+}
+
+// NewUndoClient wraps the provided client, so that deleting, destroying or
+// overwriting a secret offers to undo it. KVv2 soft deletes are undone by
+// undeleting the versions, everything else by writing a snapshot of the secret
+// taken before the mutation. Destroyed KVv2 versions can't be recovered.
+func NewUndoClient(c types.Client) types.Client {
+	return &undoClient{Client: c}
+}
+
+// snapshot returns the current data of a secret, or nil if it doesn't exist or
+// can't be read.
+func (c *undoClient) snapshot(mount *types.Mount, path string) *types.ClientGetSecretMsg {
+	vm, ok := c.Client.GetKVSecret("", mount, path, 0)().(types.ClientMsg)
+	if !ok || vm.Error != nil {
+		return nil
+	}
+
+	msg, ok := vm.Msg.(types.ClientGetSecretMsg)
+	if !ok || msg.Data == nil {
+		return nil
+	}
+	return &msg
+}
+
+// mutate returns a command which invokes cmd, and if it succeeds, offers the undo
+// returned by fn. fn is provided a snapshot of the secret taken before the
+// mutation (nil if it didn't exist, or snapshot is false), and should return nil
+// if there is nothing to undo.
+func (c *undoClient) mutate(
+	mount *types.Mount,
+	path string,
+	snapshot bool,
+	cmd tea.Cmd,
+	fn func(prev *types.ClientGetSecretMsg) tea.Cmd,
+) tea.Cmd {
+	if c.ReadOnly() {
+		return cmd
+	}
+
+	return func() tea.Msg {
+		var prev *types.ClientGetSecretMsg
+		if snapshot {
+			prev = c.snapshot(mount, path)
+		}
+
+		msg := cmd()
+		if vm, ok := msg.(types.ClientMsg); !ok || vm.Error != nil {
+			return msg
+		}
+
+		offer := fn(prev)
+		if offer == nil {
+			return msg
+		}
+		return tea.BatchMsg{types.CmdMsg(msg), offer}
+	}
+}
+
+// restore returns a command which invokes cmd to revert a mutation, letting the
+// user know once it succeeds.
+func restore(description string, cmd tea.Cmd) tea.Cmd {
+	return func() tea.Msg {
+		msg := cmd()
+		if vm, ok := msg.(types.ClientMsg); !ok || vm.Error != nil {
+			return msg
+		}
+		return tea.BatchMsg{
+			types.CmdMsg(msg),
+			types.SendStatus("undid "+description, types.Success, 2*time.Second),
+		}
+	}
+}
+
+// rewrite returns a command which writes the snapshot back to the secret. For
+// KVv2, the write is check-and-set against the version written by the mutation
+// being undone, so that later changes by others aren't clobbered.
+func (c *undoClient) rewrite(description string, prev *types.ClientGetSecretMsg, mutated bool) tea.Cmd {
+	cas := -1
+	if prev.Mount.KVVersion() == 2 {
+		cas = prev.CurrentVersion
+		if mutated {
+			cas++
+		}
+	}
+
+	return types.OfferUndo(
+		description,
+		restore(description, c.Client.PutKVSecret("", prev.Mount, prev.Path, prev.Data, cas)),
+		"",
+	)
+}
+
+func (c *undoClient) PutKVSecret(uuid string, mount *types.Mount, path string, data map[string]any, cas int) tea.Cmd {
+	return c.mutate(mount, path, true, c.Client.PutKVSecret(uuid, mount, path, data, cas), func(prev *types.ClientGetSecretMsg) tea.Cmd {
+		if prev == nil {
+			return nil // Nothing was overwritten.
+		}
+		return c.rewrite("write "+mount.Path+path, prev, true)
+	})
+}
+
+func (c *undoClient) DeleteKVSecret(uuid string, mount *types.Mount, path string, versions ...int) tea.Cmd {
+	description := "delete " + mount.Path + path
+	if len(versions) > 0 {
+		description += fmt.Sprintf(" %v", versions)
+	}
+
+	// KVv2 deletes of specific versions can be undone without a snapshot, however
+	// deleting the latest version requires knowing which version that was.
+	snapshot := mount.KVVersion() != 2 || len(versions) == 0
+
+	return c.mutate(mount, path, snapshot, c.Client.DeleteKVSecret(uuid, mount, path, versions...), func(prev *types.ClientGetSecretMsg) tea.Cmd {
+		if mount.KVVersion() != 2 {
+			if prev == nil {
+				return nil
+			}
+			return c.rewrite(description, prev, false)
+		}
+
+		undelete := versions
+		if len(undelete) == 0 {
+			if prev == nil || prev.Version < 1 {
+				return nil
+			}
+			undelete = []int{prev.Version}
+		}
+		return types.OfferUndo(
+			description,
+			restore(description, c.Client.UndeleteKVSecret("", mount, path, undelete...)),
+			"",
+		)
+	})
+}
+
+func (c *undoClient) DestroyKVSecret(uuid string, mount *types.Mount, path string, versions ...int) tea.Cmd {
+	description := "destroy " + mount.Path + path
+	if len(versions) > 0 {
+		description += fmt.Sprintf(" %v", versions)
+	}
+
+	return c.mutate(mount, path, mount.KVVersion() != 2, c.Client.DestroyKVSecret(uuid, mount, path, versions...), func(prev *types.ClientGetSecretMsg) tea.Cmd {
+		if mount.KVVersion() == 2 {
+			return types.OfferUndo(description, nil, "destroyed versions are permanently removed")
+		}
+		if prev == nil {
+			return nil
+		}
+		return c.rewrite(description, prev, false)
+	})
+}
@@ -0,0 +1,265 @@
+// Copyright (c) Liam Stanley <liam@liam.sh>. All rights reserved. Use of
+// this source code is governed by the MIT license that can be found in
+// the LICENSE file.
+
+package api
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"testing"
+
+	tea "charm.land/bubbletea/v2"
+	vapi "github.com/hashicorp/vault/api"
+	"github.com/lrstanley/vex/internal/api/fakevault"
+	"github.com/lrstanley/vex/internal/types"
+)
+
+// stubKVClient is a client which only supports KV secrets, kept in memory, and
+// records the operations made against it.
+type stubKVClient struct {
+	types.Client
+
+	readOnly bool
+	secrets  map[string]map[string]any
+	versions map[string]int
+	calls    []string
+}
+
+func newStubKVClient() *stubKVClient {
+	return &stubKVClient{
+		secrets:  make(map[string]map[string]any),
+		versions: make(map[string]int),
+	}
+}
+
+func (c *stubKVClient) ReadOnly() bool {
+	return c.readOnly
+}
+
+func (c *stubKVClient) GetKVSecret(uuid string, mount *types.Mount, path string, _ int) tea.Cmd {
+	return func() tea.Msg {
+		c.calls = append(c.calls, "get "+mount.Path+path)
+
+		data, ok := c.secrets[mount.Path+path]
+		if !ok {
+			return types.ClientMsg{UUID: uuid, Error: errors.New("secret not found")}
+		}
+		v := c.versions[mount.Path+path]
+		return types.ClientMsg{UUID: uuid, Msg: types.ClientGetSecretMsg{
+			Mount:          mount,
+			Path:           path,
+			Data:           maps.Clone(data),
+			Version:        v,
+			CurrentVersion: v,
+		}}
+	}
+}
+
+func (c *stubKVClient) PutKVSecret(uuid string, mount *types.Mount, path string, data map[string]any, cas int) tea.Cmd {
+	return func() tea.Msg {
+		c.calls = append(c.calls, fmt.Sprintf("put %s%s cas=%d", mount.Path, path, cas))
+		c.secrets[mount.Path+path] = maps.Clone(data)
+		c.versions[mount.Path+path]++
+		return types.ClientMsg{UUID: uuid, Msg: types.ClientSuccessMsg{}}
+	}
+}
+
+func (c *stubKVClient) DeleteKVSecret(uuid string, mount *types.Mount, path string, versions ...int) tea.Cmd {
+	return func() tea.Msg {
+		c.calls = append(c.calls, fmt.Sprintf("delete %s%s %v", mount.Path, path, versions))
+		delete(c.secrets, mount.Path+path)
+		return types.ClientMsg{UUID: uuid, Msg: types.ClientSuccessMsg{}}
+	}
+}
+
+func (c *stubKVClient) UndeleteKVSecret(uuid string, mount *types.Mount, path string, versions ...int) tea.Cmd {
+	return func() tea.Msg {
+		c.calls = append(c.calls, fmt.Sprintf("undelete %s%s %v", mount.Path, path, versions))
+		return types.ClientMsg{UUID: uuid, Msg: types.ClientSuccessMsg{}}
+	}
+}
+
+func (c *stubKVClient) DestroyKVSecret(uuid string, mount *types.Mount, path string, versions ...int) tea.Cmd {
+	return func() tea.Msg {
+		c.calls = append(c.calls, fmt.Sprintf("destroy %s%s %v", mount.Path, path, versions))
+		return types.ClientMsg{UUID: uuid, Msg: types.ClientSuccessMsg{}}
+	}
+}
+
+// runUndo invokes cmd, returning the undo it offered (if any). Status messages
+// (and their delayed commands) are ignored.
+func runUndo(t *testing.T, cmd tea.Cmd) *types.Undo {
+	t.Helper()
+
+	msgs := []tea.Msg{cmd()}
+	if batch, ok := msgs[0].(tea.BatchMsg); ok {
+		msgs = msgs[:0]
+		for _, cmd := range batch {
+			msgs = append(msgs, cmd())
+		}
+	}
+
+	var undo *types.Undo
+	for _, msg := range msgs {
+		switch msg := msg.(type) {
+		case types.ClientMsg:
+			if msg.Error != nil {
+				t.Fatalf("unexpected error: %v", msg.Error)
+			}
+		case types.UndoMsg:
+			undo = &msg.Undo
+		}
+	}
+	return undo
+}
+
+func TestUndoClientOffers(t *testing.T) {
+	t.Parallel()
+
+	kv1 := &types.Mount{Path: "kv/", MountOutput: &vapi.MountOutput{Options: map[string]string{"version": "1"}}}
+	kv2 := &types.Mount{Path: "secret/", MountOutput: &vapi.MountOutput{Options: map[string]string{"version": "2"}}}
+
+	tests := []struct {
+		name     string
+		readOnly bool
+		run      func(c types.Client) tea.Cmd
+		want     bool     // Whether an undo is offered.
+		possible bool     // Whether the offered undo can be performed.
+		calls    []string // Calls made by the undo.
+	}{
+		{
+			name:     "kv1-delete",
+			run:      func(c types.Client) tea.Cmd { return c.DeleteKVSecret("", kv1, "a") },
+			want:     true,
+			possible: true,
+			calls:    []string{"put kv/a cas=-1"},
+		},
+		{
+			name:     "kv2-delete-latest",
+			run:      func(c types.Client) tea.Cmd { return c.DeleteKVSecret("", kv2, "b") },
+			want:     true,
+			possible: true,
+			calls:    []string{"undelete secret/b [1]"},
+		},
+		{
+			name:     "kv2-delete-versions",
+			run:      func(c types.Client) tea.Cmd { return c.DeleteKVSecret("", kv2, "b", 1) },
+			want:     true,
+			possible: true,
+			calls:    []string{"undelete secret/b [1]"},
+		},
+		{
+			name: "kv2-destroy",
+			run:  func(c types.Client) tea.Cmd { return c.DestroyKVSecret("", kv2, "b", 1) },
+			want: true,
+		},
+		{
+			name:     "kv1-destroy",
+			run:      func(c types.Client) tea.Cmd { return c.DestroyKVSecret("", kv1, "a") },
+			want:     true,
+			possible: true,
+			calls:    []string{"put kv/a cas=-1"},
+		},
+		{
+			name:     "kv2-overwrite",
+			run:      func(c types.Client) tea.Cmd { return c.PutKVSecret("", kv2, "b", map[string]any{"k": "v2"}, 1) },
+			want:     true,
+			possible: true,
+			calls:    []string{"put secret/b cas=2"},
+		},
+		{
+			name: "create",
+			run:  func(c types.Client) tea.Cmd { return c.PutKVSecret("", kv2, "new", map[string]any{"k": "v"}, 0) },
+		},
+		{
+			name:     "read-only",
+			readOnly: true,
+			run:      func(c types.Client) tea.Cmd { return c.DeleteKVSecret("", kv1, "a") },
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			stub := newStubKVClient()
+			stub.readOnly = tt.readOnly
+			stub.secrets["kv/a"] = map[string]any{"k": "v1"}
+			stub.secrets["secret/b"] = map[string]any{"k": "v1"}
+			stub.versions["secret/b"] = 1
+
+			undo := runUndo(t, tt.run(NewUndoClient(stub)))
+			if (undo != nil) != tt.want {
+				t.Fatalf("expected undo offered to be %t, got %+v", tt.want, undo)
+			}
+			if undo == nil {
+				return
+			}
+			if undo.Possible() != tt.possible {
+				t.Fatalf("expected undo possible to be %t, got %+v", tt.possible, undo)
+			}
+			if !undo.Possible() {
+				if undo.Reason == "" {
+					t.Fatal("expected a reason the undo isn't possible")
+				}
+				return
+			}
+
+			stub.calls = nil
+			runUndo(t, undo.Cmd)
+			if !slices.Equal(stub.calls, tt.calls) {
+				t.Fatalf("expected undo calls %q, got %q", tt.calls, stub.calls)
+			}
+		})
+	}
+}
+
+func TestUndoClient(t *testing.T) {
+	srv := newTestServer(t)
+	c := NewUndoClient(newTestClient(t, srv, fakevault.RootToken))
+	kv1, kv2 := getMount(t, c, "kv/"), getMount(t, c, "secret/")
+
+	srv.PutSecret("kv/", "a", map[string]any{"k": "v1"})
+	srv.PutSecret("secret/", "b", map[string]any{"k": "v1"})
+
+	tests := []struct {
+		name     string
+		mutate   tea.Cmd
+		mount    string
+		path     string
+		possible bool
+	}{
+		{name: "kv1-overwrite", mutate: c.PutKVSecret("", kv1, "a", map[string]any{"k": "v2"}, -1), mount: "kv/", path: "a", possible: true},
+		{name: "kv1-delete", mutate: c.DeleteKVSecret("", kv1, "a"), mount: "kv/", path: "a", possible: true},
+		{name: "kv2-overwrite", mutate: c.PutKVSecret("", kv2, "b", map[string]any{"k": "v2"}, 1), mount: "secret/", path: "b", possible: true},
+		{name: "kv2-delete", mutate: c.DeleteKVSecret("", kv2, "b"), mount: "secret/", path: "b", possible: true},
+		{name: "kv2-destroy", mutate: c.DestroyKVSecret("", kv2, "b", 1)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			undo := runUndo(t, tt.mutate)
+			if undo == nil || undo.Possible() != tt.possible {
+				t.Fatalf("unexpected undo: %+v", undo)
+			}
+			if !tt.possible {
+				return
+			}
+
+			runUndo(t, undo.Cmd)
+
+			data, ok := srv.Secret(tt.mount, tt.path)
+			if !ok || data["k"] != "v1" {
+				t.Fatalf("expected secret to be restored, got %v", data)
+			}
+		})
+	}
+
+	// Nothing to undo when creating a secret.
+	if undo := runUndo(t, c.PutKVSecret("", kv1, "new", map[string]any{"k": "v"}, -1)); undo != nil {
+		t.Fatalf("unexpected undo: %+v", undo)
+	}
+}
@@ -5,6 +5,9 @@
 package api
 
 import (
+	"errors"
+	"net/http"
+
 	tea "charm.land/bubbletea/v2"
 	vapi "github.com/hashicorp/vault/api"
 	"github.com/lrstanley/vex/internal/types"
@@ -44,3 +47,10 @@ func wrapHandler[T any](uuid string, f func() (*T, error)) tea.Cmd {
 		}
 	}
 }
+
+// isPermissionDenied returns true if err is a response from the Vault server
+// denying the request, due to the policies of the token.
+func isPermissionDenied(err error) bool {
+	var rerr *vapi.ResponseError
+	return errors.As(err, &rerr) && rerr.StatusCode == http.StatusForbidden
+}
@@ -0,0 +1,78 @@
+// Copyright (c) Liam Stanley <liam@liam.sh>. All rights reserved. Use of
+// this source code is governed by the MIT license that can be found in
+// the LICENSE file.
+
+package auditlog
+
+import (
+	"cmp"
+	"slices"
+	"time"
+)
+
+// Group is the aggregate of the requests sharing the same key (e.g. the same
+// path or client).
+type Group struct {
+	Key      string
+	Requests int
+	Errors   int
+	Last     time.Time // Time of the most recent request.
+	LastPath string    // Path of the most recent request.
+}
+
+// ErrorRate returns the fraction of requests which failed, between 0 and 1.
+func (g *Group) ErrorRate() float64 {
+	if g.Requests == 0 {
+		return 0
+	}
+	return float64(g.Errors) / float64(g.Requests)
+}
+
+// GroupBy aggregates the requests by key, sorted by the number of requests (most
+// first). Requests with an empty key are skipped.
+func GroupBy(entries []*Entry, key func(*Entry) string) []*Group {
+	groups := make(map[string]*Group)
+
+	for _, e := range entries {
+		k := key(e)
+		if k == "" {
+			continue
+		}
+
+		g, ok := groups[k]
+		if !ok {
+			g = &Group{Key: k}
+			groups[k] = g
+		}
+
+		g.Requests++
+		if e.Error != "" {
+			g.Errors++
+		}
+		if !e.Time.Before(g.Last) {
+			g.Last = e.Time
+			g.LastPath = e.Path
+		}
+	}
+
+	out := make([]*Group, 0, len(groups))
+	for _, g := range groups {
+		out = append(out, g)
+	}
+	slices.SortFunc(out, func(a, b *Group) int {
+		return cmp.Or(
+			cmp.Compare(b.Requests, a.Requests),
+			cmp.Compare(a.Key, b.Key),
+		)
+	})
+	return out
+}
+
+// ByPath groups requests by path.
+func ByPath(e *Entry) string { return e.Path }
+
+// ByClient groups requests by client (see [Entry.Client]).
+func ByClient(e *Entry) string { return e.Client() }
+
+// ByError groups failed requests by error message.
+func ByError(e *Entry) string { return e.Error }
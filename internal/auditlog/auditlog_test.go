@@ -0,0 +1,213 @@
+// Copyright (c) Liam Stanley <liam@liam.sh>. All rights reserved. Use of
+// this source code is governed by the MIT license that can be found in
+// the LICENSE file.
+
+package auditlog
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testLog = `{"time":"2025-01-02T10:00:00Z","type":"request","auth":{"display_name":"token-ci","entity_id":"e1"},"request":{"id":"1","operation":"read","path":"secret/data/app","mount_point":"secret/","mount_type":"kv","remote_address":"10.0.0.1"}}
+{"time":"2025-01-02T10:00:00.250Z","type":"response","auth":{"display_name":"token-ci","entity_id":"e1"},"request":{"id":"1","operation":"read","path":"secret/data/app"}}
+{"time":"2025-01-02T10:05:00Z","type":"request","request":{"id":"2","operation":"update","path":"auth/userpass/login/bob","remote_address":"10.0.0.2"}}
+not json
+{"time":"2025-01-02T10:05:00.100Z","type":"response","error":"invalid credentials","request":{"id":"2","operation":"update","path":"auth/userpass/login/bob","mount_point":"auth/userpass/","mount_type":"userpass"}}
+vault-audit: {"time":"2025-01-02T11:00:00Z","type":"request","auth":{"display_name":"token-ci"},"request":{"id":"3","operation":"read","path":"secret/data/app"}}
+`
+
+func testEntries(t *testing.T) *Log {
+	t.Helper()
+
+	l := NewLog(0)
+	for line := range strings.SplitSeq(testLog, "\n") {
+		_ = l.Add([]byte(line))
+	}
+	return l
+}
+
+func TestLogAdd(t *testing.T) {
+	t.Parallel()
+
+	l := testEntries(t)
+
+	if len(l.Entries) != 3 {
+		t.Fatalf("expected 3 entries, got %d", len(l.Entries))
+	}
+	if l.Malformed != 1 {
+		t.Fatalf("expected 1 malformed line, got %d", l.Malformed)
+	}
+
+	first := l.Entries[0]
+	if !first.Responded || first.Duration != 250*time.Millisecond || first.Client() != "token-ci" {
+		t.Fatalf("unexpected first entry: %+v", first)
+	}
+
+	login := l.Entries[1]
+	if login.Error != "invalid credentials" || login.MountPoint != "auth/userpass/" || login.Client() != "10.0.0.2" {
+		t.Fatalf("unexpected login entry: %+v", login)
+	}
+
+	if l.Entries[2].Responded || l.Entries[2].Path != "secret/data/app" {
+		t.Fatalf("unexpected prefixed entry: %+v", l.Entries[2])
+	}
+}
+
+func TestLogMax(t *testing.T) {
+	t.Parallel()
+
+	l := NewLog(10)
+	for i := range 100 {
+		line := `{"type":"request","request":{"id":"` + strings.Repeat("x", i+1) + `","path":"p"}}`
+		if err := l.Add([]byte(line)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if len(l.Entries) > 11 || len(l.byID) != len(l.Entries) {
+		t.Fatalf("expected at most 11 entries, got %d (%d by id)", len(l.Entries), len(l.byID))
+	}
+	if got := len(l.Entries[len(l.Entries)-1].ID); got != 100 {
+		t.Fatalf("expected newest entry to be kept, got id length %d", got)
+	}
+}
+
+func TestFilter(t *testing.T) {
+	t.Parallel()
+
+	l := testEntries(t)
+	now := time.Date(2025, 1, 2, 11, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		query   string
+		want    []string // IDs.
+		wantErr bool
+	}{
+		{query: "", want: []string{"1", "2", "3"}},
+		{query: "path:secret/", want: []string{"1", "3"}},
+		{query: "op:update", want: []string{"2"}},
+		{query: "client:token-ci", want: []string{"1", "3"}},
+		{query: "entity:10.0.0.2", want: []string{"2"}},
+		{query: "mount:userpass", want: []string{"2"}},
+		{query: "error:true", want: []string{"2"}},
+		{query: "error:false path:secret", want: []string{"1", "3"}},
+		{query: "err:credentials", want: []string{"2"}},
+		{query: "since:1h", want: []string{"3"}},
+		{query: "after:2025-01-02T10:01Z until:2025-01-02T10:30Z", want: []string{"2"}},
+		{query: "BOB", want: []string{"2"}},
+		{query: "bob op:read", want: nil},
+		{query: "foo:bar", wantErr: true},
+		{query: "since:yesterday", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			t.Parallel()
+
+			f, err := ParseFilter(tt.query, now)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("expected error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var got []string
+			for _, e := range l.Entries {
+				if f.Match(e) {
+					got = append(got, e.ID)
+				}
+			}
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Fatalf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestGroupBy(t *testing.T) {
+	t.Parallel()
+
+	l := testEntries(t)
+
+	paths := GroupBy(l.Entries, ByPath)
+	if len(paths) != 2 || paths[0].Key != "secret/data/app" || paths[0].Requests != 2 {
+		t.Fatalf("unexpected path groups: %+v", paths)
+	}
+
+	clients := GroupBy(l.Entries, ByClient)
+	if len(clients) != 2 || clients[1].Key != "10.0.0.2" || clients[1].ErrorRate() != 1 {
+		t.Fatalf("unexpected client groups: %+v", clients)
+	}
+
+	errs := GroupBy(l.Entries, ByError)
+	if len(errs) != 1 || errs[0].LastPath != "auth/userpass/login/bob" {
+		t.Fatalf("unexpected error groups: %+v", errs)
+	}
+}
+
+func TestTailer(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "audit.log")
+	if err := os.WriteFile(path, []byte("one\ntw"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tail, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tail.Close()
+
+	read := func(want ...string) {
+		t.Helper()
+
+		lines, err := tail.Read(10)
+		if !errors.Is(err, io.EOF) {
+			t.Fatalf("expected EOF, got %v", err)
+		}
+
+		got := make([]string, 0, len(lines))
+		for _, line := range lines {
+			got = append(got, string(line))
+		}
+		if strings.Join(got, ",") != strings.Join(want, ",") {
+			t.Fatalf("expected %q, got %q", want, got)
+		}
+	}
+
+	read("one")
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _ = f.WriteString("o\nthree\n")
+	_ = f.Close()
+	read("two", "three")
+
+	// Truncated.
+	if err = os.WriteFile(path, []byte("a\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	read("a")
+
+	// Rotated.
+	if err = os.Rename(path, path+".1"); err != nil {
+		t.Fatal(err)
+	}
+	if err = os.WriteFile(path, []byte("b\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	read("b")
+}
@@ -0,0 +1,203 @@
+// Copyright (c) Liam Stanley <liam@liam.sh>. All rights reserved. Use of
+// this source code is governed by the MIT license that can be found in
+// the LICENSE file.
+
+// Package auditlog parses, filters and aggregates Vault audit logs, as written
+// by file audit devices (one JSON entry per line).
+package auditlog
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// ErrMalformed is returned for lines which aren't audit log entries.
+var ErrMalformed = errors.New("malformed audit log entry")
+
+// rawEntry is a single line of the audit log, which is either the request or the
+// response of a request to Vault.
+type rawEntry struct {
+	Time  time.Time `json:"time"`
+	Type  string    `json:"type"`
+	Error string    `json:"error"`
+	Auth  *struct {
+		DisplayName string   `json:"display_name"`
+		EntityID    string   `json:"entity_id"`
+		Policies    []string `json:"policies"`
+	} `json:"auth"`
+	Request *struct {
+		ID         string `json:"id"`
+		Operation  string `json:"operation"`
+		Path       string `json:"path"`
+		MountPoint string `json:"mount_point"`
+		MountType  string `json:"mount_type"`
+		RemoteAddr string `json:"remote_address"`
+		Namespace  *struct {
+			Path string `json:"path"`
+		} `json:"namespace"`
+	} `json:"request"`
+	Response *struct {
+		MountPoint string `json:"mount_point"`
+		MountType  string `json:"mount_type"`
+		Auth       *struct {
+			DisplayName string   `json:"display_name"`
+			EntityID    string   `json:"entity_id"`
+			Policies    []string `json:"policies"`
+		} `json:"auth"`
+	} `json:"response"`
+}
+
+// Entry is a single request to Vault, merged from its request and response
+// entries in the audit log.
+type Entry struct {
+	ID          string        `json:"id"`
+	Time        time.Time     `json:"time"`
+	Duration    time.Duration `json:"duration,omitempty"` // Time until the response was logged.
+	Operation   string        `json:"operation"`
+	Path        string        `json:"path"`
+	MountPoint  string        `json:"mount_point,omitempty"`
+	MountType   string        `json:"mount_type,omitempty"`
+	Namespace   string        `json:"namespace,omitempty"`
+	RemoteAddr  string        `json:"remote_address,omitempty"`
+	DisplayName string        `json:"display_name,omitempty"`
+	EntityID    string        `json:"entity_id,omitempty"`
+	Policies    []string      `json:"policies,omitempty"`
+	Error       string        `json:"error,omitempty"`
+	Responded   bool          `json:"responded"` // Whether the response was logged.
+}
+
+// Client returns the identity of the client which made the request, which is the
+// display name of the token, falling back to the entity ID and remote address.
+func (e *Entry) Client() string {
+	switch {
+	case e.DisplayName != "":
+		return e.DisplayName
+	case e.EntityID != "":
+		return e.EntityID
+	default:
+		return e.RemoteAddr
+	}
+}
+
+// Parse parses a single line of the audit log. Any prefix configured for the
+// audit device (see the "prefix" option) is skipped.
+func Parse(line []byte) (*Entry, error) {
+	i := bytes.IndexByte(line, '{')
+	if i < 0 {
+		return nil, ErrMalformed
+	}
+
+	var raw rawEntry
+	if err := json.Unmarshal(line[i:], &raw); err != nil || raw.Request == nil {
+		return nil, ErrMalformed
+	}
+
+	e := &Entry{
+		ID:         raw.Request.ID,
+		Time:       raw.Time,
+		Operation:  raw.Request.Operation,
+		Path:       raw.Request.Path,
+		MountPoint: raw.Request.MountPoint,
+		MountType:  raw.Request.MountType,
+		RemoteAddr: raw.Request.RemoteAddr,
+		Error:      raw.Error,
+		Responded:  raw.Type == "response",
+	}
+	if raw.Request.Namespace != nil {
+		e.Namespace = raw.Request.Namespace.Path
+	}
+	if raw.Auth != nil {
+		e.DisplayName = raw.Auth.DisplayName
+		e.EntityID = raw.Auth.EntityID
+		e.Policies = raw.Auth.Policies
+	}
+
+	if raw.Response != nil {
+		if e.MountPoint == "" {
+			e.MountPoint = raw.Response.MountPoint
+			e.MountType = raw.Response.MountType
+		}
+
+		// Logins are only authenticated once the response is returned.
+		if raw.Response.Auth != nil && e.DisplayName == "" {
+			e.DisplayName = raw.Response.Auth.DisplayName
+			e.EntityID = raw.Response.Auth.EntityID
+			e.Policies = raw.Response.Auth.Policies
+		}
+	}
+
+	return e, nil
+}
+
+// merge merges the response entry of the same request into e.
+func (e *Entry) merge(resp *Entry) {
+	if !e.Time.IsZero() && resp.Time.After(e.Time) {
+		e.Duration = resp.Time.Sub(e.Time)
+	}
+	if resp.Error != "" {
+		e.Error = resp.Error
+	}
+	if resp.DisplayName != "" && e.DisplayName == "" {
+		e.DisplayName = resp.DisplayName
+		e.EntityID = resp.EntityID
+		e.Policies = resp.Policies
+	}
+	if e.MountPoint == "" {
+		e.MountPoint = resp.MountPoint
+		e.MountType = resp.MountType
+	}
+	e.Responded = true
+}
+
+// Log contains the requests read from an audit log, in the order they were
+// logged, merging the request and response entries of each request.
+type Log struct {
+	// Entries are the requests, oldest first.
+	Entries []*Entry
+	// Malformed is the number of lines which couldn't be parsed.
+	Malformed int
+
+	max  int
+	byID map[string]*Entry
+}
+
+// NewLog returns a new log, which keeps the last maxEntries requests, discarding
+// the oldest ones (in batches, so up to 10% more may be kept). If maxEntries is
+// 0, all requests are kept.
+func NewLog(maxEntries int) *Log {
+	return &Log{max: maxEntries, byID: make(map[string]*Entry)}
+}
+
+// Add parses a single line of the audit log, adding the request, or merging the
+// response into the request it belongs to.
+func (l *Log) Add(line []byte) error {
+	if len(bytes.TrimSpace(line)) == 0 {
+		return nil
+	}
+
+	e, err := Parse(line)
+	if err != nil {
+		l.Malformed++
+		return err
+	}
+
+	if e.ID != "" {
+		if existing, ok := l.byID[e.ID]; ok {
+			existing.merge(e)
+			return nil
+		}
+		l.byID[e.ID] = e
+	}
+	l.Entries = append(l.Entries, e)
+
+	if l.max > 0 && len(l.Entries) > l.max+l.max/10 {
+		n := len(l.Entries) - l.max
+		for _, old := range l.Entries[:n] {
+			delete(l.byID, old.ID)
+		}
+		l.Entries = append([]*Entry(nil), l.Entries[n:]...)
+	}
+	return nil
+}
@@ -0,0 +1,157 @@
+// Copyright (c) Liam Stanley <liam@liam.sh>. All rights reserved. Use of
+// this source code is governed by the MIT license that can be found in
+// the LICENSE file.
+
+package auditlog
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// timeLayouts are the layouts accepted for absolute times in filters.
+var timeLayouts = []string{
+	time.RFC3339,
+	"2006-01-02T15:04Z07:00",
+	"2006-01-02T15:04",
+	"2006-01-02 15:04",
+	"2006-01-02",
+}
+
+// Filter matches requests against a query. See [ParseFilter] for the syntax.
+type Filter struct {
+	Paths      []string
+	Operations []string
+	Clients    []string
+	Mounts     []string
+	Errors     []string
+	OnlyErrors *bool // If set, only requests which did (or didn't) fail match.
+	Since      time.Time
+	Until      time.Time
+	Terms      []string // Free-text terms, matched against any field.
+}
+
+// ParseFilter parses a filter query, which is a space-separated list of
+// "key:value" tokens and free-text terms. All tokens must match, and values are
+// case-insensitive substrings. Supported keys are:
+//
+//   - path: the request path.
+//   - op, operation: the operation (e.g. "read", "update").
+//   - client, entity, name: the display name, entity ID or remote address.
+//   - mount: the mount point or mount type.
+//   - error, err: the error message, or "true"/"false" to match requests which
+//     did or didn't fail.
+//   - since, after, until, before: a duration relative to now (e.g. "15m"), or an
+//     absolute time (e.g. "2006-01-02T15:04" or RFC3339).
+func ParseFilter(query string, now time.Time) (*Filter, error) {
+	f := &Filter{}
+
+	for _, token := range strings.Fields(query) {
+		key, value, ok := strings.Cut(token, ":")
+		if !ok || value == "" {
+			f.Terms = append(f.Terms, strings.ToLower(token))
+			continue
+		}
+
+		value = strings.ToLower(value)
+
+		switch strings.ToLower(key) {
+		case "path":
+			f.Paths = append(f.Paths, value)
+		case "op", "operation":
+			f.Operations = append(f.Operations, value)
+		case "client", "entity", "name":
+			f.Clients = append(f.Clients, value)
+		case "mount":
+			f.Mounts = append(f.Mounts, value)
+		case "error", "err":
+			if b, err := strconv.ParseBool(value); err == nil {
+				f.OnlyErrors = &b
+				continue
+			}
+			f.Errors = append(f.Errors, value)
+		case "since", "after":
+			t, err := parseTime(value, now)
+			if err != nil {
+				return nil, fmt.Errorf("invalid %q filter: %w", key, err)
+			}
+			f.Since = t
+		case "until", "before":
+			t, err := parseTime(value, now)
+			if err != nil {
+				return nil, fmt.Errorf("invalid %q filter: %w", key, err)
+			}
+			f.Until = t
+		default:
+			return nil, fmt.Errorf("unknown filter %q", key)
+		}
+	}
+
+	return f, nil
+}
+
+// parseTime parses a duration relative to now, or an absolute time (in the local
+// timezone, unless specified).
+func parseTime(value string, now time.Time) (time.Time, error) {
+	if d, err := time.ParseDuration(value); err == nil {
+		return now.Add(-d.Abs()), nil
+	}
+
+	for _, layout := range timeLayouts {
+		if t, err := time.ParseInLocation(layout, strings.ToUpper(value), time.Local); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("expected a duration (e.g. 15m) or time (e.g. 2006-01-02T15:04), got %q", value)
+}
+
+// IsEmpty returns true if the filter matches all requests.
+func (f *Filter) IsEmpty() bool {
+	return f == nil || (len(f.Paths) == 0 && len(f.Operations) == 0 && len(f.Clients) == 0 &&
+		len(f.Mounts) == 0 && len(f.Errors) == 0 && f.OnlyErrors == nil &&
+		f.Since.IsZero() && f.Until.IsZero() && len(f.Terms) == 0)
+}
+
+// Match returns true if the request matches all tokens of the filter.
+func (f *Filter) Match(e *Entry) bool {
+	if f.IsEmpty() {
+		return true
+	}
+
+	switch {
+	case !f.Since.IsZero() && e.Time.Before(f.Since):
+		return false
+	case !f.Until.IsZero() && e.Time.After(f.Until):
+		return false
+	case f.OnlyErrors != nil && *f.OnlyErrors != (e.Error != ""):
+		return false
+	case !containsAll(f.Paths, e.Path),
+		!containsAll(f.Operations, e.Operation),
+		!containsAll(f.Clients, e.DisplayName, e.EntityID, e.RemoteAddr),
+		!containsAll(f.Mounts, e.MountPoint, e.MountType),
+		!containsAll(f.Errors, e.Error),
+		!containsAll(f.Terms, e.Path, e.Operation, e.MountPoint, e.DisplayName, e.EntityID, e.RemoteAddr, e.Error):
+		return false
+	}
+	return true
+}
+
+// containsAll returns true if each of the (lowercase) values is a substring of
+// at least one of the fields.
+func containsAll(values []string, fields ...string) bool {
+	for _, v := range values {
+		var found bool
+		for _, field := range fields {
+			if strings.Contains(strings.ToLower(field), v) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,129 @@
+// Copyright (c) Liam Stanley <liam@liam.sh>. All rights reserved. Use of
+// this source code is governed by the MIT license that can be found in
+// the LICENSE file.
+
+package auditlog
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"os"
+)
+
+// maxLineSize is the maximum size of a single line of the audit log. Longer
+// lines (e.g. with huge secrets logged raw) are truncated, and won't parse.
+const maxLineSize = 4 << 20
+
+// Tailer reads the lines of an audit log as they're written, similar to
+// "tail -F", following the file when it's truncated or rotated.
+type Tailer struct {
+	path    string
+	file    *os.File
+	reader  *bufio.Reader
+	offset  int64
+	partial []byte // Incomplete last line, until it's completed.
+}
+
+// Open opens the audit log at path, to be read from the start.
+func Open(path string) (*Tailer, error) {
+	t := &Tailer{path: path}
+	if err := t.open(); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+func (t *Tailer) open() error {
+	f, err := os.Open(t.path)
+	if err != nil {
+		return err
+	}
+	if t.file != nil {
+		_ = t.file.Close()
+	}
+
+	t.file = f
+	t.reader = bufio.NewReaderSize(f, 64<<10)
+	t.offset = 0
+	t.partial = nil
+	return nil
+}
+
+// Path returns the path of the audit log.
+func (t *Tailer) Path() string {
+	return t.path
+}
+
+// Offset returns the number of bytes read from the current file.
+func (t *Tailer) Offset() int64 {
+	return t.offset
+}
+
+// Read reads up to maxLines complete lines. Returns [io.EOF] alongside the lines
+// read, once all lines written so far have been read, after which Read can be
+// called again to read lines written since.
+func (t *Tailer) Read(maxLines int) (lines [][]byte, err error) {
+	if err = t.follow(); err != nil {
+		return nil, err
+	}
+
+	for len(lines) < maxLines {
+		chunk, rerr := t.reader.ReadSlice('\n')
+		t.offset += int64(len(chunk))
+
+		switch {
+		case rerr == nil:
+			line := append(t.partial, chunk...) //nolint:gocritic
+			t.partial = nil
+			lines = append(lines, line[:len(line)-1])
+		case errors.Is(rerr, bufio.ErrBufferFull):
+			if len(t.partial) < maxLineSize {
+				t.partial = append(t.partial, chunk...)
+			}
+		case errors.Is(rerr, io.EOF):
+			t.partial = append(t.partial, chunk...)
+			return lines, io.EOF
+		default:
+			return lines, rerr
+		}
+	}
+	return lines, nil
+}
+
+// follow reopens the audit log if it was rotated (i.e. the path now refers to a
+// different file), or truncated.
+func (t *Tailer) follow() error {
+	current, err := t.file.Stat()
+	if err != nil {
+		return err
+	}
+
+	latest, err := os.Stat(t.path)
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+		// Rotated, but the new file wasn't created yet.
+		return nil
+	case err != nil:
+		return err
+	case !os.SameFile(current, latest):
+		// Finish reading the rotated file first.
+		if t.offset < current.Size() {
+			return nil
+		}
+		return t.open()
+	case latest.Size() < t.offset:
+		if _, err = t.file.Seek(0, io.SeekStart); err != nil {
+			return err
+		}
+		t.reader.Reset(t.file)
+		t.offset = 0
+		t.partial = nil
+	}
+	return nil
+}
+
+// Close closes the audit log.
+func (t *Tailer) Close() error {
+	return t.file.Close()
+}
@@ -0,0 +1,178 @@
+// Copyright (c) Liam Stanley <liam@liam.sh>. All rights reserved. Use of
+// this source code is governed by the MIT license that can be found in
+// the LICENSE file.
+
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// BookmarksFile is the name of the file, within [GetConfigPath], which stores
+// bookmarks for each cluster profile.
+const BookmarksFile = "bookmarks.json"
+
+// Bookmark is a bookmarked secret path.
+type Bookmark struct {
+	// Name is an optional user-provided name for the bookmark.
+	Name string `json:"name,omitempty"`
+
+	// Path is the full path, including the mount (e.g. "secret/foo/bar"). Paths
+	// ending in "/" are folders.
+	Path string `json:"path"`
+
+	// Version is the KVv2 version of the secret, or 0 for the latest version.
+	Version int `json:"version,omitempty"`
+
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Target returns the path of the bookmark, including the version if set, in the
+// same format accepted by :goto (e.g. "secret/foo/bar@3").
+func (b Bookmark) Target() string {
+	if b.Version > 0 {
+		return b.Path + "@" + strconv.Itoa(b.Version)
+	}
+	return b.Path
+}
+
+// Title returns the name of the bookmark, falling back to the target.
+func (b Bookmark) Title() string {
+	if b.Name != "" {
+		return b.Name
+	}
+	return b.Target()
+}
+
+var bookmarksMu sync.Mutex
+
+// BookmarksPath returns the path to the bookmarks file.
+func BookmarksPath() string {
+	return filepath.Join(GetConfigPath(), BookmarksFile)
+}
+
+func readBookmarks() (map[string][]Bookmark, error) {
+	all := map[string][]Bookmark{}
+
+	data, err := os.ReadFile(BookmarksPath())
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return all, nil
+		}
+		return nil, fmt.Errorf("read bookmarks: %w", err)
+	}
+
+	if err = json.Unmarshal(data, &all); err != nil {
+		return nil, fmt.Errorf("parse bookmarks: %w", err)
+	}
+	return all, nil
+}
+
+// GetBookmarks returns the bookmarks of the provided cluster profile, in the order
+// they were added.
+func GetBookmarks(profile string) ([]Bookmark, error) {
+	bookmarksMu.Lock()
+	defer bookmarksMu.Unlock()
+
+	all, err := readBookmarks()
+	if err != nil {
+		return nil, err
+	}
+	return all[profile], nil
+}
+
+// HasBookmark returns true if the provided target (see [Bookmark.Target]) is
+// bookmarked for the provided cluster profile.
+func HasBookmark(profile, target string) bool {
+	bookmarks, err := GetBookmarks(profile)
+	if err != nil {
+		return false
+	}
+	return slices.ContainsFunc(bookmarks, func(b Bookmark) bool {
+		return b.Target() == target
+	})
+}
+
+// UpdateBookmarks updates the bookmarks of the provided cluster profile using
+// the provided function, and persists them.
+func UpdateBookmarks(profile string, fn func(bookmarks []Bookmark) []Bookmark) error {
+	bookmarksMu.Lock()
+	defer bookmarksMu.Unlock()
+
+	all, err := readBookmarks()
+	if err != nil {
+		return err
+	}
+
+	all[profile] = fn(all[profile])
+	if len(all[profile]) == 0 {
+		delete(all, profile)
+	}
+
+	data, err := json.MarshalIndent(all, "", "    ")
+	if err != nil {
+		return fmt.Errorf("marshal bookmarks: %w", err)
+	}
+
+	if err = writeFileAtomic(BookmarksPath(), data); err != nil {
+		return fmt.Errorf("write bookmarks: %w", err)
+	}
+	return nil
+}
+
+// AddBookmark adds a bookmark for the provided cluster profile, replacing any
+// existing bookmark with the same target (in place).
+func AddBookmark(profile string, bookmark Bookmark) error {
+	if bookmark.CreatedAt.IsZero() {
+		bookmark.CreatedAt = time.Now()
+	}
+	return UpdateBookmarks(profile, func(bookmarks []Bookmark) []Bookmark {
+		i := slices.IndexFunc(bookmarks, func(b Bookmark) bool {
+			return b.Target() == bookmark.Target()
+		})
+		if i != -1 {
+			bookmarks[i] = bookmark
+			return bookmarks
+		}
+		return append(bookmarks, bookmark)
+	})
+}
+
+// RemoveBookmark removes the bookmark with the provided target from the provided
+// cluster profile.
+func RemoveBookmark(profile, target string) error {
+	return UpdateBookmarks(profile, func(bookmarks []Bookmark) []Bookmark {
+		return slices.DeleteFunc(bookmarks, func(b Bookmark) bool {
+			return b.Target() == target
+		})
+	})
+}
+
+// ToggleBookmark adds the bookmark for the provided cluster profile, or removes
+// it if its target is already bookmarked. Returns true if it was added.
+func ToggleBookmark(profile string, bookmark Bookmark) (added bool, err error) {
+	if bookmark.CreatedAt.IsZero() {
+		bookmark.CreatedAt = time.Now()
+	}
+	err = UpdateBookmarks(profile, func(bookmarks []Bookmark) []Bookmark {
+		n := len(bookmarks)
+		bookmarks = slices.DeleteFunc(bookmarks, func(b Bookmark) bool {
+			return b.Target() == bookmark.Target()
+		})
+		if len(bookmarks) == n {
+			added = true
+			bookmarks = append(bookmarks, bookmark)
+		}
+		return bookmarks
+	})
+	return added, err
+}
@@ -0,0 +1,54 @@
+// Copyright (c) Liam Stanley <liam@liam.sh>. All rights reserved. Use of
+// this source code is governed by the MIT license that can be found in
+// the LICENSE file.
+
+package config
+
+import (
+	"testing"
+)
+
+func TestBookmarks(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	InitConfigPath()
+
+	const profile = "https://vault.example.com"
+
+	added, err := ToggleBookmark(profile, Bookmark{Path: "secret/foo/bar", Version: 2})
+	if err != nil || !added {
+		t.Fatalf("expected bookmark to be added, got added=%v err=%v", added, err)
+	}
+
+	if err = AddBookmark(profile, Bookmark{Path: "secret/baz/"}); err != nil {
+		t.Fatalf("unexpected error adding bookmark: %v", err)
+	}
+
+	// Replacing an existing target should keep its position.
+	if err = AddBookmark(profile, Bookmark{Name: "bar", Path: "secret/foo/bar", Version: 2}); err != nil {
+		t.Fatalf("unexpected error adding bookmark: %v", err)
+	}
+
+	bookmarks, err := GetBookmarks(profile)
+	if err != nil {
+		t.Fatalf("unexpected error getting bookmarks: %v", err)
+	}
+	if len(bookmarks) != 2 || bookmarks[0].Target() != "secret/foo/bar@2" || bookmarks[0].Title() != "bar" {
+		t.Fatalf("unexpected bookmarks: %+v", bookmarks)
+	}
+
+	if other, _ := GetBookmarks("https://other.example.com"); len(other) != 0 {
+		t.Fatalf("expected bookmarks to be scoped by profile, got %+v", other)
+	}
+
+	added, err = ToggleBookmark(profile, Bookmark{Path: "secret/foo/bar", Version: 2})
+	if err != nil || added {
+		t.Fatalf("expected bookmark to be removed, got added=%v err=%v", added, err)
+	}
+
+	if err = RemoveBookmark(profile, "secret/baz/"); err != nil {
+		t.Fatalf("unexpected error removing bookmark: %v", err)
+	}
+	if HasBookmark(profile, "secret/baz/") {
+		t.Fatal("expected bookmark to be removed")
+	}
+}
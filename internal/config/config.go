@@ -8,6 +8,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 )
 
 const (
@@ -45,3 +46,16 @@ func GetConfigPath() string {
 	os.Exit(1)
 	return ""
 }
+
+// ExpandPath expands a leading "~" to the users home directory.
+func ExpandPath(path string) (string, error) {
+	if path != "~" && !strings.HasPrefix(path, "~/") {
+		return path, nil
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("expand path: %w", err)
+	}
+	return filepath.Join(home, path[1:]), nil
+}
@@ -31,9 +31,14 @@ var defaultEditors = map[string][]string{
 	},
 }
 
-// ResolveEditor resolves the editor to use for the given platform, using $EDITOR
-// when available, and using a fallback list of editors when $EDITOR is not defined.
+// ResolveEditor resolves the editor to use for the given platform, using the
+// configured editor or $EDITOR when available, and using a fallback list of
+// editors when neither are defined.
 func ResolveEditor() (path string, err error) {
+	if editor := Get().Editor.Command; editor != "" {
+		return exec.LookPath(editor)
+	}
+
 	editor := os.Getenv("EDITOR")
 	if editor != "" {
 		return exec.LookPath(editor)
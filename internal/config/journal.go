@@ -0,0 +1,191 @@
+// Copyright (c) Liam Stanley <liam@liam.sh>. All rights reserved. Use of
+// this source code is governed by the MIT license that can be found in
+// the LICENSE file.
+
+package config
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// JournalFile is the name of the file, within [GetConfigPath], which stores the
+// action journal. It is append-only, with one JSON encoded [JournalEntry] per line.
+const JournalFile = "journal.jsonl"
+
+// Journal export formats, see [ExportJournal].
+const (
+	JournalFormatJSONL = "jsonl"
+	JournalFormatCSV   = "csv"
+)
+
+// JournalFormats are the supported formats for [ExportJournal].
+var JournalFormats = []string{JournalFormatJSONL, JournalFormatCSV}
+
+// JournalEntry is a single mutating action performed against a Vault server.
+// Secret values are never recorded.
+type JournalEntry struct {
+	Time time.Time `json:"time"`
+
+	// User is the local (OS) user which performed the action.
+	User string `json:"user"`
+
+	// Identity is the display name of the Vault token used, if known.
+	Identity string `json:"identity,omitempty"`
+
+	// Cluster is the address of the Vault server, and Namespace the namespace (if
+	// any) the action was performed in.
+	Cluster   string `json:"cluster"`
+	Namespace string `json:"namespace,omitempty"`
+
+	// Operation is the kind of action performed, e.g. "delete".
+	Operation string `json:"operation"`
+
+	// Path is the full path (including the mount) the action was performed on,
+	// or the affected resource for actions which aren't path based (e.g. the
+	// server ID of a removed raft peer).
+	Path string `json:"path"`
+
+	// Versions are the KVv2 versions affected, if applicable.
+	Versions []int `json:"versions,omitempty"`
+
+	// Error is the error returned by the Vault server, if the action failed.
+	Error string `json:"error,omitempty"`
+}
+
+// Succeeded returns true if the action succeeded.
+func (e JournalEntry) Succeeded() bool {
+	return e.Error == ""
+}
+
+// Profile returns the cluster profile (see [Settings.Profiles]) the action was
+// performed against.
+func (e JournalEntry) Profile() string {
+	if e.Namespace != "" {
+		return e.Cluster + "#" + e.Namespace
+	}
+	return e.Cluster
+}
+
+var journalMu sync.Mutex
+
+// JournalPath returns the path to the journal file.
+func JournalPath() string {
+	return filepath.Join(GetConfigPath(), JournalFile)
+}
+
+// AppendJournal appends the provided entry to the journal.
+func AppendJournal(entry JournalEntry) error {
+	journalMu.Lock()
+	defer journalMu.Unlock()
+
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("encode journal entry: %w", err)
+	}
+
+	path := JournalPath()
+	if err = os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return fmt.Errorf("append journal: %w", err)
+	}
+
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o600)
+	if err != nil {
+		return fmt.Errorf("append journal: %w", err)
+	}
+
+	_, err = f.Write(append(data, '\n'))
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return fmt.Errorf("append journal: %w", err)
+	}
+	return nil
+}
+
+// ReadJournal returns all entries in the journal, oldest first. Lines which can't
+// be parsed (e.g. a partially written line) are skipped.
+func ReadJournal() ([]JournalEntry, error) {
+	journalMu.Lock()
+	defer journalMu.Unlock()
+
+	f, err := os.Open(JournalPath())
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("read journal: %w", err)
+	}
+	defer f.Close() //nolint:errcheck
+
+	var entries []JournalEntry
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var entry JournalEntry
+		if json.Unmarshal(scanner.Bytes(), &entry) != nil {
+			continue
+		}
+		entries = append(entries, entry)
+	}
+
+	if err = scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read journal: %w", err)
+	}
+	return entries, nil
+}
+
+// ExportJournal writes the provided entries to w, in the provided format (see
+// [JournalFormats]).
+func ExportJournal(w io.Writer, entries []JournalEntry, format string) error {
+	switch format {
+	case JournalFormatJSONL:
+		enc := json.NewEncoder(w)
+		for _, entry := range entries {
+			if err := enc.Encode(entry); err != nil {
+				return fmt.Errorf("export journal: %w", err)
+			}
+		}
+		return nil
+	case JournalFormatCSV:
+		cw := csv.NewWriter(w)
+		_ = cw.Write([]string{"time", "user", "identity", "cluster", "namespace", "operation", "path", "versions", "error"})
+		for _, entry := range entries {
+			versions := make([]string, len(entry.Versions))
+			for i, v := range entry.Versions {
+				versions[i] = strconv.Itoa(v)
+			}
+			_ = cw.Write([]string{
+				entry.Time.Format(time.RFC3339),
+				entry.User,
+				entry.Identity,
+				entry.Cluster,
+				entry.Namespace,
+				entry.Operation,
+				entry.Path,
+				strings.Join(versions, " "),
+				entry.Error,
+			})
+		}
+		cw.Flush()
+		if err := cw.Error(); err != nil {
+			return fmt.Errorf("export journal: %w", err)
+		}
+		return nil
+	default:
+		return fmt.Errorf("export journal: unsupported format %q", format)
+	}
+}
@@ -0,0 +1,71 @@
+// Copyright (c) Liam Stanley <liam@liam.sh>. All rights reserved. Use of
+// this source code is governed by the MIT license that can be found in
+// the LICENSE file.
+
+package config
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestJournal(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	InitConfigPath()
+
+	if entries, err := ReadJournal(); err != nil || len(entries) != 0 {
+		t.Fatalf("expected empty journal, got %+v (%v)", entries, err)
+	}
+
+	now := time.Now().UTC().Truncate(time.Second)
+	want := []JournalEntry{
+		{Time: now, User: "alice", Cluster: "https://vault:8200", Operation: "write", Path: "secret/foo"},
+		{Time: now, User: "alice", Cluster: "https://vault:8200", Namespace: "team", Operation: "destroy", Path: "secret/foo", Versions: []int{1, 2}, Error: "permission denied"},
+	}
+
+	for _, e := range want {
+		if err := AppendJournal(e); err != nil {
+			t.Fatalf("unexpected error appending to journal: %v", err)
+		}
+	}
+
+	// Partially written lines should be skipped, rather than failing the read.
+	f, err := os.OpenFile(JournalPath(), os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _ = f.WriteString(`{"time":"2`)
+	_ = f.Close()
+
+	got, err := ReadJournal()
+	if err != nil {
+		t.Fatalf("unexpected error reading journal: %v", err)
+	}
+	if len(got) != len(want) {
+		t.Fatalf("expected %d entries, got %d: %+v", len(want), len(got), got)
+	}
+	if got[1].Profile() != "https://vault:8200#team" || got[1].Succeeded() || len(got[1].Versions) != 2 {
+		t.Fatalf("unexpected entry: %+v", got[1])
+	}
+
+	var buf bytes.Buffer
+	if err = ExportJournal(&buf, got, JournalFormatCSV); err != nil {
+		t.Fatalf("unexpected error exporting journal: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 3 || !strings.HasSuffix(lines[2], ",destroy,secret/foo,1 2,permission denied") {
+		t.Fatalf("unexpected csv export: %q", buf.String())
+	}
+
+	buf.Reset()
+	if err = ExportJournal(&buf, got, JournalFormatJSONL); err != nil || strings.Count(buf.String(), "\n") != 2 {
+		t.Fatalf("unexpected jsonl export: %q (%v)", buf.String(), err)
+	}
+
+	if err = ExportJournal(&buf, got, "xml"); err == nil {
+		t.Fatal("expected error for unsupported format")
+	}
+}
@@ -0,0 +1,137 @@
+// Copyright (c) Liam Stanley <liam@liam.sh>. All rights reserved. Use of
+// this source code is governed by the MIT license that can be found in
+// the LICENSE file.
+
+package config
+
+import (
+	"math"
+	"slices"
+	"time"
+)
+
+// MaxRecentPaths is the maximum number of recent paths tracked for each cluster
+// profile. The lowest ranked paths are evicted first.
+const MaxRecentPaths = 50
+
+// recentHalfLife is how long it takes for the weight of a visit to halve, when
+// ranking recent paths.
+const recentHalfLife = 7 * 24 * time.Hour
+
+// PageLocation describes where a page is (never what data it contains), so it
+// can be reconstructed in a later session.
+type PageLocation struct {
+	// Type is the type of page, e.g. "secretwalker", or the command of the page.
+	Type string `json:"type"`
+
+	// Mount is the path of the mount, if the page is tied to a mount.
+	Mount string `json:"mount,omitempty"`
+
+	// Path is the path relative to the mount, if applicable.
+	Path string `json:"path,omitempty"`
+
+	// Version is the KVv2 version, if applicable.
+	Version int `json:"version,omitempty"`
+}
+
+// Session is the page stack of a session, where the first page is the root page.
+type Session struct {
+	Pages   []PageLocation `json:"pages"`
+	SavedAt time.Time      `json:"saved_at"`
+}
+
+// RecentPath is a secret path visited by the user.
+type RecentPath struct {
+	Path        string    `json:"path"`
+	Visits      int       `json:"visits"`
+	LastVisited time.Time `json:"last_visited"`
+}
+
+// score ranks the recent path by frequency, with visits decaying over time.
+func (r RecentPath) score(now time.Time) float64 {
+	age := max(now.Sub(r.LastVisited), 0)
+	return float64(r.Visits) * math.Pow(0.5, float64(age)/float64(recentHalfLife))
+}
+
+// GetSession returns the last session of the provided cluster profile, if any.
+func GetSession(profile string) (session Session, ok bool) {
+	session, ok = GetState().Sessions[profile]
+	return session, ok && len(session.Pages) > 0
+}
+
+// SaveSession persists the page stack for the provided cluster profile. An empty
+// page stack removes the session.
+func SaveSession(profile string, pages []PageLocation) error {
+	return UpdateState(func(s *State) {
+		if len(pages) == 0 {
+			delete(s.Sessions, profile)
+			return
+		}
+
+		if s.Sessions == nil {
+			s.Sessions = make(map[string]Session)
+		}
+		s.Sessions[profile] = Session{Pages: pages, SavedAt: time.Now()}
+	})
+}
+
+// RecordVisit records a visit to the provided full secret path (including the
+// mount), for the provided cluster profile.
+func RecordVisit(profile, path string) error {
+	return UpdateState(func(s *State) {
+		if s.Recent == nil {
+			s.Recent = make(map[string][]RecentPath)
+		}
+		s.Recent[profile] = recordVisit(s.Recent[profile], path, time.Now())
+	})
+}
+
+func recordVisit(recent []RecentPath, path string, now time.Time) []RecentPath {
+	i := slices.IndexFunc(recent, func(r RecentPath) bool {
+		return r.Path == path
+	})
+	if i == -1 {
+		recent = append(recent, RecentPath{Path: path})
+		i = len(recent) - 1
+	}
+	recent[i].Visits++
+	recent[i].LastVisited = now
+
+	// Evict the lowest ranked paths, but never the path being recorded, as new
+	// paths would otherwise never make it into a full list.
+	recent = rankRecent(recent, now)
+	for len(recent) > MaxRecentPaths {
+		i := len(recent) - 1
+		if recent[i].Path == path {
+			i--
+		}
+		recent = slices.Delete(recent, i, i+1)
+	}
+	return recent
+}
+
+// rankRecent sorts the recent paths by score (highest first), falling back to the
+// most recently visited.
+func rankRecent(recent []RecentPath, now time.Time) []RecentPath {
+	slices.SortStableFunc(recent, func(a, b RecentPath) int {
+		if sa, sb := a.score(now), b.score(now); sa != sb {
+			if sa > sb {
+				return -1
+			}
+			return 1
+		}
+		return b.LastVisited.Compare(a.LastVisited)
+	})
+	return recent
+}
+
+// RecentPaths returns up to limit (or all, if limit <= 0) recent paths of the
+// provided cluster profile, ranked by how frequently and recently they were
+// visited.
+func RecentPaths(profile string, limit int) []RecentPath {
+	recent := rankRecent(GetState().Recent[profile], time.Now())
+	if limit > 0 && len(recent) > limit {
+		recent = recent[:limit]
+	}
+	return recent
+}
@@ -0,0 +1,99 @@
+// Copyright (c) Liam Stanley <liam@liam.sh>. All rights reserved. Use of
+// this source code is governed by the MIT license that can be found in
+// the LICENSE file.
+
+package config
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestRecordVisit(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+
+	var recent []RecentPath
+	recent = recordVisit(recent, "secret/old", now.Add(-30*24*time.Hour))
+	recent = recordVisit(recent, "secret/old", now.Add(-30*24*time.Hour))
+	recent = recordVisit(recent, "secret/old", now.Add(-30*24*time.Hour))
+	recent = recordVisit(recent, "secret/frequent", now.Add(-time.Hour))
+	recent = recordVisit(recent, "secret/frequent", now.Add(-time.Hour))
+	recent = recordVisit(recent, "secret/latest", now)
+
+	got := make([]string, 0, len(recent))
+	for _, r := range rankRecent(recent, now) {
+		got = append(got, r.Path)
+	}
+
+	// Old visits decay, so fewer but more recent visits should rank higher.
+	want := []string{"secret/frequent", "secret/latest", "secret/old"}
+	if !slices.Equal(got, want) {
+		t.Fatalf("expected ranking %v, got %v", want, got)
+	}
+
+	for i := range MaxRecentPaths + 5 {
+		recent = recordVisit(recent, fmt.Sprintf("secret/%d", i), now)
+	}
+	if len(recent) != MaxRecentPaths {
+		t.Fatalf("expected recent paths to be capped at %d, got %d", MaxRecentPaths, len(recent))
+	}
+
+	// A full list of frequently visited paths must not immediately evict a newly
+	// visited path.
+	for i := range MaxRecentPaths {
+		for range 3 {
+			recent = recordVisit(recent, fmt.Sprintf("secret/frequent-%d", i), now)
+		}
+	}
+	recent = recordVisit(recent, "secret/new", now.Add(-time.Minute))
+	if !slices.ContainsFunc(recent, func(r RecentPath) bool { return r.Path == "secret/new" }) {
+		t.Fatal("expected newly visited path to be kept")
+	}
+	if len(recent) != MaxRecentPaths {
+		t.Fatalf("expected recent paths to be capped at %d, got %d", MaxRecentPaths, len(recent))
+	}
+}
+
+func TestSessionRoundTrip(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	InitConfigPath()
+
+	if err := LoadState(); err != nil {
+		t.Fatalf("unexpected error loading missing state: %v", err)
+	}
+
+	const profile = "https://vault.example.com"
+
+	pages := []PageLocation{
+		{Type: "mounts"},
+		{Type: "secretwalker", Mount: "secret/", Path: "app/"},
+		{Type: "kvviewsecret", Mount: "secret/", Path: "app/db", Version: 7},
+	}
+	if err := SaveSession(profile, pages); err != nil {
+		t.Fatalf("unexpected error saving session: %v", err)
+	}
+
+	if err := LoadState(); err != nil {
+		t.Fatalf("unexpected error loading state: %v", err)
+	}
+
+	session, ok := GetSession(profile)
+	if !ok || !slices.Equal(session.Pages, pages) {
+		t.Fatalf("expected session %v, got %v (ok=%v)", pages, session.Pages, ok)
+	}
+
+	if _, ok = GetSession("https://other.example.com"); ok {
+		t.Fatal("expected sessions to be scoped by profile")
+	}
+
+	if err := SaveSession(profile, nil); err != nil {
+		t.Fatalf("unexpected error clearing session: %v", err)
+	}
+	if _, ok = GetSession(profile); ok {
+		t.Fatal("expected session to be cleared")
+	}
+}
@@ -0,0 +1,269 @@
+// Copyright (c) Liam Stanley <liam@liam.sh>. All rights reserved. Use of
+// this source code is governed by the MIT license that can be found in
+// the LICENSE file.
+
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/goccy/go-yaml"
+)
+
+// SettingsVersion is the current version of the settings file format. It should
+// be incremented (with a migration in [migrateSettings]) whenever a breaking
+// change is made to [Settings].
+const SettingsVersion = 1
+
+// SettingsFile is the name of the settings file, within [GetConfigPath].
+const SettingsFile = "config.yaml"
+
+// Settings are the user configurable settings, loaded from [SettingsFile].
+type Settings struct {
+	// Version is the version of the settings file format.
+	Version int `yaml:"version"`
+
+	// DefaultPage is the command of the page which is opened on startup (e.g.
+	// "mounts").
+	DefaultPage string `yaml:"default_page"`
+
+	Refresh   RefreshSettings   `yaml:"refresh"`
+	Masking   MaskingSettings   `yaml:"masking"`
+	Editor    EditorSettings    `yaml:"editor"`
+	Clipboard ClipboardSettings `yaml:"clipboard"`
+	Theme     ThemeSettings     `yaml:"theme"`
+	Mouse     MouseSettings     `yaml:"mouse"`
+
+	// ReadOnly refuses all operations which modify the Vault server, for all
+	// profiles. See [ProfileSettings.ReadOnly] to only protect specific profiles.
+	ReadOnly bool `yaml:"read_only"`
+
+	// Profiles overrides settings for specific clusters, keyed by the Vault address
+	// (e.g. "https://vault.example.com:8200"), optionally suffixed with
+	// "#<namespace>" to only apply to a specific namespace.
+	Profiles map[string]ProfileSettings `yaml:"profiles,omitempty"`
+
+	// KeyBindings remaps key bindings, by name (e.g. "copy"), to one or more keys.
+	KeyBindings map[string][]string `yaml:"keybindings,omitempty"`
+}
+
+type RefreshSettings struct {
+	// Disabled disables automatic refreshing of all pages.
+	Disabled bool `yaml:"disabled"`
+
+	// Intervals overrides the refresh interval of pages, by page command (e.g.
+	// "mounts"). An interval of 0 disables refreshing for that page.
+	Intervals map[string]time.Duration `yaml:"intervals,omitempty"`
+}
+
+type MaskingSettings struct {
+	// Enabled is whether secret values are masked by default.
+	Enabled bool `yaml:"enabled"`
+}
+
+type EditorSettings struct {
+	// Command is the editor to use, overriding $EDITOR.
+	Command string `yaml:"command,omitempty"`
+
+	// AllowInsecureTempDir allows falling back to the default (likely disk-backed)
+	// temp directory for files containing secrets, when no memory-backed location
+	// is available. See [SecureTempDir].
+	AllowInsecureTempDir bool `yaml:"allow_insecure_temp_dir"`
+}
+
+type ClipboardSettings struct {
+	// ClearAfter is the duration after which secrets copied to the clipboard are
+	// cleared. 0 disables clearing. If the native clipboard can't be read (e.g.
+	// over SSH, where only OSC 52 is available), the clipboard is cleared without
+	// verifying it still contains the secret, and terminals which don't support
+	// OSC 52 (or ignore empty writes) may not clear it at all.
+	ClearAfter time.Duration `yaml:"clear_after"`
+}
+
+type ThemeSettings struct {
+	// Tint is the ID of the tint (color scheme) to use, until a different tint is
+	// selected through the theme picker (which is persisted in [StateFile]).
+	Tint string `yaml:"tint,omitempty"`
+}
+
+type MouseSettings struct {
+	// Enabled is whether mouse support (e.g. clicking breadcrumbs) is enabled.
+	// Disabling it allows the terminal to handle text selection natively.
+	Enabled bool `yaml:"enabled"`
+}
+
+type ProfileSettings struct {
+	// ReadOnly refuses all operations which modify the Vault server.
+	ReadOnly bool `yaml:"read_only"`
+
+	// TypedConfirmation requires typing the name of the affected resource (e.g.
+	// the secret path) to confirm destructive actions, rather than a single
+	// keypress.
+	TypedConfirmation bool `yaml:"typed_confirmation"`
+
+	// Nodes are the API addresses of the individual nodes of the cluster (e.g.
+	// "https://vault-1.example.com:8200"), which are polled by the cluster page,
+	// in addition to any nodes discovered from the raft configuration.
+	Nodes []string `yaml:"nodes,omitempty"`
+}
+
+// DefaultSettings returns the settings used when no settings file exists, and
+// which are used as the base for any settings that are loaded.
+func DefaultSettings() *Settings {
+	return &Settings{
+		Version:     SettingsVersion,
+		DefaultPage: "mounts",
+		Masking: MaskingSettings{
+			Enabled: true,
+		},
+		Clipboard: ClipboardSettings{
+			ClearAfter: 30 * time.Second,
+		},
+		Mouse: MouseSettings{
+			Enabled: true,
+		},
+	}
+}
+
+// Validate validates the settings which can be validated without knowledge of
+// the UI (e.g. tints, pages and key bindings are validated where they are used).
+func (s *Settings) Validate() error {
+	var errs []error
+
+	if s.Clipboard.ClearAfter < 0 {
+		errs = append(errs, errors.New("clipboard.clear_after: must not be negative"))
+	}
+
+	for page, interval := range s.Refresh.Intervals {
+		if interval < 0 {
+			errs = append(errs, fmt.Errorf("refresh.intervals.%s: must not be negative", page))
+		}
+		if interval > 0 && interval < time.Second {
+			errs = append(errs, fmt.Errorf("refresh.intervals.%s: must be at least 1s", page))
+		}
+	}
+
+	for name, keys := range s.KeyBindings {
+		if len(keys) == 0 {
+			errs = append(errs, fmt.Errorf("keybindings.%s: at least one key is required", name))
+		}
+	}
+
+	for profile, p := range s.Profiles {
+		for i, node := range p.Nodes {
+			u, err := url.Parse(node)
+			if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+				errs = append(errs, fmt.Errorf("profiles.%s.nodes[%d]: must be a http(s) address", profile, i))
+			}
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// Profile returns the settings for the provided profile (see [Settings.Profiles]),
+// falling back to the settings of the address without the namespace, if the
+// namespace has no settings of its own.
+func (s *Settings) Profile(profile string) ProfileSettings {
+	p, ok := s.Profiles[profile]
+	if !ok {
+		address, _, _ := strings.Cut(profile, "#")
+		p = s.Profiles[address]
+	}
+	p.ReadOnly = p.ReadOnly || s.ReadOnly
+	return p
+}
+
+// migrateSettings migrates settings from older versions of the settings file
+// format to [SettingsVersion].
+func migrateSettings(s *Settings) error {
+	switch {
+	case s.Version == 0: // Version wasn't specified, assume current.
+		s.Version = SettingsVersion
+	case s.Version > SettingsVersion:
+		return fmt.Errorf("version: unsupported version %d (latest supported is %d)", s.Version, SettingsVersion)
+	}
+	return nil
+}
+
+var settings atomic.Pointer[Settings]
+
+func init() { //nolint:gochecknoinits
+	settings.Store(DefaultSettings())
+}
+
+// Get returns the currently active settings. Callers must not modify the
+// returned settings.
+func Get() *Settings {
+	return settings.Load()
+}
+
+// Set replaces the currently active settings.
+func Set(s *Settings) {
+	settings.Store(s)
+}
+
+// SettingsPath returns the path to the settings file.
+func SettingsPath() string {
+	return filepath.Join(GetConfigPath(), SettingsFile)
+}
+
+// ErrInvalidSettings is returned by [Load] when the settings file couldn't be
+// used, in which case the defaults are used in read-only mode.
+var ErrInvalidSettings = errors.New("invalid settings, using defaults in read-only mode")
+
+// Load loads the settings file (if it exists) on top of [DefaultSettings], and
+// makes them active. If the settings file is invalid, the defaults are used in
+// read-only mode (as protections like [Settings.ReadOnly] or
+// [ProfileSettings.TypedConfirmation] may otherwise be lost), and an error
+// wrapping [ErrInvalidSettings] is returned.
+func Load() error {
+	s, err := LoadFile(SettingsPath())
+	if err != nil {
+		s = DefaultSettings()
+		s.ReadOnly = true
+		Set(s)
+		return fmt.Errorf("%w: %w", ErrInvalidSettings, err)
+	}
+	Set(s)
+	return nil
+}
+
+// LoadFile loads and validates settings from the provided path, on top of
+// [DefaultSettings]. A missing file is not an error.
+func LoadFile(path string) (*Settings, error) {
+	s := DefaultSettings()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return s, nil
+		}
+		return nil, fmt.Errorf("read settings: %w", err)
+	}
+
+	s.Version = 0
+
+	err = yaml.UnmarshalWithOptions(data, s, yaml.DisallowUnknownField())
+	if err != nil {
+		return nil, fmt.Errorf("parse %s: %s", path, yaml.FormatError(err, false, true))
+	}
+
+	if err = migrateSettings(s); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+
+	if err = s.Validate(); err != nil {
+		return nil, fmt.Errorf("validate %s:\n%w", path, err)
+	}
+
+	return s, nil
+}
@@ -0,0 +1,130 @@
+// Copyright (c) Liam Stanley <liam@liam.sh>. All rights reserved. Use of
+// this source code is governed by the MIT license that can be found in
+// the LICENSE file.
+
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestSettingsValidate(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		modify  func(s *Settings)
+		wantErr bool
+	}{
+		{name: "defaults", modify: func(_ *Settings) {}},
+		{name: "negative-clipboard", modify: func(s *Settings) { s.Clipboard.ClearAfter = -time.Second }, wantErr: true},
+		{name: "disabled-interval", modify: func(s *Settings) { s.Refresh.Intervals = map[string]time.Duration{"mounts": 0} }},
+		{name: "short-interval", modify: func(s *Settings) { s.Refresh.Intervals = map[string]time.Duration{"mounts": time.Millisecond} }, wantErr: true},
+		{name: "empty-keybinding", modify: func(s *Settings) { s.KeyBindings = map[string][]string{"copy": {}} }, wantErr: true},
+		{name: "nodes", modify: func(s *Settings) {
+			s.Profiles = map[string]ProfileSettings{"https://prod:8200": {Nodes: []string{"https://prod-1:8200"}}}
+		}},
+		{name: "invalid-node", modify: func(s *Settings) {
+			s.Profiles = map[string]ProfileSettings{"https://prod:8200": {Nodes: []string{"prod-1:8200"}}}
+		}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			s := DefaultSettings()
+			tt.modify(s)
+
+			if err := s.Validate(); (err != nil) != tt.wantErr {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestSettingsProfile(t *testing.T) {
+	t.Parallel()
+
+	s := DefaultSettings()
+	s.Profiles = map[string]ProfileSettings{
+		"https://prod:8200":      {ReadOnly: true, TypedConfirmation: true},
+		"https://prod:8200#team": {TypedConfirmation: true},
+	}
+
+	tests := []struct {
+		profile string
+		global  bool
+		want    ProfileSettings
+	}{
+		{profile: "https://prod:8200", want: ProfileSettings{ReadOnly: true, TypedConfirmation: true}},
+		{profile: "https://prod:8200#other", want: ProfileSettings{ReadOnly: true, TypedConfirmation: true}},
+		{profile: "https://prod:8200#team", want: ProfileSettings{TypedConfirmation: true}},
+		{profile: "https://dev:8200", want: ProfileSettings{}},
+		{profile: "https://dev:8200", global: true, want: ProfileSettings{ReadOnly: true}},
+	}
+
+	for _, tt := range tests {
+		s := *s
+		s.ReadOnly = tt.global
+		if got := s.Profile(tt.profile); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Profile(%q) (global read-only: %v) = %+v, want %+v", tt.profile, tt.global, got, tt.want)
+		}
+	}
+}
+
+func TestMigrateSettings(t *testing.T) {
+	t.Parallel()
+
+	s := &Settings{}
+	if err := migrateSettings(s); err != nil || s.Version != SettingsVersion {
+		t.Fatalf("expected unversioned settings to be migrated, got version %d (%v)", s.Version, err)
+	}
+
+	s = &Settings{Version: SettingsVersion + 1}
+	if err := migrateSettings(s); err == nil {
+		t.Fatal("expected error for unsupported version")
+	}
+}
+
+func TestLoadFileMissing(t *testing.T) {
+	t.Parallel()
+
+	s, err := LoadFile(filepath.Join(t.TempDir(), SettingsFile))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(s, DefaultSettings()) {
+		t.Fatalf("expected defaults, got %#v", s)
+	}
+}
+
+func TestLoadInvalid(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", dir)
+	t.Setenv("HOME", dir)
+
+	prev := Get()
+	t.Cleanup(func() { Set(prev) })
+
+	if err := os.MkdirAll(filepath.Dir(SettingsPath()), 0o700); err != nil {
+		t.Fatal(err)
+	}
+	err := os.WriteFile(SettingsPath(), []byte("read_only: false\nrefresh:\n  intervals:\n    mounts: 1ms\n"), 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Settings which can't be used must fail closed.
+	if err = Load(); !errors.Is(err, ErrInvalidSettings) {
+		t.Fatalf("expected invalid settings error, got %v", err)
+	}
+	if !Get().ReadOnly {
+		t.Fatal("expected read-only mode with invalid settings")
+	}
+}
@@ -0,0 +1,124 @@
+// Copyright (c) Liam Stanley <liam@liam.sh>. All rights reserved. Use of
+// this source code is governed by the MIT license that can be found in
+// the LICENSE file.
+
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+)
+
+// StateFile is the name of the file, within [GetConfigPath], used to persist
+// state between sessions. Unlike [SettingsFile], it is managed by vex, and
+// shouldn't be edited by users.
+const StateFile = "state.json"
+
+// State is persisted between sessions.
+type State struct {
+	// Tint is the ID of the tint last selected by the user. Takes precedence over
+	// [ThemeSettings.Tint].
+	Tint string `json:"tint,omitempty"`
+
+	// Sessions is the page stack of the last session, by cluster profile.
+	Sessions map[string]Session `json:"sessions,omitempty"`
+
+	// Recent is the recently visited secret paths, by cluster profile.
+	Recent map[string][]RecentPath `json:"recent,omitempty"`
+}
+
+var (
+	stateMu sync.Mutex
+	state   State
+)
+
+// StatePath returns the path to the state file.
+func StatePath() string {
+	return filepath.Join(GetConfigPath(), StateFile)
+}
+
+// LoadState loads the state file, if it exists. If the state file is invalid,
+// an empty state is used.
+func LoadState() error {
+	stateMu.Lock()
+	defer stateMu.Unlock()
+
+	state = State{}
+
+	data, err := os.ReadFile(StatePath())
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+		return fmt.Errorf("read state: %w", err)
+	}
+
+	if err = json.Unmarshal(data, &state); err != nil {
+		state = State{}
+		return fmt.Errorf("parse state: %w", err)
+	}
+	return nil
+}
+
+// GetState returns a copy of the current state.
+func GetState() State {
+	stateMu.Lock()
+	defer stateMu.Unlock()
+
+	s := state
+	s.Sessions = maps.Clone(state.Sessions)
+	s.Recent = make(map[string][]RecentPath, len(state.Recent))
+	for profile, recent := range state.Recent {
+		s.Recent[profile] = slices.Clone(recent)
+	}
+	return s
+}
+
+// UpdateState updates the state using the provided function, and persists it to
+// the state file.
+func UpdateState(fn func(s *State)) error {
+	stateMu.Lock()
+	defer stateMu.Unlock()
+
+	fn(&state)
+
+	data, err := json.MarshalIndent(state, "", "    ")
+	if err != nil {
+		return fmt.Errorf("marshal state: %w", err)
+	}
+
+	if err = writeFileAtomic(StatePath(), data); err != nil {
+		return fmt.Errorf("write state: %w", err)
+	}
+	return nil
+}
+
+// writeFileAtomic writes data to a temp file alongside path first, before
+// renaming it into place, so a crash mid-write can't corrupt the file.
+func writeFileAtomic(path string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+
+	f, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+
+	_, err = f.Write(data)
+	err = errors.Join(err, f.Close())
+	if err == nil {
+		err = os.Rename(f.Name(), path)
+	}
+	if err != nil {
+		_ = os.Remove(f.Name())
+	}
+	return err
+}
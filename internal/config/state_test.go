@@ -0,0 +1,32 @@
+// Copyright (c) Liam Stanley <liam@liam.sh>. All rights reserved. Use of
+// this source code is governed by the MIT license that can be found in
+// the LICENSE file.
+
+package config
+
+import (
+	"testing"
+)
+
+func TestStateRoundTrip(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	InitConfigPath()
+
+	if err := LoadState(); err != nil {
+		t.Fatalf("unexpected error loading missing state: %v", err)
+	}
+
+	err := UpdateState(func(s *State) {
+		s.Tint = "dracula"
+	})
+	if err != nil {
+		t.Fatalf("unexpected error updating state: %v", err)
+	}
+
+	if err = LoadState(); err != nil {
+		t.Fatalf("unexpected error loading state: %v", err)
+	}
+	if got := GetState().Tint; got != "dracula" {
+		t.Fatalf("expected persisted tint %q, got %q", "dracula", got)
+	}
+}
@@ -0,0 +1,188 @@
+// Copyright (c) Liam Stanley <liam@liam.sh>. All rights reserved. Use of
+// this source code is governed by the MIT license that can be found in
+// the LICENSE file.
+
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrNoSecureTempDir is returned when there is no memory-backed location to
+// store temporary files containing secrets, and insecure temp directories aren't
+// allowed (see [EditorSettings.AllowInsecureTempDir]).
+var ErrNoSecureTempDir = errors.New("no memory-backed temp directory available (see --allow-insecure-editor)")
+
+// staleTempFileAge is the age after which temp files are always considered stale,
+// even if the process which created them appears to still be running (e.g. pid
+// reuse).
+const staleTempFileAge = 24 * time.Hour
+
+// memoryTempDirs returns candidate memory-backed directories, in order of
+// preference.
+func memoryTempDirs() (dirs []string) {
+	if dir := os.Getenv("XDG_RUNTIME_DIR"); dir != "" {
+		dirs = append(dirs, dir)
+	}
+	if runtime.GOOS == "linux" {
+		dirs = append(dirs, "/dev/shm")
+	}
+	return dirs
+}
+
+// privateDir ensures a directory only accessible by the current user exists
+// within parent, and returns its path. Existing directories are rejected if
+// they are a symlink, or aren't owned by the current user.
+func privateDir(parent string) (string, error) {
+	fi, err := os.Stat(parent)
+	if err != nil {
+		return "", err
+	}
+	if !fi.IsDir() {
+		return "", fmt.Errorf("%q is not a directory", parent)
+	}
+
+	dir := filepath.Join(parent, AppName+"-"+strconv.Itoa(os.Getuid()))
+
+	err = os.Mkdir(dir, 0o700)
+	if err != nil && !errors.Is(err, fs.ErrExist) {
+		return "", err
+	}
+
+	fi, err = os.Lstat(dir)
+	if err != nil {
+		return "", err
+	}
+	if !fi.IsDir() || fi.Mode()&fs.ModeSymlink != 0 {
+		return "", fmt.Errorf("%q is not a directory", dir)
+	}
+	if !ownedByCurrentUser(fi) {
+		return "", fmt.Errorf("%q is not owned by the current user", dir)
+	}
+	if fi.Mode().Perm() != 0o700 {
+		if err = os.Chmod(dir, 0o700); err != nil {
+			return "", err
+		}
+	}
+	return dir, nil
+}
+
+// SecureTempDir returns a private (0700) directory for temporary files which
+// contain secrets, preferring memory-backed filesystems (e.g. $XDG_RUNTIME_DIR
+// or /dev/shm), so secrets never touch the disk. Returns [ErrNoSecureTempDir]
+// if none are available, unless [EditorSettings.AllowInsecureTempDir] is set.
+func SecureTempDir() (string, error) {
+	for _, parent := range memoryTempDirs() {
+		if dir, err := privateDir(parent); err == nil {
+			return dir, nil
+		}
+	}
+
+	if !Get().Editor.AllowInsecureTempDir {
+		return "", ErrNoSecureTempDir
+	}
+	return privateDir(os.TempDir())
+}
+
+// CreateSecureTemp creates a temporary file (0600) in [SecureTempDir], using
+// the provided pattern (see [os.CreateTemp]). The file name includes the pid of
+// the current process, so stale files can be swept by [SweepTempFiles]. Files
+// should be removed with [RemoveSecureTemp].
+func CreateSecureTemp(pattern string) (*os.File, error) {
+	dir, err := SecureTempDir()
+	if err != nil {
+		return nil, err
+	}
+	return os.CreateTemp(dir, AppName+"-"+strconv.Itoa(os.Getpid())+"-"+pattern)
+}
+
+// RemoveSecureTemp overwrites the contents of the provided file with zeros,
+// before removing it.
+func RemoveSecureTemp(path string) error {
+	f, err := os.OpenFile(path, os.O_WRONLY, 0)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+
+	fi, err := f.Stat()
+	if err == nil {
+		_, err = f.WriteAt(make([]byte, fi.Size()), 0)
+	}
+	if err == nil {
+		err = f.Sync()
+	}
+	_ = f.Close()
+
+	return errors.Join(err, os.Remove(path))
+}
+
+// SweepTempFiles removes stale temporary files left behind by previous runs
+// (e.g. due to a crashed editor or panic) from [SecureTempDir]. Only the private
+// directory is swept, as other files in shared temp directories may belong to
+// other applications. Files of processes which are still running are left alone.
+func SweepTempFiles() (removed int, err error) {
+	dir, err := SecureTempDir()
+	if err != nil {
+		if errors.Is(err, ErrNoSecureTempDir) {
+			return 0, nil
+		}
+		return 0, err
+	}
+	return sweepTempDir(dir)
+}
+
+// sweepTempDir removes stale temporary files (see [isStaleTempFile]) from dir.
+func sweepTempDir(dir string) (removed int, err error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return 0, err
+	}
+
+	var errs []error
+	for _, entry := range entries {
+		if !entry.Type().IsRegular() || !strings.HasPrefix(entry.Name(), AppName+"-") {
+			continue
+		}
+
+		fi, ierr := entry.Info()
+		if ierr != nil || !ownedByCurrentUser(fi) || !isStaleTempFile(fi) {
+			continue
+		}
+
+		if rerr := RemoveSecureTemp(filepath.Join(dir, entry.Name())); rerr != nil {
+			errs = append(errs, rerr)
+			continue
+		}
+		removed++
+	}
+
+	return removed, errors.Join(errs...)
+}
+
+// isStaleTempFile returns true if the temp file was created by a process which
+// is no longer running. Files without a pid (created by older versions) are
+// stale after an hour.
+func isStaleTempFile(fi fs.FileInfo) bool {
+	age := time.Since(fi.ModTime())
+	if age > staleTempFileAge {
+		return true
+	}
+
+	name := strings.TrimPrefix(fi.Name(), AppName+"-")
+	pid, err := strconv.Atoi(name[:max(0, strings.Index(name, "-"))])
+	if err != nil || pid <= 0 {
+		return age > time.Hour
+	}
+	return pid != os.Getpid() && !processRunning(pid)
+}
@@ -0,0 +1,17 @@
+// Copyright (c) Liam Stanley <liam@liam.sh>. All rights reserved. Use of
+// this source code is governed by the MIT license that can be found in
+// the LICENSE file.
+
+//go:build !unix
+
+package config
+
+import "io/fs"
+
+func ownedByCurrentUser(_ fs.FileInfo) bool {
+	return true // Temp directories are already per-user.
+}
+
+func processRunning(_ int) bool {
+	return true // Unknown, fall back to the age of the file.
+}
@@ -0,0 +1,255 @@
+// Copyright (c) Liam Stanley <liam@liam.sh>. All rights reserved. Use of
+// this source code is governed by the MIT license that can be found in
+// the LICENSE file.
+
+//go:build unix
+
+package config
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// deadPid is above the maximum pid on Linux (and most other unix systems), so
+// it should never belong to a running process.
+const deadPid = 1 << 23
+
+func TestPrivateDir(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		setup   func(t *testing.T, parent, dir string)
+		wantErr bool
+	}{
+		{name: "missing", setup: func(*testing.T, string, string) {}},
+		{
+			name: "loose-perms",
+			setup: func(t *testing.T, _, dir string) {
+				if err := os.Mkdir(dir, 0o755); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.Chmod(dir, 0o755); err != nil {
+					t.Fatal(err)
+				}
+			},
+		},
+		{
+			name: "symlink",
+			setup: func(t *testing.T, _, dir string) {
+				if err := os.Symlink(t.TempDir(), dir); err != nil {
+					t.Fatal(err)
+				}
+			},
+			wantErr: true,
+		},
+		{
+			name: "file",
+			setup: func(t *testing.T, _, dir string) {
+				if err := os.WriteFile(dir, nil, 0o600); err != nil {
+					t.Fatal(err)
+				}
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			parent := t.TempDir()
+			want := filepath.Join(parent, AppName+"-"+strconv.Itoa(os.Getuid()))
+			tt.setup(t, parent, want)
+
+			dir, err := privateDir(parent)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected error, got dir %q", dir)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if dir != want {
+				t.Fatalf("expected dir %q, got %q", want, dir)
+			}
+
+			fi, err := os.Lstat(dir)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !fi.IsDir() || fi.Mode().Perm() != 0o700 {
+				t.Fatalf("expected directory with 0700 perms, got %v", fi.Mode())
+			}
+		})
+	}
+
+	t.Run("parent-not-dir", func(t *testing.T) {
+		t.Parallel()
+
+		parent := filepath.Join(t.TempDir(), "file")
+		if err := os.WriteFile(parent, nil, 0o600); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := privateDir(parent); err == nil {
+			t.Fatal("expected error for non-directory parent")
+		}
+	})
+}
+
+func TestSecureTemp(t *testing.T) {
+	runtimeDir := t.TempDir()
+	t.Setenv("XDG_RUNTIME_DIR", runtimeDir)
+
+	f, err := CreateSecureTemp("*.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = f.WriteString("secret")
+	_ = f.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got, want := filepath.Dir(f.Name()), filepath.Join(runtimeDir, AppName+"-"+strconv.Itoa(os.Getuid())); got != want {
+		t.Fatalf("expected file in %q, got %q", want, got)
+	}
+
+	prefix := AppName + "-" + strconv.Itoa(os.Getpid()) + "-"
+	if name := filepath.Base(f.Name()); !strings.HasPrefix(name, prefix) || !strings.HasSuffix(name, ".yaml") {
+		t.Fatalf("expected name matching %q*.yaml, got %q", prefix, name)
+	}
+
+	fi, err := os.Stat(f.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fi.Mode().Perm() != 0o600 {
+		t.Fatalf("expected 0600 perms, got %v", fi.Mode().Perm())
+	}
+
+	if err = RemoveSecureTemp(f.Name()); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = os.Stat(f.Name()); !os.IsNotExist(err) {
+		t.Fatalf("expected file to be removed, got %v", err)
+	}
+
+	if err = RemoveSecureTemp(f.Name()); err != nil {
+		t.Fatalf("expected no error removing missing file, got %v", err)
+	}
+}
+
+// writeTempFile creates a file with the provided name and age in dir, returning
+// its info.
+func writeTempFile(t *testing.T, dir, name string, age time.Duration) fs.FileInfo {
+	t.Helper()
+
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte("secret"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	mtime := time.Now().Add(-age)
+	if err := os.Chtimes(path, mtime, mtime); err != nil {
+		t.Fatal(err)
+	}
+
+	fi, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return fi
+}
+
+func TestIsStaleTempFile(t *testing.T) {
+	t.Parallel()
+
+	pid := strconv.Itoa(os.Getpid())
+	dead := strconv.Itoa(deadPid)
+
+	tests := []struct {
+		name string
+		file string
+		age  time.Duration
+		want bool
+	}{
+		{name: "running", file: AppName + "-" + pid + "-1.yaml", age: 2 * time.Hour, want: false},
+		{name: "running-expired", file: AppName + "-" + pid + "-2.yaml", age: staleTempFileAge + time.Hour, want: true},
+		{name: "dead", file: AppName + "-" + dead + "-3.yaml", age: 0, want: true},
+		{name: "no-pid-recent", file: AppName + "-4.yaml", age: time.Minute, want: false},
+		{name: "no-pid-old", file: AppName + "-5.yaml", age: 2 * time.Hour, want: true},
+		{name: "invalid-pid-old", file: AppName + "-0-6.yaml", age: 2 * time.Hour, want: true},
+	}
+
+	dir := t.TempDir()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			fi := writeTempFile(t, dir, tt.file, tt.age)
+			if got := isStaleTempFile(fi); got != tt.want {
+				t.Fatalf("expected stale=%v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestSweepTempFiles(t *testing.T) {
+	runtimeDir := t.TempDir()
+	t.Setenv("XDG_RUNTIME_DIR", runtimeDir)
+
+	dir, err := SecureTempDir()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pid := strconv.Itoa(os.Getpid())
+	dead := strconv.Itoa(deadPid)
+
+	writeTempFile(t, dir, AppName+"-"+dead+"-stale.yaml", 0)
+	writeTempFile(t, dir, AppName+"-old.yaml", 2*time.Hour)
+	writeTempFile(t, dir, AppName+"-"+pid+"-active.yaml", 0)
+	writeTempFile(t, dir, "other-"+dead+"-file.yaml", 0)
+
+	// Files outside of the private directory (e.g. in a shared temp directory)
+	// must never be touched.
+	writeTempFile(t, runtimeDir, AppName+"-"+dead+"-shared.yaml", 0)
+	writeTempFile(t, runtimeDir, AppName+"-shared.yaml", 2*time.Hour)
+
+	removed, err := SweepTempFiles()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if removed != 2 {
+		t.Fatalf("expected 2 files removed, got %d", removed)
+	}
+
+	for _, path := range []string{
+		filepath.Join(dir, AppName+"-"+pid+"-active.yaml"),
+		filepath.Join(dir, "other-"+dead+"-file.yaml"),
+		filepath.Join(runtimeDir, AppName+"-"+dead+"-shared.yaml"),
+		filepath.Join(runtimeDir, AppName+"-shared.yaml"),
+	} {
+		if _, err = os.Stat(path); err != nil {
+			t.Errorf("expected %q to be kept: %v", path, err)
+		}
+	}
+
+	for _, path := range []string{
+		filepath.Join(dir, AppName+"-"+dead+"-stale.yaml"),
+		filepath.Join(dir, AppName+"-old.yaml"),
+	} {
+		if _, err = os.Stat(path); !os.IsNotExist(err) {
+			t.Errorf("expected %q to be removed, got %v", path, err)
+		}
+	}
+}
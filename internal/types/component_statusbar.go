@@ -63,6 +63,34 @@ type ClearStatusTextMsg struct {
 	ID int64
 }
 
+// ClipboardTimerMsg is a message to show a countdown until sensitive content is
+// cleared from the clipboard. Should always be wrapped in a StatusMsg.
+type ClipboardTimerMsg struct {
+	ID      int64
+	Expires time.Time
+}
+
+// ClearClipboardTimerMsg is a message to remove the clipboard countdown, when
+// the clipboard was cleared (or no longer contains the copied content). Should
+// always be wrapped in a StatusMsg.
+type ClearClipboardTimerMsg struct {
+	ID int64
+}
+
+// UndoTimerMsg is a message to show a countdown until an offered undo (see
+// [UndoMsg]) expires. Should always be wrapped in a StatusMsg.
+type UndoTimerMsg struct {
+	ID          int64
+	Description string
+	Expires     time.Time
+}
+
+// ClearUndoTimerMsg is a message to remove the undo countdown, when the undo was
+// used or replaced. Should always be wrapped in a StatusMsg.
+type ClearUndoTimerMsg struct {
+	ID int64
+}
+
 // StatusOperationMsg is a message to add an operation to the statusbar. Should
 // always be wrapped in a StatusMsg.
 type StatusOperationMsg struct {
@@ -71,6 +99,7 @@ type StatusOperationMsg struct {
 }
 
 // AddStatusOperation is a helper function to add a status operation message.
+// If an operation with the same ID already exists, its text is replaced.
 func AddStatusOperation(id int64, text string) tea.Cmd {
 	return CmdMsg(StatusMsg{Msg: StatusOperationMsg{ID: id, Text: text}})
 }
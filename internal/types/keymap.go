@@ -5,7 +5,11 @@
 package types
 
 import (
+	"errors"
+	"fmt"
+	"maps"
 	"slices"
+	"strings"
 
 	"charm.land/bubbles/v2/key"
 )
@@ -77,6 +81,10 @@ var (
 		key.WithKeys("c"),
 		key.WithHelp("c", "copy"),
 	)
+	KeyCopyAs = key.NewBinding(
+		key.WithKeys("C"),
+		key.WithHelp("C", "copy as"),
+	)
 	KeyTabForward = key.NewBinding(
 		key.WithKeys("tab"),
 		key.WithHelp("tab", "tab forward"),
@@ -105,6 +113,30 @@ var (
 		key.WithKeys("ctrl+e"),
 		key.WithHelp("ctrl+e", "open in editor"),
 	)
+	KeyPreviousTint = key.NewBinding(
+		key.WithKeys("["),
+		key.WithHelp("[", "previous theme"),
+	)
+	KeyNextTint = key.NewBinding(
+		key.WithKeys("]"),
+		key.WithHelp("]", "next theme"),
+	)
+	KeyThemePicker = key.NewBinding(
+		key.WithKeys("ctrl+t"),
+		key.WithHelp("ctrl+t", "themes"),
+	)
+	KeyJumpToAncestor = key.NewBinding(
+		key.WithKeys("alt+1", "alt+2", "alt+3", "alt+4", "alt+5", "alt+6", "alt+7", "alt+8", "alt+9"),
+		key.WithHelp("alt+1-9", "jump to breadcrumb"),
+	)
+	KeyUndo = key.NewBinding(
+		key.WithKeys("ctrl+z"),
+		key.WithHelp("ctrl+z", "undo"),
+	)
+	KeyToggleBookmark = key.NewBinding(
+		key.WithKeys("B"),
+		key.WithHelp("B", "toggle bookmark"),
+	)
 
 	// Secret related.
 
@@ -120,6 +152,38 @@ var (
 		key.WithKeys("d"),
 		key.WithHelp("d", "toggle delete"),
 	)
+	KeyAddKey = key.NewBinding(
+		key.WithKeys("a"),
+		key.WithHelp("a", "add key"),
+	)
+	KeyRenameKey = key.NewBinding(
+		key.WithKeys("r"),
+		key.WithHelp("r", "rename key"),
+	)
+	KeyChangeType = key.NewBinding(
+		key.WithKeys("t"),
+		key.WithHelp("t", "change type"),
+	)
+	KeyGenerateValue = key.NewBinding(
+		key.WithKeys("ctrl+g"),
+		key.WithHelp("ctrl+g", "generate value"),
+	)
+	KeyLoadFromFile = key.NewBinding(
+		key.WithKeys("o"),
+		key.WithHelp("o", "load from file"),
+	)
+	KeySaveToFile = key.NewBinding(
+		key.WithKeys("w"),
+		key.WithHelp("w", "save to file"),
+	)
+	KeyApplyChanges = key.NewBinding(
+		key.WithKeys("ctrl+s"),
+		key.WithHelp("ctrl+s", "review & apply"),
+	)
+	KeyDiscardChanges = key.NewBinding(
+		key.WithKeys("ctrl+u"),
+		key.WithHelp("ctrl+u", "discard changes"),
+	)
 	KeyRenderJSON = key.NewBinding(
 		key.WithKeys("z"),
 		key.WithHelp("z", "view json"),
@@ -128,6 +192,70 @@ var (
 		key.WithKeys("r"),
 		key.WithHelp("r", "list secrets recursively"),
 	)
+	KeyEditMetadata = key.NewBinding(
+		key.WithKeys("m"),
+		key.WithHelp("m", "edit metadata"),
+	)
+	KeyEditCustomMetadata = key.NewBinding(
+		key.WithKeys("M"),
+		key.WithHelp("M", "edit custom metadata"),
+	)
+	KeyToggleMetadataColumns = key.NewBinding(
+		key.WithKeys("t"),
+		key.WithHelp("t", "toggle metadata columns"),
+	)
+	KeyToggleAllClusters = key.NewBinding(
+		key.WithKeys("t"),
+		key.WithHelp("t", "toggle all clusters"),
+	)
+	KeyEditMountConfig = key.NewBinding(
+		key.WithKeys("s"),
+		key.WithHelp("s", "mount settings"),
+	)
+	KeyUnseal = key.NewBinding(
+		key.WithKeys("u"),
+		key.WithHelp("u", "unseal"),
+	)
+	KeyResetUnseal = key.NewBinding(
+		key.WithKeys("U"),
+		key.WithHelp("U", "reset unseal"),
+	)
+	KeySeal = key.NewBinding(
+		key.WithKeys("S"),
+		key.WithHelp("S", "seal"),
+	)
+	KeyStepDown = key.NewBinding(
+		key.WithKeys("D"),
+		key.WithHelp("D", "step down"),
+	)
+	KeyRaftAutopilot = key.NewBinding(
+		key.WithKeys("a"),
+		key.WithHelp("a", "autopilot"),
+	)
+	KeyRaftJoin = key.NewBinding(
+		key.WithKeys("J"),
+		key.WithHelp("J", "join cluster"),
+	)
+	KeyEditAutopilotConfig = key.NewBinding(
+		key.WithKeys("s"),
+		key.WithHelp("s", "autopilot settings"),
+	)
+	KeyToggleRawMetrics = key.NewBinding(
+		key.WithKeys("v"),
+		key.WithHelp("v", "toggle raw metrics"),
+	)
+	KeyEnableAuditDevice = key.NewBinding(
+		key.WithKeys("n"),
+		key.WithHelp("n", "enable device"),
+	)
+	KeyAuditHash = key.NewBinding(
+		key.WithKeys("H"),
+		key.WithHelp("H", "hash value"),
+	)
+	KeyCycleAuditView = key.NewBinding(
+		key.WithKeys("v"),
+		key.WithHelp("v", "cycle view"),
+	)
 
 	// Table related.
 
@@ -143,6 +271,123 @@ var (
 	}
 )
 
+// keyBindingNames maps the names used to remap key bindings in the config file to
+// their respective key bindings.
+var keyBindingNames = map[string]*key.Binding{
+	"commander":               &KeyCommander,
+	"filter":                  &KeyFilter,
+	"up":                      &KeyUp,
+	"down":                    &KeyDown,
+	"left":                    &KeyLeft,
+	"right":                   &KeyRight,
+	"page_up":                 &KeyPageUp,
+	"page_down":               &KeyPageDown,
+	"go_to_top":               &KeyGoToTop,
+	"go_to_bottom":            &KeyGoToBottom,
+	"select_item":             &KeySelectItem,
+	"select_item_alt":         &KeySelectItemAlt,
+	"cancel":                  &KeyCancel,
+	"refresh":                 &KeyRefresh,
+	"details":                 &KeyDetails,
+	"copy":                    &KeyCopy,
+	"copy_as":                 &KeyCopyAs,
+	"tab_forward":             &KeyTabForward,
+	"tab_backward":            &KeyTabBackward,
+	"help":                    &KeyHelp,
+	"quit":                    &KeyQuit,
+	"delete":                  &KeyDelete,
+	"destroy":                 &KeyDestroy,
+	"open_editor":             &KeyOpenEditor,
+	"previous_tint":           &KeyPreviousTint,
+	"next_tint":               &KeyNextTint,
+	"theme_picker":            &KeyThemePicker,
+	"jump_to_ancestor":        &KeyJumpToAncestor,
+	"undo":                    &KeyUndo,
+	"toggle_bookmark":         &KeyToggleBookmark,
+	"toggle_mask":             &KeyToggleMask,
+	"toggle_mask_all":         &KeyToggleMaskAll,
+	"toggle_delete":           &KeyToggleDelete,
+	"add_key":                 &KeyAddKey,
+	"rename_key":              &KeyRenameKey,
+	"change_type":             &KeyChangeType,
+	"generate_value":          &KeyGenerateValue,
+	"load_from_file":          &KeyLoadFromFile,
+	"save_to_file":            &KeySaveToFile,
+	"apply_changes":           &KeyApplyChanges,
+	"discard_changes":         &KeyDiscardChanges,
+	"render_json":             &KeyRenderJSON,
+	"list_recursive":          &KeyListRecursive,
+	"edit_metadata":           &KeyEditMetadata,
+	"edit_custom_metadata":    &KeyEditCustomMetadata,
+	"toggle_metadata_columns": &KeyToggleMetadataColumns,
+	"toggle_all_clusters":     &KeyToggleAllClusters,
+	"edit_mount_config":       &KeyEditMountConfig,
+	"unseal":                  &KeyUnseal,
+	"reset_unseal":            &KeyResetUnseal,
+	"seal":                    &KeySeal,
+	"step_down":               &KeyStepDown,
+	"raft_autopilot":          &KeyRaftAutopilot,
+	"raft_join":               &KeyRaftJoin,
+	"edit_autopilot_config":   &KeyEditAutopilotConfig,
+	"toggle_raw_metrics":      &KeyToggleRawMetrics,
+	"enable_audit_device":     &KeyEnableAuditDevice,
+	"audit_hash":              &KeyAuditHash,
+	"cycle_audit_view":        &KeyCycleAuditView,
+}
+
+// KeyBindingNames returns the sorted names of all key bindings which can be
+// remapped.
+func KeyBindingNames() []string {
+	return slices.Sorted(maps.Keys(keyBindingNames))
+}
+
+// ApplyKeyBindings remaps key bindings by name (see [KeyBindingNames]) to the
+// provided keys. Unknown names and empty key lists are returned as errors, and
+// are otherwise ignored.
+func ApplyKeyBindings(overrides map[string][]string) error {
+	var errs []error
+
+	for _, name := range slices.Sorted(maps.Keys(overrides)) {
+		b, ok := keyBindingNames[name]
+		if !ok {
+			errs = append(errs, fmt.Errorf("keybindings.%s: unknown key binding", name))
+			continue
+		}
+
+		keys := overrides[name]
+		if len(keys) == 0 || slices.Contains(keys, "") {
+			errs = append(errs, fmt.Errorf("keybindings.%s: keys must not be empty", name))
+			continue
+		}
+
+		b.SetKeys(keys...)
+		b.SetHelp(strings.Join(keys, "/"), b.Help().Desc)
+	}
+
+	KeysTable = []key.Binding{
+		KeyUp,
+		KeyDown,
+		KeyLeft,
+		KeyRight,
+		KeyPageUp,
+		KeyPageDown,
+		KeyGoToTop,
+		KeyGoToBottom,
+	}
+
+	return errors.Join(errs...)
+}
+
+// EffectiveKeyBindings returns the keys of all key bindings which can be
+// remapped, by name.
+func EffectiveKeyBindings() map[string][]string {
+	out := make(map[string][]string, len(keyBindingNames))
+	for name, b := range keyBindingNames {
+		out[name] = b.Keys()
+	}
+	return out
+}
+
 // OverrideHelp overrides the help text for a key binding, returning a new key
 // binding with the same keys and the new help text.
 func OverrideHelp(b key.Binding, help string) key.Binding {
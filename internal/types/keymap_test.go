@@ -0,0 +1,68 @@
+// Copyright (c) Liam Stanley <liam@liam.sh>. All rights reserved. Use of
+// this source code is governed by the MIT license that can be found in
+// the LICENSE file.
+
+package types
+
+import (
+	"slices"
+	"testing"
+)
+
+// globalKeyBindings are the names of key bindings which are handled on every
+// page (or by the app itself), and as such must not share keys with each other,
+// or with any page-specific key binding, which would shadow them.
+var globalKeyBindings = []string{
+	"commander",
+	"filter",
+	"up",
+	"down",
+	"left",
+	"right",
+	"page_up",
+	"page_down",
+	"go_to_top",
+	"go_to_bottom",
+	"select_item",
+	"select_item_alt",
+	"cancel",
+	"refresh",
+	"tab_forward",
+	"tab_backward",
+	"help",
+	"quit",
+	"previous_tint",
+	"next_tint",
+	"theme_picker",
+	"jump_to_ancestor",
+	"undo",
+}
+
+func TestKeyBindingsNoDuplicates(t *testing.T) {
+	t.Parallel()
+
+	owners := make(map[string]string) // Key -> global binding name.
+	for _, name := range globalKeyBindings {
+		b, ok := keyBindingNames[name]
+		if !ok {
+			t.Fatalf("unknown global key binding %q", name)
+		}
+		for _, k := range b.Keys() {
+			if other, ok := owners[k]; ok {
+				t.Errorf("key %q is bound to both %q and %q", k, other, name)
+			}
+			owners[k] = name
+		}
+	}
+
+	for _, name := range KeyBindingNames() {
+		if slices.Contains(globalKeyBindings, name) {
+			continue
+		}
+		for _, k := range keyBindingNames[name].Keys() {
+			if other, ok := owners[k]; ok {
+				t.Errorf("key %q of %q shadows global key binding %q", k, name, other)
+			}
+		}
+	}
+}
@@ -6,7 +6,10 @@ package types
 
 import (
 	"encoding/json"
+	"errors"
+	"io"
 	"iter"
+	"maps"
 	"slices"
 	"strconv"
 	"strings"
@@ -25,6 +28,12 @@ type Client interface {
 	// with a [ClientMsg] containing a [ClientConfigMsg] containing the health of
 	// the Vault server.
 	GetHealth(uuid string) tea.Cmd
+	// GetNodeHealth returns a command to get the health of a single node of the
+	// cluster, at the provided API address rather than the configured address.
+	// Responds with a [ClientMsg] containing a [ClientNodeHealthMsg]. Failed checks
+	// (e.g. unreachable nodes) are reported through [ClientNodeHealthMsg.Error],
+	// rather than as an error of the request.
+	GetNodeHealth(uuid, address string) tea.Cmd
 	// TokenLookupSelf returns a command to lookup the current token. Responds with
 	// a [ClientMsg] containing a [ClientTokenLookupSelfMsg] containing the result
 	// of the token lookup.
@@ -33,6 +42,12 @@ type Client interface {
 	// inferred from its prefix (see [TokenTypeFromRaw]). Returns [TokenTypeUnknown]
 	// if no token is set or the prefix is not recognized.
 	TokenType() TokenType
+	// Profile returns an identifier for the cluster (and namespace, if any) the
+	// client is configured for, used to scope persisted data (e.g. bookmarks).
+	Profile() string
+	// ReadOnly returns whether the client refuses all operations which modify the
+	// Vault server, with an error wrapping [ErrReadOnly].
+	ReadOnly() bool
 
 	// ListMounts returns a command to list the mounts of the Vault server.
 	// Responds with a [ClientMsg] containing a [ClientListMountsMsg] containing
@@ -60,11 +75,30 @@ type Client interface {
 	// under a given mount and path. Responds with a [ClientMsg] containing a
 	// [ClientGetKVv2MetadataMsg] containing the metadata of the KVv2 secret.
 	GetKVv2Metadata(uuid string, mount *Mount, path string) tea.Cmd
-
-	// PutKVv1Secret puts a secret into the Vault server, under a given mount and path.
-	// Responds with a [ClientMsg] containing a [ClientSuccessMsg] containing the result
-	// of the operation.
-	PutKVSecret(uuid string, mount *Mount, path string, data map[string]any) tea.Cmd
+	// PutKVv2Metadata returns a command to update the metadata of a KVv2 secret
+	// under a given mount and path. Responds with a [ClientMsg] containing a
+	// [ClientSuccessMsg] containing the result of the operation.
+	PutKVv2Metadata(uuid string, mount *Mount, path string, input *KVv2MetadataInput) tea.Cmd
+	// ListKVv2Metadata returns a command to get the metadata of multiple KVv2
+	// secrets under a given mount. Responds with a [ClientMsg] containing a
+	// [ClientListKVv2MetadataMsg] containing the metadata of each secret.
+	ListKVv2Metadata(uuid string, mount *Mount, paths ...string) tea.Cmd
+	// GetKVv2MountConfig returns a command to get the mount-level configuration
+	// of a KVv2 mount. Responds with a [ClientMsg] containing a
+	// [ClientGetKVv2MountConfigMsg] containing the configuration of the mount.
+	GetKVv2MountConfig(uuid string, mount *Mount) tea.Cmd
+	// PutKVv2MountConfig returns a command to update the mount-level configuration
+	// of a KVv2 mount. Responds with a [ClientMsg] containing a [ClientSuccessMsg]
+	// containing the result of the operation.
+	PutKVv2MountConfig(uuid string, mount *Mount, config *KVv2MountConfig) tea.Cmd
+
+	// PutKVSecret puts a secret into the Vault server, under a given mount and path.
+	// For KVv2 mounts, if cas is >= 0, the write will only succeed if the current
+	// version of the secret matches cas (0 meaning the secret must not exist),
+	// otherwise an error wrapping [ErrCheckAndSetMismatch] is returned. Use -1 to
+	// disable check-and-set. Responds with a [ClientMsg] containing a
+	// [ClientSuccessMsg] containing the result of the operation.
+	PutKVSecret(uuid string, mount *Mount, path string, data map[string]any, cas int) tea.Cmd
 
 	// DeleteKVSecret deletes a secret, under a given mount and path. Responds with
 	// a [ClientMsg] containing a [ClientSuccessMsg] containing the result of the
@@ -86,16 +120,77 @@ type Client interface {
 	// Responds with a [ClientMsg] containing a [ClientGetACLPolicyMsg] containing
 	// the data of the ACL policy.
 	GetACLPolicy(uuid string, policyName string) tea.Cmd
+	// GeneratePassword returns a command to generate a password from a Vault
+	// password policy. Responds with a [ClientMsg] containing a
+	// [ClientGeneratePasswordMsg] containing the generated password.
+	GeneratePassword(uuid string, policy string) tea.Cmd
 	// GetConfigState returns a command to get the configuration of the Vault
 	// server. Responds with a [ClientMsg] containing a [ClientConfigStateMsg] containing
 	// the configuration of the Vault server.
 	GetConfigState(uuid string) tea.Cmd
+	// GetMetrics returns a command to get the telemetry metrics of the Vault
+	// server, as of the last completed telemetry interval. Responds with a
+	// [ClientMsg] containing a [ClientMetricsMsg].
+	GetMetrics(uuid string) tea.Cmd
 	// GetRaftConfig returns a command to read integrated storage raft
 	// configuration. Responds with a [ClientMsg] containing a [ClientRaftConfigMsg].
 	GetRaftConfig(uuid string) tea.Cmd
 	// RemoveRaftPeer removes a raft peer by server ID. Responds with a [ClientMsg]
 	// containing a [ClientSuccessMsg].
 	RemoveRaftPeer(uuid string, serverID string) tea.Cmd
+	// GetRaftAutopilotState returns a command to read the integrated storage
+	// autopilot state, including the health of each server. Responds with a
+	// [ClientMsg] containing a [ClientRaftAutopilotStateMsg].
+	GetRaftAutopilotState(uuid string) tea.Cmd
+	// GetRaftAutopilotConfig returns a command to read the integrated storage
+	// autopilot configuration. Responds with a [ClientMsg] containing a
+	// [ClientRaftAutopilotConfigMsg].
+	GetRaftAutopilotConfig(uuid string) tea.Cmd
+	// PutRaftAutopilotConfig updates the integrated storage autopilot
+	// configuration. Responds with a [ClientMsg] containing a [ClientSuccessMsg].
+	PutRaftAutopilotConfig(uuid string, config *vapi.AutopilotConfig) tea.Cmd
+	// SaveRaftSnapshot takes a snapshot of integrated storage, streaming it to w.
+	// Responds with a [ClientMsg] containing a [ClientSuccessMsg].
+	SaveRaftSnapshot(uuid string, w io.Writer) tea.Cmd
+	// RestoreRaftSnapshot restores integrated storage from the snapshot read from
+	// r, replacing all data in the cluster. force allows restoring snapshots taken
+	// on a different cluster. Responds with a [ClientMsg] containing a
+	// [ClientSuccessMsg].
+	RestoreRaftSnapshot(uuid string, r io.Reader, force bool) tea.Cmd
+	// JoinRaft joins the Vault server (node) to an existing raft cluster.
+	// Responds with a [ClientMsg] containing a [ClientSuccessMsg].
+	JoinRaft(uuid string, req *vapi.RaftJoinRequest) tea.Cmd
+
+	// GetSealStatus returns a command to get the seal status of the Vault server,
+	// and its HA status and seal/step-down capabilities when unsealed. Responds
+	// with a [ClientMsg] containing a [ClientSealStatusMsg].
+	GetSealStatus(uuid string) tea.Cmd
+	// Unseal submits a single unseal key share. Responds with a [ClientMsg]
+	// containing a [ClientSuccessMsg], describing the unseal progress.
+	Unseal(uuid string, key string) tea.Cmd
+	// ResetUnseal discards all unseal key shares submitted so far. Responds with a
+	// [ClientMsg] containing a [ClientSuccessMsg].
+	ResetUnseal(uuid string) tea.Cmd
+	// Seal seals the Vault server (node). Responds with a [ClientMsg] containing a
+	// [ClientSuccessMsg].
+	Seal(uuid string) tea.Cmd
+	// StepDown forces the active node to step down, and give up active status.
+	// Responds with a [ClientMsg] containing a [ClientSuccessMsg].
+	StepDown(uuid string) tea.Cmd
+
+	// ListAuditDevices returns a command to list the enabled audit devices.
+	// Responds with a [ClientMsg] containing a [ClientListAuditDevicesMsg].
+	ListAuditDevices(uuid string) tea.Cmd
+	// EnableAuditDevice enables an audit device at the provided path. Responds
+	// with a [ClientMsg] containing a [ClientSuccessMsg].
+	EnableAuditDevice(uuid string, path string, options *vapi.EnableAuditOptions) tea.Cmd
+	// DisableAuditDevice disables the audit device at the provided path. Responds
+	// with a [ClientMsg] containing a [ClientSuccessMsg].
+	DisableAuditDevice(uuid string, path string) tea.Cmd
+	// AuditHash returns a command to hash input with the salt of the audit device
+	// at the provided path, the same way as sensitive values are hashed in its
+	// log. Responds with a [ClientMsg] containing a [ClientAuditHashMsg].
+	AuditHash(uuid string, path string, input string) tea.Cmd
 }
 
 // ClientMsg is a wrapper for any message relating to Vault API/client/etc responses.
@@ -115,6 +210,16 @@ type ClientConfigMsg struct {
 	Health  *vapi.HealthResponse `json:"health,omitempty"`
 }
 
+// ClientNodeHealthMsg is a message containing the health of a single node of the
+// cluster. Health is nil if the check failed, in which case Error contains the
+// reason.
+type ClientNodeHealthMsg struct {
+	Address string               `json:"address"`
+	Health  *vapi.HealthResponse `json:"health,omitempty"`
+	Latency time.Duration        `json:"latency"`
+	Error   string               `json:"error,omitempty"`
+}
+
 // ClientListMountsMsg is a message containing the list of mounts of the Vault
 // server.
 type ClientListMountsMsg struct {
@@ -195,8 +300,26 @@ type ClientGetSecretMsg struct {
 	Mount *Mount         `json:"mount"`
 	Path  string         `json:"path"`
 	Data  map[string]any `json:"data"`
+
+	// Version is the version of the secret which was returned, and CurrentVersion
+	// is the latest version of the secret. Both are only set for KVv2 mounts, and
+	// CurrentVersion should be used for check-and-set writes.
+	Version        int `json:"version,omitempty"`
+	CurrentVersion int `json:"current_version,omitempty"`
 }
 
+// ErrCheckAndSetMismatch is returned when a write to a KVv2 secret is rejected,
+// because the secret was modified since the provided check-and-set version.
+var ErrCheckAndSetMismatch = errors.New("secret was modified since it was last read")
+
+// ErrReadOnly is returned by clients in read-only mode, for any operation which
+// would modify the Vault server.
+var ErrReadOnly = errors.New("refusing to modify vault in read-only mode")
+
+// ErrOffline is returned by offline clients (e.g. while viewing a local audit
+// log), for all operations.
+var ErrOffline = errors.New("not connected to a vault server")
+
 // ClientGetKVv2MetadataMsg is a message containing the metadata of a KVv2, under
 // a given mount and path.
 type ClientGetKVv2MetadataMsg struct {
@@ -205,6 +328,40 @@ type ClientGetKVv2MetadataMsg struct {
 	Metadata *vapi.KVMetadata `json:"metadata"`
 }
 
+// KVv2MetadataInput is the input used to update the metadata of a KVv2 secret.
+// Fields which are nil are left unchanged.
+type KVv2MetadataInput struct {
+	MaxVersions        *int           `json:"max_versions,omitempty"`
+	CASRequired        *bool          `json:"cas_required,omitempty"`
+	DeleteVersionAfter *time.Duration `json:"delete_version_after,omitempty"`
+	CustomMetadata     map[string]any `json:"custom_metadata,omitempty"`
+}
+
+// ClientListKVv2MetadataMsg is a message containing the metadata of multiple
+// KVv2 secrets, under a given mount, keyed by the path of the secret. Secrets
+// whose metadata couldn't be read (e.g. due to permissions) are instead in
+// Errors, so one secret doesn't prevent showing the metadata of the others.
+type ClientListKVv2MetadataMsg struct {
+	Mount    *Mount                      `json:"mount"`
+	Metadata map[string]*vapi.KVMetadata `json:"metadata"`
+	Errors   map[string]error            `json:"-"`
+}
+
+// KVv2MountConfig is the mount-level configuration of a KVv2 mount, which acts
+// as the default for all secrets within the mount.
+type KVv2MountConfig struct {
+	MaxVersions        int           `json:"max_versions"`
+	CASRequired        bool          `json:"cas_required"`
+	DeleteVersionAfter time.Duration `json:"delete_version_after"`
+}
+
+// ClientGetKVv2MountConfigMsg is a message containing the mount-level configuration
+// of a KVv2 mount.
+type ClientGetKVv2MountConfigMsg struct {
+	Mount  *Mount           `json:"mount"`
+	Config *KVv2MountConfig `json:"config"`
+}
+
 // ClientListKVv2VersionsMsg is a message containing the versions of a KVv2
 // secret, under a given mount and path.
 type ClientListKVv2VersionsMsg struct {
@@ -224,12 +381,89 @@ type ClientGetACLPolicyMsg struct {
 	Content string `json:"content"`
 }
 
+// ClientGeneratePasswordMsg is a message containing a password generated from
+// a Vault password policy.
+type ClientGeneratePasswordMsg struct {
+	Policy   string `json:"policy"`
+	Password string `json:"password"`
+}
+
 // ClientConfigStateMsg is a message containing the configuration state of the
 // cluster.
 type ClientConfigStateMsg struct {
 	Data json.RawMessage `json:"data"`
 }
 
+// MetricKind is the kind of a telemetry metric.
+type MetricKind string
+
+const (
+	// MetricGauge is a point-in-time value (e.g. the number of leases).
+	MetricGauge MetricKind = "gauge"
+	// MetricCounter is a value which is incremented (e.g. the number of raft
+	// applies), aggregated over the telemetry interval.
+	MetricCounter MetricKind = "counter"
+	// MetricSample is a measurement (e.g. request durations in milliseconds),
+	// aggregated over the telemetry interval.
+	MetricSample MetricKind = "sample"
+)
+
+// Metric is a single telemetry metric of the Vault server. Gauges only have a
+// Value, while counters and samples are aggregated over the telemetry interval.
+type Metric struct {
+	Kind   MetricKind        `json:"kind"`
+	Name   string            `json:"name"`
+	Labels map[string]string `json:"labels,omitempty"`
+	Value  float64           `json:"value,omitempty"`
+	Count  int               `json:"count,omitempty"`
+	Rate   float64           `json:"rate,omitempty"`
+	Sum    float64           `json:"sum,omitempty"`
+	Min    float64           `json:"min,omitempty"`
+	Max    float64           `json:"max,omitempty"`
+	Mean   float64           `json:"mean,omitempty"`
+}
+
+// ID returns an identifier of the metric, which is unique among metrics of the
+// same kind, in the same format as Prometheus (e.g. `vault.token.count{auth_method="token"}`).
+func (m *Metric) ID() string {
+	if len(m.Labels) == 0 {
+		return m.Name
+	}
+
+	labels := make([]string, 0, len(m.Labels))
+	for _, k := range slices.Sorted(maps.Keys(m.Labels)) {
+		labels = append(labels, k+"="+strconv.Quote(m.Labels[k]))
+	}
+	return m.Name + "{" + strings.Join(labels, ",") + "}"
+}
+
+// PerSecond returns the number of occurrences per second over the telemetry
+// interval, for counters (i.e. the rate at which the counter was incremented)
+// and samples (i.e. the rate at which measurements were taken, such as requests
+// per second). Returns 0 for gauges.
+func (m *Metric) PerSecond() float64 {
+	switch m.Kind {
+	case MetricCounter:
+		return m.Rate
+	case MetricSample:
+		// The rate of samples is the sum of the measurements per second, so the
+		// interval is derived from it.
+		if m.Sum == 0 {
+			return 0
+		}
+		return m.Rate * float64(m.Count) / m.Sum
+	default:
+		return 0
+	}
+}
+
+// ClientMetricsMsg is a message containing the telemetry metrics of the Vault
+// server.
+type ClientMetricsMsg struct {
+	Timestamp time.Time `json:"timestamp"`
+	Metrics   []*Metric `json:"metrics"`
+}
+
 // RaftConfigPeer is one node in a Vault integrated storage raft configuration.
 type RaftConfigPeer struct {
 	NodeID          string `json:"node_id"`
@@ -244,6 +478,43 @@ type ClientRaftConfigMsg struct {
 	Peers []*RaftConfigPeer `json:"peers"`
 }
 
+// ClientRaftAutopilotStateMsg contains the integrated storage autopilot state.
+type ClientRaftAutopilotStateMsg struct {
+	State *vapi.AutopilotState `json:"state"`
+}
+
+// ClientRaftAutopilotConfigMsg contains the integrated storage autopilot
+// configuration.
+type ClientRaftAutopilotConfigMsg struct {
+	Config *vapi.AutopilotConfig `json:"config"`
+}
+
+// ClientSealStatusMsg is a message containing the seal status of the Vault
+// server.
+type ClientSealStatusMsg struct {
+	Status *vapi.SealStatusResponse `json:"status"`
+
+	// Leader is the HA status of the node, and SealCapabilities and
+	// StepDownCapabilities are the capabilities of the token on "sys/seal" and
+	// "sys/step-down". They are only available while unsealed.
+	Leader               *vapi.LeaderResponse `json:"leader,omitempty"`
+	SealCapabilities     ClientCapabilities   `json:"seal_capabilities,omitempty"`
+	StepDownCapabilities ClientCapabilities   `json:"step_down_capabilities,omitempty"`
+}
+
+// ClientListAuditDevicesMsg is a message containing the enabled audit devices,
+// sorted by path.
+type ClientListAuditDevicesMsg struct {
+	Devices []*vapi.Audit `json:"devices"`
+}
+
+// ClientAuditHashMsg is a message containing the hash of a value, as it would
+// appear in the log of the audit device at Path.
+type ClientAuditHashMsg struct {
+	Path string `json:"path"`
+	Hash string `json:"hash"`
+}
+
 // ClientCapability contains the capabilities of a given identity, meant to
 // determine the level of permissions for a given mount/path/etc.
 type ClientCapability string
@@ -530,4 +801,8 @@ func (r *TokenLookupResult) WhenExpires() time.Duration {
 
 type ClientSuccessMsg struct {
 	Message string `json:"message,omitempty"`
+
+	// Version is the version of the secret created by the operation, if any (e.g.
+	// KVv2 writes).
+	Version int `json:"version,omitempty"`
 }
@@ -24,7 +24,10 @@ type AppState interface {
 	Client() Client
 }
 
-type AppQuitMsg struct{}
+type AppQuitMsg struct {
+	// Force skips the [PageCloseGuard] of all pages.
+	Force bool
+}
 
 // AppQuit is sent when the user wants to quit the application. Don't use [tea.Quit],
 // as different state may need to be cleaned up before quitting.
@@ -84,8 +87,65 @@ func SetClipboard(content string) tea.Cmd {
 	)
 }
 
+// SetSensitiveClipboard is the same as [SetClipboard], however the previous
+// contents of the clipboard are restored (or the clipboard is cleared) after
+// [config.ClipboardSettings.ClearAfter], as long as the clipboard still contains the
+// copied content. If the native clipboard isn't available, the clipboard is
+// cleared through OSC 52 without verifying its contents. A countdown is shown in
+// the statusbar until then.
+func SetSensitiveClipboard(content string) tea.Cmd {
+	after := config.Get().Clipboard.ClearAfter
+	if after <= 0 {
+		return SetClipboard(content)
+	}
+
+	id := time.Now().UnixNano()
+	var previous string
+
+	return tea.Sequence(
+		func() tea.Msg {
+			if v, err := clipboard.ReadAll(); err == nil && v != content {
+				previous = v
+			}
+			return nil
+		},
+		SetClipboard(content),
+		CmdMsg(StatusMsg{Msg: ClipboardTimerMsg{ID: id, Expires: time.Now().Add(after)}}),
+		CmdAfterDuration(func() tea.Msg {
+			clearTimer := CmdMsg(StatusMsg{Msg: ClearClipboardTimerMsg{ID: id}})
+
+			// The native clipboard is the only way to check the current contents
+			// (OSC 52 is write-only). If it can't be read (e.g. over SSH), clear it
+			// through OSC 52 regardless, as leaving the secret in the clipboard is
+			// worse than potentially clobbering something else the user copied.
+			current, err := clipboard.ReadAll()
+			if err != nil {
+				return tea.Batch(
+					tea.SetClipboard(""),
+					clearTimer,
+					SendStatus("clipboard cleared (unverified)", Info, 1*time.Second),
+				)()
+			}
+
+			if current != content {
+				return clearTimer()
+			}
+
+			_ = clipboard.WriteAll(previous)
+
+			return tea.Batch(
+				tea.SetClipboard(previous),
+				clearTimer,
+				SendStatus("clipboard cleared", Info, 1*time.Second),
+			)()
+		}, after),
+	)
+}
+
 // OpenTempEditor opens a temporary editor for the given path template, and
-// default content.
+// default content. The temporary file is created in a private, memory-backed
+// directory where possible (see [config.SecureTempDir]), and is overwritten
+// before being removed.
 func OpenTempEditor(uuid, pathTemplate, content string, cb func(EditorResultMsg) tea.Cmd) tea.Cmd {
 	editor, err := config.ResolveEditor()
 	if err != nil {
@@ -99,18 +159,19 @@ func OpenTempEditor(uuid, pathTemplate, content string, cb func(EditorResultMsg)
 
 	sumBefore := fmt.Sprintf("%x", md5.Sum([]byte(content))) // nolint:gosec
 
-	tmpFn, err := os.CreateTemp("", config.AppName+"-"+pathTemplate)
+	tmpFn, err := config.CreateSecureTemp(pathTemplate)
 	if err != nil {
-		return SendStatus(err.Error(), Error, 2*time.Second)
+		return SendStatus(fmt.Sprintf("refusing to open editor: %v", err), Error, 3*time.Second)
 	}
 
 	l.Info("opening editor", "path", tmpFn.Name()) // nolint:sloglint
 
 	_, err = tmpFn.WriteString(content)
+	_ = tmpFn.Close()
 	if err != nil {
+		_ = config.RemoveSecureTemp(tmpFn.Name())
 		return SendStatus(err.Error(), Error, 2*time.Second)
 	}
-	_ = tmpFn.Close()
 
 	cmd := exec.CommandContext(context.Background(), editor, tmpFn.Name())
 	cmd.Stdin = os.Stdin
@@ -119,7 +180,7 @@ func OpenTempEditor(uuid, pathTemplate, content string, cb func(EditorResultMsg)
 
 	return tea.ExecProcess(cmd, func(err error) tea.Msg {
 		defer func() {
-			rerr := os.Remove(tmpFn.Name())
+			rerr := config.RemoveSecureTemp(tmpFn.Name())
 			if rerr != nil {
 				l.Error("failed to remove temp file", "error", rerr)
 			} else {
@@ -181,3 +242,61 @@ type EditorResultMsg struct {
 	// MD5SumAfter is the MD5 sum of the content after the editor operation.
 	MD5SumAfter string
 }
+
+// UndoWindow is how long an undo is offered for, after a mutation.
+const UndoWindow = 30 * time.Second
+
+// Undo describes how to revert a mutation of a secret (see [OfferUndo]).
+type Undo struct {
+	ID int64
+
+	// Description describes the mutation, e.g. "delete secret/foo".
+	Description string
+
+	// Cmd reverts the mutation. If nil, the mutation can't be undone, and Reason
+	// describes why.
+	Cmd    tea.Cmd
+	Reason string
+
+	// Expires is when the undo is no longer offered.
+	Expires time.Time
+}
+
+// Possible returns true if the mutation can be undone.
+func (u Undo) Possible() bool {
+	return u.Cmd != nil
+}
+
+// DeleteUndoHint returns a sentence for delete confirmations, describing whether
+// deleting a secret on the provided mount can be undone. KVv2 deletes are soft
+// deletes (only destroying a version is permanent), while other secrets can only
+// be restored through [OfferUndo], within [UndoWindow].
+func DeleteUndoHint(mount *Mount) string {
+	if mount.KVVersion() == 2 {
+		return "This can be undone, as deleted versions can be undeleted."
+	}
+	return fmt.Sprintf("This can only be undone within %s.", UndoWindow)
+}
+
+// Expired returns true if the undo is no longer offered.
+func (u Undo) Expired() bool {
+	return time.Now().After(u.Expires)
+}
+
+// UndoMsg is sent after a mutation, replacing any previously offered undo.
+type UndoMsg struct {
+	Undo Undo
+}
+
+// OfferUndo offers to undo a mutation with the provided description for
+// [UndoWindow], using cmd. If cmd is nil, the user is instead told that the
+// mutation can't be undone, and why.
+func OfferUndo(description string, cmd tea.Cmd, reason string) tea.Cmd {
+	return CmdMsg(UndoMsg{Undo: Undo{
+		ID:          time.Now().UnixNano(),
+		Description: description,
+		Cmd:         cmd,
+		Reason:      reason,
+		Expires:     time.Now().Add(UndoWindow),
+	}})
+}
@@ -9,6 +9,7 @@ import (
 
 	"charm.land/bubbles/v2/key"
 	tea "charm.land/bubbletea/v2"
+	"github.com/lrstanley/vex/internal/config"
 )
 
 type PageState interface {
@@ -39,6 +40,11 @@ type PageState interface {
 
 	// HasParent returns whether the page has a parent page.
 	HasParent() bool
+
+	// GuardClose checks the [PageCloseGuard] of all pages, returning the command
+	// of the first page which prevents being closed, or nil if all pages can be
+	// closed. next is invoked if the page later agrees to be closed.
+	GuardClose(next tea.Cmd) tea.Cmd
 }
 
 type Page interface {
@@ -82,6 +88,56 @@ type Page interface {
 	Close() tea.Cmd
 }
 
+// PageCloseGuard is an optional interface which can be implemented by pages
+// that need to prevent being closed, e.g. when they have unsaved changes. If
+// GuardClose returns a non-nil command, closing the page is aborted and the
+// command is invoked instead (e.g. to open a confirmation dialog). next re-issues
+// the navigation (or quit) which closed the page, skipping any guards, and should
+// be invoked if the page agrees to be closed regardless.
+type PageCloseGuard interface {
+	GuardClose(next tea.Cmd) tea.Cmd
+}
+
+// PageLocator is an optional interface which can be implemented by pages that
+// are tied to a location (e.g. a mount and path), so they can be reconstructed
+// in a later session. Pages without a location are reconstructed from their
+// command, if they have one.
+type PageLocator interface {
+	Location() config.PageLocation
+}
+
+// PageBreadcrumb is an optional interface which can be implemented by pages to
+// provide a short label for the breadcrumb bar (e.g. only the last path segment,
+// as parent pages already show the rest of the path). Defaults to the title.
+type PageBreadcrumb interface {
+	GetBreadcrumb() string
+}
+
+// PageMutator is an optional interface which can be implemented by pages that
+// have key bindings which modify the Vault server (e.g. deleting a secret), so
+// they can be hidden and disabled in read-only mode. [PageModel] implements it
+// through [PageModel.MutatingKeyBinds].
+type PageMutator interface {
+	MutatingKeys() []key.Binding
+}
+
+// MutatingKeys returns the key bindings of the page which modify the Vault server
+// (see [PageMutator]).
+func MutatingKeys(p Page) []key.Binding {
+	if m, ok := p.(PageMutator); ok {
+		return m.MutatingKeys()
+	}
+	return nil
+}
+
+// Breadcrumb returns the breadcrumb label of the page (see [PageBreadcrumb]).
+func Breadcrumb(p Page) string {
+	if b, ok := p.(PageBreadcrumb); ok {
+		return b.GetBreadcrumb()
+	}
+	return p.GetTitle()
+}
+
 type PageModel struct {
 	uuid uuid
 
@@ -90,6 +146,11 @@ type PageModel struct {
 	RefreshInterval  time.Duration
 	ShortKeyBinds    []key.Binding
 	FullKeyBinds     [][]key.Binding
+
+	// MutatingKeyBinds are the key bindings which modify the Vault server, and
+	// are hidden and disabled in read-only mode. Help bindings which share keys
+	// with these are treated the same.
+	MutatingKeyBinds []key.Binding
 }
 
 func (b *PageModel) Init() tea.Cmd {
@@ -124,6 +185,10 @@ func (b *PageModel) FullHelp() [][]key.Binding {
 	return b.FullKeyBinds
 }
 
+func (b *PageModel) MutatingKeys() []key.Binding {
+	return b.MutatingKeyBinds
+}
+
 // GetTitle is the title of the page. Defaults to the command of the page if defined,
 // otherwise the ID. Can be overridden by the page.
 func (b *PageModel) GetTitle() string {
@@ -141,25 +206,88 @@ func (b *PageModel) HasInputFocus() bool {
 	return false
 }
 
+// GetRefreshInterval returns the refresh interval of the page, taking into
+// account any overrides from the config. Pages which don't support refreshing
+// (no default interval) can't have refreshing enabled through the config.
 func (b *PageModel) GetRefreshInterval() time.Duration {
+	if b.RefreshInterval <= 0 {
+		return 0
+	}
+
+	settings := config.Get()
+	if settings.Refresh.Disabled {
+		return 0
+	}
+
+	for _, cmd := range b.Commands {
+		if v, ok := settings.Refresh.Intervals[cmd]; ok {
+			return v
+		}
+	}
 	return b.RefreshInterval
 }
 
 type OpenPageMsg struct {
 	Page Page
 	Root bool
+
+	// Force skips the [PageCloseGuard] of the pages being replaced, if Root is set.
+	Force bool
 }
 
 func OpenPage(p Page, isRoot bool) tea.Cmd {
 	return CmdMsg(OpenPageMsg{Page: p, Root: isRoot})
 }
 
-type CloseActivePageMsg struct{}
+// OpenPageStackMsg replaces all pages with the provided pages, where the first
+// page is the root page, and the last page is the active page.
+type OpenPageStackMsg struct {
+	Pages []Page
+
+	// Force skips the [PageCloseGuard] of the pages being replaced.
+	Force bool
+}
+
+// OpenPageStack replaces all pages with the provided pages (e.g. to jump directly
+// to a deeply nested page, while still allowing navigating back through its
+// parents). Only the last page is focused.
+func OpenPageStack(pages ...Page) tea.Cmd {
+	if len(pages) == 0 {
+		return nil
+	}
+	return CmdMsg(OpenPageStackMsg{Pages: pages})
+}
+
+// JumpToPageMsg closes all pages above the page with the provided UUID, making
+// it the active page.
+type JumpToPageMsg struct {
+	UUID string
+
+	// Force skips the [PageCloseGuard] of the pages being closed.
+	Force bool
+}
+
+// JumpToPage closes all pages above the page with the provided UUID (e.g. to
+// jump to an ancestor from the breadcrumb bar), making it the active page.
+func JumpToPage(uuid string) tea.Cmd {
+	return CmdMsg(JumpToPageMsg{UUID: uuid})
+}
+
+type CloseActivePageMsg struct {
+	// Force skips the [PageCloseGuard] of the page, if implemented.
+	Force bool
+}
 
 func CloseActivePage() tea.Cmd {
 	return CmdMsg(CloseActivePageMsg{})
 }
 
+// ForceCloseActivePage closes the active page, even if it implements
+// [PageCloseGuard] and would otherwise prevent being closed.
+func ForceCloseActivePage() tea.Cmd {
+	return CmdMsg(CloseActivePageMsg{Force: true})
+}
+
 // PageVisibleMsg is sent when the active page is made visible, to the active page ONLY.
 // It is not send on initial page creation, only in situations like when a child page is
 // closed, and the parent page is made visible again.
@@ -25,6 +25,18 @@ type Config struct {
 	Columns           []*table.Column[*table.StaticRow[[]string]]
 	FilterPlaceholder string
 	SelectFunc        func(id string) tea.Cmd
+
+	// HighlightFunc is optionally invoked when the highlighted row changes (e.g.
+	// when navigating or filtering), for live previews.
+	HighlightFunc func(id string) tea.Cmd
+
+	// FilterFunc optionally transforms the input value before it's used to filter
+	// the table (e.g. to only filter on the first word, when arguments are supported).
+	FilterFunc func(value string) string
+
+	// InputFunc is optionally invoked when the input value changes, e.g. to update
+	// suggestions based on the current value.
+	InputFunc func(value string) tea.Cmd
 }
 
 var _ types.Component = (*Model)(nil) // Ensure we implement the component interface.
@@ -38,6 +50,7 @@ type Model struct {
 	// UI state.
 	config        Config
 	previousInput string
+	highlighted   string
 	suggestions   []string
 	items         [][]string
 
@@ -177,6 +190,13 @@ func (m *Model) Value() string {
 	return m.input.Value()
 }
 
+// SetValue sets the input value, moving the cursor to the end of it.
+func (m *Model) SetValue(value string) tea.Cmd {
+	m.input.SetValue(value)
+	m.input.CursorEnd()
+	return tea.Batch(m.input.Focus(), m.checkInput())
+}
+
 func (m *Model) Init() tea.Cmd {
 	return m.input.Focus()
 }
@@ -199,7 +219,7 @@ func (m *Model) Update(msg tea.Msg) tea.Cmd {
 		case msg.String() == "up" || msg.String() == "down":
 			m.input.Blur()
 			// Move down in table - the table handles this internally.
-			return m.table.Update(msg)
+			return tea.Batch(m.table.Update(msg), m.checkHighlighted())
 		case msg.String() == "left" || msg.String() == "right":
 			// Move left/right in input - the input handles this internally.
 			m.input, cmd = m.input.Update(msg)
@@ -238,18 +258,57 @@ func (m *Model) Update(msg tea.Msg) tea.Cmd {
 		return cmd
 	}
 
-	// Update filter if input changed
-	if m.input.Value() != m.previousInput {
-		m.previousInput = m.input.Value()
-		m.table.SetFilter(m.input.Value())
-	}
+	cmds = append(cmds, m.checkInput())
 
 	// Always update the table to handle navigation
-	cmds = append(cmds, m.table.Update(msg))
+	cmds = append(cmds, m.table.Update(msg), m.checkHighlighted())
 
 	return tea.Batch(cmds...)
 }
 
+// checkInput updates the filter (and invokes [Config.InputFunc]) if the input
+// value has changed since it was last checked.
+func (m *Model) checkInput() tea.Cmd {
+	value := m.input.Value()
+	if value == m.previousInput {
+		return nil
+	}
+	m.previousInput = value
+
+	if m.config.FilterFunc != nil {
+		m.table.SetFilter(m.config.FilterFunc(value))
+	} else {
+		m.table.SetFilter(value)
+	}
+
+	if m.config.InputFunc != nil {
+		return m.config.InputFunc(value)
+	}
+	return nil
+}
+
+// checkHighlighted invokes [Config.HighlightFunc] if the highlighted row has
+// changed since it was last invoked.
+func (m *Model) checkHighlighted() tea.Cmd {
+	if m.config.HighlightFunc == nil {
+		return nil
+	}
+
+	row, ok := m.table.GetSelectedRow()
+	if !ok || string(row.ID()) == m.highlighted {
+		return nil
+	}
+
+	m.highlighted = string(row.ID())
+	return m.config.HighlightFunc(m.highlighted)
+}
+
+// SetHighlighted highlights the row with the provided ID, if it exists.
+func (m *Model) SetHighlighted(id string) {
+	m.highlighted = id
+	m.table.SetSelected(table.ID(id))
+}
+
 func (m *Model) View() string {
 	var out []string
 
@@ -0,0 +1,416 @@
+// Copyright (c) Liam Stanley <liam@liam.sh>. All rights reserved. Use of
+// this source code is governed by the MIT license that can be found in
+// the LICENSE file.
+
+package form
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"charm.land/bubbles/v2/key"
+	"charm.land/bubbles/v2/textinput"
+	tea "charm.land/bubbletea/v2"
+	"charm.land/lipgloss/v2"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/lrstanley/vex/internal/types"
+	"github.com/lrstanley/vex/internal/ui/components/confirmable"
+	"github.com/lrstanley/vex/internal/ui/styles"
+	"github.com/lrstanley/x/charm/formatter"
+)
+
+var (
+	_ types.Component                            = (*Model)(nil) // Ensure we implement the component interface.
+	_ confirmable.Validatable[map[string]string] = (*Model)(nil) // Ensure we implement the validatable interface.
+	_ confirmable.Focusable                      = (*Model)(nil) // Ensure we implement the focusable interface.
+)
+
+var (
+	keyNextField = key.NewBinding(
+		key.WithKeys("down", "enter"),
+		key.WithHelp("↓/enter", "next field"),
+	)
+	keyPrevField = key.NewBinding(
+		key.WithKeys("up"),
+		key.WithHelp("↑", "previous field"),
+	)
+	keyNextOption = key.NewBinding(
+		key.WithKeys("right", "space"),
+		key.WithHelp("→/space", "next option"),
+	)
+	keyPrevOption = key.NewBinding(
+		key.WithKeys("left"),
+		key.WithHelp("←", "previous option"),
+	)
+)
+
+// KeyBinds are the key bindings used by the form, which can be used to populate
+// the help of a parent dialog.
+var KeyBinds = []key.Binding{keyNextField, keyPrevField, keyNextOption}
+
+// Field is a single field within a form.
+type Field struct {
+	// ID is the identifier of the field, used as the key in the value map
+	// returned by [Model.GetValue].
+	ID string
+
+	// Label is the text shown next to the field.
+	Label string
+
+	// Placeholder is shown when the field is empty.
+	Placeholder string
+
+	// Value is the initial value of the field.
+	Value string
+
+	// Masked hides the value of the field as it is being typed.
+	Masked bool
+
+	// Options, if provided, turns the field into a selector, where the value
+	// can only be one of the provided options. Options are cycled through using
+	// left/right/space.
+	Options []string
+
+	// Validator, if provided, is invoked for the field when the form is being
+	// validated (see [Model.Validate]).
+	Validator func(value string) error
+}
+
+// Model represents the form component.
+type Model struct {
+	types.ComponentModel
+
+	// Core state.
+	app     types.AppState
+	fields  []*Field
+	focused bool
+
+	// UI state.
+	active     int
+	labelWidth int
+
+	// Styles.
+	labelStyle        lipgloss.Style
+	activeLabelStyle  lipgloss.Style
+	optionStyle       lipgloss.Style
+	activeOptionStyle lipgloss.Style
+
+	// Child components.
+	inputs []textinput.Model
+}
+
+// New creates a new form component, with the provided fields.
+func New(app types.AppState, fields ...*Field) *Model {
+	m := &Model{
+		ComponentModel: types.ComponentModel{},
+		app:            app,
+		fields:         fields,
+		inputs:         make([]textinput.Model, len(fields)),
+	}
+
+	for i, f := range fields {
+		m.labelWidth = max(m.labelWidth, ansi.StringWidth(f.Label))
+
+		if len(f.Options) > 0 && !slices.Contains(f.Options, f.Value) {
+			f.Value = f.Options[0]
+		}
+
+		m.inputs[i] = textinput.New()
+		m.inputs[i].Prompt = ""
+		m.inputs[i].Placeholder = f.Placeholder
+		m.inputs[i].SetVirtualCursor(true)
+		m.inputs[i].SetValue(f.Value)
+		m.inputs[i].KeyMap.Paste = key.NewBinding(key.WithKeys("ctrl+v", "ctrl+shift+v"))
+
+		if f.Masked {
+			m.inputs[i].EchoMode = textinput.EchoPassword
+			m.inputs[i].EchoCharacter = '*'
+		}
+	}
+
+	m.focused = true
+	m.setActive(0)
+
+	m.initStyles()
+	return m
+}
+
+func (m *Model) initStyles() {
+	m.labelStyle = lipgloss.NewStyle().
+		Foreground(styles.Theme.AppFg()).
+		Padding(0, 1)
+
+	m.activeLabelStyle = m.labelStyle.
+		Foreground(styles.Theme.InfoFg()).
+		Bold(true)
+
+	m.optionStyle = lipgloss.NewStyle().
+		Foreground(styles.Theme.InactiveButtonFg()).
+		Background(styles.Theme.InactiveButtonBg()).
+		Padding(0, 1).
+		MarginRight(1)
+
+	m.activeOptionStyle = m.optionStyle.
+		Foreground(styles.Theme.ActiveButtonFg()).
+		Background(styles.Theme.ActiveButtonBg())
+
+	var inputStyles textinput.Styles
+
+	inputStyles.Focused.Placeholder = inputStyles.Focused.Placeholder.
+		Foreground(styles.Theme.AppFg()).
+		Faint(true)
+	inputStyles.Blurred.Placeholder = inputStyles.Focused.Placeholder
+
+	inputStyles.Focused.Text = inputStyles.Focused.Text.
+		Foreground(styles.Theme.AppFg()).
+		Background(styles.Theme.AdaptAuto(styles.Theme.AppBg(), 0.15))
+	inputStyles.Blurred.Text = inputStyles.Blurred.Text.
+		Foreground(styles.Theme.AdaptAuto(styles.Theme.AppFg(), -0.15)).
+		Background(styles.Theme.AdaptAuto(styles.Theme.AppBg(), 0.05))
+
+	inputStyles.Cursor.Color = styles.Theme.AppCursor()
+	inputStyles.Cursor.Blink = true
+
+	for i := range m.inputs {
+		m.inputs[i].SetStyles(inputStyles)
+	}
+}
+
+func (m *Model) Init() tea.Cmd {
+	return m.Focus()
+}
+
+// SetHeight sets the total height of the form.
+func (m *Model) SetHeight(height int) {
+	m.SetDimensions(m.Width, height)
+}
+
+// SetWidth sets the total width of the form.
+func (m *Model) SetWidth(width int) {
+	m.SetDimensions(width, m.Height)
+}
+
+// SetDimensions sets the dimensions of the component. Use this instead of
+// [Model.SetWidth] or [Model.SetHeight] when possible to improve performance.
+func (m *Model) SetDimensions(width, height int) {
+	m.Width = width
+	m.Height = height
+
+	iw := max(0, m.Width-m.labelWidth-m.labelStyle.GetHorizontalFrameSize()-1)
+	for i := range m.inputs {
+		m.inputs[i].SetWidth(iw)
+	}
+}
+
+// Len returns the number of fields in the form, which is also the minimum height
+// required to render the form.
+func (m *Model) Len() int {
+	return len(m.fields)
+}
+
+// Focus focuses the active field of the form.
+func (m *Model) Focus() tea.Cmd {
+	m.focused = true
+	return m.setActive(m.active)
+}
+
+// Blur blurs the form.
+func (m *Model) Blur() tea.Cmd {
+	m.focused = false
+	for i := range m.inputs {
+		m.inputs[i].Blur()
+	}
+	return nil
+}
+
+// HasInputFocus returns if the component has input focus.
+func (m *Model) HasInputFocus() bool {
+	return m.focused
+}
+
+// GetValue returns the current values of the form, keyed by [Field.ID].
+func (m *Model) GetValue() map[string]string {
+	values := make(map[string]string, len(m.fields))
+	for i, f := range m.fields {
+		if len(f.Options) > 0 {
+			values[f.ID] = f.Value
+			continue
+		}
+		values[f.ID] = m.inputs[i].Value()
+	}
+	return values
+}
+
+// Validate runs the validators of all fields, returning the first error, if any.
+// The field with the error will be made active.
+func (m *Model) Validate(values map[string]string) error {
+	for i, f := range m.fields {
+		if f.Validator == nil {
+			continue
+		}
+		if err := f.Validator(values[f.ID]); err != nil {
+			m.setActive(i)
+			return fmt.Errorf("%s: %w", strings.ToLower(f.Label), err)
+		}
+	}
+	return nil
+}
+
+func (m *Model) setActive(i int) tea.Cmd {
+	if len(m.inputs) == 0 {
+		return nil
+	}
+
+	m.active = (i + len(m.inputs)) % len(m.inputs)
+
+	var cmd tea.Cmd
+	for j := range m.inputs {
+		if j == m.active && m.focused && len(m.fields[j].Options) == 0 {
+			cmd = m.inputs[j].Focus()
+			continue
+		}
+		m.inputs[j].Blur()
+	}
+	return cmd
+}
+
+func (m *Model) cycleOption(dir int) {
+	f := m.fields[m.active]
+	idx := slices.Index(f.Options, f.Value)
+	f.Value = f.Options[(idx+dir+len(f.Options))%len(f.Options)]
+}
+
+func (m *Model) Update(msg tea.Msg) tea.Cmd {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.SetDimensions(msg.Width, msg.Height)
+		return nil
+	case styles.ThemeUpdatedMsg:
+		m.initStyles()
+		return nil
+	case tea.KeyPressMsg:
+		if len(m.fields) == 0 {
+			return nil
+		}
+
+		switch {
+		case key.Matches(msg, keyNextField):
+			return m.setActive(m.active + 1)
+		case key.Matches(msg, keyPrevField):
+			return m.setActive(m.active - 1)
+		}
+
+		if len(m.fields[m.active].Options) > 0 {
+			switch {
+			case key.Matches(msg, keyNextOption):
+				m.cycleOption(1)
+			case key.Matches(msg, keyPrevOption):
+				m.cycleOption(-1)
+			}
+			return nil
+		}
+	}
+
+	if len(m.inputs) == 0 {
+		return nil
+	}
+
+	var cmd tea.Cmd
+	m.inputs[m.active], cmd = m.inputs[m.active].Update(msg)
+	return cmd
+}
+
+func (m *Model) View() string {
+	if m.Width == 0 || m.Height == 0 {
+		return ""
+	}
+
+	rows := make([]string, 0, len(m.fields))
+
+	for i, f := range m.fields {
+		label := formatter.PadMinimum(f.Label, m.labelWidth)
+		if i == m.active && m.focused {
+			label = m.activeLabelStyle.Render(label)
+		} else {
+			label = m.labelStyle.Render(label)
+		}
+
+		var value string
+		if len(f.Options) > 0 {
+			opts := make([]string, len(f.Options))
+			for j, opt := range f.Options {
+				if opt == f.Value {
+					opts[j] = m.activeOptionStyle.Render(opt)
+					continue
+				}
+				opts[j] = m.optionStyle.Render(opt)
+			}
+			value = lipgloss.JoinHorizontal(lipgloss.Top, opts...)
+		} else {
+			value = m.inputs[i].View()
+		}
+
+		rows = append(rows, lipgloss.JoinHorizontal(lipgloss.Top, label, value))
+	}
+
+	return lipgloss.NewStyle().
+		Width(m.Width).
+		Height(m.Height).
+		Render(lipgloss.JoinVertical(lipgloss.Left, rows...))
+}
+
+// ValidateInt validates that the value is an integer that is at least minValue.
+// Empty values are allowed.
+func ValidateInt(minValue int) func(string) error {
+	return func(value string) error {
+		if value == "" {
+			return nil
+		}
+		v, err := strconv.Atoi(value)
+		if err != nil {
+			return errors.New("must be a number")
+		}
+		if v < minValue {
+			return fmt.Errorf("must be at least %d", minValue)
+		}
+		return nil
+	}
+}
+
+// ValidateIntRange validates that the value is an integer between minValue and
+// maxValue (inclusive). Empty values are allowed.
+func ValidateIntRange(minValue, maxValue int) func(string) error {
+	return func(value string) error {
+		if err := ValidateInt(minValue)(value); err != nil || value == "" {
+			return err
+		}
+		if v, _ := strconv.Atoi(value); v > maxValue {
+			return fmt.Errorf("must be at most %d", maxValue)
+		}
+		return nil
+	}
+}
+
+// ValidateDuration validates that the value is a valid duration (e.g. "1h30m",
+// or "0s" to disable). Empty values are allowed.
+func ValidateDuration(value string) error {
+	if value == "" {
+		return nil
+	}
+	if _, err := time.ParseDuration(value); err != nil {
+		return errors.New("must be a duration (e.g. 1h30m)")
+	}
+	return nil
+}
+
+// ValidateRequired validates that the value is not empty.
+func ValidateRequired(value string) error {
+	if strings.TrimSpace(value) == "" {
+		return errors.New("required")
+	}
+	return nil
+}
@@ -0,0 +1,98 @@
+// Copyright (c) Liam Stanley <liam@liam.sh>. All rights reserved. Use of
+// this source code is governed by the MIT license that can be found in
+// the LICENSE file.
+
+package form
+
+import (
+	"testing"
+
+	"github.com/lrstanley/vex/internal/api"
+	"github.com/lrstanley/vex/internal/ui/state"
+	"github.com/lrstanley/x/charm/steep"
+)
+
+func testFields() []*Field {
+	return []*Field{
+		{ID: "name", Label: "Name", Value: "foo", Validator: ValidateRequired},
+		{ID: "count", Label: "Count", Value: "3", Validator: ValidateInt(1)},
+		{ID: "enabled", Label: "Enabled", Value: "true", Options: []string{"false", "true"}},
+		{ID: "ttl", Label: "TTL", Placeholder: "e.g. 1h", Validator: ValidateDuration},
+	}
+}
+
+func TestNew(t *testing.T) {
+	t.Parallel()
+	t.Run("basic-form", func(t *testing.T) {
+		t.Parallel()
+		app := state.NewMockAppState(api.NewMockClient(), nil)
+		m := New(app, testFields()...)
+		tm := steep.NewComponentHarness(t, m)
+		tm.WaitContainsStrings(t, []string{"Name", "Count", "Enabled", "TTL", "foo", "e.g. 1h"})
+	})
+
+	t.Run("masked", func(t *testing.T) {
+		t.Parallel()
+		app := state.NewMockAppState(api.NewMockClient(), nil)
+		m := New(app, &Field{ID: "secret", Label: "Secret", Value: "hunter2", Masked: true})
+		tm := steep.NewComponentHarness(t, m)
+		tm.WaitContainsString(t, "Secret")
+		tm.RequireStringNotContains(t, "hunter2")
+	})
+}
+
+func TestValues(t *testing.T) {
+	t.Parallel()
+
+	app := state.NewMockAppState(api.NewMockClient(), nil)
+	m := New(app, testFields()...)
+
+	values := m.GetValue()
+	if values["name"] != "foo" || values["count"] != "3" || values["enabled"] != "true" || values["ttl"] != "" {
+		t.Fatalf("unexpected values: %v", values)
+	}
+
+	if err := m.Validate(values); err != nil {
+		t.Fatalf("expected no validation error, got %v", err)
+	}
+
+	values["count"] = "0"
+	if err := m.Validate(values); err == nil {
+		t.Fatal("expected validation error for count")
+	}
+	if m.active != 1 {
+		t.Fatalf("expected invalid field to be active, got %d", m.active)
+	}
+
+	values["count"] = "1"
+	values["ttl"] = "soon"
+	m.Focus()
+	if err := m.Validate(values); err == nil {
+		t.Fatal("expected validation error for ttl")
+	}
+	if m.active != 3 || !m.inputs[3].Focused() || m.inputs[1].Focused() {
+		t.Fatalf("expected only the invalid field to be focused, got active %d", m.active)
+	}
+}
+
+func TestCycleOption(t *testing.T) {
+	t.Parallel()
+
+	app := state.NewMockAppState(api.NewMockClient(), nil)
+	m := New(app, &Field{ID: "mode", Label: "Mode", Options: []string{"a", "b", "c"}})
+
+	if v := m.GetValue()["mode"]; v != "a" {
+		t.Fatalf("expected default option %q, got %q", "a", v)
+	}
+
+	m.cycleOption(-1)
+	if v := m.GetValue()["mode"]; v != "c" {
+		t.Fatalf("expected option %q, got %q", "c", v)
+	}
+
+	m.cycleOption(1)
+	m.cycleOption(1)
+	if v := m.GetValue()["mode"]; v != "b" {
+		t.Fatalf("expected option %q, got %q", "b", v)
+	}
+}
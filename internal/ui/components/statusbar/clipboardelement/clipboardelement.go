@@ -0,0 +1,93 @@
+// Copyright (c) Liam Stanley <liam@liam.sh>. All rights reserved. Use of
+// this source code is governed by the MIT license that can be found in
+// the LICENSE file.
+
+package clipboardelement
+
+import (
+	"fmt"
+	"time"
+
+	tea "charm.land/bubbletea/v2"
+	"charm.land/lipgloss/v2"
+	"github.com/lrstanley/vex/internal/types"
+	"github.com/lrstanley/vex/internal/ui/styles"
+)
+
+var _ types.Component = (*Model)(nil) // Ensure we implement the component interface.
+
+type tickMsg struct {
+	id int64
+}
+
+// Model shows a countdown until sensitive content is cleared from the clipboard.
+type Model struct {
+	types.ComponentModel
+
+	// Core state.
+	app types.AppState
+
+	// UI state.
+	id      int64
+	expires time.Time
+
+	// Styles.
+	style lipgloss.Style
+}
+
+func New(app types.AppState) *Model {
+	m := &Model{
+		ComponentModel: types.ComponentModel{},
+		app:            app,
+	}
+
+	m.setStyles()
+	return m
+}
+
+func (m *Model) setStyles() {
+	fg, bg := styles.Theme.ByStatus(types.Warning)
+	m.style = lipgloss.NewStyle().
+		Padding(0, 1).
+		Foreground(fg).
+		Background(bg)
+}
+
+func (m *Model) Init() tea.Cmd {
+	return nil
+}
+
+func (m *Model) tick() tea.Cmd {
+	return types.MsgAfterDuration(tickMsg{id: m.id}, time.Second)
+}
+
+func (m *Model) Update(msg tea.Msg) tea.Cmd {
+	switch msg := msg.(type) {
+	case styles.ThemeUpdatedMsg:
+		m.setStyles()
+	case types.StatusMsg:
+		switch msg := msg.Msg.(type) {
+		case types.ClipboardTimerMsg:
+			m.id = msg.ID
+			m.expires = msg.Expires
+			return m.tick()
+		case types.ClearClipboardTimerMsg:
+			if msg.ID == m.id {
+				m.expires = time.Time{}
+			}
+		}
+	case tickMsg:
+		if msg.id == m.id && time.Until(m.expires) > 0 {
+			return m.tick()
+		}
+	}
+	return nil
+}
+
+func (m *Model) View() string {
+	remaining := time.Until(m.expires).Round(time.Second)
+	if m.expires.IsZero() || remaining <= 0 {
+		return ""
+	}
+	return m.style.Render(fmt.Sprintf("%s %s", styles.IconClipboard(), remaining))
+}
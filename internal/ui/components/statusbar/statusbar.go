@@ -12,8 +12,10 @@ import (
 	"charm.land/lipgloss/v2"
 	"github.com/charmbracelet/x/ansi"
 	"github.com/lrstanley/vex/internal/types"
+	"github.com/lrstanley/vex/internal/ui/components/statusbar/clipboardelement"
 	"github.com/lrstanley/vex/internal/ui/components/statusbar/filterelement"
 	"github.com/lrstanley/vex/internal/ui/components/statusbar/statuselement"
+	"github.com/lrstanley/vex/internal/ui/components/statusbar/undoelement"
 	"github.com/lrstanley/vex/internal/ui/components/statusbar/vaultelement"
 	"github.com/lrstanley/vex/internal/ui/styles"
 )
@@ -35,9 +37,11 @@ type Model struct {
 	logoStyle lipgloss.Style
 
 	// Child components.
-	statusEl *statuselement.Model
-	filterEl *filterelement.Model
-	vaultEl  *vaultelement.Model
+	statusEl    *statuselement.Model
+	filterEl    *filterelement.Model
+	vaultEl     *vaultelement.Model
+	clipboardEl *clipboardelement.Model
+	undoEl      *undoelement.Model
 }
 
 func New(app types.AppState) *Model {
@@ -47,6 +51,8 @@ func New(app types.AppState) *Model {
 		statusEl:       statuselement.New(app),
 		filterEl:       filterelement.New(app),
 		vaultEl:        vaultelement.New(app),
+		clipboardEl:    clipboardelement.New(app),
+		undoEl:         undoelement.New(app),
 	}
 
 	m.setStyles()
@@ -70,6 +76,8 @@ func (m *Model) Init() tea.Cmd {
 		m.statusEl.Init(),
 		m.filterEl.Init(),
 		m.vaultEl.Init(),
+		m.clipboardEl.Init(),
+		m.undoEl.Init(),
 	)
 }
 
@@ -88,7 +96,11 @@ func (m *Model) Update(msg tea.Msg) tea.Cmd {
 	case styles.ThemeUpdatedMsg:
 		m.setStyles()
 	case types.StatusMsg:
-		return m.statusEl.Update(msg)
+		return tea.Batch(
+			m.statusEl.Update(msg),
+			m.clipboardEl.Update(msg),
+			m.undoEl.Update(msg),
+		)
 	case types.AppFocusChangedMsg:
 		if msg.ID == types.FocusStatusBar {
 			m.isFiltering = true
@@ -111,6 +123,8 @@ func (m *Model) Update(msg tea.Msg) tea.Cmd {
 		m.statusEl.Update(msg),
 		m.filterEl.Update(msg),
 		m.vaultEl.Update(msg),
+		m.clipboardEl.Update(msg),
+		m.undoEl.Update(msg),
 	)...)
 }
 
@@ -131,8 +145,10 @@ func (m *Model) View() string {
 	logo := m.logoStyle.Render("vex")
 	logow := ansi.StringWidth(logo)
 
-	m.vaultEl.Width = m.Width - logow
-	vault := m.vaultEl.View()
+	timers := m.undoEl.View() + m.clipboardEl.View()
+
+	m.vaultEl.Width = m.Width - logow - ansi.StringWidth(timers)
+	vault := timers + m.vaultEl.View()
 	vaultw := ansi.StringWidth(vault)
 
 	// This allows "overlapping" of the status on top of the regular statusbar elements.
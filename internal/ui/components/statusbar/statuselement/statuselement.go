@@ -5,6 +5,7 @@
 package statuselement
 
 import (
+	"slices"
 	"strings"
 
 	"charm.land/bubbles/v2/spinner"
@@ -100,6 +101,12 @@ func (m *Model) Update(msg tea.Msg) tea.Cmd {
 			}
 		}
 	case types.StatusOperationMsg:
+		// Operations with the same ID replace the text of the existing operation
+		// (e.g. to report progress).
+		if i := slices.IndexFunc(m.operations, func(op types.StatusOperationMsg) bool { return op.ID == msg.ID }); i >= 0 {
+			m.operations[i] = msg
+			return nil
+		}
 		m.operations = append(m.operations, msg)
 		cmds = append(cmds, m.spinner.Tick)
 	case types.ClearStatusOperationMsg:
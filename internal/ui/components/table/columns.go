@@ -105,3 +105,14 @@ func (m *Model[T]) ToggleColumn(id ID, enabled bool) {
 	m.sanitizeHighlighted()
 	m.updateCalculations()
 }
+
+// SetColumns replaces the columns of the table, e.g. for columns which are
+// derived from the data itself. Panics if the columns are invalid.
+func (m *Model[T]) SetColumns(columns []*Column[T]) {
+	m.config.Columns = columns
+	m.validateColumns()
+	m.applyFiltering()
+	m.sanitizeHighlighted()
+	m.updateCalculations()
+	m.setXOffset(m.xoffset)
+}
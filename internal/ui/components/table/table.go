@@ -23,7 +23,7 @@ import (
 type ID string
 
 type Config[T Row] struct {
-	Columns            []*Column[T]          // Columns to display in the table. Should not be modified after initialization.
+	Columns            []*Column[T]          // Columns to display in the table. Use [Model.SetColumns] to modify after initialization.
 	SelectFn           func(value T) tea.Cmd // Function to call when a row is selected.
 	FetchFn            func() tea.Cmd        // Function to call when fetching data.
 	NoResultsMsg       string                // Message to display when no results are found.
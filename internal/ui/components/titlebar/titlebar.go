@@ -16,7 +16,20 @@ import (
 	"github.com/lrstanley/x/charm/formatter"
 )
 
-const MaxTitleWidth = 50
+const (
+	MaxTitleWidth      = 50
+	MaxBreadcrumbWidth = 80
+)
+
+// breadcrumbSeparator separates breadcrumb segments.
+const breadcrumbSeparator = " " + styles.IconBreadcrumbSeparator + " "
+
+// crumb is a rendered breadcrumb segment, and the cells it occupies, used to
+// map mouse clicks back to pages.
+type crumb struct {
+	uuid       string
+	start, end int // Cell columns, end exclusive.
+}
 
 var _ types.Component = (*Model)(nil) // Ensure we implement the component interface.
 
@@ -26,8 +39,14 @@ type Model struct {
 	// Core state.
 	app types.AppState
 
+	// UI state.
+	crumbs []crumb
+
 	// Styles.
-	baseStyle lipgloss.Style
+	baseStyle        lipgloss.Style
+	crumbStyle       lipgloss.Style
+	crumbActiveStyle lipgloss.Style
+	readOnlyStyle    lipgloss.Style
 
 	// Child components.
 	help *shorthelp.Model
@@ -48,6 +67,17 @@ func New(app types.AppState) *Model {
 func (m *Model) setStyles() {
 	m.baseStyle = lipgloss.NewStyle().
 		Foreground(styles.Theme.BarFg())
+	m.crumbStyle = lipgloss.NewStyle().
+		Foreground(lipgloss.Darken(styles.Theme.TitleFg(), 0.3))
+	m.crumbActiveStyle = lipgloss.NewStyle().
+		Foreground(styles.Theme.TitleFg()).
+		Bold(true)
+	m.readOnlyStyle = lipgloss.NewStyle().
+		Foreground(styles.Theme.ErrorFg()).
+		Background(styles.Theme.ErrorBg()).
+		Bold(true).
+		Padding(0, 1).
+		MarginLeft(1)
 
 	helpStyles := shorthelp.Styles{}
 	helpStyles.Base = helpStyles.Base.
@@ -80,6 +110,15 @@ func (m *Model) Update(msg tea.Msg) tea.Cmd {
 		m.setStyles()
 	case types.AppFocusChangedMsg:
 		m.updateKeyBinds()
+	case tea.MouseClickMsg:
+		if msg.Button != tea.MouseLeft || msg.Y != 0 || m.app.Dialog().Len(false) > 0 {
+			break
+		}
+		for i, c := range m.crumbs {
+			if msg.X >= c.start && msg.X < c.end && i < len(m.crumbs)-1 {
+				return types.JumpToPage(c.uuid)
+			}
+		}
 	}
 
 	return tea.Batch(append(
@@ -92,6 +131,85 @@ func (m *Model) updateKeyBinds() {
 	m.help.SetKeyBinds(m.app.Page().ShortHelp()...)
 }
 
+// fitBreadcrumbs returns the indexes of the labels which fit within the provided
+// width, where -1 is an ellipsis. Ancestors after the root are collapsed first,
+// and if nothing else fits, only the last (active) label is returned.
+func fitBreadcrumbs(labels []string, width int) []int {
+	fits := func(indexes []int) bool {
+		w := ansi.StringWidth(breadcrumbSeparator) * (len(indexes) - 1)
+		for _, i := range indexes {
+			if i == -1 {
+				w += ansi.StringWidth(styles.IconEllipsis)
+			} else {
+				w += ansi.StringWidth(labels[i])
+			}
+		}
+		return w <= width
+	}
+
+	indexes := make([]int, len(labels))
+	for i := range labels {
+		indexes[i] = i
+	}
+	if fits(indexes) {
+		return indexes
+	}
+
+	for drop := 2; drop < len(labels); drop++ {
+		candidate := append([]int{0, -1}, indexes[drop:]...)
+		if fits(candidate) {
+			return candidate
+		}
+	}
+	return []int{len(labels) - 1}
+}
+
+// breadcrumbs renders the title, as breadcrumbs of the page stack if the active
+// page has parents, updating the click zones of each segment. offset is the
+// number of cells rendered before the title.
+func (m *Model) breadcrumbs(offset int) string {
+	m.crumbs = m.crumbs[:0]
+
+	pages := m.app.Page().All()
+	if len(pages) < 2 {
+		return " " + formatter.TruncMaybePath(m.app.Page().Get().GetTitle(), min(MaxTitleWidth, max(1, m.Width/2)))
+	}
+
+	width := min(MaxBreadcrumbWidth, max(1, m.Width/2))
+
+	labels := make([]string, len(pages))
+	for i, p := range pages {
+		labels[i] = types.Breadcrumb(p)
+	}
+
+	out := " "
+	x := offset + 1 // Leading space.
+	for i, idx := range fitBreadcrumbs(labels, width) {
+		if i > 0 {
+			out += m.crumbStyle.Render(breadcrumbSeparator)
+			x += ansi.StringWidth(breadcrumbSeparator)
+		}
+
+		if idx == -1 {
+			out += m.crumbStyle.Render(styles.IconEllipsis)
+			x += ansi.StringWidth(styles.IconEllipsis)
+			continue
+		}
+
+		label := formatter.TruncMaybePath(labels[idx], width)
+		style := m.crumbStyle
+		if idx == len(pages)-1 {
+			style = m.crumbActiveStyle
+		}
+		out += style.Render(label)
+
+		w := ansi.StringWidth(label)
+		m.crumbs = append(m.crumbs, crumb{uuid: pages[idx].UUID(), start: x, end: x + w})
+		x += w
+	}
+	return out
+}
+
 func (m *Model) View() string {
 	if m.Height == 0 || m.Width == 0 {
 		return ""
@@ -99,8 +217,12 @@ func (m *Model) View() string {
 
 	m.help.SetMaxWidth(m.Width)
 
-	var title string
-	titleText := " " + formatter.TruncMaybePath(m.app.Page().Get().GetTitle(), min(MaxTitleWidth, max(1, m.Width/2)))
+	var title, badge string
+	if m.app.Client().ReadOnly() {
+		badge = m.readOnlyStyle.Render("READ-ONLY")
+	}
+
+	titleText := badge + m.breadcrumbs(ansi.StringWidth(badge))
 	titlew := ansi.StringWidth(titleText)
 
 	if hw := ansi.StringWidth(m.help.View()); m.Width-titlew-hw > 3 {
@@ -5,6 +5,7 @@
 package titlebar
 
 import (
+	"slices"
 	"testing"
 
 	"github.com/lrstanley/vex/internal/api"
@@ -40,3 +41,32 @@ func TestNew(t *testing.T) {
 		tm.RequireSnapshotNoANSI(t)
 	})
 }
+
+func TestFitBreadcrumbs(t *testing.T) {
+	t.Parallel()
+
+	labels := []string{"mounts", "secret/", "app/", "db-creds", "v7"}
+
+	tests := []struct {
+		name  string
+		width int
+		want  []int
+	}{
+		{name: "fits", width: 80, want: []int{0, 1, 2, 3, 4}},
+		{name: "exact", width: 39, want: []int{0, 1, 2, 3, 4}},
+		{name: "collapse-one", width: 38, want: []int{0, -1, 2, 3, 4}},
+		{name: "collapse-one-min", width: 33, want: []int{0, -1, 2, 3, 4}},
+		{name: "collapse-two", width: 32, want: []int{0, -1, 3, 4}},
+		{name: "collapse-all", width: 25, want: []int{0, -1, 4}},
+		{name: "active-only", width: 14, want: []int{4}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if got := fitBreadcrumbs(labels, tt.width); !slices.Equal(got, tt.want) {
+				t.Errorf("fitBreadcrumbs(%d) = %v, want %v", tt.width, got, tt.want)
+			}
+		})
+	}
+}
@@ -5,17 +5,29 @@
 package commander
 
 import (
+	"fmt"
 	"slices"
 	"strings"
+	"time"
 
 	tea "charm.land/bubbletea/v2"
 	"charm.land/lipgloss/v2"
+	"github.com/lrstanley/vex/internal/config"
 	"github.com/lrstanley/vex/internal/types"
 	"github.com/lrstanley/vex/internal/ui/components/dialogselector"
 	"github.com/lrstanley/vex/internal/ui/components/table"
+	"github.com/lrstanley/vex/internal/ui/navigator"
 	"github.com/lrstanley/vex/internal/ui/styles"
 )
 
+// gotoCommand is a built-in command which jumps directly to a secret path, e.g.
+// "goto secret/foo/bar@2", constructing the page stack as if the user had
+// navigated there manually.
+const gotoCommand = "goto"
+
+// maxRecentPaths is the maximum number of recent paths shown.
+const maxRecentPaths = 10
+
 var columns = []*table.Column[*table.StaticRow[[]string]]{
 	{ID: "active", Title: "", MinWidth: 1},
 	{ID: "command", Title: "Command"},
@@ -43,11 +55,16 @@ type Model struct {
 	app    types.AppState
 	config Config
 
+	// UI state.
+	recent      []config.RecentPath
+	pendingGoto string // Goto target requested before mounts were loaded.
+
 	// Styles.
 	activeStyle lipgloss.Style
 
 	// Child components.
-	selector *dialogselector.Model
+	selector  *dialogselector.Model
+	completer *navigator.Completer
 }
 
 func New(app types.AppState, config Config) *Model {
@@ -65,13 +82,25 @@ func New(app types.AppState, config Config) *Model {
 			Size:            types.DialogSizeMedium,
 			DisableChildren: true,
 		},
-		app:    app,
-		config: config,
+		app:       app,
+		config:    config,
+		completer: navigator.NewCompleter(app),
 	}
 
 	m.selector = dialogselector.New(app, dialogselector.Config{
-		Columns: columns,
+		Columns:    columns,
+		FilterFunc: commandName,
+		InputFunc:  m.complete,
 		SelectFunc: func(cmd string) tea.Cmd {
+			if commandName(cmd) == gotoCommand {
+				// Recent paths use the goto command, with the path as the argument.
+				target := commandArgs(cmd)
+				if target == "" {
+					target = commandArgs(m.selector.Value())
+				}
+				return m.gotoTarget(target)
+			}
+
 			var ref PageRef
 			for _, p := range m.config.Pages {
 				if slices.Contains(p.Commands, cmd) {
@@ -112,15 +141,95 @@ func (m *Model) IsCoreDialog() bool {
 	return true
 }
 
-func (m *Model) setData() {
-	var suggestions []string
+// commandName returns the command name (first word) of the input value.
+func commandName(value string) string {
+	name, _, _ := strings.Cut(strings.TrimLeft(value, " "), " ")
+	return name
+}
+
+// commandArgs returns the arguments (everything after the first word) of the
+// input value.
+func commandArgs(value string) string {
+	_, args, _ := strings.Cut(strings.TrimLeft(value, " "), " ")
+	return strings.TrimSpace(args)
+}
+
+// suggestions returns the command suggestions, including path completions for
+// goto, if the input is a goto command.
+func (m *Model) suggestions() (suggestions []string, cmd tea.Cmd) {
+	suggestions = append(suggestions, gotoCommand+" ")
 	for _, ref := range m.config.Pages {
 		suggestions = append(suggestions, ref.Commands...)
 	}
+
+	value := m.selector.Value()
+	if commandName(value) != gotoCommand || !strings.Contains(value, " ") {
+		return suggestions, nil
+	}
+
+	// Recent paths are suggested first, as they're the most likely targets.
+	candidates := make([]string, 0, len(m.recent))
+	for _, r := range m.recent {
+		candidates = append(candidates, r.Path)
+	}
+
+	var completions []string
+	completions, cmd = m.completer.Complete(commandArgs(value))
+	for _, c := range completions {
+		if !slices.Contains(candidates, c) {
+			candidates = append(candidates, c)
+		}
+	}
+
+	for _, c := range candidates {
+		suggestions = append(suggestions, gotoCommand+" "+c)
+	}
+	return suggestions, cmd
+}
+
+// complete updates the suggestions based on the current input value.
+func (m *Model) complete(_ string) tea.Cmd {
+	suggestions, cmd := m.suggestions()
+	m.selector.SetSuggestions(suggestions)
+	return cmd
+}
+
+// gotoTarget opens the page stack for the provided target. If no target was
+// provided, the input is prefilled with the goto command so the user can type one.
+func (m *Model) gotoTarget(target string) tea.Cmd {
+	if target == "" {
+		return m.selector.SetValue(gotoCommand + " ")
+	}
+
+	if !m.completer.Loaded() {
+		m.pendingGoto = target
+		return nil
+	}
+
+	if m.completer.IsFolder(target) {
+		target = strings.TrimSuffix(target, "/") + "/"
+	}
+
+	return tea.Sequence(
+		types.CloseActiveDialog(),
+		navigator.Open(m.app, m.completer.Mounts(), target),
+	)
+}
+
+func (m *Model) setData() {
+	m.recent = config.RecentPaths(m.app.Client().Profile(), maxRecentPaths)
+
+	suggestions, _ := m.suggestions()
 	m.selector.SetSuggestions(suggestions)
 
 	currentPageCmds := m.app.Page().Get().GetCommands()
-	var rows [][]string
+	rows := [][]string{{
+		gotoCommand, // ID.
+		"",
+		gotoCommand,
+		"",
+		"jump to a path, e.g. goto secret/foo/bar@2",
+	}}
 	for _, ref := range m.config.Pages {
 		var isCurrent bool
 		for _, cmd := range ref.Commands {
@@ -142,11 +251,21 @@ func (m *Model) setData() {
 		})
 	}
 
+	for _, r := range m.recent {
+		rows = append(rows, []string{
+			gotoCommand + " " + r.Path, // ID.
+			"",
+			gotoCommand,
+			"",
+			fmt.Sprintf("%s (recent, %s)", r.Path, styles.Pluralize(r.Visits, "visit", "visits")),
+		})
+	}
+
 	m.selector.SetItems(rows)
 }
 
 func (m *Model) Init() tea.Cmd {
-	return m.selector.Init()
+	return tea.Batch(m.selector.Init(), m.completer.Init())
 }
 
 func (m *Model) Update(msg tea.Msg) tea.Cmd {
@@ -165,6 +284,21 @@ func (m *Model) Update(msg tea.Msg) tea.Cmd {
 	case tea.PasteStartMsg, tea.PasteMsg, tea.PasteEndMsg:
 		cmds = append(cmds, m.selector.Update(msg))
 		return tea.Batch(cmds...)
+	case types.ClientMsg:
+		ok, err := m.completer.Update(msg)
+		if !ok {
+			return nil
+		}
+
+		if m.pendingGoto != "" && m.completer.Loaded() {
+			target := m.pendingGoto
+			m.pendingGoto = ""
+			if err != nil {
+				return types.SendStatus("unable to list mounts: "+err.Error(), types.Error, 3*time.Second)
+			}
+			return m.gotoTarget(target)
+		}
+		return m.complete(m.selector.Value())
 	}
 
 	return tea.Batch(append(cmds, m.selector.Update(msg))...)
@@ -40,7 +40,7 @@ func newMockApp() types.AppState {
 		"current content",
 		"text",
 	)
-	app.SetPage(state.NewPageState(initial))
+	app.SetPage(state.NewPageState(app.Client(), initial))
 	return app
 }
 
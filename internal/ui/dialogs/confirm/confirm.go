@@ -5,14 +5,17 @@
 package confirm
 
 import (
+	"fmt"
 	"strings"
 
 	"charm.land/bubbles/v2/key"
 	tea "charm.land/bubbletea/v2"
 	"charm.land/lipgloss/v2"
 	"github.com/charmbracelet/x/ansi"
+	"github.com/lrstanley/vex/internal/config"
 	"github.com/lrstanley/vex/internal/types"
 	"github.com/lrstanley/vex/internal/ui/components/confirmable"
+	"github.com/lrstanley/vex/internal/ui/components/form"
 	"github.com/lrstanley/vex/internal/ui/styles"
 )
 
@@ -25,6 +28,22 @@ func (m *confirmableModel) View() string {
 	return m.model.messageStyle.Width(m.model.Width).Render(m.model.config.Message)
 }
 
+// typedModel is used instead of [confirmableModel] when typed confirmation is
+// required, showing a field below the message which must match
+// [Config.TypedConfirmation].
+type typedModel struct {
+	*form.Model
+	model *Model
+}
+
+func (m *typedModel) View() string {
+	return lipgloss.JoinVertical(
+		lipgloss.Left,
+		m.model.messageStyle.Width(m.model.Width).Render(m.model.config.Message),
+		m.Model.View(),
+	)
+}
+
 type Config struct {
 	// Title is the title of the dialog.
 	Title string
@@ -52,6 +71,16 @@ type Config struct {
 
 	// ConfirmFn is the function to call when the confirm button is pressed.
 	ConfirmFn func() tea.Cmd
+
+	// TypedConfirmation, if provided, is the text (e.g. the path of the secret
+	// being destroyed) which must be typed to confirm, when typed confirmation is
+	// enabled for the current profile (see [config.ProfileSettings]). Should be
+	// provided for all destructive actions.
+	TypedConfirmation string
+
+	// AlwaysTyped requires typed confirmation regardless of the profile settings,
+	// for actions which can't be undone in any way (e.g. restoring a snapshot).
+	AlwaysTyped bool
 }
 
 var _ types.Dialog = (*Model)(nil) // Ensure we implement the dialog interface.
@@ -63,22 +92,31 @@ type Model struct {
 	app    types.AppState
 	config Config
 
+	// UI state.
+	typed bool
+
 	// Styles.
 	messageStyle lipgloss.Style
 
 	// Child components.
-	confirmable *confirmable.Model[*confirmableModel, struct{}]
+	confirmable *confirmable.Model[confirmable.Wrapped, map[string]string]
 }
 
-func New(app types.AppState, config Config) *Model {
-	cc := confirmable.Config[struct{}]{
-		CancelText:    config.CancelText,
-		CancelFn:      config.CancelFn,
-		ConfirmText:   config.ConfirmText,
-		ConfirmStatus: config.ConfirmStatus,
-		ConfirmFn: func(_ struct{}) tea.Cmd {
-			if config.ConfirmFn != nil {
-				return config.ConfirmFn()
+func New(app types.AppState, cfg Config) *Model {
+	cc := confirmable.Config[map[string]string]{
+		CancelText:    cfg.CancelText,
+		CancelFn:      cfg.CancelFn,
+		ConfirmText:   cfg.ConfirmText,
+		ConfirmStatus: cfg.ConfirmStatus,
+		ConfirmFn: func(_ map[string]string) tea.Cmd {
+			if cfg.ConfirmFn != nil {
+				return cfg.ConfirmFn()
+			}
+			return nil
+		},
+		Validator: func(values map[string]string) error {
+			if values["confirm"] != cfg.TypedConfirmation {
+				return fmt.Errorf("type %q to confirm", cfg.TypedConfirmation)
 			}
 			return nil
 		},
@@ -91,11 +129,23 @@ func New(app types.AppState, config Config) *Model {
 			ShortKeyBinds:   []key.Binding{types.OverrideHelp(types.KeySelectItem, "confirm")},
 		},
 		app:    app,
-		config: config,
+		config: cfg,
+		typed: cfg.TypedConfirmation != "" &&
+			(cfg.AlwaysTyped || config.Get().Profile(app.Client().Profile()).TypedConfirmation),
 	}
 
-	actualModel := &confirmableModel{model: m}
-	m.confirmable = confirmable.New(app, actualModel, cc)
+	if m.typed {
+		m.confirmable = confirmable.New[confirmable.Wrapped](app, &typedModel{
+			Model: form.New(app, &form.Field{
+				ID:          "confirm",
+				Label:       "Confirm",
+				Placeholder: cfg.TypedConfirmation,
+			}),
+			model: m,
+		}, cc)
+	} else {
+		m.confirmable = confirmable.New[confirmable.Wrapped](app, &confirmableModel{model: m}, cc)
+	}
 
 	m.initStyles()
 	return m
@@ -116,6 +166,9 @@ func (m *Model) HasInputFocus() bool {
 }
 
 func (m *Model) Init() tea.Cmd {
+	if m.typed {
+		return m.confirmable.Init()
+	}
 	return nil
 }
 
@@ -130,6 +183,9 @@ func (m *Model) Update(msg tea.Msg) tea.Cmd {
 		)
 
 		m.Height = mh + m.messageStyle.GetVerticalFrameSize() + 1 // +1 for the button.
+		if m.typed {
+			m.Height++ // +1 for the confirmation field.
+		}
 		m.confirmable.SetDimensions(m.Width, m.Height)
 		return nil
 	case styles.ThemeUpdatedMsg:
@@ -0,0 +1,142 @@
+// Copyright (c) Liam Stanley <liam@liam.sh>. All rights reserved. Use of
+// this source code is governed by the MIT license that can be found in
+// the LICENSE file.
+
+package form
+
+import (
+	"charm.land/bubbles/v2/key"
+	tea "charm.land/bubbletea/v2"
+	"github.com/lrstanley/vex/internal/types"
+	"github.com/lrstanley/vex/internal/ui/components/confirmable"
+	"github.com/lrstanley/vex/internal/ui/components/form"
+	"github.com/lrstanley/vex/internal/ui/styles"
+)
+
+// Config holds the configuration for the form dialog.
+type Config struct {
+	// Title is the title of the dialog.
+	Title string
+
+	// Fields are the fields of the form.
+	Fields []*form.Field
+
+	// ConfirmText is the text of the confirm button. Defaults to "save".
+	ConfirmText string
+
+	// ConfirmStatus is the status of the confirm button. If not specified, will
+	// default to active button colors based on the theme.
+	ConfirmStatus types.Status
+
+	// ConfirmFn is called with the values of the form (keyed by [form.Field.ID])
+	// when the confirm button is pressed, and all fields pass validation. The
+	// dialog is closed afterwards.
+	ConfirmFn func(values map[string]string) tea.Cmd
+
+	// Validator, if provided, is called after all field validators have passed,
+	// for validation which spans multiple fields.
+	Validator func(values map[string]string) error
+}
+
+var _ types.Dialog = (*Model)(nil) // Ensure we implement the dialog interface.
+
+// Model represents the form dialog.
+type Model struct {
+	*types.DialogModel
+
+	// Core state.
+	app    types.AppState
+	config Config
+
+	// Child components.
+	form *confirmable.Model[*form.Model, map[string]string]
+}
+
+// New creates a new form dialog.
+func New(app types.AppState, config Config) *Model {
+	if config.ConfirmText == "" {
+		config.ConfirmText = "save"
+	}
+
+	m := &Model{
+		DialogModel: &types.DialogModel{
+			Size:            types.DialogSizeMedium,
+			DisableChildren: true,
+			ShortKeyBinds:   form.KeyBinds,
+			FullKeyBinds:    [][]key.Binding{form.KeyBinds},
+		},
+		app:    app,
+		config: config,
+	}
+
+	wrapped := form.New(app, config.Fields...)
+
+	m.form = confirmable.New(app, wrapped, confirmable.Config[map[string]string]{
+		ConfirmText:   config.ConfirmText,
+		ConfirmStatus: config.ConfirmStatus,
+		CancelFn:      types.CloseActiveDialog,
+		ConfirmFn: func(values map[string]string) tea.Cmd {
+			if config.ConfirmFn != nil {
+				return tea.Sequence(
+					types.CloseActiveDialog(),
+					config.ConfirmFn(values),
+				)
+			}
+			return types.CloseActiveDialog()
+		},
+		Validator: func(values map[string]string) error {
+			if err := wrapped.Validate(values); err != nil {
+				return err
+			}
+			if config.Validator != nil {
+				return config.Validator(values)
+			}
+			return nil
+		},
+	})
+
+	return m
+}
+
+func (m *Model) GetTitle() string {
+	return m.config.Title
+}
+
+func (m *Model) HasInputFocus() bool {
+	return true
+}
+
+func (m *Model) Init() tea.Cmd {
+	return m.form.Init()
+}
+
+func (m *Model) Update(msg tea.Msg) tea.Cmd {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.Width = msg.Width
+		m.Height = min(msg.Height, m.form.Wrapped.Len()+2) // +2 for spacing and buttons.
+		m.form.SetDimensions(m.Width, m.Height)
+		return nil
+	case styles.ThemeUpdatedMsg:
+		return m.form.Update(msg)
+	case tea.KeyMsg:
+		switch {
+		case key.Matches(msg, types.KeyQuit):
+			return types.AppQuit()
+		case key.Matches(msg, types.KeyCancel):
+			switch m.form.FocusedElement() { //nolint:exhaustive
+			case confirmable.FocusCancel, confirmable.FocusNone:
+				return types.CloseActiveDialog()
+			}
+		}
+	}
+
+	return m.form.Update(msg)
+}
+
+func (m *Model) View() string {
+	if m.Width == 0 || m.Height == 0 {
+		return ""
+	}
+	return m.form.View()
+}
@@ -0,0 +1,175 @@
+// Copyright (c) Liam Stanley <liam@liam.sh>. All rights reserved. Use of
+// this source code is governed by the MIT license that can be found in
+// the LICENSE file.
+
+package generator
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// Kind is the kind of value to generate.
+type Kind string
+
+const (
+	KindPassword Kind = "password"
+	KindHex      Kind = "hex"
+	KindBase64   Kind = "base64"
+	KindUUID     Kind = "uuid"
+	KindPolicy   Kind = "policy" // Generated by Vault, using a password policy.
+)
+
+// Kinds are all kinds of values which can be generated, in the order they are
+// presented.
+var Kinds = []string{
+	string(KindPassword),
+	string(KindHex),
+	string(KindBase64),
+	string(KindUUID),
+	string(KindPolicy),
+}
+
+// MaxLength is the maximum length of a generated value, so that generating it
+// (which happens in the UI loop) stays fast.
+const MaxLength = 1024
+
+const (
+	lower   = "abcdefghijklmnopqrstuvwxyz"
+	upper   = "ABCDEFGHIJKLMNOPQRSTUVWXYZ"
+	digits  = "0123456789"
+	symbols = "!@#$%^&*()-_=+[]{}<>?,.:;~"
+)
+
+// Charsets are the named character sets which can be used for passwords.
+var Charsets = map[string]string{
+	"all":          lower + upper + digits + symbols,
+	"alphanumeric": lower + upper + digits,
+	"letters":      lower + upper,
+	"lower+digits": lower + digits,
+	"digits":       digits,
+}
+
+// CharsetNames are the names of [Charsets], in the order they are presented.
+var CharsetNames = []string{"all", "alphanumeric", "letters", "lower+digits", "digits"}
+
+// Options are the options used when generating a value locally.
+type Options struct {
+	Kind Kind
+
+	// Length is the number of characters for passwords, and the number of random
+	// bytes for hex and base64 tokens. Ignored for UUIDs.
+	Length int
+
+	// Charset is the name of the character set (see [Charsets]) for passwords.
+	Charset string
+
+	// Exclude are characters to exclude from the character set, e.g. ambiguous
+	// characters like "0O1lI".
+	Exclude string
+}
+
+// alphabet returns the characters which passwords are generated from.
+func (o Options) alphabet() (string, error) {
+	charset, ok := Charsets[o.Charset]
+	if !ok {
+		return "", fmt.Errorf("unknown charset: %q", o.Charset)
+	}
+
+	alphabet := strings.Map(func(r rune) rune {
+		if strings.ContainsRune(o.Exclude, r) {
+			return -1
+		}
+		return r
+	}, charset)
+
+	if alphabet == "" {
+		return "", errors.New("all characters of the charset are excluded")
+	}
+	return alphabet, nil
+}
+
+// Validate validates the options, without generating a value.
+func (o Options) Validate() error {
+	switch o.Kind {
+	case KindPassword:
+		if _, err := o.alphabet(); err != nil {
+			return err
+		}
+		fallthrough
+	case KindHex, KindBase64:
+		if o.Length < 1 {
+			return errors.New("length must be at least 1")
+		}
+		if o.Length > MaxLength {
+			return fmt.Errorf("length must be at most %d", MaxLength)
+		}
+	case KindUUID:
+	default:
+		return fmt.Errorf("cannot generate %q locally", o.Kind)
+	}
+	return nil
+}
+
+// Generate generates a value using a cryptographically secure source of
+// randomness.
+func Generate(o Options) (string, error) {
+	if err := o.Validate(); err != nil {
+		return "", err
+	}
+
+	switch o.Kind {
+	case KindPassword:
+		alphabet, _ := o.alphabet() // Already validated.
+		return password(alphabet, o.Length)
+	case KindHex:
+		b, err := randomBytes(o.Length)
+		return hex.EncodeToString(b), err
+	case KindBase64:
+		b, err := randomBytes(o.Length)
+		return base64.StdEncoding.EncodeToString(b), err
+	default: // KindUUID.
+		return uuid()
+	}
+}
+
+func randomBytes(n int) ([]byte, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return nil, fmt.Errorf("generate: %w", err)
+	}
+	return b, nil
+}
+
+// password generates a password of the provided length, where each character is
+// uniformly chosen from the alphabet.
+func password(alphabet string, length int) (string, error) {
+	chars := []rune(alphabet)
+	upperBound := big.NewInt(int64(len(chars)))
+
+	var sb strings.Builder
+	for range length {
+		n, err := rand.Int(rand.Reader, upperBound)
+		if err != nil {
+			return "", fmt.Errorf("generate: %w", err)
+		}
+		sb.WriteRune(chars[n.Int64()])
+	}
+	return sb.String(), nil
+}
+
+// uuid generates a random (version 4) UUID.
+func uuid() (string, error) {
+	b, err := randomBytes(16)
+	if err != nil {
+		return "", err
+	}
+	b[6] = (b[6] & 0x0f) | 0x40 // Version 4.
+	b[8] = (b[8] & 0x3f) | 0x80 // Variant 10.
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16]), nil
+}
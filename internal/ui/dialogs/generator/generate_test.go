@@ -0,0 +1,93 @@
+// Copyright (c) Liam Stanley <liam@liam.sh>. All rights reserved. Use of
+// this source code is governed by the MIT license that can be found in
+// the LICENSE file.
+
+package generator
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestGenerate(t *testing.T) {
+	t.Parallel()
+
+	t.Run("password", func(t *testing.T) {
+		t.Parallel()
+
+		v, err := Generate(Options{Kind: KindPassword, Length: 64, Charset: "lower+digits", Exclude: "0o1l"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(v) != 64 {
+			t.Fatalf("expected length 64, got %d", len(v))
+		}
+		if strings.ContainsAny(v, "0o1lABC!") {
+			t.Fatalf("password contains excluded characters: %q", v)
+		}
+	})
+
+	t.Run("hex", func(t *testing.T) {
+		t.Parallel()
+
+		v, err := Generate(Options{Kind: KindHex, Length: 16})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if b, err := hex.DecodeString(v); err != nil || len(b) != 16 {
+			t.Fatalf("expected 16 hex encoded bytes, got %q", v)
+		}
+	})
+
+	t.Run("base64", func(t *testing.T) {
+		t.Parallel()
+
+		v, err := Generate(Options{Kind: KindBase64, Length: 24})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if b, err := base64.StdEncoding.DecodeString(v); err != nil || len(b) != 24 {
+			t.Fatalf("expected 24 base64 encoded bytes, got %q", v)
+		}
+	})
+
+	t.Run("uuid", func(t *testing.T) {
+		t.Parallel()
+
+		v, err := Generate(Options{Kind: KindUUID})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`).MatchString(v) {
+			t.Fatalf("invalid uuid: %q", v)
+		}
+	})
+}
+
+func TestOptionsValidate(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		opts Options
+	}{
+		{name: "zero-length", opts: Options{Kind: KindPassword, Length: 0, Charset: "all"}},
+		{name: "too-long", opts: Options{Kind: KindHex, Length: MaxLength + 1}},
+		{name: "unknown-charset", opts: Options{Kind: KindPassword, Length: 8, Charset: "nope"}},
+		{name: "all-excluded", opts: Options{Kind: KindPassword, Length: 8, Charset: "digits", Exclude: "0123456789"}},
+		{name: "policy", opts: Options{Kind: KindPolicy}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			if err := tt.opts.Validate(); err == nil {
+				t.Fatal("expected error")
+			}
+		})
+	}
+}
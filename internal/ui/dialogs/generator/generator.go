@@ -0,0 +1,112 @@
+// Copyright (c) Liam Stanley <liam@liam.sh>. All rights reserved. Use of
+// this source code is governed by the MIT license that can be found in
+// the LICENSE file.
+
+package generator
+
+import (
+	"errors"
+	"strconv"
+	"time"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/lrstanley/vex/internal/types"
+	"github.com/lrstanley/vex/internal/ui/components/form"
+	formdialog "github.com/lrstanley/vex/internal/ui/dialogs/form"
+)
+
+// Config holds the configuration for the generator dialog.
+type Config struct {
+	// Key is the default key which the generated value is stored in.
+	Key string
+
+	// ConfirmFn is called with the key and the value, when a value was generated
+	// locally.
+	ConfirmFn func(key, value string) tea.Cmd
+
+	// PolicyFn is called with the key and the name of the Vault password policy,
+	// when a value should be generated by Vault using a password policy.
+	PolicyFn func(key, policy string) tea.Cmd
+}
+
+// New creates a new generator dialog, which generates passwords, tokens, UUIDs,
+// or values from Vault password policies.
+func New(app types.AppState, config Config) types.Dialog {
+	return formdialog.New(app, formdialog.Config{
+		Title:       "Generate value",
+		ConfirmText: "generate",
+		Fields: []*form.Field{
+			{
+				ID:        "key",
+				Label:     "Key",
+				Value:     config.Key,
+				Validator: form.ValidateRequired,
+			},
+			{
+				ID:      "kind",
+				Label:   "Kind",
+				Value:   string(KindPassword),
+				Options: Kinds,
+			},
+			{
+				ID:          "length",
+				Label:       "Length (chars/bytes)",
+				Value:       "32",
+				Placeholder: "32",
+				Validator:   form.ValidateIntRange(1, MaxLength),
+			},
+			{
+				ID:      "charset",
+				Label:   "Charset",
+				Value:   CharsetNames[0],
+				Options: CharsetNames,
+			},
+			{
+				ID:          "exclude",
+				Label:       "Exclude chars",
+				Placeholder: "e.g. 0O1lI",
+			},
+			{
+				ID:          "policy",
+				Label:       "Password policy",
+				Placeholder: "only used with kind \"policy\"",
+			},
+		},
+		Validator: func(values map[string]string) error {
+			if Kind(values["kind"]) == KindPolicy {
+				if values["policy"] == "" {
+					return errors.New("password policy is required")
+				}
+				return nil
+			}
+			return options(values).Validate()
+		},
+		ConfirmFn: func(values map[string]string) tea.Cmd {
+			if Kind(values["kind"]) == KindPolicy {
+				if config.PolicyFn == nil {
+					return nil
+				}
+				return config.PolicyFn(values["key"], values["policy"])
+			}
+
+			value, err := Generate(options(values))
+			if err != nil {
+				return types.SendStatus(err.Error(), types.Error, 3*time.Second)
+			}
+			if config.ConfirmFn == nil {
+				return nil
+			}
+			return config.ConfirmFn(values["key"], value)
+		},
+	})
+}
+
+func options(values map[string]string) Options {
+	length, _ := strconv.Atoi(values["length"]) // Validated by the field.
+	return Options{
+		Kind:    Kind(values["kind"]),
+		Length:  length,
+		Charset: values["charset"],
+		Exclude: values["exclude"],
+	}
+}
@@ -0,0 +1,77 @@
+// Copyright (c) Liam Stanley <liam@liam.sh>. All rights reserved. Use of
+// this source code is governed by the MIT license that can be found in
+// the LICENSE file.
+
+package secretdiff
+
+import (
+	"strings"
+
+	"charm.land/lipgloss/v2"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/lrstanley/vex/internal/types"
+	"github.com/lrstanley/vex/internal/ui/styles"
+)
+
+// action is a button in the action bar of the diff dialogs.
+type action struct {
+	label  string
+	status types.Status
+}
+
+// actionBar is a horizontal, right-aligned set of buttons, where one is always
+// active.
+type actionBar struct {
+	actions []action
+	active  int
+
+	// Styles.
+	buttonStyle        lipgloss.Style
+	focusedButtonStyle lipgloss.Style
+}
+
+func newActionBar(actions ...action) *actionBar {
+	b := &actionBar{actions: actions}
+	b.initStyles()
+	return b
+}
+
+func (b *actionBar) initStyles() {
+	b.buttonStyle = lipgloss.NewStyle().
+		Foreground(styles.Theme.InactiveButtonFg()).
+		Background(styles.Theme.InactiveButtonBg()).
+		Padding(0, 2).
+		MarginRight(1)
+
+	b.focusedButtonStyle = b.buttonStyle.
+		Foreground(styles.Theme.ActiveButtonFg()).
+		Background(styles.Theme.ActiveButtonBg())
+}
+
+func (b *actionBar) next() {
+	b.active = (b.active + 1) % len(b.actions)
+}
+
+func (b *actionBar) previous() {
+	b.active = (b.active - 1 + len(b.actions)) % len(b.actions)
+}
+
+func (b *actionBar) View(width int) string {
+	buttons := make([]string, len(b.actions))
+	for i, a := range b.actions {
+		if i != b.active {
+			buttons[i] = b.buttonStyle.Render(a.label)
+			continue
+		}
+
+		style := b.focusedButtonStyle
+		if a.status != "" {
+			fg, bg := styles.Theme.ByStatus(a.status)
+			style = style.Foreground(fg).Background(bg)
+		}
+		buttons[i] = style.Render(a.label)
+	}
+
+	row := lipgloss.JoinHorizontal(lipgloss.Top, buttons...)
+	return strings.Repeat(" ", max(0, width-ansi.StringWidth(row))) + row
+}
@@ -0,0 +1,217 @@
+// Copyright (c) Liam Stanley <liam@liam.sh>. All rights reserved. Use of
+// this source code is governed by the MIT license that can be found in
+// the LICENSE file.
+
+package secretdiff
+
+import (
+	"fmt"
+	"strings"
+
+	"charm.land/bubbles/v2/key"
+	tea "charm.land/bubbletea/v2"
+	"charm.land/lipgloss/v2"
+	"github.com/lrstanley/vex/internal/types"
+	"github.com/lrstanley/vex/internal/ui/components/viewport"
+	"github.com/lrstanley/vex/internal/ui/styles"
+)
+
+// ConflictConfig holds the configuration for the conflict dialog.
+type ConflictConfig struct {
+	// Path is the full path of the secret, used for the title.
+	Path string
+
+	// Original is the data of the secret when it was originally loaded, Mine
+	// is the data which was attempted to be written, and Theirs is the current
+	// data of the secret on the server.
+	Original map[string]any
+	Mine     map[string]any
+	Theirs   map[string]any
+
+	// OriginalVersion and TheirsVersion are the versions of the original and
+	// current secret, used for display purposes.
+	OriginalVersion int
+	TheirsVersion   int
+
+	// RetryFn is called with the result of merging mine on top of theirs.
+	RetryFn func(merged map[string]any) tea.Cmd
+
+	// OverwriteFn is called with mine, to replace theirs entirely.
+	OverwriteFn func(mine map[string]any) tea.Cmd
+
+	// DiscardFn is called when the changes in mine should be thrown away. It's
+	// only called when discard is selected, not when the dialog is cancelled.
+	DiscardFn func() tea.Cmd
+}
+
+type conflictAction int
+
+const (
+	actionRetry conflictAction = iota
+	actionOverwrite
+	actionDiscard
+)
+
+var _ types.Dialog = (*ConflictModel)(nil) // Ensure we implement the dialog interface.
+
+// ConflictModel is a dialog which is shown when a write to a secret was rejected
+// because it was modified by someone else. It shows a three-way diff between
+// the original, mine and theirs, and allows retrying (merging), overwriting or
+// discarding.
+type ConflictModel struct {
+	*types.DialogModel
+
+	// Core state.
+	app       types.AppState
+	config    ConflictConfig
+	merged    map[string]any
+	conflicts []string
+
+	// UI state.
+	unmasked bool
+
+	// Child components.
+	code    *viewport.Model
+	actions *actionBar
+}
+
+// NewConflict creates a new conflict dialog.
+func NewConflict(app types.AppState, config ConflictConfig) *ConflictModel {
+	m := &ConflictModel{
+		DialogModel: &types.DialogModel{
+			Size:            types.DialogSizeLarge,
+			DisableChildren: true,
+			ShortKeyBinds: []key.Binding{
+				types.OverrideHelp(types.KeySelectItem, "select"),
+				types.KeyToggleMask,
+			},
+			FullKeyBinds: [][]key.Binding{{
+				types.OverrideHelp(types.KeySelectItem, "select"),
+				types.OverrideHelp(types.KeyTabForward, "next action"),
+				types.KeyToggleMask,
+			}},
+		},
+		app:      app,
+		config:   config,
+		code:     viewport.New(app),
+		unmasked: !maskedByDefault(),
+		actions: newActionBar(
+			action{label: "retry (merge)"},                  // actionRetry.
+			action{label: "overwrite", status: types.Error}, // actionOverwrite.
+			action{label: "discard"},                        // actionDiscard.
+		),
+	}
+
+	m.merged, m.conflicts = Merge(config.Original, config.Mine, config.Theirs)
+	m.setContent()
+	return m
+}
+
+func (m *ConflictModel) setContent() {
+	masked := !m.unmasked
+
+	var out []string
+
+	out = append(
+		out,
+		fmt.Sprintf("@@ your changes (based on version %d) @@", m.config.OriginalVersion),
+		Render(Changes(m.config.Original, m.config.Mine), masked),
+		"",
+		fmt.Sprintf("@@ their changes (version %d -> %d) @@", m.config.OriginalVersion, m.config.TheirsVersion),
+		Render(Changes(m.config.Original, m.config.Theirs), masked),
+	)
+
+	if len(m.conflicts) > 0 {
+		out = append(out, "", "@@ conflicting keys (retry keeps your changes) @@")
+		for _, k := range m.conflicts {
+			out = append(out, "! "+k)
+		}
+	}
+
+	out = append(
+		out,
+		"",
+		"@@ result of retry (version "+fmt.Sprint(m.config.TheirsVersion)+" -> merged) @@",
+		Render(Changes(m.config.Theirs, m.merged), masked),
+	)
+
+	m.code.SetCode(strings.Join(out, "\n"), "diff")
+}
+
+func (m *ConflictModel) GetTitle() string {
+	return "Conflict: " + m.config.Path
+}
+
+func (m *ConflictModel) HasInputFocus() bool {
+	return true
+}
+
+func (m *ConflictModel) Init() tea.Cmd {
+	return m.code.Init()
+}
+
+func (m *ConflictModel) Update(msg tea.Msg) tea.Cmd {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.Height, m.Width = msg.Height, msg.Width
+		m.code.SetDimensions(m.Width, m.Height-2) // -2 for spacing and buttons.
+		return nil
+	case styles.ThemeUpdatedMsg:
+		m.actions.initStyles()
+	case tea.KeyMsg:
+		switch {
+		case key.Matches(msg, types.KeyQuit):
+			return types.AppQuit()
+		case key.Matches(msg, types.KeyCancel):
+			return types.CloseActiveDialog()
+		case key.Matches(msg, types.KeyTabForward, types.KeyRight):
+			m.actions.next()
+			return nil
+		case key.Matches(msg, types.KeyTabBackward, types.KeyLeft):
+			m.actions.previous()
+			return nil
+		case key.Matches(msg, types.KeySelectItem):
+			return m.trigger(conflictAction(m.actions.active))
+		case key.Matches(msg, types.KeyToggleMask):
+			m.unmasked = !m.unmasked
+			m.setContent()
+			return nil
+		}
+	}
+
+	return m.code.Update(msg)
+}
+
+func (m *ConflictModel) trigger(action conflictAction) tea.Cmd {
+	var cmd tea.Cmd
+
+	switch action {
+	case actionRetry:
+		if m.config.RetryFn != nil {
+			cmd = m.config.RetryFn(m.merged)
+		}
+	case actionOverwrite:
+		if m.config.OverwriteFn != nil {
+			cmd = m.config.OverwriteFn(m.config.Mine)
+		}
+	case actionDiscard:
+		if m.config.DiscardFn != nil {
+			cmd = m.config.DiscardFn()
+		}
+	}
+
+	return tea.Sequence(types.CloseActiveDialog(), cmd)
+}
+
+func (m *ConflictModel) View() string {
+	if m.Width == 0 || m.Height == 0 {
+		return ""
+	}
+
+	return lipgloss.JoinVertical(
+		lipgloss.Left,
+		m.code.View(),
+		"",
+		m.actions.View(m.Width),
+	)
+}
@@ -0,0 +1,177 @@
+// Copyright (c) Liam Stanley <liam@liam.sh>. All rights reserved. Use of
+// this source code is governed by the MIT license that can be found in
+// the LICENSE file.
+
+package secretdiff
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/lrstanley/vex/internal/config"
+	"github.com/lrstanley/x/charm/formatter"
+)
+
+// ChangeType is the type of change made to a key.
+type ChangeType string
+
+const (
+	ChangeAdded    ChangeType = "added"
+	ChangeModified ChangeType = "modified"
+	ChangeRemoved  ChangeType = "removed"
+)
+
+// Change is a single change to a key, between two versions of a secret.
+type Change struct {
+	Key    string
+	Type   ChangeType
+	Before any
+	After  any
+}
+
+// Changes returns the changes between before and after, sorted by key.
+func Changes(before, after map[string]any) (changes []Change) {
+	keys := slices.Collect(maps.Keys(before))
+	for k := range after {
+		if _, ok := before[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	slices.Sort(keys)
+
+	for _, k := range keys {
+		bv, inBefore := before[k]
+		av, inAfter := after[k]
+
+		switch {
+		case inBefore && !inAfter:
+			changes = append(changes, Change{Key: k, Type: ChangeRemoved, Before: bv})
+		case !inBefore && inAfter:
+			changes = append(changes, Change{Key: k, Type: ChangeAdded, After: av})
+		case !reflect.DeepEqual(bv, av):
+			changes = append(changes, Change{Key: k, Type: ChangeModified, Before: bv, After: av})
+		}
+	}
+	return changes
+}
+
+// Merge does a three-way merge of the keys of a secret, applying the changes
+// between original and mine on top of theirs. Keys which were changed in both
+// mine and theirs (in different ways) are returned as conflicts, and mine takes
+// precedence in the merged result.
+func Merge(original, mine, theirs map[string]any) (merged map[string]any, conflicts []string) {
+	merged = maps.Clone(theirs)
+	if merged == nil {
+		merged = map[string]any{}
+	}
+
+	theirChanges := Changes(original, theirs)
+
+	for _, c := range Changes(original, mine) {
+		idx := slices.IndexFunc(theirChanges, func(tc Change) bool {
+			return tc.Key == c.Key
+		})
+		if idx >= 0 && (theirChanges[idx].Type != c.Type || !reflect.DeepEqual(theirChanges[idx].After, c.After)) {
+			conflicts = append(conflicts, c.Key)
+		}
+
+		if c.Type == ChangeRemoved {
+			delete(merged, c.Key)
+			continue
+		}
+		merged[c.Key] = c.After
+	}
+
+	return merged, conflicts
+}
+
+// maskedByDefault returns whether values should be masked when a diff is first
+// shown, based on the users masking preferences.
+func maskedByDefault() bool {
+	return config.Get().Masking.Enabled
+}
+
+// formatValue formats a value for use in a diff, masking it if requested.
+func formatValue(v any, masked bool) string {
+	if masked {
+		return formatter.MaskReplacementValue
+	}
+
+	switch vv := v.(type) {
+	case string:
+		return vv
+	case nil:
+		return "null"
+	default:
+		b, err := json.Marshal(vv)
+		if err != nil {
+			return fmt.Sprintf("%v", vv)
+		}
+		return string(b)
+	}
+}
+
+// diffLine formats a single diff line, ensuring that multi-line values are
+// prefixed on each line.
+func diffLine(prefix, key string, value any, masked bool) string {
+	lines := strings.Split(formatValue(value, masked), "\n")
+	out := prefix + " " + key + ": " + lines[0]
+	for _, l := range lines[1:] {
+		out += "\n" + prefix + " " + strings.Repeat(" ", len(key)+2) + l
+	}
+	return out
+}
+
+// Render renders the provided changes as a unified-diff-like string.
+func Render(changes []Change, masked bool) string {
+	var out []string
+	for _, c := range changes {
+		switch c.Type {
+		case ChangeAdded:
+			out = append(out, diffLine("+", c.Key, c.After, masked))
+		case ChangeRemoved:
+			out = append(out, diffLine("-", c.Key, c.Before, masked))
+		case ChangeModified:
+			out = append(
+				out,
+				diffLine("-", c.Key, c.Before, masked),
+				diffLine("+", c.Key, c.After, masked),
+			)
+		}
+	}
+	return strings.Join(out, "\n")
+}
+
+// Summary returns a short summary of the changes, e.g. "1 added, 2 modified".
+func Summary(changes []Change) string {
+	var added, modified, removed int
+	for _, c := range changes {
+		switch c.Type {
+		case ChangeAdded:
+			added++
+		case ChangeModified:
+			modified++
+		case ChangeRemoved:
+			removed++
+		}
+	}
+
+	var out []string
+	if added > 0 {
+		out = append(out, fmt.Sprintf("%d added", added))
+	}
+	if modified > 0 {
+		out = append(out, fmt.Sprintf("%d modified", modified))
+	}
+	if removed > 0 {
+		out = append(out, fmt.Sprintf("%d removed", removed))
+	}
+	if len(out) == 0 {
+		return "no changes"
+	}
+	return strings.Join(out, ", ")
+}
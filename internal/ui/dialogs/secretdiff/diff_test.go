@@ -0,0 +1,119 @@
+// Copyright (c) Liam Stanley <liam@liam.sh>. All rights reserved. Use of
+// this source code is governed by the MIT license that can be found in
+// the LICENSE file.
+
+package secretdiff
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestChanges(t *testing.T) {
+	t.Parallel()
+
+	changes := Changes(
+		map[string]any{"a": "1", "b": "2", "c": "3"},
+		map[string]any{"a": "1", "b": "20", "d": "4"},
+	)
+
+	want := []Change{
+		{Key: "b", Type: ChangeModified, Before: "2", After: "20"},
+		{Key: "c", Type: ChangeRemoved, Before: "3"},
+		{Key: "d", Type: ChangeAdded, After: "4"},
+	}
+
+	if !reflect.DeepEqual(changes, want) {
+		t.Fatalf("unexpected changes:\n got: %#v\nwant: %#v", changes, want)
+	}
+
+	if s := Summary(changes); s != "1 added, 1 modified, 1 removed" {
+		t.Fatalf("unexpected summary: %q", s)
+	}
+}
+
+func TestMerge(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name          string
+		original      map[string]any
+		mine          map[string]any
+		theirs        map[string]any
+		wantMerged    map[string]any
+		wantConflicts []string
+	}{
+		{
+			name:       "disjoint-changes",
+			original:   map[string]any{"a": "1", "b": "2"},
+			mine:       map[string]any{"a": "10", "b": "2"},
+			theirs:     map[string]any{"a": "1", "b": "2", "c": "3"},
+			wantMerged: map[string]any{"a": "10", "b": "2", "c": "3"},
+		},
+		{
+			name:          "conflicting-changes",
+			original:      map[string]any{"a": "1"},
+			mine:          map[string]any{"a": "mine"},
+			theirs:        map[string]any{"a": "theirs"},
+			wantMerged:    map[string]any{"a": "mine"},
+			wantConflicts: []string{"a"},
+		},
+		{
+			name:       "same-change",
+			original:   map[string]any{"a": "1"},
+			mine:       map[string]any{"a": "2"},
+			theirs:     map[string]any{"a": "2"},
+			wantMerged: map[string]any{"a": "2"},
+		},
+		{
+			name:          "removed-vs-modified",
+			original:      map[string]any{"a": "1", "b": "2"},
+			mine:          map[string]any{"b": "2"},
+			theirs:        map[string]any{"a": "changed", "b": "2"},
+			wantMerged:    map[string]any{"b": "2"},
+			wantConflicts: []string{"a"},
+		},
+		{
+			name:       "secret-created-by-others",
+			original:   nil,
+			mine:       map[string]any{"a": "1"},
+			theirs:     map[string]any{"b": "2"},
+			wantMerged: map[string]any{"a": "1", "b": "2"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			merged, conflicts := Merge(tt.original, tt.mine, tt.theirs)
+			if !reflect.DeepEqual(merged, tt.wantMerged) {
+				t.Fatalf("unexpected merged result:\n got: %v\nwant: %v", merged, tt.wantMerged)
+			}
+			if !reflect.DeepEqual(conflicts, tt.wantConflicts) {
+				t.Fatalf("unexpected conflicts:\n got: %v\nwant: %v", conflicts, tt.wantConflicts)
+			}
+		})
+	}
+}
+
+func TestRender(t *testing.T) {
+	t.Parallel()
+
+	changes := Changes(
+		map[string]any{"a": "old", "n": 1},
+		map[string]any{"a": "new\nline", "n": 1, "obj": map[string]any{"x": true}},
+	)
+
+	out := Render(changes, false)
+	for _, want := range []string{"- a: old", "+ a: new", "+    line", `+ obj: {"x":true}`} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("expected %q in output:\n%s", want, out)
+		}
+	}
+
+	if masked := Render(changes, true); strings.Contains(masked, "old") || strings.Contains(masked, "new") {
+		t.Fatalf("expected values to be masked:\n%s", masked)
+	}
+}
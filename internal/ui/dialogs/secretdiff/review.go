@@ -0,0 +1,152 @@
+// Copyright (c) Liam Stanley <liam@liam.sh>. All rights reserved. Use of
+// this source code is governed by the MIT license that can be found in
+// the LICENSE file.
+
+package secretdiff
+
+import (
+	"strings"
+
+	"charm.land/bubbles/v2/key"
+	tea "charm.land/bubbletea/v2"
+	"charm.land/lipgloss/v2"
+	"github.com/lrstanley/vex/internal/types"
+	"github.com/lrstanley/vex/internal/ui/components/viewport"
+	"github.com/lrstanley/vex/internal/ui/styles"
+)
+
+// ReviewConfig holds the configuration for the review dialog.
+type ReviewConfig struct {
+	// Path is the full path of the secret, used for the title.
+	Path string
+
+	// Before is the data of the secret as it is on the server, and After is the
+	// data with all staged changes applied.
+	Before map[string]any
+	After  map[string]any
+
+	// ConfirmFn is called with After, when the changes are applied.
+	ConfirmFn func(after map[string]any) tea.Cmd
+}
+
+const (
+	reviewActionCancel = iota
+	reviewActionApply
+)
+
+var _ types.Dialog = (*ReviewModel)(nil) // Ensure we implement the dialog interface.
+
+// ReviewModel is a dialog which shows the diff of all staged changes to a secret,
+// before they are applied in a single write.
+type ReviewModel struct {
+	*types.DialogModel
+
+	// Core state.
+	app     types.AppState
+	config  ReviewConfig
+	changes []Change
+
+	// UI state.
+	unmasked bool
+
+	// Child components.
+	code    *viewport.Model
+	actions *actionBar
+}
+
+// NewReview creates a new review dialog.
+func NewReview(app types.AppState, config ReviewConfig) *ReviewModel {
+	m := &ReviewModel{
+		DialogModel: &types.DialogModel{
+			Size:            types.DialogSizeLarge,
+			DisableChildren: true,
+			ShortKeyBinds: []key.Binding{
+				types.OverrideHelp(types.KeySelectItem, "select"),
+				types.KeyToggleMask,
+			},
+			FullKeyBinds: [][]key.Binding{{
+				types.OverrideHelp(types.KeySelectItem, "select"),
+				types.OverrideHelp(types.KeyTabForward, "next action"),
+				types.KeyToggleMask,
+				types.KeyCancel,
+			}},
+		},
+		app:      app,
+		config:   config,
+		changes:  Changes(config.Before, config.After),
+		code:     viewport.New(app),
+		unmasked: !maskedByDefault(),
+		actions: newActionBar(
+			action{label: "cancel"},                       // reviewActionCancel.
+			action{label: "apply", status: types.Success}, // reviewActionApply.
+		),
+	}
+
+	m.actions.active = reviewActionApply
+	m.setContent()
+	return m
+}
+
+func (m *ReviewModel) setContent() {
+	m.code.SetCode(strings.Join([]string{
+		"@@ " + Summary(m.changes) + " @@",
+		Render(m.changes, !m.unmasked),
+	}, "\n"), "diff")
+}
+
+func (m *ReviewModel) GetTitle() string {
+	return "Review changes: " + m.config.Path
+}
+
+func (m *ReviewModel) HasInputFocus() bool {
+	return true
+}
+
+func (m *ReviewModel) Init() tea.Cmd {
+	return m.code.Init()
+}
+
+func (m *ReviewModel) Update(msg tea.Msg) tea.Cmd {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.Height, m.Width = msg.Height, msg.Width
+		m.code.SetDimensions(m.Width, m.Height-2) // -2 for spacing and buttons.
+		return nil
+	case styles.ThemeUpdatedMsg:
+		m.actions.initStyles()
+	case tea.KeyMsg:
+		switch {
+		case key.Matches(msg, types.KeyQuit):
+			return types.AppQuit()
+		case key.Matches(msg, types.KeyCancel):
+			return types.CloseActiveDialog()
+		case key.Matches(msg, types.KeyTabForward, types.KeyRight, types.KeyTabBackward, types.KeyLeft):
+			m.actions.next()
+			return nil
+		case key.Matches(msg, types.KeySelectItem):
+			if m.actions.active == reviewActionCancel || m.config.ConfirmFn == nil {
+				return types.CloseActiveDialog()
+			}
+			return tea.Sequence(types.CloseActiveDialog(), m.config.ConfirmFn(m.config.After))
+		case key.Matches(msg, types.KeyToggleMask):
+			m.unmasked = !m.unmasked
+			m.setContent()
+			return nil
+		}
+	}
+
+	return m.code.Update(msg)
+}
+
+func (m *ReviewModel) View() string {
+	if m.Width == 0 || m.Height == 0 {
+		return ""
+	}
+
+	return lipgloss.JoinVertical(
+		lipgloss.Left,
+		m.code.View(),
+		"",
+		m.actions.View(m.Width),
+	)
+}
@@ -41,8 +41,8 @@ func New(app types.AppState, config confirmable.Config[string], title, defaultVa
 	config.CancelFn = func() tea.Cmd {
 		if originalCancelFn != nil {
 			return tea.Sequence(
-				originalCancelFn(),
 				types.CloseActiveDialog(),
+				originalCancelFn(),
 			)
 		}
 		return types.CloseActiveDialog()
@@ -52,8 +52,8 @@ func New(app types.AppState, config confirmable.Config[string], title, defaultVa
 	config.ConfirmFn = func(value string) tea.Cmd {
 		if originalConfirmFn != nil {
 			return tea.Sequence(
-				originalConfirmFn(value),
 				types.CloseActiveDialog(),
+				originalConfirmFn(value),
 			)
 		}
 		return types.CloseActiveDialog()
@@ -0,0 +1,162 @@
+// Copyright (c) Liam Stanley <liam@liam.sh>. All rights reserved. Use of
+// this source code is governed by the MIT license that can be found in
+// the LICENSE file.
+
+package themepicker
+
+import (
+	tea "charm.land/bubbletea/v2"
+	"charm.land/lipgloss/v2"
+	"github.com/lrstanley/vex/internal/types"
+	"github.com/lrstanley/vex/internal/ui/components/dialogselector"
+	"github.com/lrstanley/vex/internal/ui/components/table"
+	"github.com/lrstanley/vex/internal/ui/styles"
+)
+
+var columns = []*table.Column[*table.StaticRow[[]string]]{
+	{ID: "active", Title: "", MinWidth: 1},
+	{ID: "name", Title: "Name"},
+	{ID: "id", Title: "ID"},
+	{ID: "variant", Title: "Variant"},
+	{ID: "source", Title: "Source"},
+}
+
+var _ types.Dialog = (*Model)(nil) // Ensure we implement the dialog interface.
+
+// Model is a searchable picker of all registered tints, which previews the
+// highlighted tint live. The original tint is restored if the dialog is closed
+// without selecting a tint.
+type Model struct {
+	*types.DialogModel
+
+	// Core state.
+	app types.AppState
+
+	// UI state.
+	original string
+	selected bool
+
+	// Styles.
+	activeStyle lipgloss.Style
+
+	// Child components.
+	selector *dialogselector.Model
+}
+
+func New(app types.AppState) *Model {
+	m := &Model{
+		DialogModel: &types.DialogModel{
+			Size:            types.DialogSizeMedium,
+			DisableChildren: true,
+		},
+		app:      app,
+		original: styles.Theme.TintID(),
+	}
+
+	m.selector = dialogselector.New(app, dialogselector.Config{
+		Columns:           columns,
+		FilterPlaceholder: "type to filter themes",
+		SelectFunc: func(id string) tea.Cmd {
+			m.selected = true
+			return tea.Sequence(
+				types.CloseActiveDialog(),
+				styles.Theme.SelectTintID(id),
+			)
+		},
+		HighlightFunc: styles.Theme.PreviewTintID,
+	})
+
+	m.initStyles()
+	m.setData()
+	m.selector.SetHighlighted(m.original)
+	return m
+}
+
+func (m *Model) initStyles() {
+	m.activeStyle = m.activeStyle.
+		Foreground(styles.Theme.ErrorFg())
+
+	// Re-calculate the height so the dialog is only as big as we need, up to the max
+	// of the default of [DialogModel.Size].
+	m.Height = min(m.Height, m.selector.GetHeight())
+}
+
+func (m *Model) GetTitle() string {
+	return "Themes"
+}
+
+func (m *Model) setData() {
+	tints := styles.Theme.Tints()
+
+	suggestions := make([]string, 0, len(tints))
+	rows := make([][]string, 0, len(tints))
+	for _, t := range tints {
+		suggestions = append(suggestions, t.ID)
+
+		var active string
+		if t.ID == m.original {
+			active = m.activeStyle.Render(styles.IconClosedCircle)
+		}
+
+		variant := "light"
+		if t.Dark {
+			variant = "dark"
+		}
+
+		source := "builtin"
+		if styles.Theme.IsCustomTint(t.ID) {
+			source = "custom"
+		}
+
+		rows = append(rows, []string{
+			t.ID, // ID.
+			active,
+			t.DisplayName,
+			t.ID,
+			variant,
+			source,
+		})
+	}
+
+	m.selector.SetSuggestions(suggestions)
+	m.selector.SetItems(rows)
+}
+
+func (m *Model) Init() tea.Cmd {
+	return m.selector.Init()
+}
+
+func (m *Model) Update(msg tea.Msg) tea.Cmd {
+	var cmds []tea.Cmd
+
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.Height, m.Width = msg.Height, msg.Width
+		cmds = append(cmds, m.selector.Update(msg))
+		m.initStyles()
+		return tea.Sequence(cmds...)
+	case styles.ThemeUpdatedMsg:
+		m.initStyles()
+	case tea.PasteStartMsg, tea.PasteMsg, tea.PasteEndMsg:
+		cmds = append(cmds, m.selector.Update(msg))
+		return tea.Batch(cmds...)
+	}
+
+	return tea.Batch(append(cmds, m.selector.Update(msg))...)
+}
+
+// Close restores the original tint, if the dialog was closed without selecting
+// a tint.
+func (m *Model) Close() tea.Cmd {
+	if m.selected || styles.Theme.TintID() == m.original {
+		return nil
+	}
+	return styles.Theme.PreviewTintID(m.original)
+}
+
+func (m *Model) View() string {
+	if m.Width == 0 || m.Height == 0 {
+		return ""
+	}
+	return m.selector.View()
+}
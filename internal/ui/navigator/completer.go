@@ -0,0 +1,138 @@
+// Copyright (c) Liam Stanley <liam@liam.sh>. All rights reserved. Use of
+// this source code is governed by the MIT license that can be found in
+// the LICENSE file.
+
+package navigator
+
+import (
+	"slices"
+	"strings"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/lrstanley/vex/internal/types"
+)
+
+// Completer provides path completion for targets (see [ParseTarget]), lazily
+// listing mounts and secrets as the user types. Listings are cached for the
+// lifetime of the completer.
+type Completer struct {
+	*types.ComponentModel
+
+	// Core state.
+	app types.AppState
+
+	// UI state.
+	mounts   []*types.Mount
+	loaded   bool
+	listings map[string][]string // Full directory path -> full child paths.
+	pending  map[string]bool
+}
+
+func NewCompleter(app types.AppState) *Completer {
+	return &Completer{
+		ComponentModel: &types.ComponentModel{},
+		app:            app,
+		listings:       make(map[string][]string),
+		pending:        make(map[string]bool),
+	}
+}
+
+// Init fetches the mounts, which are required before any completion can happen.
+func (c *Completer) Init() tea.Cmd {
+	return c.app.Client().ListMounts(c.UUID())
+}
+
+// Update handles client responses for the completer. Returns true if the
+// message was for the completer, along with any error returned by the client.
+func (c *Completer) Update(msg tea.Msg) (ok bool, err error) {
+	cmsg, ok := msg.(types.ClientMsg)
+	if !ok || cmsg.UUID != c.UUID() {
+		return false, nil
+	}
+
+	switch vmsg := cmsg.Msg.(type) {
+	case types.ClientListMountsMsg:
+		c.mounts = slices.DeleteFunc(slices.Clone(vmsg.Mounts), func(m *types.Mount) bool {
+			return !m.IsKVLike()
+		})
+		c.loaded = true
+	case types.ClientListSecretsMsg:
+		for _, v := range vmsg.Values {
+			full := v.FullPath()
+			dir := parentDir(full)
+			if !slices.Contains(c.listings[dir], full) {
+				c.listings[dir] = append(c.listings[dir], full)
+			}
+		}
+	}
+
+	if cmsg.Error != nil && !c.loaded {
+		// Mark as loaded regardless, so callers waiting on mounts don't wait forever.
+		c.loaded = true
+	}
+	return true, cmsg.Error
+}
+
+// Loaded returns true once the mounts have been fetched (or failed to fetch).
+func (c *Completer) Loaded() bool {
+	return c.loaded
+}
+
+// Mounts returns all KV-like mounts.
+func (c *Completer) Mounts() []*types.Mount {
+	return c.mounts
+}
+
+// Complete returns all known candidates for the provided (partial) path, as full
+// paths. If the directory of the path hasn't been listed yet, a command to list
+// it is also returned, and Complete should be called again once it's been handled
+// by [Completer.Update].
+func (c *Completer) Complete(path string) (candidates []string, cmd tea.Cmd) {
+	path = strings.TrimPrefix(path, "/")
+
+	dir := path[:strings.LastIndex(path, "/")+1]
+	mount, rel, ok := ResolveMount(c.mounts, dir)
+	if dir == "" || !ok {
+		for _, m := range c.mounts {
+			candidates = append(candidates, m.Path)
+		}
+		return candidates, nil
+	}
+
+	key := mount.Path + rel
+	if listing, ok := c.listings[key]; ok {
+		return listing, nil
+	}
+
+	if !c.pending[key] {
+		c.pending[key] = true
+		cmd = c.app.Client().ListSecrets(c.UUID(), mount, rel)
+	}
+	return nil, cmd
+}
+
+// IsFolder returns true if the provided path is known to be a folder, even if it
+// doesn't have a trailing slash.
+func (c *Completer) IsFolder(path string) bool {
+	path = strings.TrimPrefix(path, "/")
+	if strings.HasSuffix(path, "/") {
+		return true
+	}
+
+	for _, m := range c.mounts {
+		if path+"/" == m.Path {
+			return true
+		}
+	}
+	return slices.Contains(c.listings[parentDir(path)], path+"/")
+}
+
+// parentDir returns the parent directory of the provided path (with a trailing
+// slash), or an empty string if it has no parent.
+func parentDir(path string) string {
+	i := strings.LastIndex(strings.TrimSuffix(path, "/"), "/")
+	if i == -1 {
+		return ""
+	}
+	return path[:i+1]
+}
@@ -0,0 +1,122 @@
+// Copyright (c) Liam Stanley <liam@liam.sh>. All rights reserved. Use of
+// this source code is governed by the MIT license that can be found in
+// the LICENSE file.
+
+// Package navigator constructs page stacks to jump directly to secret paths
+// (e.g. from bookmarks or :goto), as if the user had navigated there manually.
+package navigator
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/lrstanley/vex/internal/types"
+	"github.com/lrstanley/vex/internal/ui/pages/kvv2versions"
+	"github.com/lrstanley/vex/internal/ui/pages/kvviewsecret"
+	"github.com/lrstanley/vex/internal/ui/pages/mounts"
+	"github.com/lrstanley/vex/internal/ui/pages/secretwalker"
+)
+
+// ParseTarget parses a target in the format "<mount>/<path>[@<version>]", e.g.
+// "secret/foo/bar@3". Paths ending in "/" are folders, which can't have a version.
+func ParseTarget(target string) (path string, version int, err error) {
+	path = strings.TrimPrefix(strings.TrimSpace(target), "/")
+	if path == "" {
+		return "", 0, errors.New("path is required")
+	}
+
+	if i := strings.LastIndex(path, "@"); i != -1 {
+		v, verr := strconv.Atoi(path[i+1:])
+		if verr == nil {
+			if v < 0 {
+				return "", 0, fmt.Errorf("invalid version %d", v)
+			}
+			path, version = path[:i], v
+		}
+	}
+
+	if version > 0 && strings.HasSuffix(path, "/") {
+		return "", 0, errors.New("folders can't have a version")
+	}
+	return path, version, nil
+}
+
+// ResolveMount returns the mount which contains the provided full path (longest
+// matching mount path wins), and the path relative to the mount.
+func ResolveMount(mounts []*types.Mount, path string) (mount *types.Mount, rel string, ok bool) {
+	for _, m := range mounts {
+		if !m.IsKVLike() {
+			continue
+		}
+		if (strings.HasPrefix(path, m.Path) || path+"/" == m.Path) &&
+			(mount == nil || len(m.Path) > len(mount.Path)) {
+			mount = m
+		}
+	}
+	if mount == nil {
+		return nil, "", false
+	}
+	if path+"/" == mount.Path {
+		return mount, "", true
+	}
+	return mount, strings.TrimPrefix(path, mount.Path), true
+}
+
+// Stack returns the pages which would be open if the user navigated to the
+// provided path (relative to the mount) manually, i.e. mounts, a secret walker
+// for each folder, and for secrets, the versions (KVv2 only) and secret itself.
+func Stack(app types.AppState, mount *types.Mount, path string, version int) []types.Page {
+	pages := []types.Page{
+		mounts.New(app),
+		secretwalker.New(app, mount, ""),
+	}
+
+	isFolder := path == "" || strings.HasSuffix(path, "/")
+	parts := strings.Split(strings.TrimSuffix(path, "/"), "/")
+	if !isFolder {
+		parts = parts[:len(parts)-1]
+	}
+
+	var dir string
+	for _, part := range parts {
+		if part == "" {
+			continue
+		}
+		dir += part + "/"
+		pages = append(pages, secretwalker.New(app, mount, dir))
+	}
+
+	if isFolder {
+		return pages
+	}
+
+	if mount.KVVersion() == 2 {
+		pages = append(pages, kvv2versions.New(app, mount, path))
+	}
+	return append(pages, kvviewsecret.New(app, mount, path, version, false))
+}
+
+// Open parses and resolves the provided target (see [ParseTarget]) against the
+// provided mounts, and opens the resulting page stack. Returns an error status if
+// the target is invalid.
+func Open(app types.AppState, mounts []*types.Mount, target string) tea.Cmd {
+	path, version, err := ParseTarget(target)
+	if err != nil {
+		return types.SendStatus("invalid path: "+err.Error(), types.Error, 3*time.Second)
+	}
+
+	mount, rel, ok := ResolveMount(mounts, path)
+	if !ok {
+		return types.SendStatus("no kv mount found for path: "+path, types.Error, 3*time.Second)
+	}
+
+	if version > 0 && mount.KVVersion() != 2 {
+		return types.SendStatus("versions are only supported for kv v2 mounts", types.Error, 3*time.Second)
+	}
+
+	return types.OpenPageStack(Stack(app, mount, rel, version)...)
+}
@@ -0,0 +1,209 @@
+// Copyright (c) Liam Stanley <liam@liam.sh>. All rights reserved. Use of
+// this source code is governed by the MIT license that can be found in
+// the LICENSE file.
+
+package navigator
+
+import (
+	"fmt"
+	"testing"
+
+	vapi "github.com/hashicorp/vault/api"
+	"github.com/lrstanley/vex/internal/api"
+	"github.com/lrstanley/vex/internal/types"
+	"github.com/lrstanley/vex/internal/ui/pages/kvv2versions"
+	"github.com/lrstanley/vex/internal/ui/pages/kvviewsecret"
+	"github.com/lrstanley/vex/internal/ui/pages/mounts"
+	"github.com/lrstanley/vex/internal/ui/pages/secretwalker"
+	"github.com/lrstanley/vex/internal/ui/state"
+)
+
+func testMount(path, mountType, version string) *types.Mount {
+	return &types.Mount{
+		Path: path,
+		MountOutput: &vapi.MountOutput{
+			Type:    mountType,
+			Options: map[string]string{"version": version},
+		},
+	}
+}
+
+func TestParseTarget(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		in      string
+		path    string
+		version int
+		wantErr bool
+	}{
+		{in: "secret/foo", path: "secret/foo"},
+		{in: "/secret/foo/", path: "secret/foo/"},
+		{in: " secret/foo@3 ", path: "secret/foo", version: 3},
+		{in: "secret/user@example.com", path: "secret/user@example.com"},
+		{in: "secret/foo/@3", wantErr: true},
+		{in: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			t.Parallel()
+			path, version, err := ParseTarget(tt.in)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("expected error=%v, got %v", tt.wantErr, err)
+			}
+			if path != tt.path || version != tt.version {
+				t.Fatalf("expected %q@%d, got %q@%d", tt.path, tt.version, path, version)
+			}
+		})
+	}
+}
+
+func TestResolveMount(t *testing.T) {
+	t.Parallel()
+
+	all := []*types.Mount{
+		testMount("secret/", "kv", "2"),
+		testMount("secret/nested/", "kv", "1"),
+		testMount("transit/", "transit", ""),
+	}
+
+	tests := []struct {
+		in    string
+		mount string
+		rel   string
+		ok    bool
+	}{
+		{in: "secret/foo/bar", mount: "secret/", rel: "foo/bar", ok: true},
+		{in: "secret", mount: "secret/", rel: "", ok: true},
+		{in: "secret/nested/foo", mount: "secret/nested/", rel: "foo", ok: true},
+		{in: "transit/keys", ok: false},
+		{in: "unknown/foo", ok: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			t.Parallel()
+			mount, rel, ok := ResolveMount(all, tt.in)
+			if ok != tt.ok {
+				t.Fatalf("expected ok=%v, got %v", tt.ok, ok)
+			}
+			if !ok {
+				return
+			}
+			if mount.Path != tt.mount || rel != tt.rel {
+				t.Fatalf("expected %q + %q, got %q + %q", tt.mount, tt.rel, mount.Path, rel)
+			}
+		})
+	}
+}
+
+func TestStack(t *testing.T) {
+	t.Parallel()
+
+	app := state.NewMockAppState(api.NewMockClient(), nil)
+	kv1 := testMount("kv1/", "kv", "1")
+	kv2 := testMount("kv2/", "kv", "2")
+
+	tests := []struct {
+		name    string
+		mount   *types.Mount
+		path    string
+		version int
+		want    []string
+	}{
+		{
+			name:  "mount-root",
+			mount: kv2,
+			path:  "",
+			want:  []string{"mounts", "secretwalker:kv2/"},
+		},
+		{
+			name:  "folder",
+			mount: kv2,
+			path:  "a/b/",
+			want:  []string{"mounts", "secretwalker:kv2/", "secretwalker:kv2/a/", "secretwalker:kv2/a/b/"},
+		},
+		{
+			name:  "kv1-secret",
+			mount: kv1,
+			path:  "a/secret",
+			want:  []string{"mounts", "secretwalker:kv1/", "secretwalker:kv1/a/", "kvviewsecret:kv1/a/secret"},
+		},
+		{
+			name:    "kv2-secret-version",
+			mount:   kv2,
+			path:    "secret",
+			version: 3,
+			want:    []string{"mounts", "secretwalker:kv2/", "kvv2versions:kv2/secret", "kvviewsecret:kv2/secret"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			pages := Stack(app, tt.mount, tt.path, tt.version)
+
+			got := make([]string, 0, len(pages))
+			for _, p := range pages {
+				switch p.(type) {
+				case *mounts.Model:
+					got = append(got, "mounts")
+				case *secretwalker.Model:
+					got = append(got, "secretwalker:"+p.GetTitle())
+				case *kvv2versions.Model:
+					got = append(got, "kvv2versions:"+p.GetTitle())
+				case *kvviewsecret.Model:
+					got = append(got, "kvviewsecret:"+p.GetTitle())
+				default:
+					got = append(got, fmt.Sprintf("%T", p))
+				}
+			}
+
+			if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+				t.Fatalf("expected stack %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestLocations(t *testing.T) {
+	t.Parallel()
+
+	app := state.NewMockAppState(api.NewMockClient(), nil)
+	kv2 := testMount("kv2/", "kv", "2")
+
+	commands := func(command string) (types.Page, bool) {
+		if command == mounts.Commands[0] {
+			return mounts.New(app), true
+		}
+		return nil, false
+	}
+
+	pages := Stack(app, kv2, "a/secret", 3)
+	locations := Locate(pages, commands)
+	if len(locations) != len(pages) {
+		t.Fatalf("expected %d locations, got %d: %v", len(pages), len(locations), locations)
+	}
+
+	last := locations[len(locations)-1]
+	if last.Type != kvviewsecret.PageType || last.Mount != "kv2/" || last.Path != "a/secret" || last.Version != 3 {
+		t.Fatalf("unexpected location for secret: %+v", last)
+	}
+
+	restored := FromLocations(app, []*types.Mount{kv2}, locations, commands)
+	if len(restored) != len(pages) {
+		t.Fatalf("expected %d restored pages, got %d", len(pages), len(restored))
+	}
+	for i := range pages {
+		if restored[i].GetTitle() != pages[i].GetTitle() {
+			t.Fatalf("expected page %d to be %q, got %q", i, pages[i].GetTitle(), restored[i].GetTitle())
+		}
+	}
+
+	// Pages after a mount which no longer exists can't be restored.
+	if restored = FromLocations(app, nil, locations, commands); len(restored) != 1 {
+		t.Fatalf("expected only the mounts page to be restored, got %d pages", len(restored))
+	}
+}
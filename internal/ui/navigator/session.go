@@ -0,0 +1,107 @@
+// Copyright (c) Liam Stanley <liam@liam.sh>. All rights reserved. Use of
+// this source code is governed by the MIT license that can be found in
+// the LICENSE file.
+
+package navigator
+
+import (
+	"time"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/lrstanley/vex/internal/config"
+	"github.com/lrstanley/vex/internal/types"
+	"github.com/lrstanley/vex/internal/ui/pages/kvv2versions"
+	"github.com/lrstanley/vex/internal/ui/pages/kvviewsecret"
+	"github.com/lrstanley/vex/internal/ui/pages/recursivesecrets"
+	"github.com/lrstanley/vex/internal/ui/pages/secretwalker"
+)
+
+// CommandPageFunc returns a new page for the provided command, if one exists.
+type CommandPageFunc func(command string) (types.Page, bool)
+
+// Locate returns the locations of the provided pages (see [types.PageLocator]),
+// falling back to the command of the page. The stack is truncated at the first
+// page which can't be located, as any pages after it likely depend on it.
+func Locate(pages []types.Page, commands CommandPageFunc) (locations []config.PageLocation) {
+	for _, p := range pages {
+		if l, ok := p.(types.PageLocator); ok {
+			locations = append(locations, l.Location())
+			continue
+		}
+
+		cmds := p.GetCommands()
+		if len(cmds) == 0 {
+			break
+		}
+		if _, ok := commands(cmds[0]); !ok {
+			break
+		}
+		locations = append(locations, config.PageLocation{Type: cmds[0]})
+	}
+	return locations
+}
+
+// FromLocations reconstructs the pages from the provided locations (see [Locate]),
+// resolving mounts by path. The stack is truncated at the first location which
+// can't be reconstructed (e.g. the mount no longer exists).
+func FromLocations(
+	app types.AppState,
+	mounts []*types.Mount,
+	locations []config.PageLocation,
+	commands CommandPageFunc,
+) (pages []types.Page) {
+	for _, loc := range locations {
+		var mount *types.Mount
+		if loc.Mount != "" {
+			for _, m := range mounts {
+				if m.Path == loc.Mount && m.IsKVLike() {
+					mount = m
+					break
+				}
+			}
+			if mount == nil {
+				return pages
+			}
+		}
+
+		var page types.Page
+		switch {
+		case loc.Type == secretwalker.PageType && mount != nil:
+			page = secretwalker.New(app, mount, loc.Path)
+		case loc.Type == kvv2versions.PageType && mount != nil && mount.KVVersion() == 2:
+			page = kvv2versions.New(app, mount, loc.Path)
+		case loc.Type == kvviewsecret.PageType && mount != nil:
+			page = kvviewsecret.New(app, mount, loc.Path, loc.Version, false)
+		case loc.Type == recursivesecrets.Commands[0]:
+			page = recursivesecrets.New(app, mount)
+		default:
+			var ok bool
+			if page, ok = commands(loc.Type); !ok {
+				return pages
+			}
+		}
+		pages = append(pages, page)
+	}
+	return pages
+}
+
+// Restore fetches the mounts, and then opens the page stack reconstructed from
+// the provided locations (see [FromLocations]). Mounts are fetched within the
+// returned command, as they're only needed to resolve the locations.
+func Restore(app types.AppState, locations []config.PageLocation, commands CommandPageFunc) tea.Cmd {
+	fetch := app.Client().ListMounts("")
+
+	return func() tea.Msg {
+		msg, _ := fetch().(types.ClientMsg)
+		if msg.Error != nil {
+			return tea.BatchMsg{types.SendStatus("unable to restore session: "+msg.Error.Error(), types.Error, 3*time.Second)}
+		}
+
+		vmsg, _ := msg.Msg.(types.ClientListMountsMsg)
+		pages := FromLocations(app, vmsg.Mounts, locations, commands)
+		if len(pages) == 0 {
+			return nil
+		}
+		return types.OpenPageStackMsg{Pages: pages}
+	}
+}
@@ -0,0 +1,112 @@
+// Copyright (c) Liam Stanley <liam@liam.sh>. All rights reserved. Use of
+// this source code is governed by the MIT license that can be found in
+// the LICENSE file.
+
+package appconfig
+
+import (
+	"fmt"
+
+	"charm.land/bubbles/v2/key"
+	tea "charm.land/bubbletea/v2"
+	"github.com/goccy/go-yaml"
+	"github.com/lrstanley/vex/internal/config"
+	"github.com/lrstanley/vex/internal/types"
+	"github.com/lrstanley/vex/internal/ui/components/viewport"
+	"github.com/lrstanley/vex/internal/ui/styles"
+)
+
+var Commands = []string{"config", "settings"}
+
+var _ types.Page = (*Model)(nil) // Ensure we implement the page interface.
+
+// Model shows the effective settings, i.e. the settings loaded from the config
+// file, merged with the defaults, and all active key bindings.
+type Model struct {
+	*types.PageModel
+
+	// Core state.
+	app types.AppState
+
+	// UI state.
+	height int
+	width  int
+
+	// Child components.
+	code *viewport.Model
+}
+
+func New(app types.AppState) *Model {
+	m := &Model{
+		PageModel: &types.PageModel{
+			Commands: Commands,
+			ShortKeyBinds: []key.Binding{
+				types.OverrideHelp(types.KeyRefresh, "reload view"),
+			},
+		},
+		app: app,
+	}
+
+	m.code = viewport.New(app)
+
+	return m
+}
+
+func (m *Model) Init() tea.Cmd {
+	return tea.Batch(
+		m.code.Init(),
+		types.RefreshData(m.UUID()),
+	)
+}
+
+// effective returns the effective settings, including values which are only
+// known at runtime (e.g. key bindings which haven't been remapped).
+func effective() *config.Settings {
+	s := *config.Get()
+	s.KeyBindings = types.EffectiveKeyBindings()
+	s.Theme.Tint = styles.Theme.TintID()
+	return &s
+}
+
+func (m *Model) Update(msg tea.Msg) tea.Cmd {
+	var cmds []tea.Cmd
+
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+		m.code.SetDimensions(m.width, m.height)
+		return nil
+	case types.PageVisibleMsg, styles.ThemeUpdatedMsg:
+		return types.RefreshData(m.UUID())
+	case types.RefreshDataMsg:
+		if msg.UUID != m.UUID() {
+			return nil
+		}
+
+		out, err := yaml.Marshal(effective())
+		if err != nil {
+			return types.PageErrors(fmt.Errorf("marshal settings: %w", err))
+		}
+
+		m.code.SetCode(
+			fmt.Sprintf("# effective settings, loaded from: %s\n%s", config.SettingsPath(), out),
+			"yaml",
+		)
+		return types.PageClearState()
+	case tea.KeyMsg:
+		if key.Matches(msg, types.KeyRefresh) {
+			return types.RefreshData(m.UUID())
+		}
+	}
+
+	cmds = append(cmds, m.code.Update(msg))
+	return tea.Batch(cmds...)
+}
+
+func (m *Model) View() string {
+	if m.width == 0 || m.height == 0 {
+		return ""
+	}
+	return m.code.View()
+}
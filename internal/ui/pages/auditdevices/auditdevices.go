@@ -0,0 +1,436 @@
+// Copyright (c) Liam Stanley <liam@liam.sh>. All rights reserved. Use of
+// this source code is governed by the MIT license that can be found in
+// the LICENSE file.
+
+package auditdevices
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"charm.land/bubbles/v2/key"
+	tea "charm.land/bubbletea/v2"
+	"charm.land/lipgloss/v2"
+	vapi "github.com/hashicorp/vault/api"
+	"github.com/lrstanley/vex/internal/types"
+	"github.com/lrstanley/vex/internal/ui/components/form"
+	"github.com/lrstanley/vex/internal/ui/components/table"
+	"github.com/lrstanley/vex/internal/ui/dialogs/alert"
+	"github.com/lrstanley/vex/internal/ui/dialogs/confirm"
+	formdialog "github.com/lrstanley/vex/internal/ui/dialogs/form"
+	"github.com/lrstanley/vex/internal/ui/styles"
+)
+
+var Commands = []string{"auditdevices", "audit"}
+
+// deviceTypes are the audit device types which can be enabled.
+var deviceTypes = []string{"file", "socket", "syslog"}
+
+// typeSelectedMsg is sent once the type of the audit device to enable has been
+// selected, to open the form with the options of that type.
+type typeSelectedMsg struct {
+	uuid       string
+	deviceType string
+}
+
+var _ types.Page = (*Model)(nil) // Ensure we implement the page interface.
+
+// Model lists the enabled audit devices, with actions to enable and disable
+// them, and to hash values the same way as they appear in audit logs.
+type Model struct {
+	*types.PageModel
+
+	app types.AppState
+
+	table *table.Model[*table.StaticRow[*vapi.Audit]]
+}
+
+func New(app types.AppState) *Model {
+	m := &Model{
+		PageModel: &types.PageModel{
+			Commands:         Commands,
+			SupportFiltering: true,
+			RefreshInterval:  30 * time.Second,
+			ShortKeyBinds: []key.Binding{
+				types.KeyEnableAuditDevice,
+				types.OverrideHelp(types.KeyDelete, "disable device"),
+				types.KeyAuditHash,
+			},
+			FullKeyBinds: [][]key.Binding{
+				{
+					types.KeyEnableAuditDevice,
+					types.OverrideHelp(types.KeyDelete, "disable device"),
+				},
+				{types.KeyAuditHash},
+			},
+			MutatingKeyBinds: []key.Binding{
+				types.KeyEnableAuditDevice,
+				types.KeyDelete,
+			},
+		},
+		app: app,
+	}
+
+	m.table = table.New(app, table.Config[*table.StaticRow[*vapi.Audit]]{
+		Columns: []*table.Column[*table.StaticRow[*vapi.Audit]]{
+			{
+				ID:    "path",
+				Title: "Path",
+				AccessorFn: func(row *table.StaticRow[*vapi.Audit]) string {
+					return row.Value.Path
+				},
+			},
+			{
+				ID:       "type",
+				Title:    "Type",
+				MaxWidth: 8,
+				AccessorFn: func(row *table.StaticRow[*vapi.Audit]) string {
+					return row.Value.Type
+				},
+			},
+			{
+				ID:       "local",
+				Title:    "Local",
+				MaxWidth: 8,
+				AccessorFn: func(row *table.StaticRow[*vapi.Audit]) string {
+					return strconv.FormatBool(row.Value.Local)
+				},
+			},
+			{
+				ID:       "description",
+				Title:    "Description",
+				MaxWidth: 40,
+				AccessorFn: func(row *table.StaticRow[*vapi.Audit]) string {
+					return row.Value.Description
+				},
+			},
+			{
+				ID:    "options",
+				Title: "Options",
+				AccessorFn: func(row *table.StaticRow[*vapi.Audit]) string {
+					return formatOptions(row.Value.Options)
+				},
+				StyleFn: func(_ *table.StaticRow[*vapi.Audit], baseStyle lipgloss.Style, _, _ bool) lipgloss.Style {
+					return baseStyle.Foreground(styles.Theme.InfoFg())
+				},
+			},
+		},
+		NoResultsMsg: "no audit devices enabled",
+		FetchFn: func() tea.Cmd {
+			return app.Client().ListAuditDevices(m.UUID())
+		},
+	})
+
+	return m
+}
+
+func (m *Model) Init() tea.Cmd {
+	return tea.Batch(
+		m.table.Init(),
+		types.RefreshData(m.UUID()),
+	)
+}
+
+func (m *Model) Update(msg tea.Msg) tea.Cmd {
+	var cmds []tea.Cmd
+
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		return m.table.Update(msg)
+	case types.PageVisibleMsg:
+		return types.RefreshData(m.UUID())
+	case types.RefreshDataMsg:
+		return tea.Batch(
+			types.PageLoading(),
+			m.table.Fetch(false),
+		)
+	case types.AppFilterMsg:
+		if msg.UUID != m.UUID() {
+			return nil
+		}
+		m.table.SetFilter(msg.Text)
+	case typeSelectedMsg:
+		if msg.uuid != m.UUID() {
+			return nil
+		}
+		return m.enableWith(msg.deviceType)
+	case types.ClientMsg:
+		if msg.UUID != m.UUID() {
+			return nil
+		}
+		if msg.Error != nil {
+			return types.PageErrors(msg.Error)
+		}
+
+		switch vmsg := msg.Msg.(type) {
+		case types.ClientListAuditDevicesMsg:
+			cmds = append(cmds, types.PageClearState())
+			m.table.SetRows(table.RowsFrom(vmsg.Devices, func(d *vapi.Audit) table.ID {
+				return table.ID(d.Path)
+			}))
+		case types.ClientAuditHashMsg:
+			return tea.Batch(
+				types.SetClipboard(vmsg.Hash),
+				types.OpenDialog(alert.New(m.app, alert.Config{
+					Title: "Audit hash",
+					Message: fmt.Sprintf(
+						"%s\n\nThe value as it appears in the log of %q (copied to clipboard).",
+						vmsg.Hash,
+						vmsg.Path,
+					),
+				})),
+			)
+		}
+	case tea.KeyMsg:
+		switch {
+		case key.Matches(msg, types.KeyEnableAuditDevice):
+			return m.enable()
+		case key.Matches(msg, types.KeyDelete):
+			if row, ok := m.table.GetSelectedRow(); ok {
+				return m.disable(row.Value)
+			}
+		case key.Matches(msg, types.KeyAuditHash):
+			return m.hash()
+		}
+	}
+
+	return tea.Batch(append(cmds, m.table.Update(msg))...)
+}
+
+// enable asks for the type of the audit device to enable, as the available
+// options depend on it.
+func (m *Model) enable() tea.Cmd {
+	return types.OpenDialog(formdialog.New(m.app, formdialog.Config{
+		Title: "Enable audit device",
+		Fields: []*form.Field{{
+			ID:      "type",
+			Label:   "Type",
+			Options: deviceTypes,
+		}},
+		ConfirmText: "next",
+		ConfirmFn: func(values map[string]string) tea.Cmd {
+			return func() tea.Msg {
+				return typeSelectedMsg{uuid: m.UUID(), deviceType: values["type"]}
+			}
+		},
+	}))
+}
+
+// enableWith asks for the path and options of an audit device of the provided
+// type, and enables it.
+func (m *Model) enableWith(deviceType string) tea.Cmd {
+	fields := []*form.Field{
+		{
+			ID:        "path",
+			Label:     "Path",
+			Value:     deviceType,
+			Validator: form.ValidateRequired,
+		},
+		{
+			ID:          "description",
+			Label:       "Description",
+			Placeholder: "optional",
+		},
+		{
+			ID:      "local",
+			Label:   "Local (not replicated)",
+			Options: []string{"false", "true"},
+		},
+	}
+	fields = append(fields, optionFields(deviceType)...)
+
+	return types.OpenDialog(formdialog.New(m.app, formdialog.Config{
+		Title:       fmt.Sprintf("Enable %s audit device", deviceType),
+		Fields:      fields,
+		ConfirmText: "enable",
+		ConfirmFn: func(values map[string]string) tea.Cmd {
+			path := strings.Trim(strings.TrimSpace(values["path"]), "/")
+			options := &vapi.EnableAuditOptions{
+				Type:        deviceType,
+				Description: strings.TrimSpace(values["description"]),
+				Local:       values["local"] == "true",
+				Options:     map[string]string{},
+			}
+
+			// All other fields are options of the device.
+			for id, v := range values {
+				if v = strings.TrimSpace(v); v != "" && !slices.Contains([]string{"path", "description", "local"}, id) {
+					options.Options[id] = v
+				}
+			}
+
+			return tea.Sequence(
+				m.app.Client().EnableAuditDevice(m.UUID(), path, options),
+				types.RefreshData(m.UUID()),
+			)
+		},
+	}))
+}
+
+// optionFields returns the fields for the options of the provided audit device
+// type, followed by those common to all types.
+func optionFields(deviceType string) []*form.Field {
+	var fields []*form.Field
+
+	switch deviceType {
+	case "file":
+		fields = append(fields,
+			&form.Field{
+				ID:        "file_path",
+				Label:     "File path",
+				Value:     "/var/log/vault/audit.log",
+				Validator: form.ValidateRequired,
+			},
+			&form.Field{
+				ID:    "mode",
+				Label: "Mode",
+				Value: "0600",
+			},
+		)
+	case "socket":
+		fields = append(fields,
+			&form.Field{
+				ID:          "address",
+				Label:       "Address",
+				Placeholder: "127.0.0.1:9090",
+				Validator:   form.ValidateRequired,
+			},
+			&form.Field{
+				ID:      "socket_type",
+				Label:   "Socket type",
+				Options: []string{"tcp", "udp", "unix"},
+			},
+			&form.Field{
+				ID:    "write_timeout",
+				Label: "Write timeout",
+				Value: "2s",
+			},
+		)
+	case "syslog":
+		fields = append(fields,
+			&form.Field{
+				ID:    "facility",
+				Label: "Facility",
+				Value: "AUTH",
+			},
+			&form.Field{
+				ID:    "tag",
+				Label: "Tag",
+				Value: "vault",
+			},
+		)
+	}
+
+	return append(fields,
+		&form.Field{
+			ID:      "format",
+			Label:   "Format",
+			Options: []string{"json", "jsonx"},
+		},
+		&form.Field{
+			ID:          "prefix",
+			Label:       "Prefix",
+			Placeholder: "optional",
+		},
+		&form.Field{
+			ID:      "hmac_accessor",
+			Label:   "HMAC accessor",
+			Options: []string{"true", "false"},
+		},
+		&form.Field{
+			ID:      "log_raw",
+			Label:   "Log raw (unhashed)",
+			Options: []string{"false", "true"},
+		},
+	)
+}
+
+func (m *Model) disable(device *vapi.Audit) tea.Cmd {
+	if device == nil {
+		return nil
+	}
+	return types.OpenDialog(confirm.New(m.app, confirm.Config{
+		Title: "Disable audit device",
+		Message: fmt.Sprintf(
+			"Disable audit device %q (%s)? Requests will no longer be logged to it, and re-enabling it generates a new salt, so existing hashes can't be reproduced.",
+			device.Path,
+			device.Type,
+		),
+		ConfirmText:       "disable",
+		AllowsBlur:        true,
+		ConfirmStatus:     types.Warning,
+		TypedConfirmation: device.Path,
+		ConfirmFn: func() tea.Cmd {
+			return tea.Sequence(
+				m.app.Client().DisableAuditDevice(m.UUID(), device.Path),
+				types.CloseActiveDialog(),
+				types.RefreshData(m.UUID()),
+			)
+		},
+		CancelFn: types.CloseActiveDialog,
+	}))
+}
+
+// hash asks for a value to hash with the salt of an audit device, which defaults
+// to the selected one, so it can be searched for in its log.
+func (m *Model) hash() tea.Cmd {
+	var paths []string
+	for row := range m.table.GetRows() {
+		paths = append(paths, row.Value.Path)
+	}
+	if len(paths) == 0 {
+		return types.SendStatus("no audit devices enabled", types.Warning, 2*time.Second)
+	}
+
+	device := paths[0]
+	if row, ok := m.table.GetSelectedRow(); ok {
+		device = row.Value.Path
+	}
+
+	return types.OpenDialog(formdialog.New(m.app, formdialog.Config{
+		Title: "Audit hash",
+		Fields: []*form.Field{
+			{
+				ID:      "path",
+				Label:   "Device",
+				Value:   device,
+				Options: paths,
+			},
+			{
+				ID:          "input",
+				Label:       "Value",
+				Placeholder: "value to hash",
+				Masked:      true,
+				Validator:   form.ValidateRequired,
+			},
+		},
+		ConfirmText: "hash",
+		ConfirmFn: func(values map[string]string) tea.Cmd {
+			return m.app.Client().AuditHash(m.UUID(), strings.TrimSuffix(values["path"], "/"), values["input"])
+		},
+	}))
+}
+
+func (m *Model) View() string {
+	if m.table.Width == 0 || m.table.Height == 0 {
+		return ""
+	}
+	return m.table.View()
+}
+
+func (m *Model) TopMiddleBorder() string {
+	return styles.Pluralize(m.table.TotalFilteredRows(), "audit device", "audit devices")
+}
+
+// formatOptions formats the options of an audit device, sorted by key.
+func formatOptions(options map[string]string) string {
+	out := make([]string, 0, len(options))
+	for _, k := range slices.Sorted(maps.Keys(options)) {
+		out = append(out, k+"="+options[k])
+	}
+	return strings.Join(out, ", ")
+}
@@ -0,0 +1,561 @@
+// Copyright (c) Liam Stanley <liam@liam.sh>. All rights reserved. Use of
+// this source code is governed by the MIT license that can be found in
+// the LICENSE file.
+
+package auditlog
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"charm.land/bubbles/v2/key"
+	tea "charm.land/bubbletea/v2"
+	"charm.land/lipgloss/v2"
+	alog "github.com/lrstanley/vex/internal/auditlog"
+	"github.com/lrstanley/vex/internal/config"
+	"github.com/lrstanley/vex/internal/types"
+	"github.com/lrstanley/vex/internal/ui/components/form"
+	"github.com/lrstanley/vex/internal/ui/components/table"
+	formdialog "github.com/lrstanley/vex/internal/ui/dialogs/form"
+	"github.com/lrstanley/vex/internal/ui/dialogs/genericcode"
+	"github.com/lrstanley/vex/internal/ui/styles"
+	"github.com/lrstanley/x/charm/formatter"
+)
+
+var Commands = []string{"auditlog"}
+
+const (
+	// batchSize is the maximum number of lines read from the audit log at once,
+	// so large logs are loaded progressively.
+	batchSize = 5000
+
+	// maxEntries is the maximum number of requests kept in memory. Older requests
+	// are discarded once reached.
+	maxEntries = 100_000
+
+	// pollInterval is how often the audit log is checked for new entries, once
+	// all existing entries have been read.
+	pollInterval = time.Second
+)
+
+// view is one of the views of the audit log.
+type view int
+
+const (
+	viewRequests view = iota
+	viewPaths
+	viewClients
+	viewErrors
+)
+
+var viewNames = []string{"requests", "top paths", "top clients", "errors"}
+
+// openedMsg is sent once the audit log has been opened.
+type openedMsg struct {
+	uuid string
+	tail *alog.Tailer
+	err  error
+}
+
+// readMsg is sent with each batch of lines read from the audit log.
+type readMsg struct {
+	uuid  string
+	tail  *alog.Tailer
+	lines [][]byte
+	eof   bool
+	err   error
+}
+
+// pollMsg is sent to read the lines written to the audit log since it was last
+// read.
+type pollMsg struct {
+	uuid string
+	tail *alog.Tailer
+}
+
+var _ types.Page = (*Model)(nil) // Ensure we implement the page interface.
+
+// Model is a viewer for local Vault (file) audit logs, which follows the log as
+// it's written, and shows the requests, or aggregates of them, matching the
+// filter query (see [alog.ParseFilter]).
+type Model struct {
+	*types.PageModel
+
+	// Core state.
+	app     types.AppState
+	path    string
+	tail    *alog.Tailer
+	log     *alog.Log
+	filter  *alog.Filter
+	matched []*alog.Entry
+	errors  int // Number of matched requests which failed.
+
+	// UI state.
+	view view
+
+	// Child components.
+	requests *table.Model[*table.StaticRow[*alog.Entry]]
+	paths    *table.Model[*table.StaticRow[*alog.Group]]
+	clients  *table.Model[*table.StaticRow[*alog.Group]]
+	failures *table.Model[*table.StaticRow[*alog.Group]]
+}
+
+// New returns a new audit log viewer for the audit log at path. If path is empty,
+// the user is asked which audit log to open.
+func New(app types.AppState, path string) *Model {
+	m := &Model{
+		PageModel: &types.PageModel{
+			Commands:         Commands,
+			SupportFiltering: true,
+			ShortKeyBinds: []key.Binding{
+				types.KeyCycleAuditView,
+				types.OverrideHelp(types.KeyDetails, "details"),
+			},
+			FullKeyBinds: [][]key.Binding{{
+				types.KeyCycleAuditView,
+				types.OverrideHelp(types.KeyDetails, "details"),
+				types.OverrideHelp(types.KeyLoadFromFile, "open audit log"),
+			}},
+		},
+		app:  app,
+		path: path,
+		log:  alog.NewLog(maxEntries),
+	}
+
+	m.requests = table.New(app, table.Config[*table.StaticRow[*alog.Entry]]{
+		Columns: []*table.Column[*table.StaticRow[*alog.Entry]]{
+			{
+				ID:    "time",
+				Title: "Time",
+				AccessorFn: func(row *table.StaticRow[*alog.Entry]) string {
+					return row.Value.Time.Local().Format(time.DateTime)
+				},
+			},
+			{
+				ID:    "operation",
+				Title: "Operation",
+				AccessorFn: func(row *table.StaticRow[*alog.Entry]) string {
+					return row.Value.Operation
+				},
+			},
+			{
+				ID:    "path",
+				Title: "Path",
+				AccessorFn: func(row *table.StaticRow[*alog.Entry]) string {
+					return row.Value.Path
+				},
+			},
+			{
+				ID:    "mount",
+				Title: "Mount",
+				AccessorFn: func(row *table.StaticRow[*alog.Entry]) string {
+					return row.Value.MountPoint
+				},
+			},
+			{
+				ID:    "client",
+				Title: "Client",
+				AccessorFn: func(row *table.StaticRow[*alog.Entry]) string {
+					return row.Value.Client()
+				},
+			},
+			{
+				ID:    "remote",
+				Title: "Remote",
+				AccessorFn: func(row *table.StaticRow[*alog.Entry]) string {
+					return row.Value.RemoteAddr
+				},
+			},
+			{
+				ID:    "duration",
+				Title: "Duration",
+				Align: lipgloss.Right,
+				AccessorFn: func(row *table.StaticRow[*alog.Entry]) string {
+					if !row.Value.Responded {
+						return "pending"
+					}
+					return row.Value.Duration.Round(time.Millisecond).String()
+				},
+			},
+			{
+				ID:       "error",
+				Title:    "Error",
+				MaxWidth: 60,
+				AccessorFn: func(row *table.StaticRow[*alog.Entry]) string {
+					return row.Value.Error
+				},
+				StyleFn: func(_ *table.StaticRow[*alog.Entry], baseStyle lipgloss.Style, _, _ bool) lipgloss.Style {
+					return baseStyle.Foreground(styles.Theme.ErrorFg())
+				},
+			},
+		},
+		NoResultsMsg: "no matching requests",
+	})
+
+	m.paths = newGroupTable(app, "Path", "no matching requests")
+	m.clients = newGroupTable(app, "Client", "no matching requests")
+
+	m.failures = table.New(app, table.Config[*table.StaticRow[*alog.Group]]{
+		Columns: []*table.Column[*table.StaticRow[*alog.Group]]{
+			{
+				ID:       "error",
+				Title:    "Error",
+				MaxWidth: 80,
+				AccessorFn: func(row *table.StaticRow[*alog.Group]) string {
+					return row.Value.Key
+				},
+				StyleFn: func(_ *table.StaticRow[*alog.Group], baseStyle lipgloss.Style, _, _ bool) lipgloss.Style {
+					return baseStyle.Foreground(styles.Theme.ErrorFg())
+				},
+			},
+			{
+				ID:    "count",
+				Title: "Count",
+				Align: lipgloss.Right,
+				AccessorFn: func(row *table.StaticRow[*alog.Group]) string {
+					return strconv.Itoa(row.Value.Requests)
+				},
+			},
+			{
+				ID:    "share",
+				Title: "Share",
+				Align: lipgloss.Right,
+				AccessorFn: func(row *table.StaticRow[*alog.Group]) string {
+					return formatPercent(row.Value.Requests, m.errors)
+				},
+			},
+			{
+				ID:    "last_path",
+				Title: "Last path",
+				AccessorFn: func(row *table.StaticRow[*alog.Group]) string {
+					return row.Value.LastPath
+				},
+			},
+			{
+				ID:    "last_seen",
+				Title: "Last seen",
+				AccessorFn: func(row *table.StaticRow[*alog.Group]) string {
+					return formatter.TimeRelative(row.Value.Last, true)
+				},
+			},
+		},
+		NoResultsMsg: "no failed requests",
+	})
+
+	return m
+}
+
+// newGroupTable returns a table of requests aggregated by key.
+func newGroupTable(app types.AppState, title, noResults string) *table.Model[*table.StaticRow[*alog.Group]] {
+	return table.New(app, table.Config[*table.StaticRow[*alog.Group]]{
+		Columns: []*table.Column[*table.StaticRow[*alog.Group]]{
+			{
+				ID:    "key",
+				Title: title,
+				AccessorFn: func(row *table.StaticRow[*alog.Group]) string {
+					return row.Value.Key
+				},
+			},
+			{
+				ID:    "requests",
+				Title: "Requests",
+				Align: lipgloss.Right,
+				AccessorFn: func(row *table.StaticRow[*alog.Group]) string {
+					return strconv.Itoa(row.Value.Requests)
+				},
+			},
+			{
+				ID:    "errors",
+				Title: "Errors",
+				Align: lipgloss.Right,
+				AccessorFn: func(row *table.StaticRow[*alog.Group]) string {
+					return strconv.Itoa(row.Value.Errors)
+				},
+			},
+			{
+				ID:    "error_rate",
+				Title: "Error rate",
+				Align: lipgloss.Right,
+				AccessorFn: func(row *table.StaticRow[*alog.Group]) string {
+					return formatPercent(row.Value.Errors, row.Value.Requests)
+				},
+				StyleFn: func(row *table.StaticRow[*alog.Group], baseStyle lipgloss.Style, _, _ bool) lipgloss.Style {
+					if row.Value.Errors > 0 {
+						return baseStyle.Foreground(styles.Theme.ErrorFg())
+					}
+					return baseStyle
+				},
+			},
+			{
+				ID:    "last_seen",
+				Title: "Last seen",
+				AccessorFn: func(row *table.StaticRow[*alog.Group]) string {
+					return formatter.TimeRelative(row.Value.Last, true)
+				},
+			},
+		},
+		NoResultsMsg: noResults,
+	})
+}
+
+// formatPercent formats n as a percentage of total.
+func formatPercent(n, total int) string {
+	if total == 0 {
+		return "0%"
+	}
+	return strconv.FormatFloat(float64(n)*100/float64(total), 'f', 1, 64) + "%"
+}
+
+func (m *Model) Init() tea.Cmd {
+	cmds := []tea.Cmd{
+		m.requests.Init(),
+		m.paths.Init(),
+		m.clients.Init(),
+		m.failures.Init(),
+	}
+	if m.path == "" {
+		cmds = append(cmds, m.openPrompt())
+	} else {
+		cmds = append(cmds, types.PageLoading(), open(m.UUID(), m.path))
+	}
+	return tea.Batch(cmds...)
+}
+
+// open opens the audit log at path.
+func open(uuid, path string) tea.Cmd {
+	return func() tea.Msg {
+		path, err := config.ExpandPath(path)
+		if err != nil {
+			return openedMsg{uuid: uuid, err: err}
+		}
+
+		tail, err := alog.Open(path)
+		if err != nil {
+			return openedMsg{uuid: uuid, err: fmt.Errorf("open audit log: %w", err)}
+		}
+		return openedMsg{uuid: uuid, tail: tail}
+	}
+}
+
+// read reads the next batch of lines from the audit log.
+func read(uuid string, tail *alog.Tailer) tea.Cmd {
+	return func() tea.Msg {
+		lines, err := tail.Read(batchSize)
+		msg := readMsg{uuid: uuid, tail: tail, lines: lines, eof: errors.Is(err, io.EOF)}
+		if !msg.eof {
+			msg.err = err
+		}
+		return msg
+	}
+}
+
+// openPrompt asks the user which audit log to open.
+func (m *Model) openPrompt() tea.Cmd {
+	return types.OpenDialog(formdialog.New(m.app, formdialog.Config{
+		Title: "Open audit log",
+		Fields: []*form.Field{{
+			ID:          "path",
+			Label:       "Path",
+			Placeholder: "/var/log/vault/audit.log",
+			Value:       m.path,
+			Validator:   form.ValidateRequired,
+		}},
+		ConfirmText: "open",
+		ConfirmFn: func(values map[string]string) tea.Cmd {
+			return open(m.UUID(), strings.TrimSpace(values["path"]))
+		},
+	}))
+}
+
+// active returns the table of the current view.
+func (m *Model) active() interface {
+	Update(msg tea.Msg) tea.Cmd
+	View() string
+} {
+	switch m.view {
+	case viewPaths:
+		return m.paths
+	case viewClients:
+		return m.clients
+	case viewErrors:
+		return m.failures
+	default:
+		return m.requests
+	}
+}
+
+func (m *Model) Update(msg tea.Msg) tea.Cmd {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg, styles.ThemeUpdatedMsg:
+		return tea.Batch(
+			m.requests.Update(msg),
+			m.paths.Update(msg),
+			m.clients.Update(msg),
+			m.failures.Update(msg),
+		)
+	case types.RefreshDataMsg:
+		if msg.UUID != m.UUID() || m.path == "" {
+			return nil
+		}
+		// Reload the audit log from the start.
+		return tea.Batch(types.PageLoading(), open(m.UUID(), m.path))
+	case types.AppFilterMsg:
+		if msg.UUID != m.UUID() {
+			return nil
+		}
+
+		filter, err := alog.ParseFilter(msg.Text, time.Now())
+		if err != nil {
+			return types.SendStatus(err.Error(), types.Warning, 3*time.Second)
+		}
+		m.filter = filter
+		m.refresh()
+		return nil
+	case openedMsg:
+		if msg.uuid != m.UUID() {
+			return nil
+		}
+		if msg.err != nil {
+			return types.PageErrors(msg.err)
+		}
+
+		m.closeTail()
+		m.tail = msg.tail
+		m.path = msg.tail.Path()
+		m.log = alog.NewLog(maxEntries)
+		m.refresh()
+		return tea.Batch(types.PageClearState(), read(m.UUID(), m.tail))
+	case readMsg:
+		if msg.uuid != m.UUID() || msg.tail != m.tail {
+			return nil
+		}
+		if msg.err != nil {
+			return types.PageErrors(fmt.Errorf("read audit log: %w", msg.err))
+		}
+
+		for _, line := range msg.lines {
+			_ = m.log.Add(line) // Malformed lines are counted by the log.
+		}
+		if len(msg.lines) > 0 {
+			m.refresh()
+		}
+
+		if msg.eof {
+			return types.MsgAfterDuration(pollMsg{uuid: msg.uuid, tail: msg.tail}, pollInterval)
+		}
+		return read(m.UUID(), m.tail)
+	case pollMsg:
+		if msg.uuid != m.UUID() || msg.tail != m.tail {
+			return nil
+		}
+		return read(m.UUID(), m.tail)
+	case tea.KeyMsg:
+		switch {
+		case key.Matches(msg, types.KeyCycleAuditView):
+			m.view = (m.view + 1) % view(len(viewNames))
+			return nil
+		case key.Matches(msg, types.KeyLoadFromFile):
+			return m.openPrompt()
+		case key.Matches(msg, types.KeyDetails):
+			if m.view != viewRequests {
+				return nil
+			}
+			if row, ok := m.requests.GetSelectedRow(); ok {
+				return types.OpenDialog(genericcode.NewYAML(
+					m.app,
+					fmt.Sprintf("%s: %s", row.Value.Operation, row.Value.Path),
+					false,
+					row.Value,
+				))
+			}
+			return nil
+		}
+		return m.active().Update(msg)
+	}
+
+	return tea.Batch(
+		m.requests.Update(msg),
+		m.paths.Update(msg),
+		m.clients.Update(msg),
+		m.failures.Update(msg),
+	)
+}
+
+// refresh applies the filter to the requests read so far, and updates all views.
+func (m *Model) refresh() {
+	m.matched = m.matched[:0]
+	m.errors = 0
+	for _, e := range m.log.Entries {
+		if m.filter.Match(e) {
+			m.matched = append(m.matched, e)
+			if e.Error != "" {
+				m.errors++
+			}
+		}
+	}
+
+	// Most recent requests first.
+	rows := make([]*table.StaticRow[*alog.Entry], 0, len(m.matched))
+	for i, e := range slices.Backward(m.matched) {
+		id := e.ID
+		if id == "" {
+			id = strconv.Itoa(i)
+		}
+		rows = append(rows, &table.StaticRow[*alog.Entry]{Value: e, ValueID: table.ID(id)})
+	}
+	m.requests.SetRows(rows)
+
+	groupID := func(g *alog.Group) table.ID { return table.ID(g.Key) }
+	m.paths.SetRows(table.RowsFrom(alog.GroupBy(m.matched, alog.ByPath), groupID))
+	m.clients.SetRows(table.RowsFrom(alog.GroupBy(m.matched, alog.ByClient), groupID))
+	m.failures.SetRows(table.RowsFrom(alog.GroupBy(m.matched, alog.ByError), groupID))
+}
+
+func (m *Model) View() string {
+	if m.requests.Width == 0 || m.requests.Height == 0 {
+		return ""
+	}
+	return m.active().View()
+}
+
+func (m *Model) GetTitle() string {
+	if m.path == "" {
+		return "Audit log"
+	}
+	return "Audit log: " + filepath.Base(m.path)
+}
+
+func (m *Model) TopMiddleBorder() string {
+	out := fmt.Sprintf(
+		"%s: %s, %s errors",
+		viewNames[m.view],
+		styles.Pluralize(len(m.matched), "request", "requests"),
+		formatPercent(m.errors, len(m.matched)),
+	)
+	if m.log.Malformed > 0 {
+		out += fmt.Sprintf(", %d malformed", m.log.Malformed)
+	}
+	return out
+}
+
+// closeTail closes the audit log currently being read, if any. Reads which are
+// still in progress are discarded, as they no longer match [Model.tail].
+func (m *Model) closeTail() {
+	if m.tail == nil {
+		return
+	}
+	tail := m.tail
+	m.tail = nil
+	_ = tail.Close()
+}
+
+func (m *Model) Close() tea.Cmd {
+	m.closeTail()
+	return nil
+}
@@ -0,0 +1,255 @@
+// Copyright (c) Liam Stanley <liam@liam.sh>. All rights reserved. Use of
+// this source code is governed by the MIT license that can be found in
+// the LICENSE file.
+
+package bookmarks
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"charm.land/bubbles/v2/key"
+	tea "charm.land/bubbletea/v2"
+	"github.com/lrstanley/vex/internal/config"
+	"github.com/lrstanley/vex/internal/types"
+	"github.com/lrstanley/vex/internal/ui/components/form"
+	"github.com/lrstanley/vex/internal/ui/components/table"
+	"github.com/lrstanley/vex/internal/ui/dialogs/confirm"
+	formdialog "github.com/lrstanley/vex/internal/ui/dialogs/form"
+	"github.com/lrstanley/vex/internal/ui/navigator"
+	"github.com/lrstanley/vex/internal/ui/styles"
+	"github.com/lrstanley/x/charm/formatter"
+)
+
+var Commands = []string{"bookmarks", "bookmark"}
+
+var _ types.Page = (*Model)(nil) // Ensure we implement the page interface.
+
+// Model lists the bookmarks of the current cluster profile, and allows jumping
+// directly to them.
+type Model struct {
+	*types.PageModel
+
+	// Core state.
+	app types.AppState
+
+	// UI state.
+	mounts []*types.Mount
+
+	// Child components.
+	table *table.Model[*table.StaticRow[config.Bookmark]]
+}
+
+func New(app types.AppState) *Model {
+	m := &Model{
+		PageModel: &types.PageModel{
+			Commands:         Commands,
+			SupportFiltering: true,
+			ShortKeyBinds: []key.Binding{
+				types.OverrideHelp(types.KeyAddKey, "add bookmark"),
+				types.KeyDelete,
+			},
+			FullKeyBinds: [][]key.Binding{{
+				types.OverrideHelp(types.KeyAddKey, "add bookmark"),
+				types.OverrideHelp(types.KeyRenameKey, "rename bookmark"),
+				types.KeyDelete,
+			}},
+		},
+		app: app,
+	}
+
+	m.table = table.New(app, table.Config[*table.StaticRow[config.Bookmark]]{
+		Columns: []*table.Column[*table.StaticRow[config.Bookmark]]{
+			{
+				ID:    "name",
+				Title: "Name",
+				AccessorFn: func(row *table.StaticRow[config.Bookmark]) string {
+					return row.Value.Name
+				},
+			},
+			{
+				ID:    "path",
+				Title: "Path",
+				AccessorFn: func(row *table.StaticRow[config.Bookmark]) string {
+					if strings.HasSuffix(row.Value.Path, "/") {
+						return styles.IconFolder() + " " + row.Value.Path
+					}
+					return styles.IconSecret() + " " + row.Value.Path
+				},
+			},
+			{
+				ID:    "version",
+				Title: "Version",
+				AccessorFn: func(row *table.StaticRow[config.Bookmark]) string {
+					if row.Value.Version > 0 {
+						return strconv.Itoa(row.Value.Version)
+					}
+					return "latest"
+				},
+			},
+			{
+				ID:    "created",
+				Title: "Created",
+				AccessorFn: func(row *table.StaticRow[config.Bookmark]) string {
+					return formatter.TimeRelative(row.Value.CreatedAt, true)
+				},
+			},
+		},
+		SelectFn: func(value *table.StaticRow[config.Bookmark]) tea.Cmd {
+			return m.open(value.Value)
+		},
+	})
+
+	return m
+}
+
+func (m *Model) Init() tea.Cmd {
+	return tea.Batch(
+		m.table.Init(),
+		types.RefreshData(m.UUID()),
+	)
+}
+
+func (m *Model) Update(msg tea.Msg) tea.Cmd {
+	var cmds []tea.Cmd
+
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		return m.table.Update(msg)
+	case types.PageVisibleMsg:
+		return types.RefreshData(m.UUID())
+	case types.RefreshDataMsg:
+		return tea.Batch(
+			m.load(),
+			m.app.Client().ListMounts(m.UUID()),
+		)
+	case types.AppFilterMsg:
+		if msg.UUID != m.UUID() {
+			return nil
+		}
+		m.table.SetFilter(msg.Text)
+	case types.ClientMsg:
+		if msg.UUID != m.UUID() {
+			return nil
+		}
+		if msg.Error != nil {
+			return types.PageErrors(msg.Error)
+		}
+
+		if vmsg, ok := msg.Msg.(types.ClientListMountsMsg); ok {
+			m.mounts = vmsg.Mounts
+		}
+	case tea.KeyPressMsg:
+		switch {
+		case key.Matches(msg, types.KeyAddKey):
+			return m.edit(config.Bookmark{}, true)
+		case key.Matches(msg, types.KeyRenameKey):
+			if v, ok := m.table.GetSelectedRow(); ok {
+				return m.edit(v.Value, false)
+			}
+		case key.Matches(msg, types.KeyDelete):
+			if v, ok := m.table.GetSelectedRow(); ok {
+				return m.remove(v.Value)
+			}
+		}
+	}
+
+	return tea.Batch(append(cmds, m.table.Update(msg))...)
+}
+
+// load loads the bookmarks of the current cluster profile from disk.
+func (m *Model) load() tea.Cmd {
+	bookmarks, err := config.GetBookmarks(m.app.Client().Profile())
+	if err != nil {
+		return types.PageErrors(err)
+	}
+
+	m.table.SetRows(table.RowsFrom(bookmarks, func(b config.Bookmark) table.ID {
+		return table.ID(b.Target())
+	}))
+	return types.PageClearState()
+}
+
+func (m *Model) open(bookmark config.Bookmark) tea.Cmd {
+	if m.mounts == nil {
+		return types.SendStatus("mounts are still loading", types.Warning, 2*time.Second)
+	}
+	return navigator.Open(m.app, m.mounts, bookmark.Target())
+}
+
+// edit opens a form to add a new bookmark, or rename an existing one.
+func (m *Model) edit(bookmark config.Bookmark, isNew bool) tea.Cmd {
+	title := "Add bookmark"
+	if !isNew {
+		title = "Rename bookmark: " + bookmark.Target()
+	}
+
+	fields := []*form.Field{{
+		ID:          "name",
+		Label:       "Name",
+		Placeholder: "optional",
+		Value:       bookmark.Name,
+	}}
+	if isNew {
+		fields = append([]*form.Field{{
+			ID:          "path",
+			Label:       "Path",
+			Placeholder: "secret/foo/bar@2",
+			Validator: func(value string) error {
+				_, _, err := navigator.ParseTarget(value)
+				return err
+			},
+		}}, fields...)
+	}
+
+	return types.OpenDialog(formdialog.New(m.app, formdialog.Config{
+		Title:  title,
+		Fields: fields,
+		ConfirmFn: func(values map[string]string) tea.Cmd {
+			if isNew {
+				bookmark.Path, bookmark.Version, _ = navigator.ParseTarget(values["path"])
+			}
+			bookmark.Name = strings.TrimSpace(values["name"])
+
+			if err := config.AddBookmark(m.app.Client().Profile(), bookmark); err != nil {
+				return types.SendStatus("unable to save bookmark: "+err.Error(), types.Error, 3*time.Second)
+			}
+			return types.RefreshData(m.UUID())
+		},
+	}))
+}
+
+func (m *Model) remove(bookmark config.Bookmark) tea.Cmd {
+	return types.OpenDialog(confirm.New(m.app, confirm.Config{
+		Title:         fmt.Sprintf("Remove bookmark %s", bookmark.Title()),
+		Message:       "Are you sure you want to remove this bookmark?",
+		AllowsBlur:    true,
+		ConfirmStatus: types.Warning,
+		ConfirmFn: func() tea.Cmd {
+			if err := config.RemoveBookmark(m.app.Client().Profile(), bookmark.Target()); err != nil {
+				return tea.Sequence(
+					types.CloseActiveDialog(),
+					types.SendStatus("unable to remove bookmark: "+err.Error(), types.Error, 3*time.Second),
+				)
+			}
+			return tea.Sequence(
+				types.CloseActiveDialog(),
+				types.RefreshData(m.UUID()),
+			)
+		},
+		CancelFn: types.CloseActiveDialog,
+	}))
+}
+
+func (m *Model) View() string {
+	if m.table.Width == 0 || m.table.Height == 0 {
+		return ""
+	}
+	return m.table.View()
+}
+
+func (m *Model) TopMiddleBorder() string {
+	return styles.Pluralize(m.table.TotalFilteredRows(), "bookmark", "bookmarks")
+}
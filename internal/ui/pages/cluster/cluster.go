@@ -0,0 +1,445 @@
+// Copyright (c) Liam Stanley <liam@liam.sh>. All rights reserved. Use of
+// this source code is governed by the MIT license that can be found in
+// the LICENSE file.
+
+package cluster
+
+import (
+	"fmt"
+	"maps"
+	"net"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	tea "charm.land/bubbletea/v2"
+	"charm.land/lipgloss/v2"
+	vapi "github.com/hashicorp/vault/api"
+	"github.com/lrstanley/vex/internal/config"
+	"github.com/lrstanley/vex/internal/types"
+	"github.com/lrstanley/vex/internal/ui/components/table"
+	"github.com/lrstanley/vex/internal/ui/styles"
+)
+
+var Commands = []string{"cluster", "nodes"}
+
+// historySize is the number of latency samples shown in the sparkline of each
+// node.
+const historySize = 20
+
+const (
+	stateLeader        = "leader"
+	stateStandby       = "standby"
+	statePerfStandby   = "perf-standby"
+	stateSealed        = "sealed"
+	stateUninitialized = "uninitialized"
+	stateUnreachable   = "unreachable"
+	statePending       = "pending"
+)
+
+// node is the health of a single node, as of the last time it was polled.
+type node struct {
+	address string
+	health  *vapi.HealthResponse
+	err     string
+	latency time.Duration
+	history []time.Duration // Latency of the last checks, -1 if the check failed.
+	polling bool            // Whether a health check is in flight.
+}
+
+func (n *node) state() string {
+	switch {
+	case n.err != "":
+		return stateUnreachable
+	case n.health == nil:
+		return statePending
+	case !n.health.Initialized:
+		return stateUninitialized
+	case n.health.Sealed:
+		return stateSealed
+	case n.health.PerformanceStandby:
+		return statePerfStandby
+	case n.health.Standby:
+		return stateStandby
+	default:
+		return stateLeader
+	}
+}
+
+func (n *node) version() string {
+	if n.err != "" || n.health == nil {
+		return ""
+	}
+	return n.health.Version
+}
+
+var _ types.Page = (*Model)(nil) // Ensure we implement the page interface.
+
+// Model shows an overview of each node of the cluster, polling the health of
+// every node independently. Nodes are configured through the profile settings
+// (see [config.ProfileSettings.Nodes]), and discovered from the raft
+// configuration.
+type Model struct {
+	*types.PageModel
+
+	// Core state.
+	app        types.AppState
+	nodes      map[string]*node
+	discovered []string // API addresses of nodes discovered from raft peers.
+
+	// Child components.
+	table *table.Model[*table.StaticRow[*node]]
+}
+
+func New(app types.AppState) *Model {
+	m := &Model{
+		PageModel: &types.PageModel{
+			Commands:         Commands,
+			SupportFiltering: true,
+			RefreshInterval:  5 * time.Second,
+		},
+		app:   app,
+		nodes: make(map[string]*node),
+	}
+
+	m.table = table.New(app, table.Config[*table.StaticRow[*node]]{
+		Columns: []*table.Column[*table.StaticRow[*node]]{
+			{
+				ID:    "address",
+				Title: "Address",
+				AccessorFn: func(row *table.StaticRow[*node]) string {
+					return row.Value.address
+				},
+			},
+			{
+				ID:       "state",
+				Title:    "State",
+				MaxWidth: 14,
+				AccessorFn: func(row *table.StaticRow[*node]) string {
+					return row.Value.state()
+				},
+				StyleFn: func(row *table.StaticRow[*node], baseStyle lipgloss.Style, _, _ bool) lipgloss.Style {
+					switch row.Value.state() {
+					case stateLeader:
+						return baseStyle.Foreground(styles.Theme.SuccessFg())
+					case stateUnreachable:
+						return baseStyle.Foreground(styles.Theme.ErrorFg()).Bold(true)
+					case stateSealed, stateUninitialized:
+						return baseStyle.Foreground(styles.Theme.WarningFg())
+					}
+					return baseStyle
+				},
+			},
+			{
+				ID:       "version",
+				Title:    "Version",
+				MaxWidth: 16,
+				AccessorFn: func(row *table.StaticRow[*node]) string {
+					return row.Value.version()
+				},
+				StyleFn: func(row *table.StaticRow[*node], baseStyle lipgloss.Style, _, _ bool) lipgloss.Style {
+					if m.skewed(row.Value) {
+						return baseStyle.Foreground(styles.Theme.WarningFg()).Bold(true)
+					}
+					return baseStyle
+				},
+			},
+			{
+				ID:       "cluster",
+				Title:    "Cluster",
+				MaxWidth: 20,
+				AccessorFn: func(row *table.StaticRow[*node]) string {
+					if row.Value.health == nil {
+						return ""
+					}
+					return row.Value.health.ClusterName
+				},
+			},
+			{
+				ID:               "latency",
+				Title:            "Latency",
+				MaxWidth:         10,
+				Align:            lipgloss.Right,
+				DisableFiltering: true,
+				AccessorFn: func(row *table.StaticRow[*node]) string {
+					if row.Value.err != "" || row.Value.health == nil {
+						return ""
+					}
+					return formatLatency(row.Value.latency)
+				},
+			},
+			{
+				ID:               "history",
+				Title:            "History",
+				MinWidth:         historySize,
+				DisableFiltering: true,
+				AccessorFn: func(row *table.StaticRow[*node]) string {
+					samples := make([]float64, len(row.Value.history))
+					for i, v := range row.Value.history {
+						samples[i] = float64(v)
+					}
+					return styles.Sparkline(samples, float64(m.maxLatency()))
+				},
+			},
+			{
+				ID:    "error",
+				Title: "Error",
+				AccessorFn: func(row *table.StaticRow[*node]) string {
+					return row.Value.err
+				},
+				StyleFn: func(_ *table.StaticRow[*node], baseStyle lipgloss.Style, _, _ bool) lipgloss.Style {
+					return baseStyle.Foreground(styles.Theme.ErrorFg())
+				},
+			},
+		},
+		NoResultsMsg: "no nodes configured or discovered",
+	})
+
+	return m
+}
+
+func (m *Model) Init() tea.Cmd {
+	return tea.Batch(
+		m.table.Init(),
+		m.discover(),
+	)
+}
+
+// discoverUUID is the UUID used to discover nodes from the raft configuration,
+// to tell apart its response from the health checks.
+func (m *Model) discoverUUID() string {
+	return m.UUID() + "/discover"
+}
+
+// discover discovers the nodes of the cluster from the raft configuration.
+func (m *Model) discover() tea.Cmd {
+	return m.app.Client().GetRaftConfig(m.discoverUUID())
+}
+
+func (m *Model) Update(msg tea.Msg) tea.Cmd {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		return m.table.Update(msg)
+	case types.PageVisibleMsg:
+		return m.discover()
+	case types.RefreshDataMsg:
+		if msg.UUID != m.UUID() {
+			return nil
+		}
+		return m.poll()
+	case types.AppFilterMsg:
+		if msg.UUID != m.UUID() {
+			return nil
+		}
+		m.table.SetFilter(msg.Text)
+		return nil
+	case types.ClientMsg:
+		switch msg.UUID {
+		case m.discoverUUID():
+			// Discovery is best-effort, as not all clusters use integrated storage
+			// (or allow reading its configuration).
+			m.discovered = nil
+			if vmsg, ok := msg.Msg.(types.ClientRaftConfigMsg); ok && msg.Error == nil {
+				m.discovered = m.peerAddresses(vmsg.Peers)
+			}
+			m.setNodes()
+			return m.poll()
+		case m.UUID():
+			if vmsg, ok := msg.Msg.(types.ClientNodeHealthMsg); ok {
+				m.updateNode(vmsg)
+			}
+			return nil
+		}
+		return nil
+	}
+
+	return m.table.Update(msg)
+}
+
+// setNodes updates the nodes to those configured and discovered, falling back
+// to the configured address if there are none, keeping the history of nodes
+// which were already known.
+func (m *Model) setNodes() {
+	addresses := slices.Clone(config.Get().Profile(m.app.Client().Profile()).Nodes)
+	addresses = append(addresses, m.discovered...)
+	if len(addresses) == 0 {
+		addresses = append(addresses, m.address())
+	}
+
+	nodes := make(map[string]*node, len(addresses))
+	for _, address := range addresses {
+		address = strings.TrimSuffix(address, "/")
+		if n, ok := m.nodes[address]; ok {
+			nodes[address] = n
+			continue
+		}
+		nodes[address] = &node{address: address}
+	}
+	m.nodes = nodes
+
+	rows := table.RowsFrom(slices.Collect(maps.Values(m.nodes)), func(n *node) table.ID {
+		return table.ID(n.address)
+	})
+	slices.SortFunc(rows, func(a, b *table.StaticRow[*node]) int {
+		return strings.Compare(a.Value.address, b.Value.address)
+	})
+	m.table.SetRows(rows)
+}
+
+// poll checks the health of each node which doesn't already have a health check
+// in flight, so slow or unreachable nodes don't hold up the others.
+func (m *Model) poll() tea.Cmd {
+	cmds := make([]tea.Cmd, 0, len(m.nodes))
+	for _, n := range m.nodes {
+		if n.polling {
+			continue
+		}
+		n.polling = true
+		cmds = append(cmds, m.app.Client().GetNodeHealth(m.UUID(), n.address))
+	}
+	return tea.Batch(cmds...)
+}
+
+func (m *Model) updateNode(msg types.ClientNodeHealthMsg) {
+	n, ok := m.nodes[msg.Address]
+	if !ok {
+		return // No longer part of the cluster.
+	}
+
+	n.polling = false
+	n.health = msg.Health
+	n.err = msg.Error
+	n.latency = msg.Latency
+
+	sample := msg.Latency
+	if msg.Error != "" {
+		sample = -1
+	}
+	n.history = append(n.history, sample)
+	if len(n.history) > historySize {
+		n.history = slices.Clone(n.history[len(n.history)-historySize:])
+	}
+
+	m.table.UpdateRow(&table.StaticRow[*node]{Value: n, ValueID: table.ID(n.address)})
+}
+
+// address returns the configured address of the client, without the namespace.
+func (m *Model) address() string {
+	address, _, _ := strings.Cut(m.app.Client().Profile(), "#")
+	return address
+}
+
+// peerAddresses returns the API addresses of raft peers, which only report their
+// cluster address. The API is assumed to use the same scheme and port as the
+// configured address.
+func (m *Model) peerAddresses(peers []*types.RaftConfigPeer) []string {
+	u, err := url.Parse(m.address())
+	if err != nil {
+		return nil
+	}
+
+	addresses := make([]string, 0, len(peers))
+	for _, peer := range peers {
+		host, _, err := net.SplitHostPort(peer.Address)
+		if err != nil {
+			host = peer.Address
+		}
+		addresses = append(addresses, (&url.URL{
+			Scheme: u.Scheme,
+			Host:   net.JoinHostPort(host, u.Port()),
+		}).String())
+	}
+	return addresses
+}
+
+// referenceVersion returns the version nodes are expected to be running, which
+// is the version of the leader, or the most common version if there is no
+// reachable leader.
+func (m *Model) referenceVersion() string {
+	counts := make(map[string]int)
+	for _, n := range m.nodes {
+		v := n.version()
+		if v == "" {
+			continue
+		}
+		if n.state() == stateLeader {
+			return v
+		}
+		counts[v]++
+	}
+
+	var version string
+	for v, count := range counts {
+		if count > counts[version] || (count == counts[version] && v > version) {
+			version = v
+		}
+	}
+	return version
+}
+
+// skewed returns true if the node runs a different version than the rest of the
+// cluster.
+func (m *Model) skewed(n *node) bool {
+	v := n.version()
+	return v != "" && v != m.referenceVersion()
+}
+
+// maxLatency returns the highest latency of all nodes, which sparklines are
+// scaled to, so they can be compared between nodes.
+func (m *Model) maxLatency() time.Duration {
+	var v time.Duration
+	for _, n := range m.nodes {
+		v = max(v, slices.Max(append([]time.Duration{0}, n.history...)))
+	}
+	return v
+}
+
+// formatLatency formats a latency with a precision appropriate to its
+// magnitude.
+func formatLatency(d time.Duration) string {
+	if d < time.Millisecond {
+		return d.Round(time.Microsecond).String()
+	}
+	return d.Round(100 * time.Microsecond).String()
+}
+
+func (m *Model) View() string {
+	if m.table.Width == 0 || m.table.Height == 0 {
+		return ""
+	}
+	return m.table.View()
+}
+
+func (m *Model) TopMiddleBorder() string {
+	if len(m.nodes) == 0 {
+		return ""
+	}
+
+	parts := []string{styles.Pluralize(len(m.nodes), "node", "nodes")}
+
+	var unreachable, sealed int
+	versions := make(map[string]struct{})
+	for _, n := range m.nodes {
+		switch n.state() {
+		case stateUnreachable:
+			unreachable++
+		case stateSealed:
+			sealed++
+		}
+		if v := n.version(); v != "" {
+			versions[v] = struct{}{}
+		}
+	}
+
+	if unreachable > 0 {
+		parts = append(parts, fmt.Sprintf("%d unreachable", unreachable))
+	}
+	if sealed > 0 {
+		parts = append(parts, fmt.Sprintf("%d sealed", sealed))
+	}
+	if len(versions) > 1 {
+		parts = append(parts, "version skew: "+strings.Join(slices.Sorted(maps.Keys(versions)), ", "))
+	}
+	return strings.Join(parts, ", ")
+}
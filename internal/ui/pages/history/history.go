@@ -0,0 +1,313 @@
+// Copyright (c) Liam Stanley <liam@liam.sh>. All rights reserved. Use of
+// this source code is governed by the MIT license that can be found in
+// the LICENSE file.
+
+package history
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"charm.land/bubbles/v2/key"
+	tea "charm.land/bubbletea/v2"
+	"charm.land/lipgloss/v2"
+	"github.com/lrstanley/vex/internal/config"
+	"github.com/lrstanley/vex/internal/types"
+	"github.com/lrstanley/vex/internal/ui/components/form"
+	"github.com/lrstanley/vex/internal/ui/components/table"
+	formdialog "github.com/lrstanley/vex/internal/ui/dialogs/form"
+	"github.com/lrstanley/vex/internal/ui/dialogs/genericcode"
+	"github.com/lrstanley/vex/internal/ui/styles"
+)
+
+var Commands = []string{"history", "journal"}
+
+// exportedMsg is sent when the journal has been exported to a file.
+type exportedMsg struct {
+	uuid string
+	path string
+	err  error
+}
+
+var _ types.Page = (*Model)(nil) // Ensure we implement the page interface.
+
+// Model lists the action journal (see [config.JournalEntry]), newest first.
+type Model struct {
+	*types.PageModel
+
+	// Core state.
+	app types.AppState
+
+	// UI state.
+	entries []config.JournalEntry
+	all     bool // Show entries of all clusters, rather than only the current one.
+
+	// Child components.
+	table *table.Model[*table.StaticRow[config.JournalEntry]]
+}
+
+func New(app types.AppState) *Model {
+	m := &Model{
+		PageModel: &types.PageModel{
+			Commands:         Commands,
+			SupportFiltering: true,
+			ShortKeyBinds: []key.Binding{
+				types.OverrideHelp(types.KeyDetails, "details"),
+				types.OverrideHelp(types.KeySaveToFile, "export"),
+			},
+			FullKeyBinds: [][]key.Binding{{
+				types.OverrideHelp(types.KeyDetails, "details"),
+				types.OverrideHelp(types.KeySaveToFile, "export"),
+				types.KeyToggleAllClusters,
+			}},
+		},
+		app: app,
+	}
+
+	m.table = table.New(app, table.Config[*table.StaticRow[config.JournalEntry]]{
+		Columns: []*table.Column[*table.StaticRow[config.JournalEntry]]{
+			{
+				ID:    "time",
+				Title: "Time",
+				AccessorFn: func(row *table.StaticRow[config.JournalEntry]) string {
+					return row.Value.Time.Local().Format(time.DateTime)
+				},
+			},
+			{
+				ID:    "operation",
+				Title: "Operation",
+				AccessorFn: func(row *table.StaticRow[config.JournalEntry]) string {
+					return row.Value.Operation
+				},
+				StyleFn: func(row *table.StaticRow[config.JournalEntry], baseStyle lipgloss.Style, _, _ bool) lipgloss.Style {
+					if !row.Value.Succeeded() {
+						return baseStyle.Foreground(styles.Theme.ErrorFg()).Bold(true)
+					}
+					return baseStyle
+				},
+			},
+			{
+				ID:    "path",
+				Title: "Path",
+				AccessorFn: func(row *table.StaticRow[config.JournalEntry]) string {
+					return row.Value.Path
+				},
+			},
+			{
+				ID:    "versions",
+				Title: "Versions",
+				AccessorFn: func(row *table.StaticRow[config.JournalEntry]) string {
+					versions := make([]string, len(row.Value.Versions))
+					for i, v := range row.Value.Versions {
+						versions[i] = strconv.Itoa(v)
+					}
+					return strings.Join(versions, ", ")
+				},
+			},
+			{
+				ID:    "user",
+				Title: "User",
+				AccessorFn: func(row *table.StaticRow[config.JournalEntry]) string {
+					if row.Value.Identity != "" {
+						return row.Value.User + " (" + row.Value.Identity + ")"
+					}
+					return row.Value.User
+				},
+			},
+			{
+				ID:    "cluster",
+				Title: "Cluster",
+				AccessorFn: func(row *table.StaticRow[config.JournalEntry]) string {
+					return row.Value.Profile()
+				},
+			},
+			{
+				ID:    "result",
+				Title: "Result",
+				AccessorFn: func(row *table.StaticRow[config.JournalEntry]) string {
+					if row.Value.Succeeded() {
+						return "ok"
+					}
+					return row.Value.Error
+				},
+				StyleFn: func(row *table.StaticRow[config.JournalEntry], baseStyle lipgloss.Style, _, _ bool) lipgloss.Style {
+					if !row.Value.Succeeded() {
+						return baseStyle.Foreground(styles.Theme.ErrorFg())
+					}
+					return baseStyle.Foreground(styles.Theme.SuccessFg())
+				},
+			},
+		},
+		SelectFn: func(value *table.StaticRow[config.JournalEntry]) tea.Cmd {
+			return m.details(value.Value)
+		},
+	})
+
+	return m
+}
+
+func (m *Model) Init() tea.Cmd {
+	return tea.Batch(
+		m.table.Init(),
+		types.RefreshData(m.UUID()),
+	)
+}
+
+func (m *Model) Update(msg tea.Msg) tea.Cmd {
+	var cmds []tea.Cmd
+
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		return m.table.Update(msg)
+	case types.PageVisibleMsg:
+		return types.RefreshData(m.UUID())
+	case types.RefreshDataMsg:
+		return m.load()
+	case types.AppFilterMsg:
+		if msg.UUID != m.UUID() {
+			return nil
+		}
+		m.table.SetFilter(msg.Text)
+	case exportedMsg:
+		if msg.uuid != m.UUID() {
+			return nil
+		}
+		if msg.err != nil {
+			return types.SendStatus(msg.err.Error(), types.Error, 3*time.Second)
+		}
+		return types.SendStatus(fmt.Sprintf("exported to %q", msg.path), types.Success, 2*time.Second)
+	case tea.KeyPressMsg:
+		switch {
+		case key.Matches(msg, types.KeyDetails):
+			if v, ok := m.table.GetSelectedRow(); ok {
+				return m.details(v.Value)
+			}
+		case key.Matches(msg, types.KeySaveToFile):
+			return m.export()
+		case key.Matches(msg, types.KeyToggleAllClusters):
+			m.all = !m.all
+			return m.setRows()
+		}
+	}
+
+	return tea.Batch(append(cmds, m.table.Update(msg))...)
+}
+
+// load loads the journal from disk.
+func (m *Model) load() tea.Cmd {
+	entries, err := config.ReadJournal()
+	if err != nil {
+		return types.PageErrors(err)
+	}
+
+	slices.Reverse(entries) // Newest first.
+	m.entries = entries
+	return tea.Batch(m.setRows(), types.PageClearState())
+}
+
+// visible returns the entries which are in scope (the current cluster, or all
+// clusters).
+func (m *Model) visible() []config.JournalEntry {
+	if m.all {
+		return m.entries
+	}
+
+	profile := m.app.Client().Profile()
+	return slices.DeleteFunc(slices.Clone(m.entries), func(e config.JournalEntry) bool {
+		return e.Profile() != profile
+	})
+}
+
+func (m *Model) setRows() tea.Cmd {
+	entries := m.visible()
+	rows := make([]*table.StaticRow[config.JournalEntry], len(entries))
+	for i, e := range entries {
+		rows[i] = &table.StaticRow[config.JournalEntry]{
+			Value:   e,
+			ValueID: table.ID(strconv.Itoa(len(entries) - i)),
+		}
+	}
+	m.table.SetRows(rows)
+	return nil
+}
+
+func (m *Model) details(entry config.JournalEntry) tea.Cmd {
+	return types.OpenDialog(genericcode.NewYAML(
+		m.app,
+		fmt.Sprintf("%s: %s", entry.Operation, entry.Path),
+		false,
+		entry,
+	))
+}
+
+// export opens a form to export the entries in scope to a file.
+func (m *Model) export() tea.Cmd {
+	return types.OpenDialog(formdialog.New(m.app, formdialog.Config{
+		Title:       "Export history",
+		ConfirmText: "export",
+		Fields: []*form.Field{
+			{
+				ID:          "path",
+				Label:       "File",
+				Placeholder: "./vex-history." + config.JournalFormatJSONL,
+				Validator:   form.ValidateRequired,
+			},
+			{
+				ID:      "format",
+				Label:   "Format",
+				Value:   config.JournalFormats[0],
+				Options: config.JournalFormats,
+			},
+		},
+		ConfirmFn: func(values map[string]string) tea.Cmd {
+			return exportFile(m.UUID(), values["path"], values["format"], m.visible())
+		},
+	}))
+}
+
+// exportFile writes the provided entries to a new file at path.
+func exportFile(uuid, path, format string, entries []config.JournalEntry) tea.Cmd {
+	return func() tea.Msg {
+		msg := exportedMsg{uuid: uuid}
+
+		msg.path, msg.err = config.ExpandPath(path)
+		if msg.err != nil {
+			return msg
+		}
+
+		f, err := os.OpenFile(msg.path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+		if err != nil {
+			msg.err = fmt.Errorf("export history: %w", err)
+			return msg
+		}
+
+		// Entries are exported oldest first, same as the journal itself.
+		entries = slices.Clone(entries)
+		slices.Reverse(entries)
+
+		err = config.ExportJournal(f, entries, format)
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+		msg.err = err
+		return msg
+	}
+}
+
+func (m *Model) View() string {
+	if m.table.Width == 0 || m.table.Height == 0 {
+		return ""
+	}
+	return m.table.View()
+}
+
+func (m *Model) TopMiddleBorder() string {
+	if m.all {
+		return styles.Pluralize(m.table.TotalFilteredRows(), "action", "actions") + " (all clusters)"
+	}
+	return styles.Pluralize(m.table.TotalFilteredRows(), "action", "actions")
+}
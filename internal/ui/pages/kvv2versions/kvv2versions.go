@@ -6,6 +6,7 @@ package kvv2versions
 
 import (
 	"fmt"
+	"path"
 	"sort"
 	"strconv"
 	"time"
@@ -14,6 +15,7 @@ import (
 	tea "charm.land/bubbletea/v2"
 	"charm.land/lipgloss/v2"
 	"github.com/hashicorp/vault/api"
+	"github.com/lrstanley/vex/internal/config"
 	"github.com/lrstanley/vex/internal/types"
 	"github.com/lrstanley/vex/internal/ui/components/table"
 	"github.com/lrstanley/vex/internal/ui/dialogs/alert"
@@ -23,6 +25,9 @@ import (
 	"github.com/lrstanley/x/charm/formatter"
 )
 
+// PageType identifies the page in a [config.PageLocation].
+const PageType = "kvv2versions"
+
 var _ types.Page = (*Model)(nil) // Ensure we implement the page interface.
 
 type Model struct {
@@ -56,6 +61,11 @@ func New(app types.AppState, mount *types.Mount, path string) *Model {
 				types.KeyDelete,
 				types.KeyDestroy,
 			}},
+			MutatingKeyBinds: []key.Binding{
+				types.KeyDelete,
+				types.KeyDestroy,
+				types.KeyOpenEditor,
+			},
 		},
 		app:   app,
 		mount: mount,
@@ -237,10 +247,11 @@ func (m *Model) toggleDeleteVersion(version api.KVVersionMetadata) tea.Cmd {
 		}))
 	}
 	return types.OpenDialog(confirm.New(m.app, confirm.Config{
-		Title:         fmt.Sprintf("Delete Version %d", version.Version),
-		Message:       "Are you sure you want to delete this version? This can be undone.",
-		AllowsBlur:    true,
-		ConfirmStatus: types.Warning,
+		Title:             fmt.Sprintf("Delete Version %d", version.Version),
+		Message:           "Are you sure you want to delete this version? This can be undone.",
+		AllowsBlur:        true,
+		ConfirmStatus:     types.Warning,
+		TypedConfirmation: fmt.Sprintf("%s@%d", path.Base(m.path), version.Version),
 		ConfirmFn: func() tea.Cmd {
 			return tea.Sequence(
 				m.app.Client().DeleteKVSecret(m.UUID(), m.mount, m.path, version.Version),
@@ -260,10 +271,11 @@ func (m *Model) destroyVersion(version api.KVVersionMetadata) tea.Cmd {
 		}))
 	}
 	return types.OpenDialog(confirm.New(m.app, confirm.Config{
-		Title:         fmt.Sprintf("Destroy Version %d", version.Version),
-		Message:       "Are you sure you want to destroy this version? This cannot be undone.",
-		AllowsBlur:    true,
-		ConfirmStatus: types.Error,
+		Title:             fmt.Sprintf("Destroy Version %d", version.Version),
+		Message:           "Are you sure you want to destroy this version? This cannot be undone.",
+		AllowsBlur:        true,
+		ConfirmStatus:     types.Error,
+		TypedConfirmation: fmt.Sprintf("%s@%d", path.Base(m.path), version.Version),
 		ConfirmFn: func() tea.Cmd {
 			return tea.Sequence(
 				m.app.Client().DestroyKVSecret(m.UUID(), m.mount, m.path, version.Version),
@@ -303,3 +315,14 @@ func (m *Model) View() string {
 func (m *Model) GetTitle() string {
 	return m.mount.Path + m.path
 }
+
+// GetBreadcrumb implements [types.PageBreadcrumb], only showing the name of the
+// secret, as parent pages show the rest of the path.
+func (m *Model) GetBreadcrumb() string {
+	return path.Base(m.path)
+}
+
+// Location implements [types.PageLocator].
+func (m *Model) Location() config.PageLocation {
+	return config.PageLocation{Type: PageType, Mount: m.mount.Path, Path: m.path}
+}
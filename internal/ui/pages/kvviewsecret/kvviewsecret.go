@@ -6,9 +6,13 @@ package kvviewsecret
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"log/slog"
 	"maps"
+	"path"
 	"slices"
+	"strconv"
 	"strings"
 	"time"
 
@@ -16,27 +20,31 @@ import (
 	"charm.land/bubbles/v2/list"
 	tea "charm.land/bubbletea/v2"
 	"charm.land/lipgloss/v2"
+	"github.com/lrstanley/vex/internal/config"
 	"github.com/lrstanley/vex/internal/types"
 	"github.com/lrstanley/vex/internal/ui/components/confirmable"
+	"github.com/lrstanley/vex/internal/ui/components/form"
 	"github.com/lrstanley/vex/internal/ui/components/viewport"
 	"github.com/lrstanley/vex/internal/ui/dialogs/confirm"
+	formdialog "github.com/lrstanley/vex/internal/ui/dialogs/form"
+	"github.com/lrstanley/vex/internal/ui/dialogs/generator"
 	"github.com/lrstanley/vex/internal/ui/dialogs/genericcode"
+	"github.com/lrstanley/vex/internal/ui/dialogs/secretdiff"
 	"github.com/lrstanley/vex/internal/ui/dialogs/textarea"
 	"github.com/lrstanley/vex/internal/ui/styles"
 	"github.com/lrstanley/x/charm/formatter"
 )
 
-// TODO: change edit and delete to always be staged. i.e. edit (or delete) multiple things, then apply.
-
 type item struct {
 	model  *Model
 	key    string
 	value  any
 	masked bool
+	change secretdiff.ChangeType // Staged change to the key, if any.
 }
 
 func (i *item) ValueString() string {
-	return fmt.Sprintf("%v", i.value)
+	return formatValue(i.value)
 }
 
 func (i *item) IsMultiLine() bool {
@@ -49,19 +57,37 @@ func (i *item) FilterValue() string {
 }
 
 func (i *item) Title() string {
-	if slices.Contains(i.model.keysMarkedForDeletion, i.key) {
-		return lipgloss.NewStyle().
+	var title string
+
+	switch i.change {
+	case secretdiff.ChangeAdded:
+		title = lipgloss.NewStyle().
+			Foreground(styles.Theme.SuccessFg()).
+			Render(i.key + " (added)")
+	case secretdiff.ChangeModified:
+		title = lipgloss.NewStyle().
+			Foreground(styles.Theme.WarningFg()).
+			Render(i.key + " (modified)")
+	case secretdiff.ChangeRemoved:
+		title = lipgloss.NewStyle().
 			Foreground(styles.Theme.ErrorFg()).
+			Strikethrough(true).
 			Render(i.key)
+	default:
+		title = i.key
 	}
-	return i.key
+
+	if t := typeOf(i.value); t != typeString {
+		title += lipgloss.NewStyle().Faint(true).Render(" <" + string(t) + ">")
+	}
+	return title
 }
 
 func (i *item) Description() string {
 	var styleFunc func(...string) string
 
 	switch {
-	case slices.Contains(i.model.keysMarkedForDeletion, i.key):
+	case i.change == secretdiff.ChangeRemoved:
 		styleFunc = lipgloss.NewStyle().Foreground(styles.Theme.ErrorFg()).Bold(true).Render
 	case !i.masked:
 		styleFunc = lipgloss.NewStyle().Foreground(styles.Theme.AppFg()).Render
@@ -82,6 +108,9 @@ func (i *item) Description() string {
 	return styleFunc(i.ValueString())
 }
 
+// PageType identifies the page in a [config.PageLocation].
+const PageType = "kvviewsecret"
+
 var _ types.Page = (*Model)(nil) // Ensure we implement the page interface.
 
 type Model struct {
@@ -91,19 +120,24 @@ type Model struct {
 	app types.AppState
 
 	// UI state.
-	height                int
-	width                 int
-	openedAsEditor        bool
-	mount                 *types.Mount
-	path                  string
-	version               int
-	data                  map[string]any
-	filter                string
-	isFlat                bool
-	forceJSON             bool
-	isNonFlatMasked       bool // If masking is enabled for non-flat (json) masking.
-	unmaskedKeys          []string
-	keysMarkedForDeletion []string // Keys marked for deletion, flat-only.
+	height            int
+	width             int
+	openedAsEditor    bool
+	mount             *types.Mount
+	path              string
+	version           int
+	data              map[string]any
+	filter            string
+	isFlat            bool
+	forceJSON         bool
+	isNonFlatMasked   bool // If masking is enabled for non-flat (json) masking.
+	unmaskedKeys      []string
+	staged            map[string]any // Working copy of data with all staged changes, nil if nothing is staged.
+	currentVersion    int            // Latest version when the data was loaded, used for check-and-set (kv v2 only).
+	pendingWrite      map[string]any
+	resolvingConflict bool
+	generatingKey     string // Key to store a value generated from a password policy in.
+	visited           bool   // If the visit has been recorded in the recent paths.
 
 	// Child components.
 	delegate list.DefaultDelegate
@@ -122,24 +156,52 @@ func New(app types.AppState, mount *types.Mount, path string, version int, opene
 				types.OverrideHelp(types.KeySelectItem, "edit"),
 				types.KeyCopy,
 				types.KeyToggleMask,
+				types.KeyApplyChanges,
 			},
-			FullKeyBinds: [][]key.Binding{{
-				types.OverrideHelp(types.KeySelectItem, "edit"),
+			FullKeyBinds: [][]key.Binding{
+				{
+					types.OverrideHelp(types.KeySelectItem, "edit"),
+					types.KeyOpenEditor,
+					types.KeyCopy,
+					types.KeyCopyAs,
+					types.KeyToggleMask,
+					types.KeyToggleMaskAll,
+					types.KeyRenderJSON,
+					types.KeyDelete,
+					types.KeyToggleBookmark,
+				},
+				{
+					types.KeyAddKey,
+					types.KeyRenameKey,
+					types.KeyChangeType,
+					types.KeyGenerateValue,
+					types.KeyLoadFromFile,
+					types.KeySaveToFile,
+					types.KeyToggleDelete,
+					types.KeyApplyChanges,
+					types.KeyDiscardChanges,
+				},
+			},
+			MutatingKeyBinds: []key.Binding{
+				types.KeySelectItem,
 				types.KeyOpenEditor,
-				types.KeyCopy,
-				types.KeyToggleMask,
-				types.KeyToggleMaskAll,
-				types.KeyRenderJSON,
-				types.KeyToggleDelete,
 				types.KeyDelete,
-			}},
+				types.KeyAddKey,
+				types.KeyRenameKey,
+				types.KeyChangeType,
+				types.KeyGenerateValue,
+				types.KeyLoadFromFile,
+				types.KeyToggleDelete,
+				types.KeyApplyChanges,
+				types.KeyDiscardChanges,
+			},
 		},
 		app:             app,
 		openedAsEditor:  openedAsEditor,
 		mount:           mount,
 		path:            path,
 		version:         version,
-		isNonFlatMasked: true,
+		isNonFlatMasked: config.Get().Masking.Enabled,
 		viewport:        viewport.New(app),
 	}
 	m.delegate = list.NewDefaultDelegate()
@@ -230,16 +292,47 @@ func (m *Model) Update(msg tea.Msg) tea.Cmd {
 	case tea.WindowSizeMsg:
 		m.height = msg.Height
 		m.width = msg.Width
-		m.list.SetSize(m.width, m.height)
-		m.viewport.SetDimensions(m.width, m.height)
+		m.resize()
 		return nil
 	case types.PageVisibleMsg:
 		return types.RefreshData(m.UUID())
 	case types.RefreshDataMsg:
+		// Don't replace the data out from under any staged changes. Changes made
+		// by others in the meantime are caught by check-and-set when applying.
+		if m.resolvingConflict || m.staged != nil {
+			return nil
+		}
 		return tea.Batch(
 			types.PageLoading(),
 			m.app.Client().GetKVSecret(m.UUID(), m.mount, m.path, m.version),
 		)
+	case fileLoadedMsg:
+		if msg.uuid != m.UUID() {
+			return nil
+		}
+		if msg.err != nil {
+			return types.SendStatus(msg.err.Error(), types.Error, 3*time.Second)
+		}
+		data := maps.Clone(m.working())
+		if data == nil {
+			data = map[string]any{}
+		}
+		data[msg.key] = msg.value
+		if msg.base64 {
+			return tea.Sequence(
+				m.stage(data),
+				types.SendStatus("binary file was base64 encoded", types.Warning, 3*time.Second),
+			)
+		}
+		return m.stage(data)
+	case fileSavedMsg:
+		if msg.uuid != m.UUID() {
+			return nil
+		}
+		if msg.err != nil {
+			return types.SendStatus(msg.err.Error(), types.Error, 3*time.Second)
+		}
+		return types.SendStatus(fmt.Sprintf("saved to %q", msg.path), types.Success, 2*time.Second)
 	case types.AppFilterMsg:
 		if msg.UUID != m.UUID() {
 			return nil
@@ -255,21 +348,51 @@ func (m *Model) Update(msg tea.Msg) tea.Cmd {
 			return nil
 		}
 		if msg.Error != nil {
+			if m.generatingKey != "" {
+				m.generatingKey = ""
+				return types.SendStatus(msg.Error.Error(), types.Error, 3*time.Second)
+			}
+			if errors.Is(msg.Error, types.ErrCheckAndSetMismatch) && m.pendingWrite != nil {
+				m.resolvingConflict = true
+				return tea.Batch(
+					types.SendStatus("secret was modified by someone else", types.Warning, 2*time.Second),
+					m.app.Client().GetKVSecret(m.UUID(), m.mount, m.path, 0),
+				)
+			}
+			m.pendingWrite = nil
+			m.resolvingConflict = false
 			return types.PageErrors(msg.Error)
 		}
 
 		switch vmsg := msg.Msg.(type) {
 		case types.ClientGetSecretMsg:
+			if m.resolvingConflict {
+				return m.openConflict(vmsg)
+			}
+
+			m.currentVersion = vmsg.CurrentVersion
 			m.data = vmsg.Data
-			m.isFlat = formatter.IsFlatValue(vmsg.Data)
-			m.SupportFiltering = m.isFlat
+
+			if !m.visited {
+				m.visited = true
+				if err := config.RecordVisit(m.app.Client().Profile(), m.mount.Path+m.path); err != nil {
+					slog.Warn("failed to record visit", "path", m.mount.Path+m.path, "error", err) //nolint:sloglint
+				}
+			}
 
 			return tea.Batch(append(
 				cmds,
 				m.setFromData(),
 				types.PageClearState(),
 			)...)
+		case types.ClientGeneratePasswordMsg:
+			if m.generatingKey == "" {
+				return nil
+			}
+			return m.stageGenerated(m.generatingKey, vmsg.Password)
 		case types.ClientSuccessMsg:
+			m.pendingWrite = nil
+			m.staged = nil
 			return tea.Batch(
 				types.SendStatus(vmsg.Message, types.Success, 2*time.Second),
 				types.RefreshData(m.UUID()),
@@ -279,17 +402,11 @@ func (m *Model) Update(msg tea.Msg) tea.Cmd {
 		switch {
 		case key.Matches(msg.Key(), types.KeyCopy):
 			if !m.isFlat || m.forceJSON {
-				b, err := json.MarshalIndent(m.data, "", "    ")
-				if err == nil {
-					return types.SetClipboard(string(b))
-				}
-			} else {
-				item := m.getSelectedItem()
-				if item == nil {
-					return nil
-				}
-				return types.SetClipboard(item.ValueString())
+				return m.copyAs(copyFormatJSON)
 			}
+			return m.copyAs(copyFormatField)
+		case key.Matches(msg.Key(), types.KeyCopyAs):
+			return m.openCopyAs()
 		case key.Matches(msg.Key(), types.KeyToggleMask, types.KeyToggleMaskAll):
 			return m.toggleMasking(key.Matches(msg.Key(), types.KeyToggleMaskAll))
 		case key.Matches(msg.Key(), types.KeyRenderJSON):
@@ -301,8 +418,26 @@ func (m *Model) Update(msg tea.Msg) tea.Cmd {
 			return m.editWithEditor()
 		case key.Matches(msg.Key(), types.KeyDelete):
 			return m.delete()
-		case msg.String() == "d" && m.isFlat && !m.forceJSON:
+		case key.Matches(msg.Key(), types.KeyAddKey):
+			return m.addKey()
+		case key.Matches(msg.Key(), types.KeyRenameKey):
+			return m.renameKey()
+		case key.Matches(msg.Key(), types.KeyChangeType):
+			return m.changeType()
+		case key.Matches(msg.Key(), types.KeyGenerateValue):
+			return m.generate()
+		case key.Matches(msg.Key(), types.KeyLoadFromFile):
+			return m.loadFromFile()
+		case key.Matches(msg.Key(), types.KeySaveToFile):
+			return m.saveToFile()
+		case key.Matches(msg.Key(), types.KeyToggleDelete):
 			return m.toggleKeyDeletion()
+		case key.Matches(msg.Key(), types.KeyApplyChanges):
+			return m.review()
+		case key.Matches(msg.Key(), types.KeyDiscardChanges):
+			return m.discard()
+		case key.Matches(msg.Key(), types.KeyToggleBookmark):
+			return m.toggleBookmark()
 		}
 	case styles.ThemeUpdatedMsg:
 		m.setStyle()
@@ -321,6 +456,22 @@ func (m *Model) Update(msg tea.Msg) tea.Cmd {
 	return tea.Batch(cmds...)
 }
 
+// toggleBookmark bookmarks the secret (at the version being viewed, if one was
+// explicitly requested), or removes the bookmark if it already exists.
+func (m *Model) toggleBookmark() tea.Cmd {
+	added, err := config.ToggleBookmark(m.app.Client().Profile(), config.Bookmark{
+		Path:    m.mount.Path + m.path,
+		Version: m.version,
+	})
+	if err != nil {
+		return types.SendStatus("unable to update bookmarks: "+err.Error(), types.Error, 3*time.Second)
+	}
+	if added {
+		return types.SendStatus("bookmarked "+m.mount.Path+m.path, types.Success, 2*time.Second)
+	}
+	return types.SendStatus("removed bookmark for "+m.mount.Path+m.path, types.Info, 2*time.Second)
+}
+
 func (m *Model) getSelectedItem() *item {
 	if !m.isFlat || m.forceJSON {
 		return nil
@@ -332,29 +483,84 @@ func (m *Model) getSelectedItem() *item {
 	return item
 }
 
+// resize updates the dimensions of the child components, taking into account
+// the changeset panel when changes are staged.
+func (m *Model) resize() {
+	height := m.height
+	if m.staged != nil {
+		height--
+	}
+	m.list.SetSize(m.width, height)
+	m.viewport.SetDimensions(m.width, height)
+}
+
+// working returns the data with all staged changes applied.
+func (m *Model) working() map[string]any {
+	if m.staged != nil {
+		return m.staged
+	}
+	return m.data
+}
+
+// stage replaces the working copy of the data. If it matches what is on the
+// server, there is nothing left staged.
+func (m *Model) stage(data map[string]any) tea.Cmd {
+	changes := secretdiff.Changes(m.data, data)
+	if len(changes) == 0 {
+		m.staged = nil
+	} else {
+		m.staged = data
+	}
+
+	return tea.Batch(
+		m.setFromData(),
+		types.SendStatus("staged: "+secretdiff.Summary(changes), types.Info, 2*time.Second),
+	)
+}
+
 func (m *Model) setFromData() tea.Cmd {
 	var cmds []tea.Cmd
 
+	data := m.working()
+	m.isFlat = formatter.IsFlatValue(data)
+	m.SupportFiltering = m.isFlat
+	m.resize()
+
 	if !m.isFlat || m.forceJSON {
-		m.viewport.SetCode(formatter.ToJSON(m.data, m.isNonFlatMasked, 2), "json")
+		m.viewport.SetCode(formatter.ToJSON(data, m.isNonFlatMasked, 2), "json")
 	} else {
-		// Preserve marked keys, but remove any that no longer exist in the data
-		if len(m.keysMarkedForDeletion) > 0 {
-			m.keysMarkedForDeletion = slices.DeleteFunc(m.keysMarkedForDeletion, func(k string) bool {
-				_, exists := m.data[k]
-				return !exists
-			})
+		changes := map[string]secretdiff.ChangeType{}
+		for _, c := range secretdiff.Changes(m.data, data) {
+			changes[c.Key] = c.Type
 		}
 
+		// Removed keys are still shown (from the original data), so they can be
+		// restored.
 		var values []list.Item
-		keys := slices.Collect(maps.Keys(m.data))
+		keys := slices.Collect(maps.Keys(data))
+		for k, t := range changes {
+			if t == secretdiff.ChangeRemoved {
+				keys = append(keys, k)
+			}
+		}
 		slices.Sort(keys)
+
+		// If masking is disabled by default, unmask everything on the initial load.
+		if m.unmaskedKeys == nil && !config.Get().Masking.Enabled {
+			m.unmaskedKeys = slices.Clone(keys)
+		}
+
 		for _, k := range keys {
+			v, ok := data[k]
+			if !ok {
+				v = m.data[k]
+			}
 			values = append(values, &item{
 				model:  m,
 				key:    k,
-				value:  m.data[k],
+				value:  v,
 				masked: !slices.Contains(m.unmaskedKeys, k),
+				change: changes[k],
 			})
 		}
 		cmds = append(cmds, m.list.SetItems(values))
@@ -425,19 +631,19 @@ func (m *Model) edit() tea.Cmd {
 			m.app,
 			confirmable.Config[string]{
 				CancelText:  "cancel",
-				ConfirmText: "save",
+				ConfirmText: "stage",
 				ConfirmFn: func(v string) tea.Cmd {
 					data := map[string]any{}
-					err := json.Unmarshal([]byte(v), &data)
+					err := decodeJSON(v, &data)
 					if err != nil {
 						return types.SendStatus("failed to unmarshal json", types.Error, 2*time.Second)
 					}
-					return m.app.Client().PutKVSecret(m.UUID(), m.mount, m.path, data)
+					return m.stage(data)
 				},
 				PassthroughTab: true,
 			},
 			"Edit secret",
-			formatter.ToJSON(m.data, false, 2),
+			formatter.ToJSON(m.working(), false, 2),
 		))
 	}
 
@@ -445,25 +651,46 @@ func (m *Model) edit() tea.Cmd {
 	if item == nil {
 		return nil
 	}
+
+	t := typeOf(item.value)
 	return types.OpenDialog(textarea.New(
 		m.app,
 		confirmable.Config[string]{
 			CancelText:  "cancel",
-			ConfirmText: "save",
+			ConfirmText: "stage",
 			ConfirmFn: func(v string) tea.Cmd {
-				data := maps.Clone(m.data)
-				data[item.key] = strings.TrimSuffix(v, "\n")
-				return m.app.Client().PutKVSecret(m.UUID(), m.mount, m.path, data)
+				return m.stageEditedValue(item, v)
 			},
-			PassthroughTab: item.IsMultiLine(),
+			PassthroughTab: item.IsMultiLine() || t == typeJSON,
 		},
-		fmt.Sprintf("Edit key: %q", item.key),
+		fmt.Sprintf("Edit key: %q (%s)", item.key, t),
 		item.ValueString(),
 	))
 }
 
+// stageEditedValue parses an edited value of a key, keeping the type of the
+// original value, and stages it.
+func (m *Model) stageEditedValue(item *item, edited string) tea.Cmd {
+	t := typeOf(item.value)
+
+	// Editors generally add a trailing newline, which should only be kept if
+	// the original value had one (e.g. PEM-encoded certificates).
+	if s, ok := item.value.(string); !ok || !strings.HasSuffix(s, "\n") {
+		edited = strings.TrimSuffix(edited, "\n")
+	}
+
+	v, err := parseValue(edited, t)
+	if err != nil {
+		return types.SendStatus(err.Error(), types.Error, 3*time.Second)
+	}
+
+	data := maps.Clone(m.working())
+	data[item.key] = v
+	return m.stage(data)
+}
+
 func (m *Model) editWithEditor() tea.Cmd {
-	if m.data == nil {
+	if m.working() == nil {
 		return nil
 	}
 
@@ -471,17 +698,17 @@ func (m *Model) editWithEditor() tea.Cmd {
 		return types.OpenTempEditor(
 			m.UUID(),
 			"update-secret-*.json",
-			formatter.ToJSON(m.data, false, 2),
+			formatter.ToJSON(m.working(), false, 2),
 			func(msg types.EditorResultMsg) tea.Cmd {
 				if !msg.HasChanged {
 					return types.SendStatus("no changes detected", types.Info, 2*time.Second)
 				}
 				data := map[string]any{}
-				err := json.Unmarshal([]byte(msg.After), &data)
+				err := decodeJSON(msg.After, &data)
 				if err != nil {
 					return types.SendStatus("failed to unmarshal json", types.Error, 2*time.Second)
 				}
-				return m.app.Client().PutKVSecret(m.UUID(), m.mount, m.path, data)
+				return m.stage(data)
 			},
 		)
 	}
@@ -493,7 +720,7 @@ func (m *Model) editWithEditor() tea.Cmd {
 
 	content := item.ValueString()
 	pattern := "update-secret-*"
-	if json.Valid([]byte(content)) {
+	if typeOf(item.value) == typeJSON || json.Valid([]byte(content)) {
 		pattern = "update-secret-*.json"
 	}
 
@@ -505,108 +732,475 @@ func (m *Model) editWithEditor() tea.Cmd {
 			if !msg.HasChanged {
 				return types.SendStatus("no changes detected", types.Info, 2*time.Second)
 			}
-			data := maps.Clone(m.data)
-			data[item.key] = strings.TrimSuffix(msg.After, "\n")
-			return m.app.Client().PutKVSecret(m.UUID(), m.mount, m.path, data)
+			return m.stageEditedValue(item, msg.After)
 		},
 	)
 }
 
+// put writes the data to the secret. For kv v2 mounts, the write is done using
+// check-and-set against the version which was last loaded, so that changes made
+// by others in the meantime aren't silently overwritten.
+func (m *Model) put(data map[string]any) tea.Cmd {
+	cas := -1
+	if m.mount.KVVersion() == 2 {
+		cas = m.currentVersion
+	}
+	m.pendingWrite = data
+	return m.app.Client().PutKVSecret(m.UUID(), m.mount, m.path, data, cas)
+}
+
+// openConflict opens the conflict dialog, comparing the data which was originally
+// loaded, what we attempted to write, and what is now on the server.
+func (m *Model) openConflict(theirs types.ClientGetSecretMsg) tea.Cmd {
+	original, mine := m.data, m.pendingWrite
+	originalVersion := m.currentVersion
+
+	m.resolvingConflict = false
+
+	// Their data is now what we base any further writes off of. Our changes are
+	// merged on top of it and stay staged until the conflict is resolved, so they
+	// aren't lost if the dialog is cancelled, and applying them later doesn't
+	// revert their changes. They're only cleared once the retry or overwrite
+	// succeeds, or they're discarded.
+	staged := m.staged
+	if staged == nil {
+		staged = mine
+	}
+	m.currentVersion = theirs.CurrentVersion
+	m.data = theirs.Data
+	m.staged, _ = secretdiff.Merge(original, staged, theirs.Data)
+	if len(secretdiff.Changes(m.data, m.staged)) == 0 {
+		m.staged = nil
+	}
+
+	return tea.Batch(
+		m.setFromData(),
+		types.OpenDialog(secretdiff.NewConflict(m.app, secretdiff.ConflictConfig{
+			Path:            m.mount.Path + m.path,
+			Original:        original,
+			Mine:            mine,
+			Theirs:          theirs.Data,
+			OriginalVersion: originalVersion,
+			TheirsVersion:   theirs.CurrentVersion,
+			RetryFn:         m.put,
+			OverwriteFn:     m.put,
+			DiscardFn: func() tea.Cmd {
+				m.pendingWrite = nil
+				m.staged = nil
+				return tea.Batch(
+					m.setFromData(),
+					types.SendStatus("discarded changes", types.Info, 2*time.Second),
+				)
+			},
+		})),
+	)
+}
+
 func (m *Model) View() string {
+	height := m.height
+	if m.staged != nil {
+		height--
+	}
+
+	var out string
 	if !m.isFlat || m.forceJSON {
-		return m.viewport.View()
+		out = m.viewport.View()
+	} else {
+		out = lipgloss.NewStyle().
+			Width(m.width).
+			Height(height).
+			Render(m.list.View())
+	}
+
+	if m.staged == nil {
+		return out
+	}
+	return lipgloss.JoinVertical(lipgloss.Left, out, m.changesetView())
+}
+
+// changesetView renders the panel which summarizes the staged changes.
+func (m *Model) changesetView() string {
+	if m.staged == nil {
+		return ""
 	}
+
+	fg, bg := styles.Theme.ByStatus(types.Warning)
 	return lipgloss.NewStyle().
+		Foreground(fg).
+		Background(bg).
+		Padding(0, 1).
 		Width(m.width).
-		Height(m.height).
-		Render(m.list.View())
+		MaxHeight(1).
+		Render(fmt.Sprintf(
+			"%s staged: %s · %s review & apply · %s discard",
+			styles.IconCaution(),
+			secretdiff.Summary(secretdiff.Changes(m.data, m.staged)),
+			types.KeyApplyChanges.Help().Key,
+			types.KeyDiscardChanges.Help().Key,
+		))
 }
 
 func (m *Model) GetTitle() string {
 	return m.mount.Path + m.path
 }
 
-func (m *Model) toggleKeyDeletion() tea.Cmd {
-	if !m.isFlat || m.forceJSON {
+// GetBreadcrumb implements [types.PageBreadcrumb]. For KVv2, the versions page
+// already shows the name of the secret, so only the version is shown.
+func (m *Model) GetBreadcrumb() string {
+	if m.mount.KVVersion() != 2 {
+		return path.Base(m.path)
+	}
+	if m.version > 0 {
+		return "v" + strconv.Itoa(m.version)
+	}
+	return "latest"
+}
+
+// Location implements [types.PageLocator].
+func (m *Model) Location() config.PageLocation {
+	return config.PageLocation{Type: PageType, Mount: m.mount.Path, Path: m.path, Version: m.version}
+}
+
+// GuardClose implements [types.PageCloseGuard], asking for confirmation before
+// closing the page when there are staged changes.
+func (m *Model) GuardClose(next tea.Cmd) tea.Cmd {
+	if m.staged == nil {
 		return nil
 	}
 
+	return types.OpenDialog(confirm.New(m.app, confirm.Config{
+		Title: "Unsaved changes",
+		Message: fmt.Sprintf(
+			"%s has unsaved changes (%s). Discard them?",
+			m.mount.Path+m.path,
+			secretdiff.Summary(secretdiff.Changes(m.data, m.staged)),
+		),
+		AllowsBlur:    true,
+		ConfirmText:   "discard",
+		ConfirmStatus: types.Error,
+		ConfirmFn: func() tea.Cmd {
+			m.staged = nil
+			return tea.Sequence(types.CloseActiveDialog(), next)
+		},
+		CancelFn: types.CloseActiveDialog,
+	}))
+}
+
+func (m *Model) addKey() tea.Cmd {
+	return types.OpenDialog(formdialog.New(m.app, formdialog.Config{
+		Title:       "Add key",
+		ConfirmText: "stage",
+		Fields: []*form.Field{
+			{
+				ID:        "key",
+				Label:     "Key",
+				Validator: m.validateNewKey(""),
+			},
+			{
+				ID:      "type",
+				Label:   "Type",
+				Value:   string(typeString),
+				Options: valueTypes,
+			},
+			{
+				ID:    "value",
+				Label: "Value",
+			},
+		},
+		Validator: func(values map[string]string) error {
+			_, err := parseValue(values["value"], valueType(values["type"]))
+			return err
+		},
+		ConfirmFn: func(values map[string]string) tea.Cmd {
+			v, _ := parseValue(values["value"], valueType(values["type"])) // Already validated.
+
+			data := maps.Clone(m.working())
+			if data == nil {
+				data = map[string]any{}
+			}
+			data[values["key"]] = v
+			return m.stage(data)
+		},
+	}))
+}
+
+func (m *Model) renameKey() tea.Cmd {
 	item := m.getSelectedItem()
-	if item == nil {
+	if item == nil || item.change == secretdiff.ChangeRemoved {
 		return nil
 	}
 
-	if slices.Contains(m.keysMarkedForDeletion, item.key) {
-		m.keysMarkedForDeletion = slices.DeleteFunc(m.keysMarkedForDeletion, func(k string) bool {
-			return k == item.key
-		})
-	} else {
-		m.keysMarkedForDeletion = append(m.keysMarkedForDeletion, item.key)
+	return types.OpenDialog(formdialog.New(m.app, formdialog.Config{
+		Title:       fmt.Sprintf("Rename key: %q", item.key),
+		ConfirmText: "stage",
+		Fields: []*form.Field{{
+			ID:        "key",
+			Label:     "Key",
+			Value:     item.key,
+			Validator: m.validateNewKey(item.key),
+		}},
+		ConfirmFn: func(values map[string]string) tea.Cmd {
+			if values["key"] == item.key {
+				return nil
+			}
+			data := maps.Clone(m.working())
+			data[values["key"]] = data[item.key]
+			delete(data, item.key)
+			return m.stage(data)
+		},
+	}))
+}
+
+func (m *Model) changeType() tea.Cmd {
+	item := m.getSelectedItem()
+	if item == nil || item.change == secretdiff.ChangeRemoved {
+		return nil
 	}
 
-	return m.setFromData()
+	return types.OpenDialog(formdialog.New(m.app, formdialog.Config{
+		Title:       fmt.Sprintf("Change type: %q", item.key),
+		ConfirmText: "stage",
+		Fields: []*form.Field{{
+			ID:      "type",
+			Label:   "Type",
+			Value:   string(typeOf(item.value)),
+			Options: valueTypes,
+			Validator: func(v string) error {
+				_, err := convertValue(item.value, valueType(v))
+				return err
+			},
+		}},
+		ConfirmFn: func(values map[string]string) tea.Cmd {
+			v, _ := convertValue(item.value, valueType(values["type"])) // Already validated.
+
+			data := maps.Clone(m.working())
+			data[item.key] = v
+			return m.stage(data)
+		},
+	}))
 }
 
-func (m *Model) delete() tea.Cmd {
-	var title, message string
-	var confirmFn func() tea.Cmd
+type copyFormat string
 
-	var all bool
+const (
+	copyFormatField copyFormat = "field"
+	copyFormatJSON  copyFormat = "json"
+	copyFormatEnv   copyFormat = "env"
+)
 
-	switch {
-	case m.isFlat && !m.forceJSON:
-		if len(m.keysMarkedForDeletion) > 0 && len(m.keysMarkedForDeletion) < len(slices.Collect(maps.Keys(m.data))) {
-			var keys []string
+// copyAs copies the secret (or the selected field) to the clipboard, in the
+// provided format. The clipboard is automatically cleared after a while.
+func (m *Model) copyAs(format copyFormat) tea.Cmd {
+	switch format {
+	case copyFormatField:
+		item := m.getSelectedItem()
+		if item == nil {
+			return nil
+		}
+		return types.SetSensitiveClipboard(item.ValueString())
+	case copyFormatEnv:
+		return types.SetSensitiveClipboard(formatEnv(m.working()))
+	default:
+		b, err := json.MarshalIndent(m.working(), "", "    ")
+		if err != nil {
+			return types.SendStatus(err.Error(), types.Error, 2*time.Second)
+		}
+		return types.SetSensitiveClipboard(string(b))
+	}
+}
 
-			for _, key := range m.keysMarkedForDeletion {
-				keys = append(keys, lipgloss.NewStyle().Foreground(styles.Theme.ErrorFg()).Bold(true).Render(styles.IconCaution()+" "+key))
-			}
+func (m *Model) openCopyAs() tea.Cmd {
+	formats := []string{string(copyFormatJSON), string(copyFormatEnv)}
+	if m.getSelectedItem() != nil {
+		formats = append([]string{string(copyFormatField)}, formats...)
+	}
 
-			title = fmt.Sprintf("Delete keys from %s", m.mount.Path+m.path)
-			message = fmt.Sprintf(
-				"Are you sure you want to delete the following keys?:\n%s\n\nThis cannot be undone.",
-				strings.Join(keys, "\n"),
-			)
-			confirmFn = func() tea.Cmd {
-				data := maps.Clone(m.data)
-				for _, key := range m.keysMarkedForDeletion {
-					delete(data, key)
-				}
-				return tea.Sequence(
-					m.app.Client().PutKVSecret(m.UUID(), m.mount, m.path, data),
-					types.CloseActiveDialog(),
-				)
-			}
-		} else {
-			all = true
-		}
-	case !m.isFlat:
-		all = true
+	return types.OpenDialog(formdialog.New(m.app, formdialog.Config{
+		Title:       "Copy secret",
+		ConfirmText: "copy",
+		Fields: []*form.Field{{
+			ID:      "format",
+			Label:   "Format",
+			Value:   formats[0],
+			Options: formats,
+		}},
+		ConfirmFn: func(values map[string]string) tea.Cmd {
+			return m.copyAs(copyFormat(values["format"]))
+		},
+	}))
+}
+
+// generate opens the generator dialog, which stores the generated value in the
+// selected key (or a new key).
+func (m *Model) generate() tea.Cmd {
+	var current string
+	if item := m.getSelectedItem(); item != nil {
+		current = item.key
 	}
 
-	if all {
-		title = fmt.Sprintf("Delete %s", m.mount.Path+m.path)
-		message = "Are you sure you want to delete this secret? This cannot be undone."
-		confirmFn = func() tea.Cmd {
-			return tea.Sequence(
-				m.app.Client().DeleteKVSecret(m.UUID(), m.mount, m.path),
-				types.CloseActiveDialog(),
-				types.CloseActivePage(),
-			)
+	return types.OpenDialog(generator.New(m.app, generator.Config{
+		Key:       current,
+		ConfirmFn: m.stageGenerated,
+		PolicyFn: func(key, policy string) tea.Cmd {
+			m.generatingKey = key
+			return m.app.Client().GeneratePassword(m.UUID(), policy)
+		},
+	}))
+}
+
+func (m *Model) stageGenerated(key, value string) tea.Cmd {
+	m.generatingKey = ""
+
+	data := maps.Clone(m.working())
+	if data == nil {
+		data = map[string]any{}
+	}
+	data[key] = value
+	return m.stage(data)
+}
+
+func (m *Model) loadFromFile() tea.Cmd {
+	var current string
+	if item := m.getSelectedItem(); item != nil {
+		current = item.key
+	}
+
+	return types.OpenDialog(formdialog.New(m.app, formdialog.Config{
+		Title:       "Load value from file",
+		ConfirmText: "load",
+		Fields: []*form.Field{
+			{
+				ID:          "path",
+				Label:       "File",
+				Placeholder: "~/certs/tls.crt",
+				Validator:   form.ValidateRequired,
+			},
+			{
+				ID:        "key",
+				Label:     "Key",
+				Value:     current,
+				Validator: form.ValidateRequired,
+			},
+		},
+		ConfirmFn: func(values map[string]string) tea.Cmd {
+			return loadFile(m.UUID(), values["key"], values["path"])
+		},
+	}))
+}
+
+func (m *Model) saveToFile() tea.Cmd {
+	item := m.getSelectedItem()
+	if item == nil {
+		return nil
+	}
+
+	return types.OpenDialog(formdialog.New(m.app, formdialog.Config{
+		Title:       fmt.Sprintf("Save key to file: %q", item.key),
+		ConfirmText: "save",
+		Fields: []*form.Field{{
+			ID:          "path",
+			Label:       "File",
+			Placeholder: "./" + item.key,
+			Validator:   form.ValidateRequired,
+		}},
+		ConfirmFn: func(values map[string]string) tea.Cmd {
+			return saveFile(m.UUID(), values["path"], item.value)
+		},
+	}))
+}
+
+// validateNewKey returns a validator which ensures a key is provided, and that
+// it doesn't already exist in the working copy of the data (unless it is the
+// current key, when renaming).
+func (m *Model) validateNewKey(current string) func(string) error {
+	return func(v string) error {
+		if err := form.ValidateRequired(v); err != nil {
+			return err
+		}
+		if _, ok := m.working()[v]; ok && v != current {
+			return fmt.Errorf("key %q already exists", v)
 		}
+		return nil
+	}
+}
+
+func (m *Model) toggleKeyDeletion() tea.Cmd {
+	if !m.isFlat || m.forceJSON {
+		return nil
 	}
 
-	if title == "" || message == "" || confirmFn == nil {
+	item := m.getSelectedItem()
+	if item == nil {
+		return nil
+	}
+
+	data := maps.Clone(m.working())
+	if item.change == secretdiff.ChangeRemoved {
+		data[item.key] = m.data[item.key]
+	} else {
+		delete(data, item.key)
+	}
+
+	return m.stage(data)
+}
+
+// review opens a dialog with the diff of all staged changes, which are applied
+// in a single write when confirmed.
+func (m *Model) review() tea.Cmd {
+	if m.staged == nil {
+		return types.SendStatus("no staged changes", types.Info, 2*time.Second)
+	}
+
+	return types.OpenDialog(secretdiff.NewReview(m.app, secretdiff.ReviewConfig{
+		Path:      m.mount.Path + m.path,
+		Before:    m.data,
+		After:     m.staged,
+		ConfirmFn: m.put,
+	}))
+}
+
+func (m *Model) discard() tea.Cmd {
+	if m.staged == nil {
 		return nil
 	}
 
 	return types.OpenDialog(confirm.New(m.app, confirm.Config{
-		Title:         title,
-		Message:       message,
+		Title: "Discard changes",
+		Message: fmt.Sprintf(
+			"Are you sure you want to discard all staged changes (%s)?",
+			secretdiff.Summary(secretdiff.Changes(m.data, m.staged)),
+		),
 		AllowsBlur:    true,
+		ConfirmText:   "discard",
 		ConfirmStatus: types.Error,
-		ConfirmFn:     confirmFn,
-		CancelFn:      types.CloseActiveDialog,
+		ConfirmFn: func() tea.Cmd {
+			m.staged = nil
+			return tea.Sequence(
+				types.CloseActiveDialog(),
+				m.setFromData(),
+				types.SendStatus("discarded changes", types.Info, 2*time.Second),
+			)
+		},
+		CancelFn: types.CloseActiveDialog,
+	}))
+}
+
+func (m *Model) delete() tea.Cmd {
+	return types.OpenDialog(confirm.New(m.app, confirm.Config{
+		Title:             fmt.Sprintf("Delete %s", m.mount.Path+m.path),
+		Message:           "Are you sure you want to delete this secret? " + types.DeleteUndoHint(m.mount),
+		AllowsBlur:        true,
+		ConfirmStatus:     types.Error,
+		TypedConfirmation: path.Base(m.path),
+		ConfirmFn: func() tea.Cmd {
+			m.staged = nil
+			return tea.Sequence(
+				m.app.Client().DeleteKVSecret(m.UUID(), m.mount, m.path),
+				types.CloseActiveDialog(),
+				types.CloseActivePage(),
+			)
+		},
+		CancelFn: types.CloseActiveDialog,
 	}))
 }
 
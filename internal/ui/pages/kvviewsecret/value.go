@@ -0,0 +1,270 @@
+// Copyright (c) Liam Stanley <liam@liam.sh>. All rights reserved. Use of
+// this source code is governed by the MIT license that can be found in
+// the LICENSE file.
+
+package kvviewsecret
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/lrstanley/vex/internal/config"
+)
+
+// maxFileSize is the maximum size of a file which can be loaded into a secret.
+const maxFileSize = 1 << 20 // 1MiB.
+
+// valueType is the type of a value of a secret key. Secret data is JSON, so these
+// map directly to JSON types.
+type valueType string
+
+const (
+	typeString valueType = "string"
+	typeNumber valueType = "number"
+	typeBool   valueType = "bool"
+	typeJSON   valueType = "json" // Objects and arrays.
+	typeNull   valueType = "null"
+)
+
+// valueTypes are all value types, in the order they are presented.
+var valueTypes = []string{
+	string(typeString),
+	string(typeNumber),
+	string(typeBool),
+	string(typeJSON),
+	string(typeNull),
+}
+
+// typeOf returns the type of the provided value, as decoded from JSON.
+func typeOf(v any) valueType {
+	switch v.(type) {
+	case nil:
+		return typeNull
+	case string:
+		return typeString
+	case bool:
+		return typeBool
+	case json.Number, float64, float32, int, int64, int32, uint, uint64, uint32:
+		return typeNumber
+	default:
+		return typeJSON
+	}
+}
+
+// formatValue formats a value for display or editing. Strings are returned
+// as-is, objects and arrays are indented, and everything else is returned as
+// its JSON representation, such that [parseValue] is able to reverse it.
+func formatValue(v any) string {
+	switch vv := v.(type) {
+	case string:
+		return vv
+	case map[string]any, []any:
+		b, err := json.MarshalIndent(vv, "", "  ")
+		if err != nil {
+			return fmt.Sprintf("%v", vv)
+		}
+		return string(b)
+	default:
+		b, err := json.Marshal(vv)
+		if err != nil {
+			return fmt.Sprintf("%v", vv)
+		}
+		return string(b)
+	}
+}
+
+// decodeJSON decodes JSON, keeping numbers as [json.Number] (the same as the
+// Vault API client), so that large integers aren't converted to floats.
+func decodeJSON(data string, v any) error {
+	dec := json.NewDecoder(strings.NewReader(data))
+	dec.UseNumber()
+	if err := dec.Decode(v); err != nil {
+		return err
+	}
+	if dec.More() {
+		return errors.New("unexpected data after top-level value")
+	}
+	return nil
+}
+
+// parseValue parses the provided (edited) value as the provided type.
+func parseValue(s string, t valueType) (any, error) {
+	switch t {
+	case typeString:
+		return s, nil
+	case typeNumber:
+		// Validate against the JSON number grammar, rather than what Go accepts
+		// (e.g. "NaN", "Inf", "0x1p-2" or "1_0"), which Vault would reject.
+		s = strings.TrimSpace(s)
+		var v any
+		if !json.Valid([]byte(s)) || decodeJSON(s, &v) != nil {
+			return nil, fmt.Errorf("invalid number: %q", s)
+		}
+		n, ok := v.(json.Number)
+		if !ok {
+			return nil, fmt.Errorf("invalid number: %q", s)
+		}
+		return n, nil
+	case typeBool:
+		b, err := strconv.ParseBool(strings.TrimSpace(s))
+		if err != nil {
+			return nil, fmt.Errorf("invalid bool: %q", s)
+		}
+		return b, nil
+	case typeJSON:
+		var v any
+		if err := decodeJSON(s, &v); err != nil {
+			return nil, fmt.Errorf("invalid json: %w", err)
+		}
+		switch v.(type) {
+		case map[string]any, []any:
+			return v, nil
+		default:
+			return nil, errors.New("invalid json: expected an object or array")
+		}
+	case typeNull:
+		return nil, nil
+	default:
+		return nil, fmt.Errorf("unknown type: %q", t)
+	}
+}
+
+// convertValue converts a value to another type, e.g. "true" (string) to true
+// (bool), or 123 (number) to "123" (string).
+func convertValue(v any, t valueType) (any, error) {
+	if t == typeNull {
+		return nil, nil
+	}
+	if v == nil && t == typeString {
+		return "", nil
+	}
+	return parseValue(formatValue(v), t)
+}
+
+// envKeyReplacer replaces characters which aren't valid in environment variable
+// names.
+var envKeyReplacer = regexp.MustCompile(`[^A-Za-z0-9_]`)
+
+// envSafeValue matches values which don't need quoting in env lines.
+var envSafeValue = regexp.MustCompile(`^[A-Za-z0-9_./:@%+,=-]*$`)
+
+// formatEnv formats the data as "KEY=value" lines (sorted by key), quoting values
+// such that they can be sourced by a shell, or used as a dotenv file.
+func formatEnv(data map[string]any) string {
+	keys := slices.Collect(maps.Keys(data))
+	slices.Sort(keys)
+
+	var sb strings.Builder
+	for _, k := range keys {
+		name := strings.ToUpper(envKeyReplacer.ReplaceAllString(k, "_"))
+		if name != "" && name[0] >= '0' && name[0] <= '9' {
+			name = "_" + name
+		}
+
+		value := formatValue(data[k])
+		if !envSafeValue.MatchString(value) {
+			value = "'" + strings.ReplaceAll(value, "'", `'\''`) + "'"
+		}
+
+		sb.WriteString(name + "=" + value + "\n")
+	}
+	return sb.String()
+}
+
+// fileLoadedMsg is sent when a file has been loaded, to be stored in a key.
+type fileLoadedMsg struct {
+	uuid   string
+	key    string
+	value  string
+	base64 bool // Whether the file was binary, and was base64 encoded.
+	err    error
+}
+
+// loadFile reads a file for storing in the provided key. Files which aren't
+// valid UTF-8 (e.g. DER-encoded certificates) are base64 encoded, as secret
+// data is JSON.
+func loadFile(uuid, key, path string) tea.Cmd {
+	return func() tea.Msg {
+		msg := fileLoadedMsg{uuid: uuid, key: key}
+
+		path, err := config.ExpandPath(path)
+		if err != nil {
+			msg.err = err
+			return msg
+		}
+
+		f, err := os.Open(path)
+		if err != nil {
+			msg.err = fmt.Errorf("load file: %w", err)
+			return msg
+		}
+		defer f.Close() //nolint:errcheck
+
+		data, err := io.ReadAll(io.LimitReader(f, maxFileSize+1))
+		if err != nil {
+			msg.err = fmt.Errorf("load file: %w", err)
+			return msg
+		}
+
+		if len(data) > maxFileSize {
+			msg.err = fmt.Errorf("load file: %q is larger than %d bytes", path, maxFileSize)
+			return msg
+		}
+
+		if utf8.Valid(data) && !bytes.ContainsRune(data, 0) {
+			msg.value = string(data)
+			return msg
+		}
+
+		msg.value = base64.StdEncoding.EncodeToString(data)
+		msg.base64 = true
+		return msg
+	}
+}
+
+// fileSavedMsg is sent when a value has been saved to a file.
+type fileSavedMsg struct {
+	uuid string
+	path string
+	err  error
+}
+
+// saveFile writes the provided value to a new file, readable only by the current
+// user. Existing files are never overwritten.
+func saveFile(uuid, path string, value any) tea.Cmd {
+	return func() tea.Msg {
+		msg := fileSavedMsg{uuid: uuid}
+
+		msg.path, msg.err = config.ExpandPath(path)
+		if msg.err != nil {
+			return msg
+		}
+
+		f, err := os.OpenFile(msg.path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+		if err != nil {
+			msg.err = fmt.Errorf("save file: %w", err)
+			return msg
+		}
+
+		_, err = f.WriteString(formatValue(value))
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+		if err != nil {
+			msg.err = fmt.Errorf("save file: %w", err)
+		}
+		return msg
+	}
+}
@@ -0,0 +1,109 @@
+// Copyright (c) Liam Stanley <liam@liam.sh>. All rights reserved. Use of
+// this source code is governed by the MIT license that can be found in
+// the LICENSE file.
+
+package kvviewsecret
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestParseValue(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		in      string
+		typ     valueType
+		want    any
+		wantErr bool
+	}{
+		{name: "string", in: "foo", typ: typeString, want: "foo"},
+		{name: "string-json-like", in: `{"a":1}`, typ: typeString, want: `{"a":1}`},
+		{name: "number", in: " 12345678901234567890 ", typ: typeNumber, want: json.Number("12345678901234567890")},
+		{name: "number-invalid", in: "abc", typ: typeNumber, wantErr: true},
+		{name: "number-float", in: "-1.5e3", typ: typeNumber, want: json.Number("-1.5e3")},
+		{name: "number-nan", in: "NaN", typ: typeNumber, wantErr: true},
+		{name: "number-inf", in: "Inf", typ: typeNumber, wantErr: true},
+		{name: "number-hex-float", in: "0x1p-2", typ: typeNumber, wantErr: true},
+		{name: "number-underscore", in: "1_0", typ: typeNumber, wantErr: true},
+		{name: "number-leading-zero", in: "01", typ: typeNumber, wantErr: true},
+		{name: "number-string", in: `"1"`, typ: typeNumber, wantErr: true},
+		{name: "bool", in: "true", typ: typeBool, want: true},
+		{name: "bool-invalid", in: "yes", typ: typeBool, wantErr: true},
+		{name: "json-object", in: `{"a":1}`, typ: typeJSON, want: map[string]any{"a": json.Number("1")}},
+		{name: "json-array", in: `[true]`, typ: typeJSON, want: []any{true}},
+		{name: "json-scalar", in: `1`, typ: typeJSON, wantErr: true},
+		{name: "json-trailing", in: `{} {}`, typ: typeJSON, wantErr: true},
+		{name: "null", in: "anything", typ: typeNull, want: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := parseValue(tt.in, tt.typ)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("unexpected value:\n got: %#v\nwant: %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatValueRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	for _, v := range []any{
+		"-----BEGIN CERTIFICATE-----\nabc\n-----END CERTIFICATE-----\n",
+		json.Number("1.5"),
+		false,
+		map[string]any{"nested": []any{json.Number("1"), "two"}},
+		nil,
+	} {
+		got, err := parseValue(formatValue(v), typeOf(v))
+		if err != nil {
+			t.Fatalf("unexpected error for %#v: %v", v, err)
+		}
+		if !reflect.DeepEqual(got, v) {
+			t.Fatalf("value changed on round trip:\n got: %#v\nwant: %#v", got, v)
+		}
+	}
+}
+
+func TestConvertValue(t *testing.T) {
+	t.Parallel()
+
+	if v, err := convertValue("true", typeBool); err != nil || v != true {
+		t.Fatalf("expected bool conversion, got %#v (%v)", v, err)
+	}
+	if v, err := convertValue(json.Number("42"), typeString); err != nil || v != "42" {
+		t.Fatalf("expected string conversion, got %#v (%v)", v, err)
+	}
+	if v, err := convertValue(nil, typeString); err != nil || v != "" {
+		t.Fatalf("expected empty string from null, got %#v (%v)", v, err)
+	}
+	if _, err := convertValue("not a number", typeNumber); err == nil {
+		t.Fatal("expected error converting invalid number")
+	}
+}
+
+func TestFormatEnv(t *testing.T) {
+	t.Parallel()
+
+	got := formatEnv(map[string]any{
+		"db-user":  "admin",
+		"password": "it's a secret",
+		"port":     json.Number("5432"),
+		"1st":      true,
+	})
+
+	want := "_1ST=true\nDB_USER=admin\nPASSWORD='it'\\''s a secret'\nPORT=5432\n"
+	if got != want {
+		t.Fatalf("unexpected env output:\n got: %q\nwant: %q", got, want)
+	}
+}
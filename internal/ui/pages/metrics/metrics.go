@@ -0,0 +1,369 @@
+// Copyright (c) Liam Stanley <liam@liam.sh>. All rights reserved. Use of
+// this source code is governed by the MIT license that can be found in
+// the LICENSE file.
+
+package metrics
+
+import (
+	"cmp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"charm.land/bubbles/v2/key"
+	tea "charm.land/bubbletea/v2"
+	"charm.land/lipgloss/v2"
+	"github.com/lrstanley/vex/internal/types"
+	"github.com/lrstanley/vex/internal/ui/components/table"
+	"github.com/lrstanley/vex/internal/ui/styles"
+)
+
+var Commands = []string{"metrics", "telemetry"}
+
+// historySize is the number of samples shown in sparklines. With the default
+// telemetry interval of 10s, this covers the last 5 minutes.
+const historySize = 30
+
+// series is a single metric of the raw metric browser, with its history.
+type series struct {
+	metric  *types.Metric
+	history []float64
+}
+
+// value returns the value of the metric tracked in its history, which is the
+// value of gauges, the rate of counters, and the mean of samples.
+func (s *series) value() float64 {
+	switch s.metric.Kind {
+	case types.MetricCounter:
+		return s.metric.PerSecond()
+	case types.MetricSample:
+		return s.metric.Mean
+	default:
+		return s.metric.Value
+	}
+}
+
+var _ types.Page = (*Model)(nil) // Ensure we implement the page interface.
+
+// Model shows a dashboard of key metrics of the Vault server, from sys/metrics,
+// with sparklines of their recent history, and a browser of all raw metrics.
+type Model struct {
+	*types.PageModel
+
+	// Core state.
+	app    types.AppState
+	stats  []*stat
+	series map[table.ID]*series
+
+	// UI state.
+	raw bool // Whether the raw metric browser is shown, rather than the dashboard.
+
+	// Child components.
+	dashboard *table.Model[*table.StaticRow[*stat]]
+	browser   *table.Model[*table.StaticRow[*series]]
+}
+
+func New(app types.AppState) *Model {
+	m := &Model{
+		PageModel: &types.PageModel{
+			Commands:         Commands,
+			SupportFiltering: true,
+			RefreshInterval:  10 * time.Second,
+			ShortKeyBinds:    []key.Binding{types.KeyToggleRawMetrics},
+			FullKeyBinds:     [][]key.Binding{{types.KeyToggleRawMetrics}},
+		},
+		app:    app,
+		stats:  newStats(),
+		series: make(map[table.ID]*series),
+	}
+
+	m.dashboard = table.New(app, table.Config[*table.StaticRow[*stat]]{
+		Columns: []*table.Column[*table.StaticRow[*stat]]{
+			{
+				ID:    "title",
+				Title: "Metric",
+				AccessorFn: func(row *table.StaticRow[*stat]) string {
+					return row.Value.title
+				},
+			},
+			{
+				ID:               "value",
+				Title:            "Value",
+				Align:            lipgloss.Right,
+				DisableFiltering: true,
+				AccessorFn: func(row *table.StaticRow[*stat]) string {
+					if !row.Value.found {
+						return "n/a"
+					}
+					return row.Value.format(row.Value.current)
+				},
+				StyleFn: func(row *table.StaticRow[*stat], baseStyle lipgloss.Style, _, _ bool) lipgloss.Style {
+					if !row.Value.found {
+						return baseStyle.Foreground(styles.Theme.AppFg()).Faint(true)
+					}
+					return baseStyle
+				},
+			},
+			{
+				ID:               "history",
+				Title:            "History",
+				MinWidth:         historySize,
+				DisableFiltering: true,
+				AccessorFn: func(row *table.StaticRow[*stat]) string {
+					return styles.Sparkline(row.Value.history, slices.Max(append([]float64{0}, row.Value.history...)))
+				},
+				StyleFn: func(_ *table.StaticRow[*stat], baseStyle lipgloss.Style, _, _ bool) lipgloss.Style {
+					return baseStyle.Foreground(styles.Theme.InfoFg())
+				},
+			},
+			{
+				ID:    "source",
+				Title: "Source",
+				AccessorFn: func(row *table.StaticRow[*stat]) string {
+					return "vault." + row.Value.name
+				},
+			},
+		},
+	})
+
+	m.browser = table.New(app, table.Config[*table.StaticRow[*series]]{
+		Columns: []*table.Column[*table.StaticRow[*series]]{
+			{
+				ID:    "name",
+				Title: "Name",
+				AccessorFn: func(row *table.StaticRow[*series]) string {
+					return row.Value.metric.Name
+				},
+			},
+			{
+				ID:       "labels",
+				Title:    "Labels",
+				MaxWidth: 40,
+				AccessorFn: func(row *table.StaticRow[*series]) string {
+					labels := make([]string, 0, len(row.Value.metric.Labels))
+					for k, v := range row.Value.metric.Labels {
+						labels = append(labels, k+"="+v)
+					}
+					slices.Sort(labels)
+					return strings.Join(labels, ",")
+				},
+			},
+			{
+				ID:       "kind",
+				Title:    "Kind",
+				MaxWidth: 8,
+				AccessorFn: func(row *table.StaticRow[*series]) string {
+					return string(row.Value.metric.Kind)
+				},
+			},
+			{
+				ID:               "value",
+				Title:            "Value",
+				Align:            lipgloss.Right,
+				DisableFiltering: true,
+				AccessorFn: func(row *table.StaticRow[*series]) string {
+					switch row.Value.metric.Kind {
+					case types.MetricGauge:
+						return formatNumber(row.Value.metric.Value)
+					case types.MetricCounter:
+						return formatNumber(row.Value.metric.Sum)
+					default:
+						return strconv.Itoa(row.Value.metric.Count)
+					}
+				},
+			},
+			{
+				ID:               "rate",
+				Title:            "Rate",
+				Align:            lipgloss.Right,
+				DisableFiltering: true,
+				AccessorFn: func(row *table.StaticRow[*series]) string {
+					if row.Value.metric.Kind == types.MetricGauge {
+						return ""
+					}
+					return formatRate(row.Value.metric.PerSecond())
+				},
+			},
+			{
+				ID:               "mean",
+				Title:            "Mean",
+				Align:            lipgloss.Right,
+				DisableFiltering: true,
+				AccessorFn: func(row *table.StaticRow[*series]) string {
+					return row.Value.aggregate(row.Value.metric.Mean)
+				},
+			},
+			{
+				ID:               "min",
+				Title:            "Min",
+				Align:            lipgloss.Right,
+				DisableFiltering: true,
+				AccessorFn: func(row *table.StaticRow[*series]) string {
+					return row.Value.aggregate(row.Value.metric.Min)
+				},
+			},
+			{
+				ID:               "max",
+				Title:            "Max",
+				Align:            lipgloss.Right,
+				DisableFiltering: true,
+				AccessorFn: func(row *table.StaticRow[*series]) string {
+					return row.Value.aggregate(row.Value.metric.Max)
+				},
+			},
+			{
+				ID:               "history",
+				Title:            "History",
+				MinWidth:         historySize,
+				DisableFiltering: true,
+				AccessorFn: func(row *table.StaticRow[*series]) string {
+					return styles.Sparkline(row.Value.history, slices.Max(append([]float64{0}, row.Value.history...)))
+				},
+				StyleFn: func(_ *table.StaticRow[*series], baseStyle lipgloss.Style, _, _ bool) lipgloss.Style {
+					return baseStyle.Foreground(styles.Theme.InfoFg())
+				},
+			},
+		},
+		NoResultsMsg: "no metrics reported",
+	})
+
+	return m
+}
+
+// aggregate formats v, which is an aggregate of the metric over the telemetry
+// interval, and as such isn't available for gauges.
+func (s *series) aggregate(v float64) string {
+	if s.metric.Kind == types.MetricGauge {
+		return ""
+	}
+	return formatNumber(v)
+}
+
+func (m *Model) Init() tea.Cmd {
+	return tea.Batch(
+		m.dashboard.Init(),
+		m.browser.Init(),
+		types.RefreshData(m.UUID()),
+	)
+}
+
+// active returns the table which is currently shown.
+func (m *Model) active() interface {
+	Update(msg tea.Msg) tea.Cmd
+	View() string
+} {
+	if m.raw {
+		return m.browser
+	}
+	return m.dashboard
+}
+
+func (m *Model) Update(msg tea.Msg) tea.Cmd {
+	var cmds []tea.Cmd
+
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		return tea.Batch(m.dashboard.Update(msg), m.browser.Update(msg))
+	case styles.ThemeUpdatedMsg:
+		return tea.Batch(m.dashboard.Update(msg), m.browser.Update(msg))
+	case types.PageVisibleMsg:
+		return types.RefreshData(m.UUID())
+	case types.RefreshDataMsg:
+		if msg.UUID != m.UUID() {
+			return nil
+		}
+		return tea.Batch(
+			types.PageLoading(),
+			m.app.Client().GetMetrics(m.UUID()),
+		)
+	case types.AppFilterMsg:
+		if msg.UUID != m.UUID() {
+			return nil
+		}
+		m.dashboard.SetFilter(msg.Text)
+		m.browser.SetFilter(msg.Text)
+		return nil
+	case types.ClientMsg:
+		if msg.UUID != m.UUID() {
+			return nil
+		}
+		if msg.Error != nil {
+			return types.PageErrors(msg.Error)
+		}
+
+		if vmsg, ok := msg.Msg.(types.ClientMetricsMsg); ok {
+			m.update(vmsg.Metrics)
+			return types.PageClearState()
+		}
+		return nil
+	case tea.KeyMsg:
+		if key.Matches(msg, types.KeyToggleRawMetrics) {
+			m.raw = !m.raw
+			return nil
+		}
+		return m.active().Update(msg)
+	}
+
+	cmds = append(cmds, m.dashboard.Update(msg), m.browser.Update(msg))
+	return tea.Batch(cmds...)
+}
+
+// update records the latest metrics in the dashboard and the raw metric browser.
+func (m *Model) update(metrics []*types.Metric) {
+	for _, s := range m.stats {
+		s.update(metrics)
+	}
+	m.dashboard.SetRows(table.RowsFrom(m.stats, func(s *stat) table.ID {
+		return table.ID(s.name + "/" + s.title)
+	}))
+
+	all := make(map[table.ID]*series, len(metrics))
+	for _, metric := range metrics {
+		id := table.ID(string(metric.Kind) + ":" + metric.ID())
+
+		s, ok := m.series[id]
+		if !ok {
+			s = &series{}
+		}
+		s.metric = metric
+		s.history = appendHistory(s.history, s.value())
+		all[id] = s
+	}
+	m.series = all
+
+	rows := make([]*table.StaticRow[*series], 0, len(all))
+	for id, s := range all {
+		rows = append(rows, &table.StaticRow[*series]{Value: s, ValueID: id})
+	}
+	slices.SortFunc(rows, func(a, b *table.StaticRow[*series]) int {
+		return cmp.Or(
+			strings.Compare(a.Value.metric.Name, b.Value.metric.Name),
+			strings.Compare(string(a.ValueID), string(b.ValueID)),
+		)
+	})
+	m.browser.SetRows(rows)
+}
+
+// appendHistory appends v to history, keeping at most [historySize] samples.
+func appendHistory(history []float64, v float64) []float64 {
+	history = append(history, v)
+	if len(history) > historySize {
+		history = slices.Clone(history[len(history)-historySize:])
+	}
+	return history
+}
+
+func (m *Model) View() string {
+	if m.dashboard.Width == 0 || m.dashboard.Height == 0 {
+		return ""
+	}
+	return m.active().View()
+}
+
+func (m *Model) TopMiddleBorder() string {
+	if m.raw {
+		return styles.Pluralize(len(m.series), "metric", "metrics")
+	}
+	return "key metrics"
+}
@@ -0,0 +1,120 @@
+// Copyright (c) Liam Stanley <liam@liam.sh>. All rights reserved. Use of
+// this source code is governed by the MIT license that can be found in
+// the LICENSE file.
+
+package metrics
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/lrstanley/vex/internal/types"
+	"github.com/lrstanley/vex/internal/ui/styles"
+)
+
+// stat is a key metric shown on the dashboard, aggregated over all label sets
+// of the metric (e.g. the token count of all namespaces and auth methods).
+type stat struct {
+	title  string
+	name   string // Name of the metric, without the "vault." prefix.
+	value  func(metrics []*types.Metric) float64
+	format func(v float64) string
+
+	current float64
+	found   bool      // Whether the metric was reported in the last update.
+	history []float64 // Values of the last updates, -1 if the metric wasn't reported.
+}
+
+// newStats returns the key metrics shown on the dashboard.
+func newStats() []*stat {
+	return []*stat{
+		{title: "Requests", name: "core.handle_request", value: perSecond, format: formatRate},
+		{title: "Request latency", name: "core.handle_request", value: mean, format: formatMillis},
+		{title: "Logins", name: "core.handle_login_request", value: perSecond, format: formatRate},
+		{title: "Tokens", name: "token.count", value: sum, format: formatNumber},
+		{title: "Leases", name: "expire.num_leases", value: sum, format: formatNumber},
+		{title: "Irrevocable leases", name: "expire.num_irrevocable_leases", value: sum, format: formatNumber},
+		{title: "KV secrets", name: "secret.kv.count", value: sum, format: formatNumber},
+		{title: "Raft commit time", name: "raft.commitTime", value: mean, format: formatMillis},
+		{title: "Raft applies", name: "raft.apply", value: perSecond, format: formatRate},
+		{title: "Barrier reads", name: "barrier.get", value: perSecond, format: formatRate},
+		{title: "Barrier writes", name: "barrier.put", value: perSecond, format: formatRate},
+		{title: "Barrier lists", name: "barrier.list", value: perSecond, format: formatRate},
+		{title: "Barrier deletes", name: "barrier.delete", value: perSecond, format: formatRate},
+		{title: "Goroutines", name: "runtime.num_goroutines", value: sum, format: formatNumber},
+		{title: "Memory allocated", name: "runtime.alloc_bytes", value: sum, format: func(v float64) string {
+			return styles.FormatBytes(int64(v))
+		}},
+	}
+}
+
+// matches returns true if the provided metric name is the metric of the stat.
+// Some metrics are prefixed with the hostname of the server (e.g.
+// "vault.<hostname>.runtime.alloc_bytes"), depending on the telemetry
+// configuration, so only the suffix is compared.
+func (s *stat) matches(name string) bool {
+	return name == "vault."+s.name || strings.HasSuffix(name, "."+s.name)
+}
+
+// update updates the stat from the latest metrics.
+func (s *stat) update(metrics []*types.Metric) {
+	var matched []*types.Metric
+	for _, metric := range metrics {
+		if s.matches(metric.Name) {
+			matched = append(matched, metric)
+		}
+	}
+
+	s.found = len(matched) > 0
+	if !s.found {
+		s.history = appendHistory(s.history, -1)
+		return
+	}
+
+	s.current = s.value(matched)
+	s.history = appendHistory(s.history, s.current)
+}
+
+// sum returns the sum of the values of gauges.
+func sum(metrics []*types.Metric) (v float64) {
+	for _, metric := range metrics {
+		v += metric.Value
+	}
+	return v
+}
+
+// perSecond returns the combined rate of counters or samples.
+func perSecond(metrics []*types.Metric) (v float64) {
+	for _, metric := range metrics {
+		v += metric.PerSecond()
+	}
+	return v
+}
+
+// mean returns the combined mean of samples.
+func mean(metrics []*types.Metric) float64 {
+	var total float64
+	var count int
+	for _, metric := range metrics {
+		total += metric.Sum
+		count += metric.Count
+	}
+	if count == 0 {
+		return 0
+	}
+	return total / float64(count)
+}
+
+// formatNumber formats v with at most 2 decimal places.
+func formatNumber(v float64) string {
+	s := strconv.FormatFloat(v, 'f', 2, 64)
+	return strings.TrimSuffix(strings.TrimRight(s, "0"), ".")
+}
+
+func formatRate(v float64) string {
+	return formatNumber(v) + "/s"
+}
+
+func formatMillis(v float64) string {
+	return formatNumber(v) + "ms"
+}
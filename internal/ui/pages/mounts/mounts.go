@@ -6,6 +6,7 @@ package mounts
 
 import (
 	"fmt"
+	"strconv"
 	"strings"
 	"time"
 
@@ -13,8 +14,10 @@ import (
 	tea "charm.land/bubbletea/v2"
 	"charm.land/lipgloss/v2"
 	"github.com/lrstanley/vex/internal/types"
+	"github.com/lrstanley/vex/internal/ui/components/form"
 	"github.com/lrstanley/vex/internal/ui/components/table"
 	"github.com/lrstanley/vex/internal/ui/dialogs/alert"
+	formdialog "github.com/lrstanley/vex/internal/ui/dialogs/form"
 	"github.com/lrstanley/vex/internal/ui/dialogs/genericcode"
 	"github.com/lrstanley/vex/internal/ui/pages/recursivesecrets"
 	"github.com/lrstanley/vex/internal/ui/pages/secretwalker"
@@ -51,7 +54,11 @@ func New(app types.AppState) *Model {
 			FullKeyBinds: [][]key.Binding{{
 				types.KeyDetails,
 				types.KeyListRecursive,
+				types.OverrideHelp(types.KeyEditMountConfig, "mount settings (kv v2 only)"),
 			}},
+			MutatingKeyBinds: []key.Binding{
+				types.KeyEditMountConfig,
+			},
 		},
 		app: app,
 	}
@@ -186,6 +193,10 @@ func (m *Model) Update(msg tea.Msg) tea.Cmd {
 			m.table.SetRows(table.RowsFrom(vmsg.Mounts, func(m *types.Mount) table.ID {
 				return table.ID(m.Path)
 			}))
+		case types.ClientGetKVv2MountConfigMsg:
+			return m.editConfig(vmsg)
+		case types.ClientSuccessMsg:
+			return types.SendStatus(vmsg.Message, types.Success, 2*time.Second)
 		}
 	case tea.KeyMsg:
 		switch {
@@ -197,6 +208,10 @@ func (m *Model) Update(msg tea.Msg) tea.Cmd {
 			if v, ok := m.table.GetSelectedRow(); ok {
 				return m.openRecursive(v)
 			}
+		case key.Matches(msg, types.KeyEditMountConfig):
+			if v, ok := m.table.GetSelectedRow(); ok && v.Value.KVVersion() == 2 {
+				return m.app.Client().GetKVv2MountConfig(m.UUID(), v.Value)
+			}
 		}
 	}
 
@@ -227,6 +242,44 @@ func (m *Model) openDetails(row *table.StaticRow[*types.Mount]) tea.Cmd {
 	return types.OpenDialog(genericcode.NewYAML(m.app, fmt.Sprintf("Mount Details: %q", row.Value.Path), false, row.Value))
 }
 
+func (m *Model) editConfig(msg types.ClientGetKVv2MountConfigMsg) tea.Cmd {
+	return types.OpenDialog(formdialog.New(m.app, formdialog.Config{
+		Title: fmt.Sprintf("Mount Settings: %q", msg.Mount.Path),
+		Fields: []*form.Field{
+			{
+				ID:          "max_versions",
+				Label:       "Max versions",
+				Placeholder: "0 (default of 10)",
+				Value:       strconv.Itoa(msg.Config.MaxVersions),
+				Validator:   form.ValidateInt(0),
+			},
+			{
+				ID:      "cas_required",
+				Label:   "Require CAS",
+				Value:   strconv.FormatBool(msg.Config.CASRequired),
+				Options: []string{"false", "true"},
+			},
+			{
+				ID:          "delete_version_after",
+				Label:       "Delete versions after",
+				Placeholder: "0s (never)",
+				Value:       msg.Config.DeleteVersionAfter.String(),
+				Validator:   form.ValidateDuration,
+			},
+		},
+		ConfirmFn: func(values map[string]string) tea.Cmd {
+			config := &types.KVv2MountConfig{
+				CASRequired: values["cas_required"] == "true",
+			}
+			config.MaxVersions, _ = strconv.Atoi(values["max_versions"])
+			if values["delete_version_after"] != "" {
+				config.DeleteVersionAfter, _ = time.ParseDuration(values["delete_version_after"])
+			}
+			return m.app.Client().PutKVv2MountConfig(m.UUID(), msg.Mount, config)
+		},
+	}))
+}
+
 func (m *Model) openRecursive(row *table.StaticRow[*types.Mount]) tea.Cmd {
 	return types.OpenPage(recursivesecrets.New(m.app, row.Value), false)
 }
@@ -0,0 +1,320 @@
+// Copyright (c) Liam Stanley <liam@liam.sh>. All rights reserved. Use of
+// this source code is governed by the MIT license that can be found in the
+// LICENSE file.
+
+package raftautopilot
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"charm.land/bubbles/v2/key"
+	tea "charm.land/bubbletea/v2"
+	"charm.land/lipgloss/v2"
+	vapi "github.com/hashicorp/vault/api"
+	"github.com/lrstanley/vex/internal/types"
+	"github.com/lrstanley/vex/internal/ui/components/form"
+	"github.com/lrstanley/vex/internal/ui/components/table"
+	formdialog "github.com/lrstanley/vex/internal/ui/dialogs/form"
+	"github.com/lrstanley/vex/internal/ui/styles"
+	"github.com/lrstanley/x/charm/formatter"
+)
+
+var Commands = []string{"autopilot", "raftautopilot"}
+
+var _ types.Page = (*Model)(nil) // Ensure we implement the page interface.
+
+// Model shows the integrated storage autopilot state, including the health of
+// each server, with an action to edit the autopilot configuration.
+type Model struct {
+	*types.PageModel
+
+	app   types.AppState
+	state *vapi.AutopilotState
+
+	table *table.Model[*table.StaticRow[*vapi.AutopilotServer]]
+}
+
+func New(app types.AppState) *Model {
+	m := &Model{
+		PageModel: &types.PageModel{
+			Commands:         Commands,
+			SupportFiltering: true,
+			RefreshInterval:  10 * time.Second,
+			ShortKeyBinds: []key.Binding{
+				types.KeyEditAutopilotConfig,
+			},
+			FullKeyBinds: [][]key.Binding{{
+				types.KeyEditAutopilotConfig,
+			}},
+			MutatingKeyBinds: []key.Binding{
+				types.KeyEditAutopilotConfig,
+			},
+		},
+		app: app,
+	}
+
+	m.table = table.New(app, table.Config[*table.StaticRow[*vapi.AutopilotServer]]{
+		Columns: []*table.Column[*table.StaticRow[*vapi.AutopilotServer]]{
+			{
+				ID:    "name",
+				Title: "Node ID",
+				AccessorFn: func(row *table.StaticRow[*vapi.AutopilotServer]) string {
+					return row.Value.Name
+				},
+			},
+			{
+				ID:    "address",
+				Title: "Address",
+				AccessorFn: func(row *table.StaticRow[*vapi.AutopilotServer]) string {
+					return row.Value.Address
+				},
+			},
+			{
+				ID:       "status",
+				Title:    "Status",
+				MaxWidth: 10,
+				AccessorFn: func(row *table.StaticRow[*vapi.AutopilotServer]) string {
+					return row.Value.Status
+				},
+				StyleFn: func(row *table.StaticRow[*vapi.AutopilotServer], baseStyle lipgloss.Style, _, _ bool) lipgloss.Style {
+					if row.Value.Status == "leader" {
+						return baseStyle.Foreground(styles.Theme.SuccessFg())
+					}
+					return baseStyle
+				},
+			},
+			{
+				ID:       "healthy",
+				Title:    "Healthy",
+				MaxWidth: 8,
+				AccessorFn: func(row *table.StaticRow[*vapi.AutopilotServer]) string {
+					return strconv.FormatBool(row.Value.Healthy)
+				},
+				StyleFn: func(row *table.StaticRow[*vapi.AutopilotServer], baseStyle lipgloss.Style, _, _ bool) lipgloss.Style {
+					if !row.Value.Healthy {
+						return baseStyle.Foreground(styles.Theme.ErrorFg()).Bold(true)
+					}
+					return baseStyle
+				},
+			},
+			{
+				ID:       "node_status",
+				Title:    "Node Status",
+				MaxWidth: 12,
+				AccessorFn: func(row *table.StaticRow[*vapi.AutopilotServer]) string {
+					return row.Value.NodeStatus
+				},
+				StyleFn: func(row *table.StaticRow[*vapi.AutopilotServer], baseStyle lipgloss.Style, _, _ bool) lipgloss.Style {
+					if row.Value.NodeStatus != "alive" {
+						return baseStyle.Foreground(styles.Theme.WarningFg())
+					}
+					return baseStyle
+				},
+			},
+			{
+				ID:       "last_contact",
+				Title:    "Last Contact",
+				MaxWidth: 12,
+				AccessorFn: func(row *table.StaticRow[*vapi.AutopilotServer]) string {
+					return row.Value.LastContact
+				},
+			},
+			{
+				ID:       "last_index",
+				Title:    "Last Index",
+				MaxWidth: 12,
+				AccessorFn: func(row *table.StaticRow[*vapi.AutopilotServer]) string {
+					return strconv.FormatUint(row.Value.LastIndex, 10)
+				},
+			},
+			{
+				ID:       "version",
+				Title:    "Version",
+				MaxWidth: 10,
+				AccessorFn: func(row *table.StaticRow[*vapi.AutopilotServer]) string {
+					return row.Value.Version
+				},
+			},
+			{
+				ID:    "stable_since",
+				Title: "Stable Since",
+				AccessorFn: func(row *table.StaticRow[*vapi.AutopilotServer]) string {
+					since, err := time.Parse(time.RFC3339, row.Value.StableSince)
+					if err != nil || since.IsZero() || since.Year() <= 1 {
+						return ""
+					}
+					return formatter.TimeRelative(since, true)
+				},
+			},
+		},
+		FetchFn: func() tea.Cmd {
+			return app.Client().GetRaftAutopilotState(m.UUID())
+		},
+	})
+
+	return m
+}
+
+func (m *Model) Init() tea.Cmd {
+	return tea.Batch(
+		m.table.Init(),
+		types.RefreshData(m.UUID()),
+	)
+}
+
+func (m *Model) Update(msg tea.Msg) tea.Cmd {
+	var cmds []tea.Cmd
+
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		return m.table.Update(msg)
+	case types.PageVisibleMsg:
+		return types.RefreshData(m.UUID())
+	case types.RefreshDataMsg:
+		return tea.Batch(
+			types.PageLoading(),
+			m.table.Fetch(false),
+		)
+	case types.AppFilterMsg:
+		if msg.UUID != m.UUID() {
+			return nil
+		}
+		m.table.SetFilter(msg.Text)
+	case types.ClientMsg:
+		if msg.UUID != m.UUID() {
+			return nil
+		}
+		if msg.Error != nil {
+			return types.PageErrors(msg.Error)
+		}
+
+		switch vmsg := msg.Msg.(type) {
+		case types.ClientRaftAutopilotStateMsg:
+			cmds = append(cmds, types.PageClearState())
+			m.state = vmsg.State
+
+			servers := slices.SortedFunc(maps.Values(vmsg.State.Servers), func(a, b *vapi.AutopilotServer) int {
+				return strings.Compare(strings.ToLower(a.Address), strings.ToLower(b.Address))
+			})
+			m.table.SetRows(table.RowsFrom(servers, func(s *vapi.AutopilotServer) table.ID {
+				return table.ID(s.ID)
+			}))
+		case types.ClientRaftAutopilotConfigMsg:
+			return m.editConfig(vmsg.Config)
+		case types.ClientSuccessMsg:
+			return tea.Batch(
+				types.SendStatus(vmsg.Message, types.Success, 2*time.Second),
+				types.RefreshData(m.UUID()),
+			)
+		}
+	case tea.KeyMsg:
+		if key.Matches(msg, types.KeyEditAutopilotConfig) {
+			return m.app.Client().GetRaftAutopilotConfig(m.UUID())
+		}
+	}
+
+	return tea.Batch(append(cmds, m.table.Update(msg))...)
+}
+
+func (m *Model) editConfig(config *vapi.AutopilotConfig) tea.Cmd {
+	return types.OpenDialog(formdialog.New(m.app, formdialog.Config{
+		Title: "Autopilot Settings",
+		Fields: []*form.Field{
+			{
+				ID:      "cleanup_dead_servers",
+				Label:   "Cleanup dead servers",
+				Value:   strconv.FormatBool(config.CleanupDeadServers),
+				Options: []string{"false", "true"},
+			},
+			{
+				ID:        "last_contact_threshold",
+				Label:     "Last contact threshold",
+				Value:     config.LastContactThreshold.String(),
+				Validator: form.ValidateDuration,
+			},
+			{
+				ID:        "dead_server_last_contact_threshold",
+				Label:     "Dead server threshold",
+				Value:     config.DeadServerLastContactThreshold.String(),
+				Validator: form.ValidateDuration,
+			},
+			{
+				ID:        "server_stabilization_time",
+				Label:     "Stabilization time",
+				Value:     config.ServerStabilizationTime.String(),
+				Validator: form.ValidateDuration,
+			},
+			{
+				ID:        "max_trailing_logs",
+				Label:     "Max trailing logs",
+				Value:     strconv.FormatUint(config.MaxTrailingLogs, 10),
+				Validator: form.ValidateInt(0),
+			},
+			{
+				ID:          "min_quorum",
+				Label:       "Min quorum",
+				Placeholder: "0 (no minimum)",
+				Value:       strconv.FormatUint(uint64(config.MinQuorum), 10),
+				Validator:   form.ValidateInt(0),
+			},
+			{
+				ID:      "disable_upgrade_migration",
+				Label:   "Disable upgrade migration",
+				Value:   strconv.FormatBool(config.DisableUpgradeMigration),
+				Options: []string{"false", "true"},
+			},
+		},
+		ConfirmFn: func(values map[string]string) tea.Cmd {
+			// Fields left empty keep their current value.
+			updated := *config
+			updated.CleanupDeadServers = values["cleanup_dead_servers"] == "true"
+			updated.DisableUpgradeMigration = values["disable_upgrade_migration"] == "true"
+
+			for id, v := range map[string]*time.Duration{
+				"last_contact_threshold":             &updated.LastContactThreshold,
+				"dead_server_last_contact_threshold": &updated.DeadServerLastContactThreshold,
+				"server_stabilization_time":          &updated.ServerStabilizationTime,
+			} {
+				if d, err := time.ParseDuration(values[id]); err == nil {
+					*v = d
+				}
+			}
+			if v, err := strconv.ParseUint(values["max_trailing_logs"], 10, 64); err == nil {
+				updated.MaxTrailingLogs = v
+			}
+			if v, err := strconv.ParseUint(values["min_quorum"], 10, 0); err == nil {
+				updated.MinQuorum = uint(v)
+			}
+
+			return m.app.Client().PutRaftAutopilotConfig(m.UUID(), &updated)
+		},
+	}))
+}
+
+func (m *Model) View() string {
+	if m.table.Width == 0 || m.table.Height == 0 {
+		return ""
+	}
+	return m.table.View()
+}
+
+func (m *Model) TopMiddleBorder() string {
+	if m.state == nil {
+		return ""
+	}
+
+	health := "healthy"
+	if !m.state.Healthy {
+		health = "unhealthy"
+	}
+	return fmt.Sprintf(
+		"%s, tolerates %s",
+		health,
+		styles.Pluralize(m.state.FailureTolerance, "failure", "failures"),
+	)
+}
@@ -6,15 +6,23 @@ package raftconfig
 
 import (
 	"fmt"
+	"os"
+	"path/filepath"
 	"strconv"
+	"strings"
 	"time"
 
 	"charm.land/bubbles/v2/key"
 	tea "charm.land/bubbletea/v2"
 	"charm.land/lipgloss/v2"
+	vapi "github.com/hashicorp/vault/api"
+	"github.com/lrstanley/vex/internal/config"
 	"github.com/lrstanley/vex/internal/types"
+	"github.com/lrstanley/vex/internal/ui/components/form"
 	"github.com/lrstanley/vex/internal/ui/components/table"
 	"github.com/lrstanley/vex/internal/ui/dialogs/confirm"
+	formdialog "github.com/lrstanley/vex/internal/ui/dialogs/form"
+	"github.com/lrstanley/vex/internal/ui/pages/raftautopilot"
 	"github.com/lrstanley/vex/internal/ui/styles"
 )
 
@@ -25,7 +33,8 @@ var _ types.Page = (*Model)(nil) // Ensure we implement the page interface.
 type Model struct {
 	*types.PageModel
 
-	app types.AppState
+	app      types.AppState
+	transfer *transfer // Snapshot transfer in progress, if any.
 
 	table *table.Model[*table.StaticRow[*types.RaftConfigPeer]]
 }
@@ -38,10 +47,25 @@ func New(app types.AppState) *Model {
 			RefreshInterval:  30 * time.Second,
 			ShortKeyBinds: []key.Binding{
 				types.OverrideHelp(types.KeyDelete, "remove peer"),
+				types.KeyRaftAutopilot,
+				types.OverrideHelp(types.KeySaveToFile, "save snapshot"),
 			},
-			FullKeyBinds: [][]key.Binding{{
+			FullKeyBinds: [][]key.Binding{
+				{
+					types.OverrideHelp(types.KeyDelete, "remove peer"),
+					types.KeyRaftJoin,
+				},
+				{
+					types.KeyRaftAutopilot,
+					types.OverrideHelp(types.KeySaveToFile, "save snapshot"),
+					types.OverrideHelp(types.KeyLoadFromFile, "restore snapshot"),
+				},
+			},
+			MutatingKeyBinds: []key.Binding{
 				types.KeyDelete,
-			}},
+				types.KeyRaftJoin,
+				types.KeyLoadFromFile,
+			},
 		},
 		app: app,
 	}
@@ -126,7 +150,23 @@ func (m *Model) Update(msg tea.Msg) tea.Cmd {
 			return nil
 		}
 		m.table.SetFilter(msg.Text)
+	case transferOpenedMsg:
+		if msg.uuid != m.UUID() {
+			return nil
+		}
+		return m.transferOpened(msg)
+	case transferTickMsg:
+		if m.transfer == nil || m.transfer.id != msg.id {
+			return nil
+		}
+		return tea.Batch(
+			types.AddStatusOperation(msg.id, m.transfer.progress()),
+			types.MsgAfterDuration(msg, transferTickInterval),
+		)
 	case types.ClientMsg:
+		if m.transfer != nil && msg.UUID == m.transfer.uuid {
+			return m.transferDone(msg)
+		}
 		if msg.UUID != m.UUID() {
 			return nil
 		}
@@ -150,6 +190,14 @@ func (m *Model) Update(msg tea.Msg) tea.Cmd {
 			if row, ok := m.table.GetSelectedRow(); ok {
 				return m.removePeer(row.Value)
 			}
+		case key.Matches(msg, types.KeyRaftAutopilot):
+			return types.OpenPage(raftautopilot.New(m.app), false)
+		case key.Matches(msg, types.KeySaveToFile):
+			return m.saveSnapshot()
+		case key.Matches(msg, types.KeyLoadFromFile):
+			return m.restoreSnapshot()
+		case key.Matches(msg, types.KeyRaftJoin):
+			return m.join()
 		}
 	}
 
@@ -161,10 +209,11 @@ func (m *Model) removePeer(peer *types.RaftConfigPeer) tea.Cmd {
 		return nil
 	}
 	return types.OpenDialog(confirm.New(m.app, confirm.Config{
-		Title:         "Remove raft peer",
-		Message:       fmt.Sprintf("Remove peer %q (node_id %q)?", peer.Address, peer.NodeID),
-		AllowsBlur:    true,
-		ConfirmStatus: types.Warning,
+		Title:             "Remove raft peer",
+		Message:           fmt.Sprintf("Remove peer %q (node_id %q)?", peer.Address, peer.NodeID),
+		AllowsBlur:        true,
+		ConfirmStatus:     types.Warning,
+		TypedConfirmation: peer.NodeID,
 		ConfirmFn: func() tea.Cmd {
 			return tea.Sequence(
 				m.app.Client().RemoveRaftPeer(m.UUID(), peer.NodeID),
@@ -176,6 +225,235 @@ func (m *Model) removePeer(peer *types.RaftConfigPeer) tea.Cmd {
 	}))
 }
 
+func (m *Model) saveSnapshot() tea.Cmd {
+	if m.transfer != nil {
+		return types.SendStatus("snapshot transfer already in progress", types.Warning, 2*time.Second)
+	}
+	return types.OpenDialog(formdialog.New(m.app, formdialog.Config{
+		Title: "Save raft snapshot",
+		Fields: []*form.Field{{
+			ID:        "path",
+			Label:     "Path",
+			Value:     fmt.Sprintf("raft-%s.snap", time.Now().Format("20060102-150405")),
+			Validator: form.ValidateRequired,
+		}},
+		ConfirmText: "save",
+		ConfirmFn: func(values map[string]string) tea.Cmd {
+			return createSnapshot(m.UUID(), strings.TrimSpace(values["path"]))
+		},
+	}))
+}
+
+func (m *Model) restoreSnapshot() tea.Cmd {
+	if m.transfer != nil {
+		return types.SendStatus("snapshot transfer already in progress", types.Warning, 2*time.Second)
+	}
+	return types.OpenDialog(formdialog.New(m.app, formdialog.Config{
+		Title: "Restore raft snapshot",
+		Fields: []*form.Field{
+			{
+				ID:          "path",
+				Label:       "Path",
+				Placeholder: "path to snapshot",
+				Validator:   form.ValidateRequired,
+			},
+			{
+				ID:      "force",
+				Label:   "Force (different cluster)",
+				Options: []string{"false", "true"},
+			},
+		},
+		ConfirmText: "next",
+		ConfirmFn: func(values map[string]string) tea.Cmd {
+			return openSnapshot(m.UUID(), strings.TrimSpace(values["path"]), values["force"] == "true")
+		},
+	}))
+}
+
+// transferOpened starts saving a snapshot, or asks for confirmation before
+// restoring one.
+func (m *Model) transferOpened(msg transferOpenedMsg) tea.Cmd {
+	if msg.err != nil {
+		return types.SendStatus(msg.err.Error(), types.Error, 5*time.Second)
+	}
+
+	t := msg.transfer
+	if !t.restore {
+		return m.startTransfer(t)
+	}
+
+	verified := "no checksum file to verify against"
+	if t.checked {
+		verified = "checksum verified"
+	}
+	force := ""
+	if t.force {
+		force = " Force is enabled, so snapshots from other clusters will be restored as well."
+	}
+
+	name := filepath.Base(t.path)
+	return types.OpenDialog(confirm.New(m.app, confirm.Config{
+		Title: "Restore raft snapshot",
+		Message: fmt.Sprintf(
+			"Restore %q (%s, sha256 %s, %s)? All data in the cluster will be replaced with the contents of the snapshot, which can't be undone.%s",
+			name,
+			styles.FormatBytes(t.size),
+			t.checksum(),
+			verified,
+			force,
+		),
+		ConfirmText:       "restore",
+		ConfirmStatus:     types.Error,
+		TypedConfirmation: name,
+		AlwaysTyped:       true,
+		ConfirmFn: func() tea.Cmd {
+			return tea.Sequence(
+				types.CloseActiveDialog(),
+				m.startTransfer(t),
+			)
+		},
+		CancelFn: func() tea.Cmd {
+			_ = t.file.Close()
+			return types.CloseActiveDialog()
+		},
+	}))
+}
+
+func (m *Model) startTransfer(t *transfer) tea.Cmd {
+	if m.transfer != nil {
+		_ = t.finish(true)
+		return types.SendStatus("snapshot transfer already in progress", types.Warning, 2*time.Second)
+	}
+
+	t.id = time.Now().UnixNano()
+	t.uuid = fmt.Sprintf("%s/snapshot-%d", m.UUID(), t.id)
+	m.transfer = t
+
+	var cmd tea.Cmd
+	if t.restore {
+		cmd = m.app.Client().RestoreRaftSnapshot(t.uuid, t, t.force)
+	} else {
+		cmd = m.app.Client().SaveRaftSnapshot(t.uuid, t)
+	}
+
+	return tea.Batch(
+		types.AddStatusOperation(t.id, t.progress()),
+		types.MsgAfterDuration(transferTickMsg{id: t.id}, transferTickInterval),
+		cmd,
+	)
+}
+
+// transferDone cleans up after a snapshot transfer completes. Errors are already
+// reported by the client.
+func (m *Model) transferDone(msg types.ClientMsg) tea.Cmd {
+	t := m.transfer
+	m.transfer = nil
+
+	cmds := []tea.Cmd{types.ClearStatusOperation(t.id)}
+
+	if err := t.finish(msg.Error != nil); err != nil {
+		return tea.Batch(append(cmds, types.SendStatus(err.Error(), types.Error, 5*time.Second))...)
+	}
+	if msg.Error != nil {
+		return tea.Batch(cmds...)
+	}
+
+	if t.restore {
+		return tea.Batch(append(
+			cmds,
+			types.SendStatus("snapshot restored from "+t.path, types.Success, 5*time.Second),
+			types.RefreshData(m.UUID()),
+		)...)
+	}
+
+	return tea.Batch(append(cmds, types.SendStatus(
+		fmt.Sprintf("snapshot saved to %s (%s, sha256 %s)", t.path, styles.FormatBytes(t.done.Load()), t.checksum()),
+		types.Success,
+		5*time.Second,
+	))...)
+}
+
+func (m *Model) join() tea.Cmd {
+	return types.OpenDialog(formdialog.New(m.app, formdialog.Config{
+		Title: "Join raft cluster",
+		Fields: []*form.Field{
+			{
+				ID:          "leader_api_addr",
+				Label:       "Leader API address",
+				Placeholder: "https://vault-1:8200",
+				Validator:   form.ValidateRequired,
+			},
+			{
+				ID:          "leader_ca_cert",
+				Label:       "Leader CA cert",
+				Placeholder: "path to PEM file (optional)",
+			},
+			{
+				ID:          "leader_client_cert",
+				Label:       "Client cert",
+				Placeholder: "path to PEM file (optional)",
+			},
+			{
+				ID:          "leader_client_key",
+				Label:       "Client key",
+				Placeholder: "path to PEM file (optional)",
+			},
+			{
+				ID:      "non_voter",
+				Label:   "Non-voter",
+				Options: []string{"false", "true"},
+			},
+			{
+				ID:      "retry",
+				Label:   "Retry",
+				Options: []string{"false", "true"},
+			},
+		},
+		ConfirmText: "join",
+		ConfirmFn: func(values map[string]string) tea.Cmd {
+			return tea.Sequence(
+				m.joinWith(values),
+				types.RefreshData(m.UUID()),
+			)
+		},
+	}))
+}
+
+// joinWith joins the raft cluster, loading the TLS certificates and key from the
+// paths in values.
+func (m *Model) joinWith(values map[string]string) tea.Cmd {
+	return func() tea.Msg {
+		req := &vapi.RaftJoinRequest{
+			LeaderAPIAddr: strings.TrimSpace(values["leader_api_addr"]),
+			NonVoter:      values["non_voter"] == "true",
+			Retry:         values["retry"] == "true",
+		}
+
+		for id, v := range map[string]*string{
+			"leader_ca_cert":     &req.LeaderCACert,
+			"leader_client_cert": &req.LeaderClientCert,
+			"leader_client_key":  &req.LeaderClientKey,
+		} {
+			path := strings.TrimSpace(values[id])
+			if path == "" {
+				continue
+			}
+
+			path, err := config.ExpandPath(path)
+			if err == nil {
+				var data []byte
+				data, err = os.ReadFile(path)
+				*v = string(data)
+			}
+			if err != nil {
+				return types.ClientMsg{UUID: m.UUID(), Error: fmt.Errorf("join raft cluster: %w", err)}
+			}
+		}
+
+		return m.app.Client().JoinRaft(m.UUID(), req)()
+	}
+}
+
 func (m *Model) View() string {
 	if m.table.Width == 0 || m.table.Height == 0 {
 		return ""
@@ -0,0 +1,203 @@
+// Copyright (c) Liam Stanley <liam@liam.sh>. All rights reserved. Use of
+// this source code is governed by the MIT license that can be found in the
+// LICENSE file.
+
+package raftconfig
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/lrstanley/vex/internal/config"
+	"github.com/lrstanley/vex/internal/ui/styles"
+)
+
+// transferTickInterval is how often the progress of a snapshot transfer is
+// updated in the statusbar.
+const transferTickInterval = 250 * time.Millisecond
+
+// transfer is a snapshot being saved to, or restored from, a local file. It's
+// passed to the client as the writer (or reader) of the snapshot, tracking the
+// progress and checksum of the snapshot as it's transferred.
+type transfer struct {
+	id      int64  // ID of the statusbar operation.
+	uuid    string // UUID used for the client request, to tell apart its response.
+	restore bool
+	force   bool
+	path    string
+	file    *os.File
+	hash    hash.Hash
+	sum     string // Checksum of the snapshot, only known upfront when restoring.
+	size    int64  // Size of the snapshot, only known upfront when restoring.
+	checked bool   // Whether the checksum was verified against a checksum file.
+	done    atomic.Int64
+}
+
+func (t *transfer) Write(p []byte) (int, error) {
+	n, err := t.file.Write(p)
+	t.hash.Write(p[:n])
+	t.done.Add(int64(n))
+	return n, err
+}
+
+func (t *transfer) Read(p []byte) (int, error) {
+	n, err := t.file.Read(p)
+	t.done.Add(int64(n))
+	return n, err
+}
+
+// progress returns the progress of the transfer, for the statusbar.
+func (t *transfer) progress() string {
+	done := t.done.Load()
+	if !t.restore {
+		return "saving snapshot: " + styles.FormatBytes(done)
+	}
+	return fmt.Sprintf(
+		"restoring snapshot: %d%% (%s/%s)",
+		done*100/max(1, t.size),
+		styles.FormatBytes(done),
+		styles.FormatBytes(t.size),
+	)
+}
+
+// checksum returns the checksum of the transferred snapshot.
+func (t *transfer) checksum() string {
+	if t.sum == "" {
+		t.sum = hex.EncodeToString(t.hash.Sum(nil))
+	}
+	return t.sum
+}
+
+// transferTickMsg is sent periodically while a transfer is in progress.
+type transferTickMsg struct {
+	id int64
+}
+
+// transferOpenedMsg is sent when the file of a transfer has been opened (and
+// for restores, checksummed), and the transfer can be started.
+type transferOpenedMsg struct {
+	uuid     string
+	transfer *transfer
+	err      error
+}
+
+// checksumPath returns the path of the checksum file for a snapshot, in the same
+// format as sha256sum(1).
+func checksumPath(path string) string {
+	return path + ".sha256"
+}
+
+// createSnapshot creates the file a snapshot will be saved to. Existing files
+// are never overwritten.
+func createSnapshot(uuid, path string) tea.Cmd {
+	return func() tea.Msg {
+		msg := transferOpenedMsg{uuid: uuid}
+
+		path, err := config.ExpandPath(path)
+		if err != nil {
+			msg.err = err
+			return msg
+		}
+
+		f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+		if err != nil {
+			msg.err = fmt.Errorf("save snapshot: %w", err)
+			return msg
+		}
+
+		msg.transfer = &transfer{path: path, file: f, hash: sha256.New()}
+		return msg
+	}
+}
+
+// openSnapshot opens a snapshot to be restored, calculating its checksum, and
+// verifying it against the checksum file alongside it, if one exists.
+func openSnapshot(uuid, path string, force bool) tea.Cmd {
+	return func() tea.Msg {
+		msg := transferOpenedMsg{uuid: uuid}
+
+		path, err := config.ExpandPath(path)
+		if err != nil {
+			msg.err = err
+			return msg
+		}
+
+		f, err := os.Open(path)
+		if err != nil {
+			msg.err = fmt.Errorf("restore snapshot: %w", err)
+			return msg
+		}
+
+		t := &transfer{restore: true, force: force, path: path, file: f, hash: sha256.New()}
+
+		t.size, err = io.Copy(t.hash, f)
+		if err == nil {
+			_, err = f.Seek(0, io.SeekStart)
+		}
+		if err == nil {
+			t.checked, err = verifyChecksum(path, t.checksum())
+		}
+		if err != nil {
+			_ = f.Close()
+			msg.err = fmt.Errorf("restore snapshot: %w", err)
+			return msg
+		}
+
+		msg.transfer = t
+		return msg
+	}
+}
+
+// verifyChecksum verifies sum against the checksum file of the snapshot at path,
+// returning false if there is no checksum file.
+func verifyChecksum(path, sum string) (bool, error) {
+	data, err := os.ReadFile(checksumPath(path))
+	if errors.Is(err, os.ErrNotExist) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	expected, _, _ := strings.Cut(strings.TrimSpace(string(data)), " ")
+	if !strings.EqualFold(expected, sum) {
+		return false, fmt.Errorf("checksum mismatch: expected %s, got %s", expected, sum)
+	}
+	return true, nil
+}
+
+// finish closes the file of the transfer. Saved snapshots are removed if the
+// transfer failed, and otherwise have their checksum written alongside them.
+func (t *transfer) finish(failed bool) error {
+	err := t.file.Close()
+
+	switch {
+	case t.restore:
+		return nil
+	case failed:
+		_ = os.Remove(t.path)
+		return nil
+	case err != nil:
+		return fmt.Errorf("save snapshot: %w", err)
+	}
+
+	err = os.WriteFile(
+		checksumPath(t.path),
+		[]byte(t.checksum()+"  "+filepath.Base(t.path)+"\n"),
+		0o600,
+	)
+	if err != nil {
+		return fmt.Errorf("save snapshot checksum: %w", err)
+	}
+	return nil
+}
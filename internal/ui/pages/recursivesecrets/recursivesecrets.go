@@ -6,6 +6,7 @@ package recursivesecrets
 
 import (
 	"fmt"
+	"path"
 	"slices"
 	"strings"
 	"time"
@@ -13,6 +14,7 @@ import (
 	"charm.land/bubbles/v2/key"
 	tea "charm.land/bubbletea/v2"
 	"charm.land/lipgloss/v2"
+	"github.com/lrstanley/vex/internal/config"
 	"github.com/lrstanley/vex/internal/types"
 	"github.com/lrstanley/vex/internal/ui/components/table"
 	"github.com/lrstanley/vex/internal/ui/dialogs/confirm"
@@ -62,6 +64,10 @@ func New(app types.AppState, mount *types.Mount) *Model {
 				types.KeyOpenEditor,
 				types.KeyDelete,
 			}},
+			MutatingKeyBinds: []key.Binding{
+				types.KeyOpenEditor,
+				types.KeyDelete,
+			},
 		},
 		app:   app,
 		mount: mount,
@@ -247,10 +253,11 @@ func (m *Model) deleteSecret(secret *types.ClientSecretTreeRef) tea.Cmd {
 	}
 
 	return types.OpenDialog(confirm.New(m.app, confirm.Config{
-		Title:         fmt.Sprintf("Delete %s", secret.GetFullPath(true)),
-		Message:       "Are you sure you want to delete this secret? This cannot be undone.",
-		AllowsBlur:    true,
-		ConfirmStatus: types.Error,
+		Title:             fmt.Sprintf("Delete %s", secret.GetFullPath(true)),
+		Message:           "Are you sure you want to delete this secret? " + types.DeleteUndoHint(secret.Mount),
+		AllowsBlur:        true,
+		ConfirmStatus:     types.Error,
+		TypedConfirmation: path.Base(secret.Path),
 		ConfirmFn: func() tea.Cmd {
 			return tea.Sequence(confirmCmds...)
 		},
@@ -297,3 +304,12 @@ func (m *Model) GetTitle() string {
 
 	return fmt.Sprintf("Secrets (recursive): %s", m.mount.Path)
 }
+
+// Location implements [types.PageLocator].
+func (m *Model) Location() config.PageLocation {
+	loc := config.PageLocation{Type: Commands[0]}
+	if m.mount != nil {
+		loc.Mount = m.mount.Path
+	}
+	return loc
+}
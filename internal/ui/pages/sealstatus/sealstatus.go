@@ -0,0 +1,356 @@
+// Copyright (c) Liam Stanley <liam@liam.sh>. All rights reserved. Use of
+// this source code is governed by the MIT license that can be found in
+// the LICENSE file.
+
+package sealstatus
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"charm.land/bubbles/v2/key"
+	tea "charm.land/bubbletea/v2"
+	"charm.land/lipgloss/v2"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/lrstanley/vex/internal/types"
+	"github.com/lrstanley/vex/internal/ui/components/form"
+	"github.com/lrstanley/vex/internal/ui/dialogs/confirm"
+	formdialog "github.com/lrstanley/vex/internal/ui/dialogs/form"
+	"github.com/lrstanley/vex/internal/ui/styles"
+	"github.com/lrstanley/x/charm/formatter"
+)
+
+var Commands = []string{"seal", "sealstatus", "unseal"}
+
+// maxProgressWidth is the maximum width of the unseal progress bar.
+const maxProgressWidth = 40
+
+var _ types.Page = (*Model)(nil) // Ensure we implement the page interface.
+
+// Model shows the seal and HA status of the Vault server (node), with actions to
+// unseal, seal and step down, depending on the state of the node and the
+// capabilities of the token.
+type Model struct {
+	*types.PageModel
+
+	// Core state.
+	app    types.AppState
+	status *types.ClientSealStatusMsg
+
+	// UI state.
+	height int
+	width  int
+
+	// Styles.
+	labelStyle    lipgloss.Style
+	valueStyle    lipgloss.Style
+	progressStyle lipgloss.Style
+	trackStyle    lipgloss.Style
+}
+
+func New(app types.AppState) *Model {
+	m := &Model{
+		PageModel: &types.PageModel{
+			Commands:        Commands,
+			RefreshInterval: 5 * time.Second,
+			ShortKeyBinds: []key.Binding{
+				types.KeyUnseal,
+				types.KeySeal,
+				types.KeyStepDown,
+			},
+			FullKeyBinds: [][]key.Binding{{
+				types.KeyUnseal,
+				types.OverrideHelp(types.KeyResetUnseal, "reset unseal progress"),
+				types.KeySeal,
+				types.KeyStepDown,
+			}},
+			MutatingKeyBinds: []key.Binding{
+				types.KeyUnseal,
+				types.KeyResetUnseal,
+				types.KeySeal,
+				types.KeyStepDown,
+			},
+		},
+		app: app,
+	}
+
+	m.setStyles()
+	return m
+}
+
+func (m *Model) setStyles() {
+	m.labelStyle = lipgloss.NewStyle().
+		Foreground(styles.Theme.InfoFg()).
+		Bold(true)
+	m.valueStyle = lipgloss.NewStyle().
+		Foreground(styles.Theme.AppFg())
+	m.progressStyle = lipgloss.NewStyle().
+		Foreground(styles.Theme.SuccessFg())
+	m.trackStyle = lipgloss.NewStyle().
+		Foreground(styles.Theme.ScrollbarTrackFg())
+}
+
+func (m *Model) Init() tea.Cmd {
+	return types.RefreshData(m.UUID())
+}
+
+func (m *Model) Update(msg tea.Msg) tea.Cmd {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+		return nil
+	case styles.ThemeUpdatedMsg:
+		m.setStyles()
+		return nil
+	case types.PageVisibleMsg:
+		return types.RefreshData(m.UUID())
+	case types.RefreshDataMsg:
+		if msg.UUID != m.UUID() {
+			return nil
+		}
+		return tea.Batch(
+			types.PageLoading(),
+			m.app.Client().GetSealStatus(m.UUID()),
+		)
+	case types.ClientMsg:
+		if msg.UUID != m.UUID() {
+			return nil
+		}
+		if msg.Error != nil {
+			return types.PageErrors(msg.Error)
+		}
+
+		switch vmsg := msg.Msg.(type) {
+		case types.ClientSealStatusMsg:
+			m.status = &vmsg
+			return types.PageClearState()
+		case types.ClientSuccessMsg:
+			// Also refresh the health shown in the status bar, which changes with
+			// the seal status.
+			return tea.Batch(
+				types.SendStatus(vmsg.Message, types.Success, 2*time.Second),
+				types.RefreshData(m.UUID()),
+				m.app.Client().GetHealth(m.UUID()),
+			)
+		}
+	case tea.KeyMsg:
+		if m.status == nil {
+			return nil
+		}
+
+		sealed := m.status.Status.Sealed
+
+		switch {
+		case key.Matches(msg, types.KeyUnseal):
+			if !sealed {
+				return types.SendStatus("already unsealed", types.Info, 2*time.Second)
+			}
+			return m.unseal()
+		case key.Matches(msg, types.KeyResetUnseal):
+			if !sealed || m.status.Status.Progress == 0 {
+				return types.SendStatus("no unseal in progress", types.Info, 2*time.Second)
+			}
+			return m.resetUnseal()
+		case key.Matches(msg, types.KeySeal):
+			switch {
+			case sealed:
+				return types.SendStatus("already sealed", types.Info, 2*time.Second)
+			case !m.status.SealCapabilities.Contains(types.CapabilitySudo):
+				return types.SendStatus("sealing requires sudo on sys/seal", types.Warning, 2*time.Second)
+			}
+			return m.seal()
+		case key.Matches(msg, types.KeyStepDown):
+			leader := m.status.Leader
+			switch {
+			case leader == nil || !leader.HAEnabled || !leader.IsSelf:
+				return types.SendStatus("only the active node of a HA cluster can step down", types.Info, 2*time.Second)
+			case !m.status.StepDownCapabilities.Contains(types.CapabilitySudo):
+				return types.SendStatus("stepping down requires sudo on sys/step-down", types.Warning, 2*time.Second)
+			}
+			return m.stepDown()
+		}
+	}
+
+	return nil
+}
+
+func (m *Model) unseal() tea.Cmd {
+	return types.OpenDialog(formdialog.New(m.app, formdialog.Config{
+		Title: "Unseal",
+		Fields: []*form.Field{{
+			ID:        "key",
+			Label:     "Unseal key",
+			Masked:    true,
+			Validator: form.ValidateRequired,
+		}},
+		ConfirmText: "unseal",
+		ConfirmFn: func(values map[string]string) tea.Cmd {
+			return m.app.Client().Unseal(m.UUID(), strings.TrimSpace(values["key"]))
+		},
+	}))
+}
+
+func (m *Model) resetUnseal() tea.Cmd {
+	return types.OpenDialog(confirm.New(m.app, confirm.Config{
+		Title:         "Reset unseal progress",
+		Message:       "Discard all unseal key shares submitted so far?",
+		AllowsBlur:    true,
+		ConfirmStatus: types.Warning,
+		ConfirmFn: func() tea.Cmd {
+			return tea.Sequence(
+				types.CloseActiveDialog(),
+				m.app.Client().ResetUnseal(m.UUID()),
+			)
+		},
+		CancelFn: types.CloseActiveDialog,
+	}))
+}
+
+func (m *Model) seal() tea.Cmd {
+	return types.OpenDialog(confirm.New(m.app, confirm.Config{
+		Title: "Seal node",
+		Message: fmt.Sprintf(
+			"Seal %q? All requests to it will fail until it is unsealed again, which requires %s.",
+			m.app.Client().Profile(),
+			styles.Pluralize(m.status.Status.T, "unseal key share", "unseal key shares"),
+		),
+		AllowsBlur:        true,
+		ConfirmText:       "seal",
+		ConfirmStatus:     types.Error,
+		TypedConfirmation: m.status.Status.ClusterName,
+		ConfirmFn: func() tea.Cmd {
+			return tea.Sequence(
+				types.CloseActiveDialog(),
+				m.app.Client().Seal(m.UUID()),
+			)
+		},
+		CancelFn: types.CloseActiveDialog,
+	}))
+}
+
+func (m *Model) stepDown() tea.Cmd {
+	return types.OpenDialog(confirm.New(m.app, confirm.Config{
+		Title:         "Step down",
+		Message:       "Step down the active node? Another node will be elected as the active node.",
+		AllowsBlur:    true,
+		ConfirmText:   "step down",
+		ConfirmStatus: types.Warning,
+		ConfirmFn: func() tea.Cmd {
+			return tea.Sequence(
+				types.CloseActiveDialog(),
+				m.app.Client().StepDown(m.UUID()),
+			)
+		},
+		CancelFn: types.CloseActiveDialog,
+	}))
+}
+
+// progress renders the unseal progress bar.
+func (m *Model) progress(width int) string {
+	status := m.status.Status
+	label := fmt.Sprintf(" %d/%d", status.Progress, status.T)
+
+	width = min(maxProgressWidth, width-ansi.StringWidth(label))
+	if width <= 0 || status.T <= 0 {
+		return label
+	}
+
+	filled := min(width, width*status.Progress/status.T)
+	return m.progressStyle.Render(strings.Repeat("█", filled)) +
+		m.trackStyle.Render(strings.Repeat("░", width-filled)) +
+		m.valueStyle.Render(label)
+}
+
+func (m *Model) View() string {
+	if m.width == 0 || m.height == 0 || m.status == nil {
+		return ""
+	}
+
+	status := m.status.Status
+
+	var rows [][2]string
+	add := func(label, value string) {
+		if value != "" {
+			rows = append(rows, [2]string{label, value})
+		}
+	}
+
+	state, stateStatus := "unsealed", types.Success
+	if status.Sealed {
+		state, stateStatus = "sealed", types.Warning
+	}
+	fg, _ := styles.Theme.ByStatus(stateStatus)
+
+	add("Status", lipgloss.NewStyle().Foreground(fg).Bold(true).Render(state))
+	add("Seal type", status.Type)
+	add("Initialized", strconv.FormatBool(status.Initialized))
+	add("Threshold", fmt.Sprintf("%d of %d key shares", status.T, status.N))
+	add("Version", status.Version)
+	add("Storage", status.StorageType)
+	add("Cluster name", status.ClusterName)
+	add("Cluster ID", status.ClusterID)
+
+	if leader := m.status.Leader; leader != nil {
+		add("HA enabled", strconv.FormatBool(leader.HAEnabled))
+		if leader.HAEnabled {
+			switch {
+			case leader.IsSelf:
+				add("HA mode", "active")
+			case leader.PerfStandby:
+				add("HA mode", "performance standby")
+			default:
+				add("HA mode", "standby")
+			}
+			add("Leader address", leader.LeaderAddress)
+			add("Leader cluster", leader.LeaderClusterAddress)
+			if !leader.ActiveTime.IsZero() {
+				add("Active since", formatter.TimeRelative(leader.ActiveTime, true))
+			}
+		}
+	}
+
+	const progressLabel = "Unseal progress"
+
+	var labelWidth int
+	if status.Sealed {
+		labelWidth = ansi.StringWidth(progressLabel)
+	}
+	for _, row := range rows {
+		labelWidth = max(labelWidth, ansi.StringWidth(row[0]))
+	}
+	labelWidth += 2 // Spacing between labels and values.
+
+	lines := make([]string, 0, len(rows)+2)
+	for _, row := range rows {
+		lines = append(lines, m.labelStyle.Width(labelWidth).Render(row[0])+m.valueStyle.Render(row[1]))
+	}
+
+	if status.Sealed {
+		lines = append(
+			lines,
+			"",
+			m.labelStyle.Width(labelWidth).Render(progressLabel)+m.progress(m.width-labelWidth-2),
+		)
+	}
+
+	return lipgloss.NewStyle().
+		Width(m.width).
+		MaxWidth(m.width).
+		Height(m.height).
+		MaxHeight(m.height).
+		Padding(0, 1).
+		Render(lipgloss.JoinVertical(lipgloss.Left, lines...))
+}
+
+func (m *Model) TopMiddleBorder() string {
+	if m.status == nil {
+		return ""
+	}
+	if m.status.Status.Sealed {
+		return "sealed"
+	}
+	return "unsealed"
+}
@@ -5,24 +5,48 @@
 package secretwalker
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
+	"path"
+	"slices"
+	"strconv"
 	"strings"
 	"time"
 
 	"charm.land/bubbles/v2/key"
 	tea "charm.land/bubbletea/v2"
 	"charm.land/lipgloss/v2"
+	vapi "github.com/hashicorp/vault/api"
+	"github.com/lrstanley/vex/internal/config"
 	"github.com/lrstanley/vex/internal/types"
+	"github.com/lrstanley/vex/internal/ui/components/confirmable"
+	"github.com/lrstanley/vex/internal/ui/components/form"
 	"github.com/lrstanley/vex/internal/ui/components/table"
 	"github.com/lrstanley/vex/internal/ui/dialogs/confirm"
+	formdialog "github.com/lrstanley/vex/internal/ui/dialogs/form"
 	"github.com/lrstanley/vex/internal/ui/dialogs/genericcode"
+	"github.com/lrstanley/vex/internal/ui/dialogs/textarea"
 	"github.com/lrstanley/vex/internal/ui/pages/kvv2versions"
 	"github.com/lrstanley/vex/internal/ui/pages/kvviewsecret"
 	"github.com/lrstanley/vex/internal/ui/styles"
 )
 
+// PageType identifies the page in a [config.PageLocation].
+const PageType = "secretwalker"
+
 var _ types.Page = (*Model)(nil) // Ensure we implement the page interface.
 
+// metadataIntent is what to do with the metadata of a secret, once it has been
+// fetched.
+type metadataIntent int
+
+const (
+	metadataView metadataIntent = iota
+	metadataEdit
+	metadataEditCustom
+)
+
 type Model struct {
 	*types.PageModel
 
@@ -30,8 +54,12 @@ type Model struct {
 	app types.AppState
 
 	// UI state.
-	mount *types.Mount
-	path  string
+	mount          *types.Mount
+	path           string
+	metadataIntent metadataIntent
+	showMetadata   bool
+	metadata       map[string]*vapi.KVMetadata
+	baseColumns    []*table.Column[*table.StaticRow[*types.SecretListRef]]
 
 	// Child components.
 	table *table.Model[*table.StaticRow[*types.SecretListRef]]
@@ -44,51 +72,70 @@ func New(app types.AppState, mount *types.Mount, path string) *Model {
 			RefreshInterval:  30 * time.Second,
 			FullKeyBinds: [][]key.Binding{{
 				types.OverrideHelp(types.KeyDetails, "view metadata (kv v2 only)"),
+				types.KeyEditMetadata,
+				types.KeyEditCustomMetadata,
 				types.KeyOpenEditor,
 				types.KeyDelete,
+				types.KeyToggleBookmark,
 			}},
+			MutatingKeyBinds: []key.Binding{
+				types.KeyEditMetadata,
+				types.KeyEditCustomMetadata,
+				types.KeyOpenEditor,
+				types.KeyDelete,
+			},
 		},
 		app:   app,
 		mount: mount,
 		path:  path,
 	}
 
-	m.table = table.New(app, table.Config[*table.StaticRow[*types.SecretListRef]]{
-		Columns: []*table.Column[*table.StaticRow[*types.SecretListRef]]{
-			{
-				ID:    "mount",
-				Title: "Mount",
-				AccessorFn: func(row *table.StaticRow[*types.SecretListRef]) string {
-					return row.Value.Mount.Path
-				},
+	if mount.KVVersion() == 2 {
+		m.FullKeyBinds = append(m.FullKeyBinds, []key.Binding{
+			types.KeyEditMetadata,
+			types.KeyEditCustomMetadata,
+			types.KeyToggleMetadataColumns,
+		})
+	}
+
+	m.baseColumns = []*table.Column[*table.StaticRow[*types.SecretListRef]]{
+		{
+			ID:    "mount",
+			Title: "Mount",
+			AccessorFn: func(row *table.StaticRow[*types.SecretListRef]) string {
+				return row.Value.Mount.Path
 			},
-			{
-				ID:    "key",
-				Title: "Key",
-				AccessorFn: func(row *table.StaticRow[*types.SecretListRef]) string {
-					if strings.HasSuffix(row.Value.Path, "/") {
-						return styles.IconFolder() + " " + row.Value.Path
-					}
-					return styles.IconSecret() + " " + row.Value.Path
-				},
-				StyleFn: func(row *table.StaticRow[*types.SecretListRef], baseStyle lipgloss.Style, _, _ bool) lipgloss.Style {
-					if strings.HasSuffix(row.Value.Path, "/") {
-						return baseStyle.Bold(true).Foreground(styles.Theme.InfoFg())
-					}
-					return baseStyle
-				},
+		},
+		{
+			ID:    "key",
+			Title: "Key",
+			AccessorFn: func(row *table.StaticRow[*types.SecretListRef]) string {
+				if strings.HasSuffix(row.Value.Path, "/") {
+					return styles.IconFolder() + " " + row.Value.Path
+				}
+				return styles.IconSecret() + " " + row.Value.Path
 			},
-			{
-				ID:    "capabilities",
-				Title: "Capabilities",
-				AccessorFn: func(row *table.StaticRow[*types.SecretListRef]) string {
-					return string(row.Value.Capabilities.Highest(row.Value.FullPath()))
-				},
-				StyleFn: func(row *table.StaticRow[*types.SecretListRef], baseStyle lipgloss.Style, _, _ bool) lipgloss.Style {
-					return styles.ClientCapabilities(baseStyle, row.Value.Capabilities, row.Value.FullPath())
-				},
+			StyleFn: func(row *table.StaticRow[*types.SecretListRef], baseStyle lipgloss.Style, _, _ bool) lipgloss.Style {
+				if strings.HasSuffix(row.Value.Path, "/") {
+					return baseStyle.Bold(true).Foreground(styles.Theme.InfoFg())
+				}
+				return baseStyle
+			},
+		},
+		{
+			ID:    "capabilities",
+			Title: "Capabilities",
+			AccessorFn: func(row *table.StaticRow[*types.SecretListRef]) string {
+				return string(row.Value.Capabilities.Highest(row.Value.FullPath()))
+			},
+			StyleFn: func(row *table.StaticRow[*types.SecretListRef], baseStyle lipgloss.Style, _, _ bool) lipgloss.Style {
+				return styles.ClientCapabilities(baseStyle, row.Value.Capabilities, row.Value.FullPath())
 			},
 		},
+	}
+
+	m.table = table.New(app, table.Config[*table.StaticRow[*types.SecretListRef]]{
+		Columns: m.baseColumns,
 		FetchFn: func() tea.Cmd {
 			return app.Client().ListSecrets(m.UUID(), m.mount, m.path)
 		},
@@ -139,20 +186,61 @@ func (m *Model) Update(msg tea.Msg) tea.Cmd {
 			m.table.SetRows(table.RowsFrom(vmsg.Values, func(v *types.SecretListRef) table.ID {
 				return table.ID(v.Mount.Path + v.Path)
 			}))
+
+			if m.showMetadata {
+				cmds = append(cmds, m.fetchMetadata())
+			}
+		case types.ClientListKVv2MetadataMsg:
+			if m.showMetadata {
+				m.metadata = vmsg.Metadata
+				m.updateMetadataColumns()
+			}
+			if len(vmsg.Errors) > 0 {
+				return types.SendStatus(
+					fmt.Sprintf("unable to read metadata of %s", styles.Pluralize(len(vmsg.Errors), "secret", "secrets")),
+					types.Warning,
+					3*time.Second,
+				)
+			}
 		case types.ClientGetKVv2MetadataMsg:
-			return types.OpenDialog(genericcode.NewYAML(
-				m.app,
-				"Metadata: "+vmsg.Mount.Path+vmsg.Path,
-				false,
-				vmsg.Metadata,
-			))
+			intent := m.metadataIntent
+			m.metadataIntent = metadataView
+
+			switch intent {
+			case metadataEdit:
+				return m.editMetadata(vmsg)
+			case metadataEditCustom:
+				return m.editCustomMetadata(vmsg)
+			default:
+				return types.OpenDialog(genericcode.NewYAML(
+					m.app,
+					"Metadata: "+vmsg.Mount.Path+vmsg.Path,
+					false,
+					vmsg.Metadata,
+				))
+			}
+		case types.ClientSuccessMsg:
+			return tea.Batch(
+				types.SendStatus(vmsg.Message, types.Success, 2*time.Second),
+				types.RefreshData(m.UUID()),
+			)
 		}
 	case tea.KeyMsg:
 		switch {
 		case key.Matches(msg, types.KeyDetails) && m.mount.KVVersion() == 2:
-			if v, ok := m.table.GetSelectedRow(); ok && !strings.HasSuffix(v.Value.Path, "/") {
-				return m.app.Client().GetKVv2Metadata(m.UUID(), v.Value.Mount, v.Value.Path)
+			return m.getMetadata(metadataView)
+		case key.Matches(msg, types.KeyEditMetadata) && m.mount.KVVersion() == 2:
+			return m.getMetadata(metadataEdit)
+		case key.Matches(msg, types.KeyEditCustomMetadata) && m.mount.KVVersion() == 2:
+			return m.getMetadata(metadataEditCustom)
+		case key.Matches(msg, types.KeyToggleMetadataColumns) && m.mount.KVVersion() == 2:
+			m.showMetadata = !m.showMetadata
+			if m.showMetadata {
+				return m.fetchMetadata()
 			}
+			m.metadata = nil
+			m.table.SetColumns(m.baseColumns)
+			return nil
 		case key.Matches(msg, types.KeyOpenEditor):
 			if v, ok := m.table.GetSelectedRow(); ok {
 				return m.editSecret(v.Value)
@@ -161,6 +249,10 @@ func (m *Model) Update(msg tea.Msg) tea.Cmd {
 			if v, ok := m.table.GetSelectedRow(); ok {
 				return m.deleteSecret(v.Value)
 			}
+		case key.Matches(msg, types.KeyToggleBookmark):
+			if v, ok := m.table.GetSelectedRow(); ok {
+				return m.toggleBookmark(v.Value)
+			}
 		}
 	}
 
@@ -177,6 +269,21 @@ func (m *Model) selectSecret(secret *types.SecretListRef) tea.Cmd {
 	return types.OpenPage(New(m.app, secret.Mount, secret.Path), false)
 }
 
+// toggleBookmark bookmarks the secret or folder, or removes the bookmark if it
+// already exists.
+func (m *Model) toggleBookmark(secret *types.SecretListRef) tea.Cmd {
+	added, err := config.ToggleBookmark(m.app.Client().Profile(), config.Bookmark{
+		Path: secret.FullPath(),
+	})
+	if err != nil {
+		return types.SendStatus("unable to update bookmarks: "+err.Error(), types.Error, 3*time.Second)
+	}
+	if added {
+		return types.SendStatus("bookmarked "+secret.FullPath(), types.Success, 2*time.Second)
+	}
+	return types.SendStatus("removed bookmark for "+secret.FullPath(), types.Info, 2*time.Second)
+}
+
 func (m *Model) editSecret(secret *types.SecretListRef) tea.Cmd {
 	if strings.HasSuffix(secret.Path, "/") {
 		return nil
@@ -184,6 +291,159 @@ func (m *Model) editSecret(secret *types.SecretListRef) tea.Cmd {
 	return types.OpenPage(kvviewsecret.New(m.app, secret.Mount, secret.Path, 0, true), false)
 }
 
+// getMetadata fetches the metadata of the selected secret, and once received,
+// acts on it based on the provided intent.
+func (m *Model) getMetadata(intent metadataIntent) tea.Cmd {
+	v, ok := m.table.GetSelectedRow()
+	if !ok || strings.HasSuffix(v.Value.Path, "/") {
+		return nil
+	}
+	m.metadataIntent = intent
+	return m.app.Client().GetKVv2Metadata(m.UUID(), v.Value.Mount, v.Value.Path)
+}
+
+func (m *Model) editMetadata(msg types.ClientGetKVv2MetadataMsg) tea.Cmd {
+	return types.OpenDialog(formdialog.New(m.app, formdialog.Config{
+		Title: "Edit metadata: " + msg.Mount.Path + msg.Path,
+		Fields: []*form.Field{
+			{
+				ID:          "max_versions",
+				Label:       "Max versions",
+				Placeholder: "0 (use mount default)",
+				Value:       strconv.Itoa(msg.Metadata.MaxVersions),
+				Validator:   form.ValidateInt(0),
+			},
+			{
+				ID:      "cas_required",
+				Label:   "Require CAS",
+				Value:   strconv.FormatBool(msg.Metadata.CASRequired),
+				Options: []string{"false", "true"},
+			},
+			{
+				ID:          "delete_version_after",
+				Label:       "Delete versions after",
+				Placeholder: "0s (never)",
+				Value:       msg.Metadata.DeleteVersionAfter.String(),
+				Validator:   form.ValidateDuration,
+			},
+		},
+		ConfirmFn: func(values map[string]string) tea.Cmd {
+			maxVersions, _ := strconv.Atoi(values["max_versions"])
+			casRequired := values["cas_required"] == "true"
+
+			var deleteAfter time.Duration
+			if values["delete_version_after"] != "" {
+				deleteAfter, _ = time.ParseDuration(values["delete_version_after"])
+			}
+
+			return m.app.Client().PutKVv2Metadata(m.UUID(), msg.Mount, msg.Path, &types.KVv2MetadataInput{
+				MaxVersions:        &maxVersions,
+				CASRequired:        &casRequired,
+				DeleteVersionAfter: &deleteAfter,
+			})
+		},
+	}))
+}
+
+func (m *Model) editCustomMetadata(msg types.ClientGetKVv2MetadataMsg) tea.Cmd {
+	current := msg.Metadata.CustomMetadata
+	if current == nil {
+		current = map[string]any{}
+	}
+
+	b, err := json.MarshalIndent(current, "", "    ")
+	if err != nil {
+		return types.PageErrors(err)
+	}
+
+	return types.OpenDialog(textarea.New(
+		m.app,
+		confirmable.Config[string]{
+			ConfirmText: "save",
+			Validator: func(value string) error {
+				_, err := parseCustomMetadata(value)
+				return err
+			},
+			ConfirmFn: func(value string) tea.Cmd {
+				data, _ := parseCustomMetadata(value)
+				return m.app.Client().PutKVv2Metadata(m.UUID(), msg.Mount, msg.Path, &types.KVv2MetadataInput{
+					CustomMetadata: data,
+				})
+			},
+		},
+		"Edit custom metadata: "+msg.Mount.Path+msg.Path,
+		string(b),
+	))
+}
+
+// parseCustomMetadata parses custom metadata as a JSON object of string keys
+// and values, which is what Vault requires.
+func parseCustomMetadata(value string) (map[string]any, error) {
+	var raw map[string]string
+	if err := json.Unmarshal([]byte(value), &raw); err != nil {
+		return nil, errors.New("must be a JSON object with string values")
+	}
+
+	data := make(map[string]any, len(raw))
+	for k, v := range raw {
+		data[k] = v
+	}
+	return data, nil
+}
+
+// fetchMetadata fetches the metadata of all secrets in the table, used for
+// custom metadata columns.
+func (m *Model) fetchMetadata() tea.Cmd {
+	var paths []string
+	for _, row := range m.table.GetAllRows() {
+		if !strings.HasSuffix(row.Value.Path, "/") {
+			paths = append(paths, row.Value.Path)
+		}
+	}
+	if len(paths) == 0 {
+		return nil
+	}
+	return m.app.Client().ListKVv2Metadata(m.UUID(), m.mount, paths...)
+}
+
+// updateMetadataColumns adds a column for each custom metadata key found across
+// all secrets in the table.
+func (m *Model) updateMetadataColumns() {
+	var keys []string
+	for _, md := range m.metadata {
+		if md == nil {
+			continue
+		}
+		for k := range md.CustomMetadata {
+			if !slices.Contains(keys, k) {
+				keys = append(keys, k)
+			}
+		}
+	}
+	slices.Sort(keys)
+
+	columns := slices.Clone(m.baseColumns)
+	for _, k := range keys {
+		columns = append(columns, &table.Column[*table.StaticRow[*types.SecretListRef]]{
+			ID:       table.ID("metadata:" + k),
+			Title:    k,
+			MaxWidth: 30,
+			AccessorFn: func(row *table.StaticRow[*types.SecretListRef]) string {
+				md, ok := m.metadata[row.Value.Path]
+				if !ok || md == nil {
+					return ""
+				}
+				if v, ok := md.CustomMetadata[k]; ok {
+					return fmt.Sprintf("%v", v)
+				}
+				return ""
+			},
+		})
+	}
+
+	m.table.SetColumns(columns)
+}
+
 func (m *Model) deleteSecret(secret *types.SecretListRef) tea.Cmd {
 	if strings.HasSuffix(secret.Path, "/") {
 		return nil
@@ -205,10 +465,11 @@ func (m *Model) deleteSecret(secret *types.SecretListRef) tea.Cmd {
 	}
 
 	return types.OpenDialog(confirm.New(m.app, confirm.Config{
-		Title:         fmt.Sprintf("Delete %s", secret.FullPath()),
-		Message:       "Are you sure you want to delete this secret? This cannot be undone.",
-		AllowsBlur:    true,
-		ConfirmStatus: types.Error,
+		Title:             fmt.Sprintf("Delete %s", secret.FullPath()),
+		Message:           "Are you sure you want to delete this secret? " + types.DeleteUndoHint(secret.Mount),
+		AllowsBlur:        true,
+		ConfirmStatus:     types.Error,
+		TypedConfirmation: path.Base(secret.Path),
 		ConfirmFn: func() tea.Cmd {
 			return tea.Sequence(confirmCmds...)
 		},
@@ -227,6 +488,20 @@ func (m *Model) GetTitle() string {
 	return m.mount.Path + m.path
 }
 
+// GetBreadcrumb implements [types.PageBreadcrumb], only showing the mount or
+// the last folder, as parent pages show the rest of the path.
+func (m *Model) GetBreadcrumb() string {
+	if m.path == "" {
+		return m.mount.Path
+	}
+	return path.Base(m.path) + "/"
+}
+
+// Location implements [types.PageLocator].
+func (m *Model) Location() config.PageLocation {
+	return config.PageLocation{Type: PageType, Mount: m.mount.Path, Path: m.path}
+}
+
 func (m *Model) TopMiddleBorder() string {
 	return styles.Pluralize(m.table.TotalFilteredRows(), "secret", "secrets")
 }
@@ -19,7 +19,7 @@ func NewMockAppState(client types.Client, initialPage types.Page) *AppState {
 		initialPage = &mockPage{} // Stub.
 	}
 	return &AppState{
-		page:   NewPageState(initialPage),
+		page:   NewPageState(client, initialPage),
 		dialog: NewDialogState(),
 		client: client,
 	}
@@ -5,7 +5,9 @@
 package state
 
 import (
+	"slices"
 	"sync/atomic"
+	"time"
 
 	"charm.land/bubbles/v2/key"
 	"charm.land/bubbles/v2/spinner"
@@ -27,6 +29,8 @@ const (
 var _ types.PageState = &pageState{} // Ensure state implements types.PageState.
 
 type pageState struct {
+	client types.Client
+
 	// Various temporary states.
 	windowHeight int
 	windowWidth  int
@@ -48,8 +52,9 @@ type pageState struct {
 	errorview *errorview.Model
 }
 
-func NewPageState(initial types.Page) types.PageState {
+func NewPageState(client types.Client, initial types.Page) types.PageState {
 	t := &pageState{
+		client:    client,
 		loader:    loader.New(),
 		errorview: errorview.New(),
 	}
@@ -140,6 +145,12 @@ func (s *pageState) Update(msg tea.Msg) tea.Cmd { //nolint:gocognit,funlen
 			active = true
 		}
 	case types.OpenPageMsg:
+		if msg.Root && !msg.Force {
+			if cmd := s.GuardClose(types.CmdMsg(types.OpenPageMsg{Page: msg.Page, Root: true, Force: true})); cmd != nil {
+				return cmd
+			}
+		}
+
 		s.loading.Store(false)
 		s.errored.Store(false)
 
@@ -164,11 +175,45 @@ func (s *pageState) Update(msg tea.Msg) tea.Cmd { //nolint:gocognit,funlen
 			}),
 			types.FocusChange(types.FocusPage),
 		)...)
+	case types.OpenPageStackMsg:
+		if !msg.Force {
+			if cmd := s.GuardClose(types.CmdMsg(types.OpenPageStackMsg{Pages: msg.Pages, Force: true})); cmd != nil {
+				return cmd
+			}
+		}
+
+		s.loading.Store(false)
+		s.errored.Store(false)
+
+		for page := range s.pages.IterValues() {
+			cmds = append(cmds, page.Close())
+		}
+		s.pages.Set(msg.Pages)
+
+		// Parent pages are initialized (and will refresh once they become visible
+		// again), but only the active page is focused.
+		for _, page := range msg.Pages {
+			cmds = append(cmds,
+				page.Init(),
+				page.Update(tea.WindowSizeMsg{
+					Height: s.windowHeight - PageVPadding,
+					Width:  s.windowWidth - PageHPadding,
+				}),
+			)
+		}
+
+		return tea.Batch(append(cmds, types.FocusChange(types.FocusPage))...)
 	case types.CloseActivePageMsg:
 		if s.pages.Len() <= 1 {
 			return nil
 		}
 
+		if guard, ok := s.pages.Peek().(types.PageCloseGuard); ok && !msg.Force {
+			if cmd := guard.GuardClose(types.ForceCloseActivePage()); cmd != nil {
+				return cmd
+			}
+		}
+
 		s.errored.Store(false)
 		s.loading.Store(false)
 
@@ -182,6 +227,39 @@ func (s *pageState) Update(msg tea.Msg) tea.Cmd { //nolint:gocognit,funlen
 			types.FocusChange(types.FocusPage),
 			s.pages.Peek().Update(types.PageVisibleMsg{}),
 		)
+	case types.JumpToPageMsg:
+		pages := s.pages.Get()
+		i := slices.IndexFunc(pages, func(p types.Page) bool {
+			return p.UUID() == msg.UUID
+		})
+		if i == -1 || i == len(pages)-1 {
+			return nil
+		}
+
+		if !msg.Force {
+			next := types.CmdMsg(types.JumpToPageMsg{UUID: msg.UUID, Force: true})
+			for _, page := range pages[i+1:] {
+				if guard, ok := page.(types.PageCloseGuard); ok {
+					if cmd := guard.GuardClose(next); cmd != nil {
+						return cmd
+					}
+				}
+			}
+		}
+
+		s.errored.Store(false)
+		s.loading.Store(false)
+
+		for range pages[i+1:] {
+			page, _ := s.pages.Pop()
+			cmds = append(cmds, page.Close())
+		}
+
+		return tea.Batch(append(
+			cmds,
+			types.FocusChange(types.FocusPage),
+			s.pages.Peek().Update(types.PageVisibleMsg{}),
+		)...)
 	case types.AppFocusChangedMsg:
 		if msg.ID == types.FocusPage {
 			s.focused.Store(true)
@@ -223,6 +301,17 @@ func (s *pageState) Update(msg tea.Msg) tea.Cmd { //nolint:gocognit,funlen
 				return types.RefreshData(s.Get().UUID())
 			case key.Matches(msg, types.KeyQuit):
 				return types.AppQuit()
+			case key.Matches(msg, types.KeyJumpToAncestor):
+				// The n-th key jumps to the n-th page in the stack (root first).
+				i := slices.Index(types.KeyJumpToAncestor.Keys(), msg.String())
+				if pages := s.pages.Get(); i >= 0 && i < len(pages)-1 {
+					return types.JumpToPage(pages[i].UUID())
+				}
+				return nil
+			}
+
+			if s.client.ReadOnly() && s.isMutatingKey(msg) {
+				return types.SendStatus("disabled in read-only mode", types.Warning, 2*time.Second)
 			}
 		}
 		active = true
@@ -255,6 +344,17 @@ func (s *pageState) Update(msg tea.Msg) tea.Cmd { //nolint:gocognit,funlen
 	return tea.Batch(cmds...)
 }
 
+func (s *pageState) GuardClose(next tea.Cmd) tea.Cmd {
+	for page := range s.pages.IterValues() {
+		if guard, ok := page.(types.PageCloseGuard); ok {
+			if cmd := guard.GuardClose(next); cmd != nil {
+				return cmd
+			}
+		}
+	}
+	return nil
+}
+
 func (s *pageState) View() string {
 	p := s.pages.Peek()
 
@@ -316,11 +416,30 @@ func (s *pageState) IsFocused(uuid string) bool {
 	return s.focused.Load() && s.pages.Peek().UUID() == uuid
 }
 
+// isMutatingKey returns true if the key matches any of the key bindings of the
+// active page which modify the Vault server (see [types.PageMutator]).
+func (s *pageState) isMutatingKey(msg tea.KeyMsg) bool {
+	return key.Matches(msg, types.MutatingKeys(s.Get())...)
+}
+
+// withoutMutating returns the provided key bindings, without any which share
+// keys with the mutating key bindings of the active page.
+func (s *pageState) withoutMutating(keys []key.Binding) []key.Binding {
+	mutating := types.MutatingKeys(s.Get())
+	return slices.DeleteFunc(slices.Clone(keys), func(b key.Binding) bool {
+		return types.KeyBindingContains(mutating, b)
+	})
+}
+
 func (s *pageState) ShortHelp() []key.Binding {
 	var prepended []key.Binding
 	page := s.Get()
 	keys := page.ShortHelp()
 
+	if s.client.ReadOnly() {
+		keys = s.withoutMutating(keys)
+	}
+
 	if !types.KeyBindingContains(keys, types.KeyCommander) {
 		prepended = append(prepended, types.KeyCommander)
 	}
@@ -341,6 +460,16 @@ func (s *pageState) FullHelp() [][]key.Binding {
 	page := s.Get()
 	keys := page.FullHelp()
 
+	if s.client.ReadOnly() {
+		filtered := make([][]key.Binding, 0, len(keys))
+		for _, group := range keys {
+			if group = s.withoutMutating(group); len(group) > 0 {
+				filtered = append(filtered, group)
+			}
+		}
+		keys = filtered
+	}
+
 	if s.HasParent() && !types.KeyBindingContainsFull(keys, types.KeyCancel) {
 		prepended = append(prepended, types.KeyCancel)
 	}
@@ -361,6 +490,18 @@ func (s *pageState) FullHelp() [][]key.Binding {
 		appended = append(appended, types.KeyHelp)
 	}
 
+	if !types.KeyBindingContainsFull(keys, types.KeyThemePicker) {
+		appended = append(appended, types.KeyThemePicker)
+	}
+
+	if s.HasParent() && !types.KeyBindingContainsFull(keys, types.KeyJumpToAncestor) {
+		appended = append(appended, types.KeyJumpToAncestor)
+	}
+
+	if !s.client.ReadOnly() && !types.KeyBindingContainsFull(keys, types.KeyUndo) {
+		appended = append(appended, types.KeyUndo)
+	}
+
 	if !types.KeyBindingContainsFull(keys, types.KeyQuit) {
 		appended = append(appended, types.KeyQuit)
 	}
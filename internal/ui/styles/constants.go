@@ -20,11 +20,13 @@ var advancedColorProfiles = []colorprofile.Profile{
 const (
 	IconSeparator            = "•"
 	IconEllipsis             = "…"
+	IconBreadcrumbSeparator  = "›"
 	IconOpenDottedCircle     = "◌"
 	IconSemiFilledCircle     = "◎"
 	IconClosedCircle         = "◉"
 	IconFilledCircle         = "⏺"
 	IconRefresh              = "⟳"
+	IconUndo                 = "↶"
 	IconTitleGradientDivider = "⫻"
 	IconScrollbar            = "┃"
 )
@@ -39,6 +41,7 @@ var (
 	IconFolder       = iconFallback("📁", "🖿")
 	IconSecret       = iconFallback("🔑", "🔒")
 	IconProhibited   = iconFallback("⛔", "🛇")
+	IconClipboard    = iconFallback("📋", "⎘")
 )
 
 func iconFallback(icon, fallback string) func() string {
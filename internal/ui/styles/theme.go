@@ -17,36 +17,21 @@ import (
 	"github.com/charmbracelet/colorprofile"
 	"github.com/lrstanley/bubbletint/chromatint/v2"
 	tint "github.com/lrstanley/bubbletint/v2"
+	"github.com/lrstanley/vex/internal/config"
 	"github.com/lrstanley/vex/internal/types"
 )
 
 //go:generate go run github.com/masaushi/accessory@v0.5.0 -type ThemeConfig -receiver tc -lock mu -output theme.gen.go
 
 var Theme = (&ThemeConfig{
-	registry: tint.NewRegistry(
-		tint.TintCga,
-		tint.TintPencilDark,
-		tint.TintDjango,
-		tint.TintAfterglow,
-		tint.TintSpacedust,
-		tint.TintLabFox,
-		tint.TintTokyoNightLight,
-		tint.TintOneHalfLight,
-		tint.TintGrape,
-		tint.TintCyberPunk2077,
-		tint.TintCyberdyne,
-		tint.TintWryan,
-		tint.TintUbuntu,
-		tint.TintTomorrowNightBurns,
-		tint.TintSolarizedDarkPatched,
-		// tint.DefaultTints()...,
-	),
-	profile: colorprofile.ANSI,
-	chroma:  chromastyles.Fallback,
+	registry: tint.NewRegistry(tint.TintCga, tint.DefaultTints()...),
+	profile:  colorprofile.ANSI,
+	chroma:   chromastyles.Fallback,
 }).set()
 
 type ThemeConfig struct {
 	registry *tint.Registry
+	custom   map[string]*CustomTint
 	mu       sync.RWMutex
 
 	profile                colorprofile.Profile
@@ -284,6 +269,8 @@ func (tc *ThemeConfig) set() *ThemeConfig {
 	tc.activeButtonFg = white
 	tc.activeButtonBg = tc.AdaptAuto(t.Cyan, -0.3)
 
+	tc.applyOverrides(t)
+
 	borderGradientCache.Clear()
 	return tc
 }
@@ -335,10 +322,7 @@ func (tc *ThemeConfig) NextTint() tea.Cmd {
 	}
 	tc.registry.NextTint()
 	tc.set()
-	return tea.Batch(
-		types.SendStatus("Switched to "+tc.registry.Current().ID, types.Success, 1*time.Second),
-		tc.updateThemeCmd(),
-	)
+	return tc.selectedCmd()
 }
 
 func (tc *ThemeConfig) PreviousTint() tea.Cmd {
@@ -347,12 +331,62 @@ func (tc *ThemeConfig) PreviousTint() tea.Cmd {
 	}
 	tc.registry.PreviousTint()
 	tc.set()
+	return tc.selectedCmd()
+}
+
+// PreviewTintID temporarily switches to the tint with the provided ID, without
+// persisting it (e.g. while browsing tints).
+func (tc *ThemeConfig) PreviewTintID(id string) tea.Cmd {
+	if !tc.SetTintID(id) {
+		return nil
+	}
+	return tc.updateThemeCmd()
+}
+
+// SelectTintID switches to the tint with the provided ID, and persists it so it's
+// used for future sessions.
+func (tc *ThemeConfig) SelectTintID(id string) tea.Cmd {
+	if !tc.SetTintID(id) {
+		return types.SendStatus("unknown tint: "+id, types.Error, 2*time.Second)
+	}
+	return tc.selectedCmd()
+}
+
+// selectedCmd persists the current tint, and notifies the user.
+func (tc *ThemeConfig) selectedCmd() tea.Cmd {
+	id := tc.registry.Current().ID
+
+	err := config.UpdateState(func(s *config.State) {
+		s.Tint = id
+	})
+	if err != nil {
+		return tea.Batch(
+			types.SendStatus("failed to persist theme: "+err.Error(), types.Error, 5*time.Second),
+			tc.updateThemeCmd(),
+		)
+	}
+
 	return tea.Batch(
-		types.SendStatus("Switched to "+tc.registry.Current().ID, types.Success, 1*time.Second),
+		types.SendStatus("Switched to "+id, types.Success, 1*time.Second),
 		tc.updateThemeCmd(),
 	)
 }
 
+// SetTintID sets the active tint by ID, returning false if no tint with the
+// provided ID exists.
+func (tc *ThemeConfig) SetTintID(id string) bool {
+	if !tc.registry.SetTintID(id) {
+		return false
+	}
+	tc.set()
+	return true
+}
+
+// TintID returns the ID of the active tint.
+func (tc *ThemeConfig) TintID() string {
+	return tc.registry.Current().ID
+}
+
 func (tc *ThemeConfig) updateThemeCmd() tea.Cmd {
 	return types.CmdMsg(ThemeUpdatedMsg{})
 }
@@ -0,0 +1,262 @@
+// Copyright (c) Liam Stanley <liam@liam.sh>. All rights reserved. Use of
+// this source code is governed by the MIT license that can be found in
+// the LICENSE file.
+
+package styles
+
+import (
+	"errors"
+	"fmt"
+	"image/color"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	chromastyles "github.com/alecthomas/chroma/v2/styles"
+	"github.com/goccy/go-yaml"
+	tint "github.com/lrstanley/bubbletint/v2"
+	"github.com/lucasb-eyer/go-colorful"
+)
+
+// CustomTintsDir is the name of the directory, within the config path, which
+// contains custom tint definitions.
+const CustomTintsDir = "tints"
+
+// CustomTint is a user-defined tint, loaded from [CustomTintsDir]. All palette
+// colors are required, and individual theme color slots (see [ThemeSlots]) can
+// be overridden.
+//
+// Example:
+//
+//	id: my_tint
+//	display_name: My Tint
+//	fg: "#c0caf5"
+//	bg: "#1a1b26"
+//	black: "#15161e"
+//	# ... remaining palette colors ...
+//	chroma: tokyonight-night
+//	overrides:
+//	  success_bg: "#2d4f3c"
+type CustomTint struct {
+	tint.Tint `yaml:",inline"`
+
+	// Chroma is the name of the chroma style used for syntax highlighting. Defaults
+	// to a style generated from the palette.
+	Chroma string `yaml:"chroma,omitempty"`
+
+	// Overrides overrides individual theme color slots, by name (e.g. "success_bg").
+	Overrides map[string]*tint.Color `yaml:"overrides,omitempty"`
+}
+
+// slots returns pointers to all configurable colors of the theme, by name. Must
+// be called with the lock held.
+func (tc *ThemeConfig) slots() map[string]*color.Color {
+	return map[string]*color.Color{
+		"app_cursor":                     &tc.appCursor,
+		"app_bright_fg":                  &tc.appBrightFg,
+		"app_fg":                         &tc.appFg,
+		"app_bg":                         &tc.appBg,
+		"success_fg":                     &tc.successFg,
+		"success_bg":                     &tc.successBg,
+		"warning_fg":                     &tc.warningFg,
+		"warning_bg":                     &tc.warningBg,
+		"error_fg":                       &tc.errorFg,
+		"error_bg":                       &tc.errorBg,
+		"info_fg":                        &tc.infoFg,
+		"info_bg":                        &tc.infoBg,
+		"scrollbar_thumb_fg":             &tc.scrollbarThumbFg,
+		"scrollbar_track_fg":             &tc.scrollbarTrackFg,
+		"bar_bg":                         &tc.barBg,
+		"bar_fg":                         &tc.barFg,
+		"status_bar_filter_text_fg":      &tc.statusBarFilterTextFg,
+		"status_bar_filter_bg":           &tc.statusBarFilterBg,
+		"status_bar_filter_fg":           &tc.statusBarFilterFg,
+		"status_bar_addr_bg":             &tc.statusBarAddrBg,
+		"status_bar_addr_fg":             &tc.statusBarAddrFg,
+		"status_bar_user_fg":             &tc.statusBarUserFg,
+		"status_bar_user_bg":             &tc.statusBarUserBg,
+		"status_bar_token_ttl_fg":        &tc.statusBarTokenTTLFg,
+		"status_bar_token_ttl_bg":        &tc.statusBarTokenTTLBg,
+		"status_bar_logo_bg":             &tc.statusBarLogoBg,
+		"status_bar_logo_fg":             &tc.statusBarLogoFg,
+		"short_help_key_fg":              &tc.shortHelpKeyFg,
+		"dialog_fg":                      &tc.dialogFg,
+		"dialog_border_fg":               &tc.dialogBorderFg,
+		"dialog_border_gradient_from_fg": &tc.dialogBorderGradientFromFg,
+		"dialog_border_gradient_to_fg":   &tc.dialogBorderGradientToFg,
+		"dialog_backdrop_fg":             &tc.dialogBackdropFg,
+		"dialog_backdrop_bg":             &tc.dialogBackdropBg,
+		"title_fg":                       &tc.titleFg,
+		"title_from_fg":                  &tc.titleFromFg,
+		"title_to_fg":                    &tc.titleToFg,
+		"page_border_fg":                 &tc.pageBorderFg,
+		"page_border_filter_fg":          &tc.pageBorderFilterFg,
+		"list_item_fg":                   &tc.listItemFg,
+		"list_item_selected_fg":          &tc.listItemSelectedFg,
+		"inactive_button_fg":             &tc.inactiveButtonFg,
+		"inactive_button_bg":             &tc.inactiveButtonBg,
+		"active_button_fg":               &tc.activeButtonFg,
+		"active_button_bg":               &tc.activeButtonBg,
+	}
+}
+
+// ThemeSlots returns the sorted names of all theme color slots which can be
+// overridden by custom tints.
+func ThemeSlots() []string {
+	return slices.Sorted(maps.Keys((&ThemeConfig{}).slots()))
+}
+
+// applyOverrides applies the chroma style and color overrides of the current
+// tint, if it's a custom tint. Must be called with the lock held.
+func (tc *ThemeConfig) applyOverrides(t *tint.Tint) {
+	ct, ok := tc.custom[t.ID]
+	if !ok {
+		return
+	}
+
+	if ct.Chroma != "" {
+		tc.chroma = chromastyles.Get(ct.Chroma)
+	}
+
+	slots := tc.slots()
+	for name, c := range ct.Overrides {
+		if slot, ok := slots[name]; ok {
+			*slot = c
+		}
+	}
+}
+
+// validate validates the custom tint, filling in any defaults.
+func (ct *CustomTint) validate() error {
+	if ct.ID == "" {
+		return errors.New("id: required")
+	}
+	if ct.DisplayName == "" {
+		ct.DisplayName = ct.ID
+	}
+
+	var errs []error
+
+	palette := map[string]*tint.Color{
+		"fg": ct.Fg, "bg": ct.Bg,
+		"black": ct.Black, "red": ct.Red, "green": ct.Green, "yellow": ct.Yellow,
+		"blue": ct.Blue, "purple": ct.Purple, "cyan": ct.Cyan, "white": ct.White,
+		"bright_black": ct.BrightBlack, "bright_red": ct.BrightRed,
+		"bright_green": ct.BrightGreen, "bright_yellow": ct.BrightYellow,
+		"bright_blue": ct.BrightBlue, "bright_purple": ct.BrightPurple,
+		"bright_cyan": ct.BrightCyan, "bright_white": ct.BrightWhite,
+	}
+	for _, name := range slices.Sorted(maps.Keys(palette)) {
+		if palette[name] == nil {
+			errs = append(errs, fmt.Errorf("%s: required", name))
+		}
+	}
+
+	slots := (&ThemeConfig{}).slots()
+	for _, name := range slices.Sorted(maps.Keys(ct.Overrides)) {
+		if _, ok := slots[name]; !ok {
+			errs = append(errs, fmt.Errorf("overrides.%s: unknown color slot", name))
+		} else if ct.Overrides[name] == nil {
+			errs = append(errs, fmt.Errorf("overrides.%s: color required", name))
+		}
+	}
+
+	if ct.Chroma != "" {
+		if _, ok := chromastyles.Registry[ct.Chroma]; !ok {
+			errs = append(errs, fmt.Errorf("chroma: unknown style %q", ct.Chroma))
+		}
+	}
+
+	if err := errors.Join(errs...); err != nil {
+		return err
+	}
+
+	// Dark isn't commonly known by users, so infer it from the background.
+	if !ct.Dark {
+		if bg, ok := colorful.MakeColor(ct.Bg); ok {
+			_, _, l := bg.Hsl()
+			ct.Dark = l < 0.5
+		}
+	}
+	return nil
+}
+
+// LoadCustomTints loads all custom tint definitions (yaml or json) from the
+// provided directory. A missing directory is not an error. Invalid definitions
+// are skipped, and returned as errors.
+func LoadCustomTints(dir string) (tints []*CustomTint, err error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("read custom tints: %w", err)
+	}
+
+	var errs []error
+	for _, entry := range entries {
+		ext := filepath.Ext(entry.Name())
+		if entry.IsDir() || !slices.Contains([]string{".yaml", ".yml", ".json"}, ext) {
+			continue
+		}
+
+		path := filepath.Join(dir, entry.Name())
+
+		data, rerr := os.ReadFile(path)
+		if rerr != nil {
+			errs = append(errs, fmt.Errorf("read %s: %w", path, rerr))
+			continue
+		}
+
+		ct := &CustomTint{}
+		rerr = yaml.UnmarshalWithOptions(data, ct, yaml.DisallowUnknownField(), yaml.UseJSONUnmarshaler())
+		if rerr != nil {
+			errs = append(errs, fmt.Errorf("parse %s: %s", path, yaml.FormatError(rerr, false, true)))
+			continue
+		}
+
+		if ct.ID == "" {
+			ct.ID = strings.TrimSuffix(entry.Name(), ext)
+		}
+
+		if rerr = ct.validate(); rerr != nil {
+			errs = append(errs, fmt.Errorf("validate %s:\n%w", path, rerr))
+			continue
+		}
+
+		tints = append(tints, ct)
+	}
+
+	return tints, errors.Join(errs...)
+}
+
+// RegisterCustomTints registers the provided custom tints, replacing any existing
+// tints with the same ID.
+func (tc *ThemeConfig) RegisterCustomTints(tints ...*CustomTint) {
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
+
+	if tc.custom == nil {
+		tc.custom = make(map[string]*CustomTint, len(tints))
+	}
+
+	for _, ct := range tints {
+		tc.custom[ct.ID] = ct
+		tc.registry.Register(&ct.Tint)
+	}
+}
+
+// IsCustomTint returns true if the tint with the provided ID is a custom tint.
+func (tc *ThemeConfig) IsCustomTint(id string) bool {
+	tc.mu.RLock()
+	defer tc.mu.RUnlock()
+	_, ok := tc.custom[id]
+	return ok
+}
+
+// Tints returns all registered tints, sorted by ID.
+func (tc *ThemeConfig) Tints() []*tint.Tint {
+	return tc.registry.Tints()
+}
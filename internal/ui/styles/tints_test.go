@@ -0,0 +1,84 @@
+// Copyright (c) Liam Stanley <liam@liam.sh>. All rights reserved. Use of
+// this source code is governed by the MIT license that can be found in
+// the LICENSE file.
+
+package styles
+
+import (
+	"strings"
+	"testing"
+
+	tint "github.com/lrstanley/bubbletint/v2"
+)
+
+func testCustomTint() *CustomTint {
+	base := *tint.TintCga
+	base.ID = "custom"
+	base.Dark = false
+	return &CustomTint{Tint: base}
+}
+
+func TestCustomTintValidate(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		modify  func(ct *CustomTint)
+		wantErr string
+	}{
+		{name: "valid", modify: func(_ *CustomTint) {}},
+		{name: "missing-id", modify: func(ct *CustomTint) { ct.ID = "" }, wantErr: "id: required"},
+		{name: "missing-color", modify: func(ct *CustomTint) { ct.BrightRed = nil }, wantErr: "bright_red: required"},
+		{
+			name:    "unknown-slot",
+			modify:  func(ct *CustomTint) { ct.Overrides = map[string]*tint.Color{"nope": {}} },
+			wantErr: "overrides.nope: unknown color slot",
+		},
+		{name: "known-slot", modify: func(ct *CustomTint) { ct.Overrides = map[string]*tint.Color{"success_bg": {}} }},
+		{name: "unknown-chroma", modify: func(ct *CustomTint) { ct.Chroma = "does-not-exist" }, wantErr: "chroma: unknown style"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ct := testCustomTint()
+			tt.modify(ct)
+
+			err := ct.validate()
+			switch {
+			case tt.wantErr == "" && err != nil:
+				t.Fatalf("unexpected error: %v", err)
+			case tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)):
+				t.Fatalf("expected error containing %q, got: %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestCustomTintInferDark(t *testing.T) {
+	t.Parallel()
+
+	ct := testCustomTint()
+	ct.Bg = &tint.Color{R: 0x10, G: 0x10, B: 0x10, A: 0xff}
+	if err := ct.validate(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !ct.Dark {
+		t.Fatal("expected tint with dark background to be inferred as dark")
+	}
+}
+
+func TestThemeSlots(t *testing.T) {
+	t.Parallel()
+
+	slots := (&ThemeConfig{}).slots()
+	for name, slot := range slots {
+		if slot == nil {
+			t.Fatalf("slot %q has no backing field", name)
+		}
+	}
+	if len(ThemeSlots()) != len(slots) {
+		t.Fatalf("expected %d slot names, got %d", len(slots), len(ThemeSlots()))
+	}
+}
@@ -91,3 +91,37 @@ func Pluralize(count int, singular, plural string) string {
 	}
 	return fmt.Sprintf("%d %s", count, plural)
 }
+
+// FormatBytes formats a size in bytes, using binary units (e.g. "1.5 MiB").
+func FormatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for v := n / unit; v >= unit; v /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
+
+// sparkBlocks are the characters used for sparklines, from lowest to highest.
+var sparkBlocks = []rune("▁▂▃▄▅▆▇█")
+
+// Sparkline renders samples as a sparkline, one character per sample, scaled to
+// peak. Negative samples (e.g. failed measurements) are rendered as gaps.
+func Sparkline(samples []float64, peak float64) string {
+	var b strings.Builder
+	for _, v := range samples {
+		switch {
+		case v < 0:
+			b.WriteRune('·')
+		case peak <= 0:
+			b.WriteRune(sparkBlocks[0])
+		default:
+			b.WriteRune(sparkBlocks[min(len(sparkBlocks)-1, int(v/peak*float64(len(sparkBlocks)-1)))])
+		}
+	}
+	return b.String()
+}
@@ -5,9 +5,12 @@
 package ui
 
 import (
+	"errors"
 	"fmt"
 	"image"
 	"log/slog"
+	"path/filepath"
+	"slices"
 	"time"
 
 	"charm.land/bubbles/v2/key"
@@ -19,15 +22,29 @@ import (
 	"github.com/lrstanley/vex/internal/types"
 	"github.com/lrstanley/vex/internal/ui/components/statusbar"
 	"github.com/lrstanley/vex/internal/ui/components/titlebar"
+	"github.com/lrstanley/vex/internal/ui/dialogs/alert"
 	"github.com/lrstanley/vex/internal/ui/dialogs/commander"
+	"github.com/lrstanley/vex/internal/ui/dialogs/confirm"
 	"github.com/lrstanley/vex/internal/ui/dialogs/help"
+	"github.com/lrstanley/vex/internal/ui/dialogs/themepicker"
+	"github.com/lrstanley/vex/internal/ui/navigator"
 	"github.com/lrstanley/vex/internal/ui/pages/aclpolicies"
+	"github.com/lrstanley/vex/internal/ui/pages/appconfig"
+	"github.com/lrstanley/vex/internal/ui/pages/auditdevices"
+	"github.com/lrstanley/vex/internal/ui/pages/auditlog"
+	"github.com/lrstanley/vex/internal/ui/pages/bookmarks"
+	"github.com/lrstanley/vex/internal/ui/pages/cluster"
 	"github.com/lrstanley/vex/internal/ui/pages/configstate"
+	"github.com/lrstanley/vex/internal/ui/pages/history"
+	"github.com/lrstanley/vex/internal/ui/pages/metrics"
 	"github.com/lrstanley/vex/internal/ui/pages/mounts"
+	"github.com/lrstanley/vex/internal/ui/pages/raftautopilot"
 	"github.com/lrstanley/vex/internal/ui/pages/raftconfig"
 	"github.com/lrstanley/vex/internal/ui/pages/recursivesecrets"
+	"github.com/lrstanley/vex/internal/ui/pages/sealstatus"
 	"github.com/lrstanley/vex/internal/ui/state"
 	"github.com/lrstanley/vex/internal/ui/styles"
+	"github.com/lrstanley/x/charm/formatter"
 )
 
 // Absolute minimum window size. If below this size, we display a message.
@@ -52,6 +69,20 @@ func pageInitializer(app types.AppState) []commander.PageRef {
 				return recursivesecrets.New(app, nil)
 			},
 		},
+		{
+			Description: "View bookmarked paths",
+			Commands:    bookmarks.Commands,
+			New: func() types.Page {
+				return bookmarks.New(app)
+			},
+		},
+		{
+			Description: "View history of changes made through vex",
+			Commands:    history.Commands,
+			New: func() types.Page {
+				return history.New(app)
+			},
+		},
 		{
 			Description: "View ACL policies",
 			Commands:    aclpolicies.Commands,
@@ -67,15 +98,76 @@ func pageInitializer(app types.AppState) []commander.PageRef {
 			},
 		},
 		{
-			Description: "View raft configuration",
+			Description: "View the health, version and latency of each node",
+			Commands:    cluster.Commands,
+			New: func() types.Page {
+				return cluster.New(app)
+			},
+		},
+		{
+			Description: "View raft peers, join a cluster or save and restore snapshots",
 			Commands:    raftconfig.Commands,
 			New: func() types.Page {
 				return raftconfig.New(app)
 			},
 		},
+		{
+			Description: "View raft autopilot state and server health",
+			Commands:    raftautopilot.Commands,
+			New: func() types.Page {
+				return raftautopilot.New(app)
+			},
+		},
+		{
+			Description: "View seal status, unseal, seal or step down",
+			Commands:    sealstatus.Commands,
+			New: func() types.Page {
+				return sealstatus.New(app)
+			},
+		},
+		{
+			Description: "View key server metrics, or browse all raw metrics",
+			Commands:    metrics.Commands,
+			New: func() types.Page {
+				return metrics.New(app)
+			},
+		},
+		{
+			Description: "View audit devices, enable or disable them, or hash values",
+			Commands:    auditdevices.Commands,
+			New: func() types.Page {
+				return auditdevices.New(app)
+			},
+		},
+		{
+			Description: "View and analyze a local file audit log",
+			Commands:    auditlog.Commands,
+			New: func() types.Page {
+				return auditlog.New(app, "")
+			},
+		},
+		{
+			Description: "View effective vex settings",
+			Commands:    appconfig.Commands,
+			New: func() types.Page {
+				return appconfig.New(app)
+			},
+		},
 	}
 }
 
+// defaultPage returns the page configured to be opened on startup, falling back
+// to the mounts page if it's unknown.
+func defaultPage(app types.AppState, pages []commander.PageRef) (types.Page, error) {
+	name := config.Get().DefaultPage
+	for _, ref := range pages {
+		if slices.Contains(ref.Commands, name) {
+			return ref.New(), nil
+		}
+	}
+	return mounts.New(app), fmt.Errorf("default_page: unknown page %q", name)
+}
+
 var lastMouseEvent time.Time
 
 func DownsampleMouseEvents(_ tea.Model, msg tea.Msg) tea.Msg {
@@ -109,25 +201,66 @@ type Model struct { //nolint:recvcheck
 	focused       types.FocusID
 	previousFocus types.FocusID
 	cmdConfig     commander.Config
+	startupErrors []error
+	explicitPage  bool       // Whether the initial page was provided, see [NewWithPage].
+	undo          types.Undo // Last offered undo, see [types.UndoMsg].
 
 	// Sub-components.
 	titlebar  types.Component
 	statusbar types.Component
 }
 
-func New(client types.Client) *Model {
+// New creates the root model of the TUI. Any startupErrors (e.g. invalid
+// settings) are shown to the user once the TUI has started.
+func New(client types.Client, startupErrors ...error) *Model {
+	return NewWithPage(client, nil, startupErrors...)
+}
+
+// NewWithPage is like [New], but starts the TUI with the page returned by
+// initial, rather than the configured default page, in which case the previous
+// session isn't offered to be restored either. If initial is nil, it's the same
+// as [New].
+func NewWithPage(client types.Client, initial func(app types.AppState) types.Page, startupErrors ...error) *Model {
 	app := &state.AppState{}
 	app.SetClient(client)
 	app.SetDialog(state.NewDialogState())
-	app.SetPage(state.NewPageState(mounts.New(app)))
+
+	pages := pageInitializer(app)
+
+	var page types.Page
+	if initial != nil {
+		page = initial(app)
+	} else {
+		var err error
+		page, err = defaultPage(app, pages)
+		if err != nil {
+			startupErrors = append(startupErrors, err)
+		}
+	}
+	app.SetPage(state.NewPageState(client, page))
+
+	tints, err := styles.LoadCustomTints(filepath.Join(config.GetConfigPath(), styles.CustomTintsDir))
+	if err != nil {
+		startupErrors = append(startupErrors, err)
+	}
+	styles.Theme.RegisterCustomTints(tints...)
+
+	// The last tint selected by the user takes precedence over the configured tint.
+	if tint := config.GetState().Tint; tint == "" || !styles.Theme.SetTintID(tint) {
+		if tint = config.Get().Theme.Tint; tint != "" && !styles.Theme.SetTintID(tint) {
+			startupErrors = append(startupErrors, fmt.Errorf("theme.tint: unknown tint %q", tint))
+		}
+	}
 
 	return &Model{
-		app:       app,
-		debouncer: debouncer.New(),
-		canvas:    lipgloss.NewCanvas(0, 0),
+		app:           app,
+		debouncer:     debouncer.New(),
+		canvas:        lipgloss.NewCanvas(0, 0),
+		startupErrors: startupErrors,
+		explicitPage:  initial != nil,
 		cmdConfig: commander.Config{
 			App:   app,
-			Pages: pageInitializer(app),
+			Pages: pages,
 		},
 		focused:   types.FocusPage,
 		titlebar:  titlebar.New(app),
@@ -147,9 +280,139 @@ func (m Model) Init() tea.Cmd {
 			m.statusbar.Init(),
 		),
 		types.FocusChange(types.FocusPage),
+		m.startupErrorsCmd(),
+		m.restoreSessionCmd(),
 	)
 }
 
+// commandPage returns a new page for the provided command, if one exists.
+func (m Model) commandPage(command string) (types.Page, bool) {
+	for _, ref := range m.cmdConfig.Pages {
+		if slices.Contains(ref.Commands, command) {
+			return ref.New(), true
+		}
+	}
+	return nil, false
+}
+
+// restoreSessionCmd offers to restore the page stack of the last session against
+// the same cluster, if it differs from the page stack we started with.
+func (m Model) restoreSessionCmd() tea.Cmd {
+	if m.explicitPage {
+		return nil
+	}
+
+	session, ok := config.GetSession(m.app.Client().Profile())
+	if !ok || slices.Equal(session.Pages, navigator.Locate(m.app.Page().All(), m.commandPage)) {
+		return nil
+	}
+
+	last := session.Pages[len(session.Pages)-1]
+	where := last.Type
+	if last.Mount != "" {
+		where = last.Mount + last.Path
+	}
+
+	return types.OpenDialog(confirm.New(m.app, confirm.Config{
+		Title:       "Restore session",
+		Message:     fmt.Sprintf("Restore your previous session at %s (%s)?", where, formatter.TimeRelative(session.SavedAt, true)),
+		AllowsBlur:  true,
+		ConfirmText: "restore",
+		CancelText:  "start fresh",
+		ConfirmFn: func() tea.Cmd {
+			return tea.Sequence(
+				types.CloseActiveDialog(),
+				navigator.Restore(m.app, session.Pages, m.commandPage),
+			)
+		},
+		CancelFn: types.CloseActiveDialog,
+	}))
+}
+
+// saveSession persists the location of all pages in the page stack (never their
+// data), so it can be restored in the next session. Sessions which started with
+// an explicit page (e.g. viewing an audit log) aren't saved, same as they aren't
+// restored.
+func (m Model) saveSession() {
+	if m.explicitPage {
+		return
+	}
+
+	locations := navigator.Locate(m.app.Page().All(), m.commandPage)
+	if err := config.SaveSession(m.app.Client().Profile(), locations); err != nil {
+		slog.Warn("failed to save session", "error", err) //nolint:sloglint
+	}
+}
+
+// hasInputFocus returns true if the focused dialog or page is capturing input
+// (e.g. a text input), in which case global key bindings should be ignored.
+func (m Model) hasInputFocus() bool {
+	switch m.focused {
+	case types.FocusDialog:
+		v := m.app.Dialog().Get(false)
+		return v != nil && v.HasInputFocus()
+	case types.FocusPage:
+		return m.app.Page().Get().HasInputFocus()
+	}
+	return false
+}
+
+// offerUndo replaces the last offered undo, showing a countdown until it expires,
+// or letting the user know if the mutation can't be undone.
+func (m *Model) offerUndo(undo types.Undo) tea.Cmd {
+	cmds := []tea.Cmd{types.CmdMsg(types.StatusMsg{Msg: types.ClearUndoTimerMsg{ID: m.undo.ID}})}
+	m.undo = undo
+
+	if !undo.Possible() {
+		return tea.Batch(append(
+			cmds,
+			types.SendStatus(fmt.Sprintf("%s can't be undone: %s", undo.Description, undo.Reason), types.Warning, 3*time.Second),
+		)...)
+	}
+
+	return tea.Batch(append(cmds, types.CmdMsg(types.StatusMsg{Msg: types.UndoTimerMsg{
+		ID:          undo.ID,
+		Description: undo.Description,
+		Expires:     undo.Expires,
+	}}))...)
+}
+
+// runUndo reverts the last offered undo (if it hasn't expired), refreshing the
+// active page once done.
+func (m *Model) runUndo() tea.Cmd {
+	undo := m.undo
+	if !undo.Possible() || undo.Expired() {
+		return types.SendStatus("nothing to undo", types.Info, 2*time.Second)
+	}
+
+	m.undo = types.Undo{}
+	return tea.Batch(
+		types.CmdMsg(types.StatusMsg{Msg: types.ClearUndoTimerMsg{ID: undo.ID}}),
+		tea.Sequence(undo.Cmd, types.RefreshData(m.app.Page().Get().UUID())),
+	)
+}
+
+// startupErrorsCmd opens an alert with any errors which occurred on startup.
+func (m Model) startupErrorsCmd() tea.Cmd {
+	if len(m.startupErrors) == 0 {
+		return nil
+	}
+
+	for _, err := range m.startupErrors {
+		slog.Error("startup error", "error", err) //nolint:sloglint
+	}
+
+	hint := "Defaults are being used for invalid settings."
+	if slices.ContainsFunc(m.startupErrors, func(err error) bool { return errors.Is(err, config.ErrInvalidSettings) }) {
+		hint = "The settings file couldn't be used, so the defaults are being used in read-only mode. Fix the settings file and restart to make changes."
+	}
+
+	return types.OpenDialog(alert.New(m.app, alert.Config{
+		Title:   "Configuration errors",
+		Message: errors.Join(m.startupErrors...).Error() + "\n\n" + hint + " See :config for the effective settings.",
+	}))
+}
+
 func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	slog.Debug("tui update", "message", fmt.Sprintf("%#v", msg), "type", fmt.Sprintf("%T", msg))
 
@@ -157,6 +420,17 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 	switch msg := msg.(type) {
 	case types.AppQuitMsg:
+		if !msg.Force {
+			if cmd := m.app.Page().GuardClose(types.CmdMsg(types.AppQuitMsg{Force: true})); cmd != nil {
+				// Most dialogs don't allow opening other dialogs on top of them,
+				// so make room for the guard (e.g. quitting from a dialog).
+				if m.app.Dialog().Get(false) != nil {
+					cmd = tea.Sequence(types.CloseActiveDialog(), cmd)
+				}
+				return m, cmd
+			}
+		}
+		m.saveSession()
 		return m, tea.Quit
 	case tea.WindowSizeMsg:
 		m.height = msg.Height
@@ -180,12 +454,21 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case tea.BackgroundColorMsg, tea.ColorProfileMsg:
 		return m, styles.Theme.Update(msg)
 	case tea.KeyMsg:
-		// TODO: temporary bindings, will eventually be handled by the config package.
-		switch {
-		case msg.String() == "[":
-			return m, styles.Theme.PreviousTint()
-		case msg.String() == "]":
-			return m, styles.Theme.NextTint()
+		if m.focused != types.FocusStatusBar && !m.hasInputFocus() {
+			switch {
+			case key.Matches(msg, types.KeyPreviousTint):
+				return m, styles.Theme.PreviousTint()
+			case key.Matches(msg, types.KeyNextTint):
+				return m, styles.Theme.NextTint()
+			case key.Matches(msg, types.KeyThemePicker):
+				if !styles.Theme.SupportsAdvancedColors() {
+					return m, types.SendStatus("themes require a terminal with true color support", types.Warning, 3*time.Second)
+				}
+				if _, ok := m.app.Dialog().Get(false).(*themepicker.Model); ok {
+					return m, nil
+				}
+				return m, types.OpenDialog(themepicker.New(m.app))
+			}
 		}
 
 		switch m.focused {
@@ -207,6 +490,8 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					return m, types.FocusChange(types.FocusStatusBar)
 				case key.Matches(msg, types.KeyHelp):
 					return m, types.OpenDialog(help.New(m.app))
+				case key.Matches(msg, types.KeyUndo):
+					return m, m.runUndo()
 				}
 			}
 			return m, m.app.Page().Update(msg)
@@ -231,6 +516,8 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case types.StatusMsg:
 		cmds = append(cmds, m.statusbar.Update(msg))
 		return m, tea.Batch(cmds...)
+	case types.UndoMsg:
+		return m, m.offerUndo(msg.Undo)
 	case debouncer.InvokeMsg, debouncer.DebounceMsg:
 		return m, m.debouncer.Update(msg)
 	}
@@ -264,6 +551,9 @@ func (m *Model) View() tea.View {
 	view.ForegroundColor = styles.Theme.AppFg()
 	view.WindowTitle = m.appTitle()
 	view.AltScreen = true
+	if config.Get().Mouse.Enabled {
+		view.MouseMode = tea.MouseModeCellMotion
+	}
 
 	if m.width < MinWinWidth || m.height < MinWinHeight {
 		view.Content = lipgloss.NewCanvas(m.width, m.height).Compose(
@@ -0,0 +1,353 @@
+// Copyright (c) Liam Stanley <liam@liam.sh>. All rights reserved. Use of
+// this source code is governed by the MIT license that can be found in
+// the LICENSE file.
+
+package ui
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/lrstanley/vex/internal/api"
+	"github.com/lrstanley/vex/internal/config"
+	"github.com/lrstanley/vex/internal/types"
+	"github.com/lrstanley/vex/internal/ui/pages/auditlog"
+	"github.com/lrstanley/vex/internal/ui/uitest"
+)
+
+func newHarness(t *testing.T) *uitest.Harness {
+	t.Helper()
+	return uitest.New(t, func() tea.Model { return New(api.NewMockClient()) }, uitest.WithSize(100, 24))
+}
+
+func TestTUI(t *testing.T) {
+	t.Run("startup", func(t *testing.T) {
+		h := newHarness(t)
+		h.RequireContains("3 mounts", "kv-v1-1/", "kv-v2-1/", "cubbyhole/", "test-cluster").
+			RequireSnapshot("mounts")
+
+		h.Resize(40, 10).
+			RequireContains("window too small").
+			RequireSnapshot("too-small")
+
+		h.Resize(100, 24).RequireContains("3 mounts")
+	})
+
+	t.Run("navigation", func(t *testing.T) {
+		h := newHarness(t)
+
+		h.Press("down", "enter").
+			RequireContains("mounts › kv-v2-1/", "foo/", "bar", "baz").
+			RequireSnapshot("kv-v2-1")
+
+		h.Press("enter").
+			RequireContains("mounts › kv-v2-1/ › bar", "2 (latest)").
+			RequireSnapshot("versions")
+
+		h.Press("enter").
+			RequireContains("mounts › kv-v2-1/ › bar › v2", "2 secrets", "*****").
+			RequireSnapshot("secret")
+
+		h.Press("esc", "esc").RequireContains("mounts › kv-v2-1/").RequireNotContains("› bar")
+		h.Press("esc").RequireContains("3 mounts")
+	})
+
+	t.Run("commander", func(t *testing.T) {
+		h := newHarness(t)
+
+		h.Press(":").
+			RequireContains("Commands", "goto", "mounts", "aclpolicies").
+			RequireSnapshot("open")
+
+		h.Type("aclpol").
+			RequireNotContains("View mounts").
+			RequireContains("View ACL policies").
+			RequireSnapshot("filtered")
+
+		h.Press("enter").
+			RequireNotContains("Commands").
+			RequireContains("5 acl policies", "test-policy-1").
+			RequireSnapshot("aclpolicies")
+	})
+
+	t.Run("help", func(t *testing.T) {
+		h := newHarness(t)
+
+		h.Press("?").
+			RequireContains("Keybind Help", "refresh", "quit").
+			RequireSnapshot("open")
+
+		h.Press("esc").RequireNotContains("Keybind Help")
+	})
+
+	t.Run("stage-and-apply", func(t *testing.T) {
+		h := newHarness(t)
+		h.Press("down", "enter", "down", "enter", "enter")
+
+		h.Press("enter").RequireContains(`Edit key: "bar"`)
+		h.Press("end").Type("-new").Press("esc", "right", "enter").
+			RequireNotContains("Edit key").
+			RequireContains("bar (modified)", "staged: 1 modified").
+			RequireSnapshot("staged")
+
+		h.Press("ctrl+s").
+			RequireContains("Review changes", "@@ 1 modified @@").
+			RequireSnapshot("review")
+
+		h.Press("enter").
+			RequireNotContains("Review changes", "staged: 1 modified", "(modified)")
+
+		// Status text is cleared after a delay.
+		h.Wait(2500 * time.Millisecond).RequireNotContains("req success")
+	})
+
+	t.Run("conflict", func(t *testing.T) {
+		client := api.NewMockClient()
+		h := uitest.New(t, func() tea.Model { return New(client) }, uitest.WithSize(100, 24))
+		h.Press("down", "enter", "down", "enter", "enter")
+
+		h.Press("enter").RequireContains(`Edit key: "bar"`)
+		h.Press("end").Type("-new").Press("esc", "right", "enter").
+			RequireContains("staged: 1 modified")
+
+		// Someone else writes to the secret before our changes are applied.
+		client.PutSecret("kv-v2-1/", "baz", map[string]any{"foo": "bar", "bar": "baz", "other": "theirs"})
+
+		h.Press("ctrl+s", "enter").
+			RequireContains("Conflict: kv-v2-1/baz", "retry (merge)", "+ other").
+			RequireSnapshot("conflict")
+
+		// Cancelling the dialog keeps our changes staged, on top of theirs.
+		h.Press("esc").
+			RequireNotContains("Conflict:").
+			RequireContains("staged: 1 modified", "other")
+
+		// Quitting from the dialog asks before dropping the staged changes.
+		client.PutSecret("kv-v2-1/", "baz", map[string]any{"foo": "bar", "bar": "baz", "other": "theirs-2"})
+		h.Press("ctrl+s", "enter").RequireContains("Conflict: kv-v2-1/baz")
+		h.Press("ctrl+c").
+			RequireNotContains("Conflict:").
+			RequireContains("Unsaved changes")
+		h.Press("esc").RequireContains("staged: 1 modified")
+
+		h.Press("ctrl+s", "enter").RequireNotContains("Review changes", "staged:")
+
+		data, _ := client.Secret("kv-v2-1/", "baz")
+		if data["bar"] != "baz-new" || data["other"] != "theirs-2" {
+			t.Fatalf("expected merged secret, got %v", data)
+		}
+	})
+
+	t.Run("unsaved-changes", func(t *testing.T) {
+		h := newHarness(t)
+		h.Press("down", "enter", "down", "enter", "enter")
+
+		h.Press("enter").RequireContains(`Edit key: "bar"`)
+		h.Press("end").Type("-new").Press("esc", "right", "enter").
+			RequireContains("staged: 1 modified")
+
+		h.Press("ctrl+c").RequireContains("Unsaved changes", "Discard them?")
+		h.Press("esc").RequireNotContains("Unsaved changes").RequireContains("staged: 1 modified")
+		if h.Quit() {
+			t.Fatal("expected quit to be aborted")
+		}
+
+		// Discarding continues the navigation which was interrupted.
+		h.Press("alt+1").RequireContains("Unsaved changes")
+		h.Press("right", "enter").
+			RequireNotContains("Unsaved changes", "staged:").
+			RequireContains("3 mounts")
+
+		h.Press("ctrl+c")
+		if !h.Quit() {
+			t.Fatal("expected quit without staged changes")
+		}
+	})
+
+	t.Run("seal", func(t *testing.T) {
+		h := newHarness(t)
+
+		h.Press(":").Type("sealstatus").Press("enter").
+			RequireContains("unsealed", "shamir", "3 of 5 key shares", "active").
+			RequireSnapshot("unsealed")
+
+		h.Press("S").RequireContains("Seal node")
+		h.Press("right", "enter").
+			RequireNotContains("Seal node").
+			RequireContains("Unseal progress", "0/3").
+			RequireSnapshot("sealed")
+
+		for i, key := range []string{"key-1", "key-2", "key-3"} {
+			h.Press("u").RequireContains("Unseal key")
+			h.Type(key).Press("esc", "right", "enter").RequireNotContains("Unseal key")
+			if i < 2 {
+				h.RequireContains(fmt.Sprintf("%d/3", i+1))
+			}
+		}
+
+		h.RequireNotContains("Unseal progress").RequireContains("unsealed")
+	})
+
+	t.Run("raft", func(t *testing.T) {
+		h := newHarness(t)
+		path := filepath.Join(t.TempDir(), "raft.snap")
+
+		h.Press(":").Type("raftconfig").Press("enter").
+			RequireContains("3 peers", "raft-1", "10.0.0.3:8201")
+
+		h.Press("w").RequireContains("Save raft snapshot")
+		h.Press("ctrl+u").Type(path).Press("esc", "right", "enter").
+			RequireNotContains("Save raft snapshot")
+		for _, p := range []string{path, path + ".sha256"} {
+			if _, err := os.Stat(p); err != nil {
+				t.Fatalf("expected snapshot file: %v", err)
+			}
+		}
+
+		h.Press("G", "ctrl+d", "right", "enter").RequireContains("2 peers")
+
+		h.Press("o").Type(path).Press("esc", "right", "enter").
+			RequireContains("Restore raft snapshot", "checksum verified")
+
+		// Restoring always requires typed confirmation.
+		h.Type("raft.snap").Press("tab", "tab", "enter").
+			RequireNotContains("Restore raft snapshot").
+			RequireContains("3 peers")
+
+		h.Press("a").
+			RequireContains("raftconfig › autopilot", "healthy, tolerates 0 failures", "leader", "non-voter").
+			RequireSnapshot("autopilot")
+
+		h.Press("s").RequireContains("Autopilot Settings", "10s", "1000")
+	})
+
+	t.Run("cluster", func(t *testing.T) {
+		h := newHarness(t)
+
+		prev := config.Get()
+		t.Cleanup(func() { config.Set(prev) })
+
+		settings := config.DefaultSettings()
+		settings.Profiles = map[string]config.ProfileSettings{
+			"http://127.0.0.1:8200": {Nodes: []string{"http://10.0.0.9:8200"}},
+		}
+		config.Set(settings)
+
+		// Nodes are discovered from the raft peers, in addition to those configured.
+		h.Press(":").Type("cluster").Press("enter").
+			RequireContains("4 nodes, 1 unreachable", "http://10.0.0.1:8200", "leader", "perf-standby", "10.0.0.9").
+			RequireSnapshot("cluster")
+	})
+
+	t.Run("metrics", func(t *testing.T) {
+		h := newHarness(t)
+
+		h.Press(":").Type("metrics").Press("enter").
+			RequireContains("key metrics", "Requests", "Tokens", "43", "vault.core.handle_request").
+			RequireSnapshot("metrics-dashboard")
+
+		// The raw metric browser lists every metric, with its labels.
+		h.Press("v").
+			RequireContains("metrics", "vault.barrier.get", "auth_method=approle").
+			RequireSnapshot("metrics-raw")
+	})
+
+	t.Run("audit", func(t *testing.T) {
+		h := newHarness(t)
+
+		h.Press(":").Type("auditdevices").Press("enter").
+			RequireContains("2 audit devices", "file/", "syslog/", "file_path=/var/log/vault/audit.log").
+			RequireSnapshot("devices")
+
+		// The options depend on the type of device being enabled.
+		h.Press("n").RequireContains("Enable audit device")
+		h.Press("right", "esc", "right", "enter").
+			RequireContains("Enable socket audit device", "Socket type", "Write timeout")
+		h.Press("down", "down", "down").Type("127.0.0.1:9090").Press("esc", "right", "enter").
+			RequireNotContains("Enable socket audit device").
+			RequireContains("3 audit devices", "socket/", "address=127.0.0.1:9090")
+
+		h.Press("H").RequireContains("Audit hash", "Device")
+		h.Press("down").Type("s.secret").Press("esc", "right", "enter").
+			RequireContains("sha256:", "copied to clipboard").
+			RequireSnapshot("hash")
+
+		h.Press("enter", "ctrl+d").RequireContains("Disable audit device", "file/")
+		h.Press("right", "enter").
+			RequireNotContains("Disable audit device").
+			RequireContains("2 audit devices").
+			RequireNotContains("file_path=")
+	})
+
+	t.Run("auditlog", func(t *testing.T) {
+		h := newHarness(t)
+
+		path := filepath.Join(t.TempDir(), "audit.log")
+		err := os.WriteFile(path, []byte(
+			`{"time":"2025-01-02T10:00:00Z","type":"request","auth":{"display_name":"token-ci"},"request":{"id":"1","operation":"read","path":"secret/data/app","mount_point":"secret/"}}
+{"time":"2025-01-02T10:00:01Z","type":"response","auth":{"display_name":"token-ci"},"request":{"id":"1","operation":"read","path":"secret/data/app"}}
+{"time":"2025-01-02T10:05:00Z","type":"request","request":{"id":"2","operation":"update","path":"auth/userpass/login/bob","remote_address":"10.0.0.2"}}
+{"time":"2025-01-02T10:05:01Z","type":"response","error":"invalid credentials","request":{"id":"2","operation":"update","path":"auth/userpass/login/bob"}}
+`), 0o600)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		h.Press(":").Type("auditlog").Press("enter").RequireContains("Open audit log")
+		h.Type(path).Press("esc", "right", "enter").
+			RequireNotContains("Open audit log").
+			RequireContains("requests: 2 requests, 50.0% errors", "secret/data/app", "token-ci", "10.0.0.2").
+			RequireSnapshot("requests")
+
+		h.Press("v").RequireContains("top paths: 2 requests", "auth/userpass/login/bob", "100.0%")
+		h.Press("v").RequireContains("top clients: 2 requests", "token-ci", "10.0.0.2")
+		h.Press("v").RequireContains("errors: 2 requests", "invalid credentials").
+			RequireSnapshot("errors")
+
+		h.Press("v", "/").Type("op:read").Press("enter").Wait(500*time.Millisecond).
+			RequireContains("requests: 1 request, 0.0% errors", "secret/data/app").
+			RequireNotContains("auth/userpass/login/bob")
+
+		// New entries are picked up as they're written.
+		f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, _ = f.WriteString(`{"time":"2025-01-02T10:10:00Z","type":"request","auth":{"display_name":"token-ci"},"request":{"id":"3","operation":"read","path":"secret/data/db"}}` + "\n")
+		_ = f.Close()
+
+		h.Wait(1500*time.Millisecond).RequireContains("requests: 2 requests", "secret/data/db")
+	})
+
+	t.Run("audit-view", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "audit.log")
+		err := os.WriteFile(path, []byte(
+			`{"time":"2025-01-02T10:00:00Z","type":"request","auth":{"display_name":"token-ci"},"request":{"id":"1","operation":"read","path":"secret/data/app"}}`+"\n",
+		), 0o600)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// Viewing an audit log never connects to a Vault server.
+		h := uitest.New(t, func() tea.Model {
+			return NewWithPage(api.NewOfflineClient(), func(app types.AppState) types.Page {
+				return auditlog.New(app, path)
+			})
+		}, uitest.WithSize(100, 24))
+
+		h.RequireContains("requests: 1 request", "secret/data/app", "token-ci").
+			RequireNotContains("test-cluster", "not connected")
+	})
+
+	t.Run("quit", func(t *testing.T) {
+		h := newHarness(t)
+		h.Press("ctrl+c")
+		if !h.Quit() {
+			t.Fatal("expected ctrl+c to quit")
+		}
+	})
+}
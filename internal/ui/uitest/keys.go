@@ -0,0 +1,96 @@
+// Copyright (c) Liam Stanley <liam@liam.sh>. All rights reserved. Use of
+// this source code is governed by the MIT license that can be found in
+// the LICENSE file.
+
+package uitest
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	tea "charm.land/bubbletea/v2"
+)
+
+// namedKeys are the special keys which can be used in key strings, keyed by
+// their name (e.g. "enter", "pgdown" or "f1").
+var namedKeys = func() map[string]rune {
+	codes := []rune{
+		tea.KeyEnter, tea.KeyTab, tea.KeyBackspace, tea.KeyEscape, tea.KeySpace,
+		tea.KeyUp, tea.KeyDown, tea.KeyLeft, tea.KeyRight,
+		tea.KeyInsert, tea.KeyDelete, tea.KeyPgUp, tea.KeyPgDown, tea.KeyHome, tea.KeyEnd,
+		tea.KeyF1, tea.KeyF2, tea.KeyF3, tea.KeyF4, tea.KeyF5, tea.KeyF6,
+		tea.KeyF7, tea.KeyF8, tea.KeyF9, tea.KeyF10, tea.KeyF11, tea.KeyF12,
+	}
+
+	keys := make(map[string]rune, len(codes)+1)
+	for _, code := range codes {
+		keys[tea.Key{Code: code}.Keystroke()] = code
+	}
+	keys["escape"] = tea.KeyEscape
+	return keys
+}()
+
+var namedMods = map[string]tea.KeyMod{
+	"ctrl":  tea.ModCtrl,
+	"alt":   tea.ModAlt,
+	"shift": tea.ModShift,
+	"meta":  tea.ModMeta,
+	"super": tea.ModSuper,
+}
+
+// ParseKey returns the key press for the provided key string, in the same form
+// used by key bindings (e.g. "enter", "ctrl+z", "shift+tab", "G" or ":"). The
+// string of the returned key press always matches the provided key string.
+func ParseKey(s string) (tea.KeyPressMsg, error) {
+	var key tea.Key
+
+	name := s
+	if len(s) > 1 {
+		parts := strings.Split(s, "+")
+		if s[len(s)-1] == '+' { // e.g. "ctrl++".
+			parts = append(parts[:len(parts)-2], "+")
+		}
+
+		name = parts[len(parts)-1]
+		for _, mod := range parts[:len(parts)-1] {
+			v, ok := namedMods[mod]
+			if !ok {
+				return tea.KeyPressMsg{}, fmt.Errorf("invalid key %q: unknown modifier %q", s, mod)
+			}
+			key.Mod |= v
+		}
+	}
+
+	if code, ok := namedKeys[name]; ok {
+		key.Code = code
+		if code == tea.KeySpace && key.Mod == 0 {
+			key.Text = " "
+		}
+	} else {
+		r, size := utf8.DecodeRuneInString(name)
+		if r == utf8.RuneError || size != len(name) {
+			return tea.KeyPressMsg{}, fmt.Errorf("invalid key %q: unknown key %q", s, name)
+		}
+
+		key.Code = r
+		switch {
+		case key.Mod == 0 && unicode.IsUpper(r):
+			// Shifted letters are reported as the lowercase key, with the shifted
+			// character as the text.
+			key.Code = unicode.ToLower(r)
+			key.ShiftedCode = r
+			key.Mod = tea.ModShift
+			key.Text = name
+		case key.Mod == 0:
+			key.Text = name
+		}
+	}
+
+	msg := tea.KeyPressMsg(key)
+	if msg.String() != s {
+		return tea.KeyPressMsg{}, fmt.Errorf("invalid key %q: parsed as %q", s, msg.String())
+	}
+	return msg, nil
+}
@@ -0,0 +1,54 @@
+// Copyright (c) Liam Stanley <liam@liam.sh>. All rights reserved. Use of
+// this source code is governed by the MIT license that can be found in
+// the LICENSE file.
+
+package uitest
+
+import (
+	"testing"
+
+	tea "charm.land/bubbletea/v2"
+)
+
+func TestParseKey(t *testing.T) {
+	tests := []struct {
+		key     string
+		want    tea.Key
+		wantErr bool
+	}{
+		{key: "a", want: tea.Key{Code: 'a', Text: "a"}},
+		{key: ":", want: tea.Key{Code: ':', Text: ":"}},
+		{key: "G", want: tea.Key{Code: 'g', ShiftedCode: 'G', Mod: tea.ModShift, Text: "G"}},
+		{key: "enter", want: tea.Key{Code: tea.KeyEnter}},
+		{key: "esc", want: tea.Key{Code: tea.KeyEscape}},
+		{key: "space", want: tea.Key{Code: tea.KeySpace, Text: " "}},
+		{key: "pgdown", want: tea.Key{Code: tea.KeyPgDown}},
+		{key: "f5", want: tea.Key{Code: tea.KeyF5}},
+		{key: "ctrl+z", want: tea.Key{Code: 'z', Mod: tea.ModCtrl}},
+		{key: "shift+tab", want: tea.Key{Code: tea.KeyTab, Mod: tea.ModShift}},
+		{key: "ctrl+alt+up", want: tea.Key{Code: tea.KeyUp, Mod: tea.ModCtrl | tea.ModAlt}},
+		{key: "ctrl++", want: tea.Key{Code: '+', Mod: tea.ModCtrl}},
+		{key: "", wantErr: true},
+		{key: "foo", wantErr: true},
+		{key: "hyper+a", wantErr: true},
+		{key: "alt+ctrl+a", wantErr: true}, // Modifiers must be in canonical order.
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			got, err := ParseKey(tt.key)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected error, got %#v", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if tea.Key(got) != tt.want {
+				t.Fatalf("got %#v, want %#v", tea.Key(got), tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,512 @@
+// Copyright (c) Liam Stanley <liam@liam.sh>. All rights reserved. Use of
+// this source code is governed by the MIT license that can be found in
+// the LICENSE file.
+
+// Package uitest provides a harness for end-to-end tests of the TUI, which drives
+// a [tea.Model] with a script of window sizes and key presses, without a
+// terminal, and compares rendered frames against golden snapshots.
+//
+// Commands are executed the same way as by a [tea.Program], however the harness
+// waits for all commands to settle after every step, so async results (e.g.
+// [types.ClientMsg] from a fake client) are always rendered before frames are
+// inspected. Timers (commands created with [tea.Tick] or [tea.Every], e.g.
+// spinners, health checks, or clearing status text) don't hold up settling, and
+// their messages are only delivered when time is explicitly let pass with
+// [Harness.Wait]. This keeps frames deterministic, regardless of how long a test
+// takes to run.
+package uitest
+
+import (
+	"fmt"
+	"reflect"
+	"runtime"
+	"runtime/debug"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"charm.land/bubbles/v2/cursor"
+	tea "charm.land/bubbletea/v2"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/lrstanley/vex/internal/config"
+	"github.com/lrstanley/x/charm/steep/snapshot"
+)
+
+const (
+	DefaultWidth   = 120
+	DefaultHeight  = 30
+	DefaultTimeout = 10 * time.Second
+)
+
+// Option configures a [Harness].
+type Option func(h *Harness)
+
+// WithSize sets the initial window size.
+func WithSize(width, height int) Option {
+	return func(h *Harness) {
+		h.width, h.height = width, height
+	}
+}
+
+// WithTimeout sets how long commands have to settle after each step, before the
+// test fails. Defaults to [DefaultTimeout].
+func WithTimeout(d time.Duration) Option {
+	return func(h *Harness) {
+		h.timeout = d
+	}
+}
+
+// task tracks a running command.
+type task struct {
+	name     string
+	key      []int // Position in the tree of commands, used to order results.
+	parent   *task // The task which is waiting on this task, if part of a batch.
+	children int   // Number of child tasks created, used for their keys.
+	timer    bool  // Set while waiting on a timer, until its message is processed.
+	result   *result
+}
+
+// result is a message returned by a command.
+type result struct {
+	msg     tea.Msg
+	panic   string        // Set if the command panicked, with the stack trace.
+	applied chan struct{} // Closed once the message has been processed.
+}
+
+// Harness drives a [tea.Model] in tests. It isn't safe for concurrent use, and
+// each harness should only be used by the test which created it.
+type Harness struct {
+	tb      testing.TB
+	model   tea.Model
+	width   int
+	height  int
+	timeout time.Duration
+	view    string
+	quit    bool
+
+	mu      sync.Mutex
+	tasks   []*task
+	seq     int           // Number of top-level tasks created.
+	changed chan struct{} // Signaled when a task has returned a result, or finished.
+	done    chan struct{}
+}
+
+// New creates a new harness for the model returned by fn, initializing it and
+// sending the initial window size. Before fn is called, the config directory
+// (and home directory) are pointed at a temporary directory, and the default
+// settings and an empty state are loaded, so tests never read or modify the
+// users config, state or journal, or see changes from previous tests. As such,
+// tests using the harness can't be run in parallel.
+func New(tb testing.TB, fn func() tea.Model, opts ...Option) *Harness {
+	tb.Helper()
+
+	dir := tb.TempDir()
+	tb.Setenv("XDG_CONFIG_HOME", dir)
+	tb.Setenv("HOME", dir)
+
+	if err := config.Load(); err != nil {
+		tb.Fatalf("failed to load settings: %v", err)
+	}
+	if err := config.LoadState(); err != nil {
+		tb.Fatalf("failed to load state: %v", err)
+	}
+
+	h := &Harness{
+		tb:      tb,
+		width:   DefaultWidth,
+		height:  DefaultHeight,
+		timeout: DefaultTimeout,
+		changed: make(chan struct{}, 1),
+		done:    make(chan struct{}),
+	}
+
+	for _, opt := range opts {
+		opt(h)
+	}
+
+	tb.Cleanup(func() { close(h.done) })
+
+	h.model = fn()
+	h.exec(nil, h.model.Init(), nil)
+	return h.Resize(h.width, h.height)
+}
+
+// notify signals that the state of a task has changed.
+func (h *Harness) notify() {
+	select {
+	case h.changed <- struct{}{}:
+	default:
+	}
+}
+
+// exec runs cmd in the background, the same way as [tea.Program] would. If
+// provided, wg is done once the command has returned.
+func (h *Harness) exec(parent *task, cmd tea.Cmd, wg *sync.WaitGroup) {
+	if cmd == nil {
+		return
+	}
+
+	t := &task{name: funcName(cmd), parent: parent}
+	h.mu.Lock()
+	if parent == nil {
+		h.seq++
+		t.key = []int{h.seq}
+	} else {
+		parent.children++
+		t.key = append(slices.Clone(parent.key), parent.children)
+	}
+	h.tasks = append(h.tasks, t)
+	h.mu.Unlock()
+
+	if wg != nil {
+		wg.Add(1)
+	}
+
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				h.send(t, result{panic: fmt.Sprintf("%v\n%s", r, debug.Stack())})
+			}
+
+			h.mu.Lock()
+			h.tasks = slices.DeleteFunc(h.tasks, func(v *task) bool { return v == t })
+			h.mu.Unlock()
+			h.notify()
+
+			if wg != nil {
+				wg.Done()
+			}
+		}()
+		h.run(t, cmd)
+	}()
+}
+
+// run invokes cmd, delivering the resulting message(s). Batches are run
+// concurrently (each as their own task), and sequences in order, waiting for
+// each message to be processed before invoking the next command. Same as
+// [tea.Program], run only returns once all batches have returned, so sequences
+// containing batches also wait for any timers in those batches.
+func (h *Harness) run(t *task, cmd tea.Cmd) {
+	if isTimer(cmd) {
+		h.setTimer(t, true)
+	}
+
+	switch msg := cmd().(type) {
+	case nil:
+		h.setTimer(t, false)
+	case tea.BatchMsg:
+		h.setTimer(t, false)
+		var wg sync.WaitGroup
+		for _, cmd := range msg {
+			h.exec(t, cmd, &wg)
+		}
+		wg.Wait()
+	default:
+		if cmds, ok := sequence(msg); ok {
+			h.setTimer(t, false)
+			for _, cmd := range cmds {
+				if cmd != nil {
+					h.run(t, cmd)
+				}
+			}
+			return
+		}
+		h.send(t, result{msg: msg})
+	}
+}
+
+// timerFuncs are the name prefixes of functions which return timer commands.
+var timerFuncs = []string{
+	reflect.TypeFor[tea.Cmd]().PkgPath() + ".Tick.",
+	reflect.TypeFor[tea.Cmd]().PkgPath() + ".Every.",
+	reflect.TypeFor[cursor.Model]().PkgPath() + ".(*Model).Blink.",
+}
+
+// funcName returns the name of the function backing cmd.
+func funcName(cmd tea.Cmd) string {
+	if fn := runtime.FuncForPC(reflect.ValueOf(cmd).Pointer()); fn != nil {
+		return fn.Name()
+	}
+	return ""
+}
+
+// isTimer returns true if cmd was created with [tea.Tick], [tea.Every], or is a
+// cursor blink.
+func isTimer(cmd tea.Cmd) bool {
+	name := funcName(cmd)
+	return slices.ContainsFunc(timerFuncs, func(prefix string) bool {
+		return strings.HasPrefix(name, prefix)
+	})
+}
+
+// setTimer sets whether t is waiting on a timer.
+func (h *Harness) setTimer(t *task, v bool) {
+	h.mu.Lock()
+	t.timer = v
+	h.mu.Unlock()
+	h.notify()
+}
+
+// sequence returns the commands of a [tea.Sequence], which uses an unexported
+// message type.
+func sequence(msg tea.Msg) ([]tea.Cmd, bool) {
+	v := reflect.ValueOf(msg)
+	if v.Kind() != reflect.Slice || v.Type().Name() != "sequenceMsg" || !v.CanConvert(reflect.TypeFor[[]tea.Cmd]()) {
+		return nil, false
+	}
+	return v.Convert(reflect.TypeFor[[]tea.Cmd]()).Interface().([]tea.Cmd), true //nolint:errcheck
+}
+
+// send delivers the result of t to the harness, waiting until it has been
+// processed.
+func (h *Harness) send(t *task, r result) {
+	r.applied = make(chan struct{})
+
+	h.mu.Lock()
+	t.result = &r
+	h.mu.Unlock()
+	h.notify()
+
+	select {
+	case <-r.applied:
+	case <-h.done:
+	}
+}
+
+// next returns the next result to be processed. Unless timers is true, results
+// of timers are skipped, and nothing is returned until all other commands have
+// either returned a result, or are waiting on timers, so results are always
+// processed in the same order. busy is true if commands are still running.
+func (h *Harness) next(timers bool) (r *result, busy bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	children := make(map[*task][]*task, len(h.tasks))
+	for _, v := range h.tasks {
+		if v.parent != nil {
+			children[v.parent] = append(children[v.parent], v)
+		}
+	}
+
+	var idle func(v *task) bool
+	idle = func(v *task) bool {
+		if v.timer || v.result != nil {
+			return true
+		}
+		c := children[v]
+		return len(c) > 0 && !slices.ContainsFunc(c, func(v *task) bool { return !idle(v) })
+	}
+
+	busy = slices.ContainsFunc(h.tasks, func(v *task) bool { return !idle(v) })
+	if busy && !timers {
+		return nil, true
+	}
+
+	var t *task
+	for _, v := range h.tasks {
+		if v.result == nil || (v.timer && !timers && v.result.panic == "") {
+			continue
+		}
+		if t == nil || slices.Compare(v.key, t.key) < 0 {
+			t = v
+		}
+	}
+
+	if t != nil {
+		r, t.result = t.result, nil
+		t.timer = false
+	}
+	return r, busy
+}
+
+// running returns the names of all running commands.
+func (h *Harness) running() []string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	names := make([]string, 0, len(h.tasks))
+	for _, t := range h.tasks {
+		names = append(names, t.name)
+	}
+	return names
+}
+
+// apply processes a result, updating the model.
+func (h *Harness) apply(r *result) {
+	h.tb.Helper()
+	defer close(r.applied)
+
+	if r.panic != "" {
+		h.tb.Fatalf("command panicked: %s", r.panic)
+	}
+
+	if _, ok := r.msg.(tea.QuitMsg); ok {
+		h.quit = true
+	}
+	if h.quit {
+		return
+	}
+
+	var cmd tea.Cmd
+	h.model, cmd = h.model.Update(r.msg)
+	h.exec(nil, cmd, nil)
+}
+
+// settle processes results until no commands (apart from timers) are running,
+// then renders the view.
+func (h *Harness) settle() {
+	h.tb.Helper()
+
+	deadline := time.After(h.timeout)
+	for {
+		r, busy := h.next(false)
+		if r != nil {
+			h.apply(r)
+			continue
+		}
+		if !busy {
+			break
+		}
+
+		select {
+		case <-h.changed:
+		case <-deadline:
+			h.tb.Fatalf("commands didn't settle within %s, running: %s", h.timeout, strings.Join(h.running(), ", "))
+		}
+	}
+
+	h.view = ansi.Strip(h.model.View().Content)
+}
+
+// Send sends the provided messages to the model, in order, waiting for the
+// results of each to settle.
+func (h *Harness) Send(msgs ...tea.Msg) *Harness {
+	h.tb.Helper()
+	for _, msg := range msgs {
+		h.apply(&result{msg: msg, applied: make(chan struct{})})
+		h.settle()
+	}
+	return h
+}
+
+// Resize sends a window size message.
+func (h *Harness) Resize(width, height int) *Harness {
+	h.tb.Helper()
+	h.width, h.height = width, height
+	return h.Send(tea.WindowSizeMsg{Width: width, Height: height})
+}
+
+// Press sends the provided key presses (see [ParseKey] for the format), in order.
+func (h *Harness) Press(keys ...string) *Harness {
+	h.tb.Helper()
+	for _, k := range keys {
+		msg, err := ParseKey(k)
+		if err != nil {
+			h.tb.Fatal(err)
+		}
+		h.Send(msg)
+	}
+	return h
+}
+
+// Type sends a key press for each character of text.
+func (h *Harness) Type(text string) *Harness {
+	h.tb.Helper()
+	for _, r := range text {
+		if r == ' ' {
+			h.Send(tea.KeyPressMsg{Code: tea.KeySpace, Text: " "})
+			continue
+		}
+		h.Send(tea.KeyPressMsg{Code: r, Text: string(r)})
+	}
+	return h
+}
+
+// Wait lets d pass, delivering the messages of any timers which fire in the
+// meantime (e.g. debounced input, or status text being cleared).
+func (h *Harness) Wait(d time.Duration) *Harness {
+	h.tb.Helper()
+
+	deadline := time.After(d)
+	for {
+		if r, _ := h.next(true); r != nil {
+			h.apply(r)
+			continue
+		}
+
+		select {
+		case <-h.changed:
+			continue
+		case <-deadline:
+		}
+		break
+	}
+
+	h.settle()
+	return h
+}
+
+// Model returns the current model.
+func (h *Harness) Model() tea.Model {
+	return h.model
+}
+
+// View returns the last rendered frame, without ANSI escape codes.
+func (h *Harness) View() string {
+	return h.view
+}
+
+// Quit returns true if the model has requested to quit.
+func (h *Harness) Quit() bool {
+	return h.quit
+}
+
+// RequireContains fails the test if the last rendered frame doesn't contain all
+// of the provided strings.
+func (h *Harness) RequireContains(s ...string) *Harness {
+	h.tb.Helper()
+	for _, v := range s {
+		if !strings.Contains(h.view, v) {
+			h.tb.Fatalf("expected view to contain %q, got:\n%s", v, h.view)
+		}
+	}
+	return h
+}
+
+// RequireNotContains fails the test if the last rendered frame contains any of
+// the provided strings.
+func (h *Harness) RequireNotContains(s ...string) *Harness {
+	h.tb.Helper()
+	for _, v := range s {
+		if strings.Contains(h.view, v) {
+			h.tb.Fatalf("expected view to not contain %q, got:\n%s", v, h.view)
+		}
+	}
+	return h
+}
+
+// RequireSnapshot compares the last rendered frame against the golden snapshot
+// at "testdata/<test name>/<name>.snap", using [snapshot.RequireEqual] (same as
+// component tests), within a subtest of the provided name. Missing or outdated
+// snapshots fail the test, unless snapshots are being updated (see the
+// "test:update" task).
+func (h *Harness) RequireSnapshot(name string) *Harness {
+	h.tb.Helper()
+
+	t, ok := h.tb.(interface {
+		Run(name string, fn func(t *testing.T)) bool
+	})
+	if !ok {
+		h.tb.Fatalf("snapshots require a *testing.T, got %T", h.tb)
+	}
+
+	view := h.view
+	if !t.Run(name, func(t *testing.T) {
+		snapshot.RequireEqual(t, view)
+	}) {
+		h.tb.FailNow()
+	}
+	return h
+}
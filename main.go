@@ -19,7 +19,9 @@ import (
 	"github.com/lrstanley/vex/internal/config"
 	"github.com/lrstanley/vex/internal/logging"
 	"github.com/lrstanley/vex/internal/report"
+	"github.com/lrstanley/vex/internal/types"
 	"github.com/lrstanley/vex/internal/ui"
+	"github.com/lrstanley/vex/internal/ui/pages/auditlog"
 	"github.com/lrstanley/x/logging/handlers"
 )
 
@@ -44,9 +46,20 @@ type Flags struct {
 	Logging               logging.Flags `embed:""`
 	EnablePprof           bool          `help:"enable pprof debugging server"`
 	MaxConcurrentRequests int           `env:"MAX_CONCURRENT_REQUESTS" default:"10" help:"maximum number of concurrent requests to the vault server"`
+	AllowInsecureEditor   bool          `env:"ALLOW_INSECURE_EDITOR" help:"allow editing secrets in an external editor using the default temp directory, when no memory-backed location (e.g. /dev/shm) is available"`
+	ReadOnly              bool          `env:"READ_ONLY" help:"refuse all operations which modify the vault server (e.g. writing or deleting secrets)"`
+	Record                string        `type:"path" xor:"cassette" help:"record all requests to the vault server (and their responses) to a new cassette file, with tokens and secret values redacted, for reproducing issues"`
+	Replay                string        `type:"existingfile" xor:"cassette" help:"serve all requests from a cassette file recorded with --record, rather than a vault server"`
 
 	Report struct{} `cmd:"" help:"print system information for issue reporting"`
-	UI     struct{} `cmd:"" default:"1" hidden:"" help:"start the terminal UI (default)"`
+	Audit  struct {
+		View struct {
+			File string `arg:"" type:"existingfile" help:"path to the audit log, as written by a file audit device"`
+		} `cmd:"" help:"view and analyze a local file audit log, following it as it's written"`
+	} `cmd:"" help:"audit log tools"`
+	UI struct {
+		api.MockFlags `embed:""`
+	} `cmd:"" default:"withargs" hidden:"" help:"start the terminal UI (default)"`
 }
 
 func main() {
@@ -66,6 +79,33 @@ func main() {
 	logCloser := logging.New(config.AppVersion, cli.Flags.Logging)
 	defer logCloser() //nolint:errcheck
 
+	var startupErrors []error
+
+	if err := config.Load(); err != nil {
+		startupErrors = append(startupErrors, err)
+	}
+
+	if err := config.LoadState(); err != nil {
+		slog.Warn("failed to load state", "error", err)
+	}
+
+	if cli.Flags.AllowInsecureEditor || cli.Flags.ReadOnly {
+		settings := *config.Get()
+		settings.Editor.AllowInsecureTempDir = settings.Editor.AllowInsecureTempDir || cli.Flags.AllowInsecureEditor
+		settings.ReadOnly = settings.ReadOnly || cli.Flags.ReadOnly
+		config.Set(&settings)
+	}
+
+	if err := types.ApplyKeyBindings(config.Get().KeyBindings); err != nil {
+		startupErrors = append(startupErrors, err)
+	}
+
+	if removed, serr := config.SweepTempFiles(); serr != nil {
+		slog.Warn("failed to sweep stale temp files", "error", serr)
+	} else if removed > 0 {
+		slog.Info("swept stale temp files", "count", removed)
+	}
+
 	if cli.Flags.EnablePprof {
 		go func() {
 			slog.Info("pprof server starting on http://localhost:6060")
@@ -76,15 +116,33 @@ func main() {
 		}()
 	}
 
-	client, err := api.NewClient(slog.Default(), cli.Flags.MaxConcurrentRequests)
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "failed to create vault client: %v\n", err)
-		returnCode = 1
-		return
+	var client types.Client
+	var initialPage func(app types.AppState) types.Page
+
+	if cli.Context.Command() == "audit view <file>" {
+		// Audit logs are viewed offline, without connecting to (or polling) a Vault
+		// server.
+		client = api.NewOfflineClient()
+		initialPage = func(app types.AppState) types.Page {
+			return auditlog.New(app, cli.Flags.Audit.View.File)
+		}
+	} else {
+		var closer func() error
+		var err error
+
+		client, closer, err = newClient()
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			returnCode = 1
+			return
+		}
+		if closer != nil {
+			defer closer() //nolint:errcheck
+		}
 	}
 
 	tui := tea.NewProgram(
-		ui.New(client),
+		ui.NewWithPage(client, initialPage, startupErrors...),
 		tea.WithFilter(ui.DownsampleMouseEvents),
 		tea.WithFPS(30),
 	)
@@ -92,7 +150,7 @@ func main() {
 	panicCloser := handlers.NewPanicCatcher(handlers.PanicPathName(config.GetConfigPath(), config.AppName))
 	defer panicCloser(tui.Kill) //nolint:errcheck
 
-	_, err = tui.Run()
+	_, err := tui.Run()
 	if err != nil {
 		if errors.Is(err, tea.ErrProgramPanic) {
 			panic(err)
@@ -102,3 +160,58 @@ func main() {
 		returnCode = 1
 	}
 }
+
+// newClient creates the client used to connect to the Vault server, based on the
+// provided flags. closer, if not nil, must be called once the client is no longer
+// used.
+func newClient() (client types.Client, closer func() error, err error) {
+	var opts []api.ClientOption
+
+	switch {
+	case cli.Flags.UI.Mock && (cli.Flags.Record != "" || cli.Flags.Replay != ""):
+		return nil, nil, errors.New("--mock can't be used with --record or --replay")
+	case cli.Flags.Record != "":
+		recorder, rerr := api.NewRecorder(cli.Flags.Record)
+		if rerr != nil {
+			return nil, nil, fmt.Errorf("failed to start recording: %w", rerr)
+		}
+		closer = recorder.Close
+
+		slog.Info("recording requests", "path", cli.Flags.Record)
+		opts = append(opts, api.WithRecorder(recorder))
+	case cli.Flags.Replay != "":
+		replayer, rerr := api.LoadCassette(cli.Flags.Replay)
+		if rerr != nil {
+			return nil, nil, fmt.Errorf("failed to load replay: %w", rerr)
+		}
+
+		slog.Info("replaying requests", "path", cli.Flags.Replay, "address", replayer.Address())
+		opts = append(opts, api.WithReplayer(replayer))
+	}
+
+	if cli.Flags.UI.Mock {
+		slog.Info("using mock vault server")
+		client = api.NewMockClientFromFlags(cli.Flags.UI.MockFlags)
+	} else {
+		client, err = api.NewClient(slog.Default(), cli.Flags.MaxConcurrentRequests, opts...)
+		if err != nil {
+			if closer != nil {
+				_ = closer()
+			}
+			return nil, nil, fmt.Errorf("failed to create vault client: %w", err)
+		}
+	}
+
+	// Read-only mode wraps the journal, so refused operations aren't recorded, and
+	// undo wraps both, so that nothing is offered for refused operations, while
+	// undoing an operation is still recorded. Replayed operations never reach a
+	// Vault server (and neither do mocked operations), so they aren't recorded.
+	if cli.Flags.Replay == "" && !cli.Flags.UI.Mock {
+		client = api.NewJournalClient(client)
+	}
+	if config.Get().Profile(client.Profile()).ReadOnly {
+		slog.Info("read-only mode enabled", "profile", client.Profile())
+		client = api.NewReadOnlyClient(client)
+	}
+	return api.NewUndoClient(client), closer, nil
+}